@@ -0,0 +1,42 @@
+package main
+
+import "fmt"
+
+// 作弊类型 - 客户端发来的、应由服务器决定的数据
+const (
+	CHEAT_CLIENT_HEALTH_CHANGE = 1 // 客户端试图修改生命值
+)
+
+// cheatTypeNames 作弊类型名称
+var cheatTypeNames = map[uint8]string{
+	CHEAT_CLIENT_HEALTH_CHANGE: "客户端修改生命值",
+}
+
+// ReportCheat 记录会话的作弊嫌疑 - 只记录不执行，由GM根据次数处理
+func (ws *WorldSession) ReportCheat(cheatType uint8, detail string) {
+	ws.mutex.Lock()
+	if ws.cheatReports == nil {
+		ws.cheatReports = make(map[uint8]uint32)
+	}
+	ws.cheatReports[cheatType]++
+	count := ws.cheatReports[cheatType]
+	ws.mutex.Unlock()
+
+	fmt.Printf("[反作弊] 会话 %d(%s) %s: %s (第%d次)\n",
+		ws.id, ws.accountName, cheatTypeNames[cheatType], detail, count)
+}
+
+// GetCheatReports 获取某类作弊嫌疑的次数
+func (ws *WorldSession) GetCheatReports(cheatType uint8) uint32 {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+	return ws.cheatReports[cheatType]
+}
+
+// HandleClientHealthChangeOpcode 客户端上报的生命值变化 - 生命值只由服务器结算，忽略并标记作弊
+func (ws *WorldSession) HandleClientHealthChangeOpcode(packet *WorldPacket) {
+	targetGuid := packet.ReadUint64()
+	damage := packet.ReadUint32()
+	ws.ReportCheat(CHEAT_CLIENT_HEALTH_CHANGE,
+		fmt.Sprintf("操作码 0x%X 目标 %d 数值 %d", packet.GetOpcode(), targetGuid, damage))
+}
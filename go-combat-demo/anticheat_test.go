@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestClientReportedDamageIsFlaggedAsCheat(t *testing.T) {
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	player := newThreatTestUnit("player", 0)
+	session := NewWorldSession(1, "cheater", nil, world)
+	session.SetPlayer(player)
+
+	packet := NewWorldPacket(CMSG_DAMAGE_TAKEN)
+	packet.WriteUint64(player.GetGUID())
+	packet.WriteUint32(600)
+	session.handlePacket(packet)
+
+	if player.GetHealth() != 1000 {
+		t.Fatalf("client reported damage should not change health")
+	}
+	if session.GetCheatReports(CHEAT_CLIENT_HEALTH_CHANGE) != 1 {
+		t.Fatalf("client reported damage should be flagged as a cheat")
+	}
+}
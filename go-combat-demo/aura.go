@@ -0,0 +1,479 @@
+package main
+
+import "fmt"
+
+// 光环类型 - 基于AzerothCore的AuraType (SpellAuraDefines.h)
+type AuraType int
+
+const (
+	SPELL_AURA_NONE                        AuraType = 0   // 无
+	SPELL_AURA_PERIODIC_DAMAGE             AuraType = 3   // 周期伤害(DOT)
+	SPELL_AURA_MOD_CONFUSE                 AuraType = 5   // 迷惑 - 变形术
+	SPELL_AURA_MOD_FEAR                    AuraType = 7   // 恐惧
+	SPELL_AURA_PERIODIC_HEAL               AuraType = 8   // 周期治疗(HOT)
+	SPELL_AURA_MOD_STUN                    AuraType = 12  // 昏迷
+	SPELL_AURA_MOD_DAMAGE_DONE             AuraType = 13  // 伤害加成
+	SPELL_AURA_MOD_DAMAGE_TAKEN            AuraType = 14  // 受到伤害修正
+	SPELL_AURA_MOD_ROOT                    AuraType = 26  // 定身
+	SPELL_AURA_MOD_STAT                    AuraType = 29  // 属性修正
+	SPELL_AURA_MOD_INCREASE_SPEED          AuraType = 31  // 移动速度提高(百分比)
+	SPELL_AURA_MOD_DECREASE_SPEED          AuraType = 33  // 移动速度降低(百分比)
+	SPELL_AURA_SCHOOL_IMMUNITY             AuraType = 39  // 学派免疫(MiscValue为学派掩码)
+	SPELL_AURA_MOD_SPELL_HIT_CHANCE        AuraType = 55  // 法术命中率修正
+	SPELL_AURA_MOD_CASTING_SPEED_NOT_STACK AuraType = 65  // 施法速度修正
+	SPELL_AURA_REFLECT_SPELLS              AuraType = 74  // 法术反射(数值为反射几率)
+	SPELL_AURA_MECHANIC_IMMUNITY           AuraType = 77  // 机制免疫(MiscValue为机制)
+	SPELL_AURA_MOD_DAMAGE_PERCENT_DONE     AuraType = 79  // 造成伤害百分比修正
+	SPELL_AURA_MOD_ATTACK_POWER            AuraType = 99  // 攻击强度修正
+	SPELL_AURA_MOD_HEALING                 AuraType = 115 // 受到治疗修正
+	SPELL_AURA_MOD_HEALING_DONE            AuraType = 135 // 治疗加成
+	SPELL_AURA_MOD_MELEE_HASTE             AuraType = 138 // 攻击速度修正
+)
+
+// 法术机制 - 基于AzerothCore的Mechanics
+const (
+	MECHANIC_NONE      = 0  // 无
+	MECHANIC_FEAR      = 5  // 恐惧
+	MECHANIC_ROOT      = 7  // 定身
+	MECHANIC_SILENCE   = 9  // 沉默
+	MECHANIC_STUN      = 12 // 昏迷
+	MECHANIC_FREEZE    = 13 // 冻结
+	MECHANIC_POLYMORPH = 17 // 变形
+)
+
+// 光环打断标志 - 基于AzerothCore的SpellAuraInterruptFlags
+const (
+	AURA_INTERRUPT_FLAG_HITBYSPELL  = 0x00000001 // 被法术命中时移除
+	AURA_INTERRUPT_FLAG_TAKE_DAMAGE = 0x00000002 // 受到伤害时移除
+)
+
+// 光环移除方式 - 基于AzerothCore的AuraRemoveMode
+const (
+	AURA_REMOVE_BY_DEFAULT   = 1 // 默认移除
+	AURA_REMOVE_BY_INTERRUPT = 2 // 被打断(如受到伤害)
+	AURA_REMOVE_BY_CANCEL    = 3 // 主动取消
+	AURA_REMOVE_BY_EXPIRE    = 5 // 持续时间结束
+	AURA_REMOVE_BY_DEATH     = 6 // 单位死亡
+)
+
+// 递减分组 - 基于AzerothCore的DiminishingGroup，同组控制效果共享递减
+const (
+	DIMINISHING_NONE            = 0
+	DIMINISHING_CONTROLLED_STUN = 1 // 昏迷
+	DIMINISHING_CONTROLLED_ROOT = 2 // 定身
+	DIMINISHING_FEAR            = 3 // 恐惧
+	DIMINISHING_POLYMORPH       = 4 // 变形
+)
+
+// 递减等级 - 基于AzerothCore的DiminishingLevels
+const (
+	DIMINISHING_LEVEL_1      = 0 // 完整持续时间
+	DIMINISHING_LEVEL_2      = 1 // 50%持续时间
+	DIMINISHING_LEVEL_3      = 2 // 25%持续时间
+	DIMINISHING_LEVEL_IMMUNE = 3 // 免疫
+
+	DIMINISHING_RETURNS_WINDOW = 18000 // 递减重置时间(毫秒) - 18秒内未再受到同组控制则重置
+)
+
+// Aura 光环实例 - 基于AzerothCore的Aura/AuraEffect
+type Aura struct {
+	id             uint32     // 法术ID
+	spellInfo      *SpellInfo // 来源法术
+	caster         IUnit      // 施法者
+	target         IUnit      // 光环所在单位
+	duration       uint32     // 剩余持续时间(毫秒)
+	maxDuration    uint32     // 总持续时间(毫秒)
+	auraType       AuraType   // 光环类型
+	value          int32      // 效果数值
+	miscValue      int32      // 附加数值(免疫的学派掩码或机制)
+	mechanic       int        // 法术机制
+	interruptFlags uint32     // 打断标志
+
+	effect        *SpellEffect // 来源效果
+	amplitude     uint32       // 周期光环的触发间隔(毫秒)，0表示不是周期光环
+	periodicTimer uint32       // 距离上次触发经过的时间
+}
+
+// DiminishingReturn 单位在某个递减分组上的递减状态
+type DiminishingReturn struct {
+	level      int    // 当前递减等级
+	resetTimer uint32 // 距离递减重置的剩余时间(毫秒)
+}
+
+// NewAura 根据法术效果创建光环
+func NewAura(spellInfo *SpellInfo, effect *SpellEffect, caster, target IUnit, duration uint32) *Aura {
+	aura := &Aura{
+		id:             spellInfo.ID,
+		spellInfo:      spellInfo,
+		caster:         caster,
+		target:         target,
+		duration:       duration,
+		maxDuration:    duration,
+		auraType:       AuraType(effect.ApplyAuraName),
+		value:          effect.BasePoints,
+		miscValue:      effect.MiscValue,
+		mechanic:       effect.Mechanic,
+		interruptFlags: spellInfo.AuraInterruptFlags,
+		effect:         effect,
+	}
+	if effect.Amplitude > 0 {
+		aura.amplitude = uint32(effect.Amplitude)
+	}
+	return aura
+}
+
+func (a *Aura) GetId() uint32          { return a.id }
+func (a *Aura) GetCaster() IUnit       { return a.caster }
+func (a *Aura) GetDuration() uint32    { return a.duration }
+func (a *Aura) GetMaxDuration() uint32 { return a.maxDuration }
+func (a *Aura) GetAuraType() AuraType  { return a.auraType }
+
+// getAuraUnitState 控制类光环对应的单位状态
+func getAuraUnitState(auraType AuraType) uint32 {
+	switch auraType {
+	case SPELL_AURA_MOD_STUN:
+		return UNIT_STATE_STUNNED
+	case SPELL_AURA_MOD_ROOT:
+		return UNIT_STATE_ROOTED
+	case SPELL_AURA_MOD_FEAR:
+		return UNIT_STATE_FLEEING
+	case SPELL_AURA_MOD_CONFUSE:
+		return UNIT_STATE_CONFUSED
+	}
+	return 0
+}
+
+// AddAura 应用光环 - 同一施法者的同一法术效果只刷新持续时间
+func (u *Unit) AddAura(aura *Aura) {
+	for _, existing := range u.auras {
+		if existing.id == aura.id && existing.auraType == aura.auraType &&
+			existing.caster != nil && aura.caster != nil && existing.caster.GetGUID() == aura.caster.GetGUID() {
+			existing.duration = aura.duration
+			existing.maxDuration = aura.maxDuration
+			if u.world != nil {
+				u.world.BroadcastAuraUpdate(u, existing, false)
+			}
+			return
+		}
+	}
+
+	u.auras = append(u.auras, aura)
+	u.handleAuraEffect(aura, true)
+	aura.callAuraApplyScripts()
+
+	if u.world != nil {
+		u.world.BroadcastAuraUpdate(u, aura, false)
+	}
+
+	fmt.Printf("%s 获得了 %s 效果 (%.1f秒)\n", u.name, aura.spellInfo.Name, float32(aura.duration)/1000)
+}
+
+// RemoveAura 移除光环
+func (u *Unit) RemoveAura(aura *Aura, removeMode int) {
+	found := false
+	for i, existing := range u.auras {
+		if existing == aura {
+			u.auras = append(u.auras[:i], u.auras[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+
+	u.handleAuraEffect(aura, false)
+	aura.callAuraRemoveScripts(removeMode)
+
+	// 同组控制全部结束后才开始递减重置计时 - 基于AzerothCore的Unit::ApplyDiminishingAura
+	if group := getDiminishingReturnsGroup(aura.auraType); group != DIMINISHING_NONE && !u.hasDiminishingAura(group) {
+		if dr, exists := u.diminishing[group]; exists {
+			dr.resetTimer = DIMINISHING_RETURNS_WINDOW
+		}
+	}
+
+	if u.world != nil {
+		u.world.BroadcastAuraUpdate(u, aura, true)
+	}
+
+	switch removeMode {
+	case AURA_REMOVE_BY_INTERRUPT:
+		fmt.Printf("%s 的 %s 效果被打破\n", u.name, aura.spellInfo.Name)
+	case AURA_REMOVE_BY_EXPIRE:
+		fmt.Printf("%s 的 %s 效果消失了\n", u.name, aura.spellInfo.Name)
+	}
+}
+
+// handleAuraEffect 应用/移除光环带来的单位状态
+func (u *Unit) handleAuraEffect(aura *Aura, apply bool) {
+	state := getAuraUnitState(aura.auraType)
+	if state == 0 {
+		return
+	}
+
+	if apply {
+		u.AddUnitState(state)
+		// 昏迷、恐惧、变形会打断正在施放的法术
+		if state&UNIT_STATE_LOST_CONTROL != 0 {
+			u.InterruptNonMeleeSpells(false)
+		}
+		// 恐惧期间远离施法者逃跑
+		if aura.auraType == SPELL_AURA_MOD_FEAR {
+			u.motionMaster.MoveFleeing(aura.caster, 0)
+		}
+		return
+	}
+
+	// 同类型光环全部移除后才清除状态
+	if !u.HasAuraType(aura.auraType) {
+		u.ClearUnitState(state)
+		if aura.auraType == SPELL_AURA_MOD_FEAR {
+			u.motionMaster.ClearSlot(MOTION_SLOT_CONTROLLED)
+		}
+	}
+}
+
+// RemoveAurasDueToSpell 移除指定法术产生的所有光环
+func (u *Unit) RemoveAurasDueToSpell(spellId uint32) {
+	for _, aura := range append([]*Aura(nil), u.auras...) {
+		if aura.id == spellId {
+			u.RemoveAura(aura, AURA_REMOVE_BY_DEFAULT)
+		}
+	}
+}
+
+// RemoveAurasWithInterruptFlags 移除带有指定打断标志的光环 - 基于AzerothCore的Unit::RemoveAurasWithInterruptFlags
+func (u *Unit) RemoveAurasWithInterruptFlags(flags uint32) {
+	for _, aura := range append([]*Aura(nil), u.auras...) {
+		if aura.interruptFlags&flags != 0 {
+			u.RemoveAura(aura, AURA_REMOVE_BY_INTERRUPT)
+		}
+	}
+}
+
+// RemoveAllAuras 移除所有光环
+func (u *Unit) RemoveAllAuras(removeMode int) {
+	for _, aura := range append([]*Aura(nil), u.auras...) {
+		u.RemoveAura(aura, removeMode)
+	}
+}
+
+// HasAuraType 检查是否有指定类型的光环
+func (u *Unit) HasAuraType(auraType AuraType) bool {
+	for _, aura := range u.auras {
+		if aura.auraType == auraType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTotalAuraModifier 同类型光环的数值之和 - 基于AzerothCore的Unit::GetTotalAuraModifier
+func (u *Unit) GetTotalAuraModifier(auraType AuraType) int32 {
+	var total int32
+	for _, aura := range u.auras {
+		if aura.auraType == auraType {
+			total += aura.value
+		}
+	}
+	return total
+}
+
+// GetAttackPowerMod 光环提供的攻击强度
+func (u *Unit) GetAttackPowerMod() int32 {
+	return u.GetTotalAuraModifier(SPELL_AURA_MOD_ATTACK_POWER)
+}
+
+// GetDamageDoneMultiplier 造成伤害的百分比修正(激怒、狂暴)
+func (u *Unit) GetDamageDoneMultiplier() float32 {
+	pct := 100 + u.GetTotalAuraModifier(SPELL_AURA_MOD_DAMAGE_PERCENT_DONE)
+	if pct < 0 {
+		pct = 0
+	}
+	return float32(pct) / 100 * u.damageModifier
+}
+
+// SetDamageModifier 设置伤害系数
+func (u *Unit) SetDamageModifier(modifier float32) { u.damageModifier = modifier }
+
+// GetDamageModifier 伤害系数
+func (u *Unit) GetDamageModifier() float32 { return u.damageModifier }
+
+// GetAttackTime 受攻击速度光环影响的近战攻击间隔
+func (u *Unit) GetAttackTime() int32 {
+	haste := 100 + u.GetTotalAuraModifier(SPELL_AURA_MOD_MELEE_HASTE)
+	if haste <= 0 {
+		return BASE_ATTACK_TIME
+	}
+	return BASE_ATTACK_TIME * 100 / haste
+}
+
+// AddSpellAura 不经过施法直接施加法术的所有光环效果 - 基于AzerothCore的Unit::AddAura(spellId, target)
+func (u *Unit) AddSpellAura(spellId uint32, target IUnit) bool {
+	if GlobalSpellManager == nil {
+		return false
+	}
+	spellInfo := GlobalSpellManager.GetSpell(spellId)
+	unit := getBaseUnit(target)
+	if spellInfo == nil || unit == nil {
+		return false
+	}
+
+	applied := false
+	duration := uint32(spellInfo.Duration.Milliseconds())
+	for i := range spellInfo.Effects {
+		if spellInfo.Effects[i].EffectType == SPELL_EFFECT_APPLY_AURA {
+			unit.AddAura(NewAura(spellInfo, &spellInfo.Effects[i], u, target, duration))
+			applied = true
+		}
+	}
+	return applied
+}
+
+// HasAura 检查是否有指定法术的光环
+func (u *Unit) HasAura(spellId uint32) bool {
+	for _, aura := range u.auras {
+		if aura.id == spellId {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAuras 获取单位身上的所有光环
+func (u *Unit) GetAuras() []*Aura {
+	return u.auras
+}
+
+// updateAuras 更新光环持续时间，周期光环按间隔触发，到期的光环被移除
+func (u *Unit) updateAuras(diff uint32) {
+	for _, aura := range append([]*Aura(nil), u.auras...) {
+		aura.updatePeriodic(min(diff, aura.duration))
+		if !u.IsAlive() {
+			return
+		}
+		if aura.duration > diff {
+			aura.duration -= diff
+			continue
+		}
+		aura.duration = 0
+		u.RemoveAura(aura, AURA_REMOVE_BY_EXPIRE)
+	}
+}
+
+// isPeriodic 是否为按间隔触发的周期光环
+func (a *Aura) isPeriodic() bool {
+	switch a.auraType {
+	case SPELL_AURA_PERIODIC_DAMAGE, SPELL_AURA_PERIODIC_HEAL:
+		return a.amplitude > 0
+	}
+	return false
+}
+
+// updatePeriodic 每经过一个间隔触发一次，到期前的最后一跳也会触发 - 基于AzerothCore的AuraEffect::Update
+func (a *Aura) updatePeriodic(diff uint32) {
+	if !a.isPeriodic() {
+		return
+	}
+	a.periodicTimer += diff
+	for a.periodicTimer >= a.amplitude && a.target.IsAlive() {
+		a.periodicTimer -= a.amplitude
+		a.periodicTick()
+	}
+}
+
+// periodicTick 周期伤害或治疗触发一次 - 基于AzerothCore的AuraEffect::HandlePeriodicDamageAurasTick和HandlePeriodicHealAurasTick
+func (a *Aura) periodicTick() {
+	var amount uint32
+	if a.value > 0 {
+		amount = uint32(a.value)
+	}
+	amount = callEffectPeriodicScripts(a.id, a.caster, a.target, a.effect, amount)
+	if amount == 0 {
+		return
+	}
+	switch a.auraType {
+	case SPELL_AURA_PERIODIC_DAMAGE:
+		amount -= calcPartialResist(a.caster, a.target, a.spellInfo.SchoolMask, amount)
+		if amount == 0 {
+			return
+		}
+		actualDamage := a.target.DealDamage(a.caster, amount, DOT, a.spellInfo.SchoolMask)
+		fmt.Printf("%s 的 %s 造成 %d 点持续伤害\n", a.target.GetName(), a.spellInfo.Name, actualDamage)
+	case SPELL_AURA_PERIODIC_HEAL:
+		a.target.Heal(a.caster, amount)
+	}
+}
+
+// getDiminishingReturnsGroup 获取光环所属的递减分组 - 基于AzerothCore的GetDiminishingReturnsGroupForSpell
+func getDiminishingReturnsGroup(auraType AuraType) int {
+	switch auraType {
+	case SPELL_AURA_MOD_STUN:
+		return DIMINISHING_CONTROLLED_STUN
+	case SPELL_AURA_MOD_ROOT:
+		return DIMINISHING_CONTROLLED_ROOT
+	case SPELL_AURA_MOD_FEAR:
+		return DIMINISHING_FEAR
+	case SPELL_AURA_MOD_CONFUSE:
+		return DIMINISHING_POLYMORPH
+	}
+	return DIMINISHING_NONE
+}
+
+// applyDiminishingToDuration 根据递减等级缩短持续时间 - 100% → 50% → 25% → 免疫
+func applyDiminishingToDuration(duration uint32, level int) uint32 {
+	switch level {
+	case DIMINISHING_LEVEL_1:
+		return duration
+	case DIMINISHING_LEVEL_2:
+		return duration / 2
+	case DIMINISHING_LEVEL_3:
+		return duration / 4
+	}
+	return 0
+}
+
+// GetDiminishing 获取单位在某个递减分组上的当前等级
+func (u *Unit) GetDiminishing(group int) int {
+	if dr, exists := u.diminishing[group]; exists {
+		return dr.level
+	}
+	return DIMINISHING_LEVEL_1
+}
+
+// IncrDiminishing 提升递减等级，18秒重置计时在该组控制结束后开始
+func (u *Unit) IncrDiminishing(group int) {
+	dr, exists := u.diminishing[group]
+	if !exists {
+		dr = &DiminishingReturn{}
+		u.diminishing[group] = dr
+	}
+	if dr.level < DIMINISHING_LEVEL_IMMUNE {
+		dr.level++
+	}
+	dr.resetTimer = DIMINISHING_RETURNS_WINDOW
+}
+
+// hasDiminishingAura 是否还有该递减分组的控制光环生效
+func (u *Unit) hasDiminishingAura(group int) bool {
+	for _, aura := range u.auras {
+		if getDiminishingReturnsGroup(aura.auraType) == group {
+			return true
+		}
+	}
+	return false
+}
+
+// updateDiminishing 递减重置计时，同组控制生效期间计时冻结
+func (u *Unit) updateDiminishing(diff uint32) {
+	for group, dr := range u.diminishing {
+		if u.hasDiminishingAura(group) {
+			continue
+		}
+		if dr.resetTimer > diff {
+			dr.resetTimer -= diff
+			continue
+		}
+		delete(u.diminishing, group)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import "testing"
+
+func newAuraTestSpell(t *testing.T, caster IUnit, spellId uint32) *Spell {
+	info := GlobalSpellManager.GetSpell(spellId)
+	if info == nil {
+		t.Fatalf("spell %d not loaded", spellId)
+	}
+	return NewSpell(caster, info, nil)
+}
+
+func TestPvPDiminishingReturns(t *testing.T) {
+	mage := newThreatTestUnit("mage", 0)
+	rogue := newThreatTestUnit("rogue", 10)
+
+	spell := newAuraTestSpell(t, mage, SPELL_POLYMORPH)
+	expected := []uint32{50000, 25000, 12500}
+	for i, want := range expected {
+		spell.applyAura(rogue, &spell.info.Effects[0])
+		auras := rogue.GetAuras()
+		if len(auras) != 1 || auras[0].GetMaxDuration() != want {
+			t.Fatalf("application %d: expected duration %d, got %+v", i+1, want, auras)
+		}
+		rogue.RemoveAllAuras(AURA_REMOVE_BY_DEFAULT)
+	}
+
+	spell.applyAura(rogue, &spell.info.Effects[0])
+	if rogue.HasAura(SPELL_POLYMORPH) {
+		t.Fatalf("fourth polymorph within the window should be immune")
+	}
+
+	rogue.Update(DIMINISHING_RETURNS_WINDOW)
+	spell.applyAura(rogue, &spell.info.Effects[0])
+	if auras := rogue.GetAuras(); len(auras) != 1 || auras[0].GetMaxDuration() != 50000 {
+		t.Fatalf("diminishing returns should reset after 18 seconds")
+	}
+}
+
+func TestDiminishingWindowStartsWhenControlEnds(t *testing.T) {
+	mage := newThreatTestUnit("mage", 0)
+	rogue := newThreatTestUnit("rogue", 10)
+
+	// 变形持续50秒，期间递减不会重置
+	spell := newAuraTestSpell(t, mage, SPELL_POLYMORPH)
+	spell.applyAura(rogue, &spell.info.Effects[0])
+	rogue.Update(DIMINISHING_RETURNS_WINDOW)
+	if rogue.GetDiminishing(DIMINISHING_POLYMORPH) != DIMINISHING_LEVEL_2 {
+		t.Fatal("diminishing returns should not reset while the control is active")
+	}
+
+	// 结束后10秒再次变形：距离施放已超过18秒，但距离结束不到18秒
+	rogue.Update(50000 - DIMINISHING_RETURNS_WINDOW)
+	if rogue.HasAura(SPELL_POLYMORPH) {
+		t.Fatal("polymorph should have expired")
+	}
+	rogue.Update(10000)
+	spell.applyAura(rogue, &spell.info.Effects[0])
+	if auras := rogue.GetAuras(); len(auras) != 1 || auras[0].GetMaxDuration() != 25000 {
+		t.Fatalf("reapplication within 18 seconds of expiry should be diminished, got %+v", auras)
+	}
+
+	rogue.Update(25000)
+	rogue.Update(DIMINISHING_RETURNS_WINDOW)
+	spell.applyAura(rogue, &spell.info.Effects[0])
+	if auras := rogue.GetAuras(); len(auras) != 1 || auras[0].GetMaxDuration() != 50000 {
+		t.Fatal("diminishing returns should reset 18 seconds after the control ends")
+	}
+}
+
+func TestPolymorphBreaksOnDamage(t *testing.T) {
+	mage := newThreatTestUnit("mage", 0)
+	target := newThreatTestUnit("target", 10)
+
+	spell := newAuraTestSpell(t, mage, SPELL_POLYMORPH)
+	spell.applyAura(target, &spell.info.Effects[0])
+	if !target.HasUnitState(UNIT_STATE_CONFUSED) {
+		t.Fatalf("polymorph should confuse the target")
+	}
+
+	target.DealDamage(mage, 10, SPELL_DIRECT_DAMAGE, SPELL_SCHOOL_FIRE)
+	if target.HasAura(SPELL_POLYMORPH) || target.HasUnitState(UNIT_STATE_CONFUSED) {
+		t.Fatalf("polymorph should break on damage")
+	}
+}
+
+func TestStunBlocksCastingAndRootBlocksMovement(t *testing.T) {
+	paladin := newThreatTestUnit("paladin", 0)
+	mage := newThreatTestUnit("mage", 5)
+	mage.SetMaxPower(POWER_MANA, 1000)
+	mage.SetPower(POWER_MANA, 1000)
+
+	stun := newAuraTestSpell(t, paladin, SPELL_HAMMER_OF_JUSTICE)
+	stun.applyAura(mage, &stun.info.Effects[0])
+	if mage.CanMove() {
+		t.Fatalf("stunned unit should not move")
+	}
+	if result := newAuraTestSpell(t, mage, SPELL_FIREBALL).checkCast(paladin); result != 108 {
+		t.Fatalf("stunned unit should fail with SPELL_FAILED_STUNNED (108), got %d", result)
+	}
+
+	mage.RemoveAurasDueToSpell(SPELL_HAMMER_OF_JUSTICE)
+	nova := newAuraTestSpell(t, mage, SPELL_FROST_NOVA)
+	nova.applyAura(paladin, &nova.info.Effects[1])
+	if !paladin.HasUnitState(UNIT_STATE_ROOTED) || paladin.CanMove() {
+		t.Fatalf("frost nova should root the target")
+	}
+	if paladin.HasUnitState(UNIT_STATE_LOST_CONTROL) {
+		t.Fatalf("rooted unit should still be able to act")
+	}
+}
+
+func TestPeriodicAurasTickAndCallScripts(t *testing.T) {
+	warlock := newThreatTestUnit("warlock", 0)
+	target := newThreatTestUnit("target", 10)
+	immolate := newAuraTestSpell(t, warlock, SPELL_IMMOLATE)
+	dot := &immolate.info.Effects[1]
+	if AuraType(dot.ApplyAuraName) != SPELL_AURA_PERIODIC_DAMAGE {
+		t.Fatalf("immolate should have a periodic damage effect")
+	}
+
+	var ticks int
+	RegisterSpellScript(SPELL_IMMOLATE, &SpellScript{
+		OnEffectPeriodic: func(caster IUnit, victim IUnit, effect *SpellEffect, amount uint32) uint32 {
+			ticks++
+			if caster != warlock || victim != target || effect != dot {
+				t.Fatalf("periodic hook should get the aura's caster, target and effect")
+			}
+			return amount * 2
+		},
+	})
+	defer delete(spellScripts, SPELL_IMMOLATE)
+
+	// 15秒持续时间，每3秒一跳，到期前最后一跳也会触发
+	immolate.applyAura(target, dot)
+	target.Update(2999)
+	if ticks != 0 || target.GetHealth() != 1000 {
+		t.Fatalf("periodic aura should not tick before its amplitude")
+	}
+	target.Update(1)
+	if ticks != 1 || target.GetHealth() != 1000-uint32(dot.BasePoints)*2 {
+		t.Fatalf("first tick should deal the scripted damage, ticks=%d health=%d", ticks, target.GetHealth())
+	}
+	for i := 0; i < 12; i++ {
+		target.Update(1000)
+	}
+	if ticks != 5 || target.HasAura(SPELL_IMMOLATE) {
+		t.Fatalf("immolate should tick 5 times and expire, ticks=%d", ticks)
+	}
+
+	// 周期治疗同样按间隔触发，脚本返回0时跳过本次触发
+	priest := newThreatTestUnit("priest", 0)
+	renew := &SpellInfo{ID: SPELL_RENEW, Name: "恢复", SchoolMask: SPELL_SCHOOL_HOLY}
+	hot := &SpellEffect{ApplyAuraName: int(SPELL_AURA_PERIODIC_HEAL), BasePoints: 50, Amplitude: 3000}
+	skipped := false
+	RegisterSpellScript(SPELL_RENEW, &SpellScript{
+		OnEffectPeriodic: func(caster IUnit, victim IUnit, effect *SpellEffect, amount uint32) uint32 {
+			if !skipped {
+				skipped = true
+				return 0
+			}
+			return amount
+		},
+	})
+	defer delete(spellScripts, SPELL_RENEW)
+	target.AddAura(NewAura(renew, hot, priest, target, 6000))
+	health := target.GetHealth()
+	target.Update(3000)
+	if target.GetHealth() != health {
+		t.Fatalf("a tick the script zeroes should not heal")
+	}
+	target.Update(3000)
+	if target.GetHealth() != health+50 {
+		t.Fatalf("renew should heal on its last tick, health %d", target.GetHealth())
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -162,6 +164,10 @@ func (cs *ClientSimulator) handleIncomingPacket(packet *WorldPacket) {
 		cs.handleSpellGo(packet)
 	case SMSG_ATTACKERSTATEUPDATE:
 		cs.handleAttackerStateUpdate(packet)
+	case SMSG_THREAT_UPDATE, SMSG_HIGHEST_THREAT_UPDATE:
+		cs.handleThreatUpdate(packet)
+	case SMSG_MONSTER_MOVE:
+		cs.handleMonsterMove(packet)
 	default:
 		// 其他数据包的处理
 	}
@@ -213,7 +219,18 @@ func (cs *ClientSimulator) handleHealthUpdate(packet *WorldPacket) {
 
 // handleSpellGo 处理法术施放结果数据包
 func (cs *ClientSimulator) handleSpellGo(packet *WorldPacket) {
-	fmt.Printf("[客户端 %s] 收到法术施放结果\n", cs.name)
+	spellId := packet.ReadUint32()
+	hitCount := packet.ReadUint32()
+	for i := uint32(0); i < hitCount; i++ {
+		packet.ReadUint64()
+	}
+	missCount := packet.ReadUint32()
+	fmt.Printf("[客户端 %s] 收到法术施放结果: 法术 %d 命中 %d 个目标，未命中 %d 个\n", cs.name, spellId, hitCount, missCount)
+	for i := uint32(0); i < missCount; i++ {
+		guid := packet.ReadUint64()
+		missInfo := packet.ReadUint8()
+		fmt.Printf("[客户端 %s]   目标 %d: %s\n", cs.name, guid, getSpellMissName(missInfo))
+	}
 }
 
 // handleAttackerStateUpdate 处理攻击状态更新数据包
@@ -221,6 +238,41 @@ func (cs *ClientSimulator) handleAttackerStateUpdate(packet *WorldPacket) {
 	fmt.Printf("[客户端 %s] 收到攻击状态更新\n", cs.name)
 }
 
+// handleThreatUpdate 处理仇恨表更新数据包 - 用于客户端仇恨统计
+func (cs *ClientSimulator) handleThreatUpdate(packet *WorldPacket) {
+	ownerGuid := packet.ReadUint64()
+	if packet.GetOpcode() == SMSG_HIGHEST_THREAT_UPDATE {
+		packet.ReadUint64() // 新的最高仇恨目标
+	}
+	count := packet.ReadUint32()
+
+	player := cs.GetPlayer()
+	for i := uint32(0); i < count; i++ {
+		guid := packet.ReadUint64()
+		threat := packet.ReadUint32()
+		if player != nil && guid == player.GetGUID() {
+			fmt.Printf("[客户端 %s] 对单位 %d 的仇恨: %.1f\n", cs.name, ownerGuid, float32(threat)/100)
+		}
+	}
+}
+
+// handleMonsterMove 处理服务器驱动的样条移动 - 客户端按时长在路径点间插值
+func (cs *ClientSimulator) handleMonsterMove(packet *WorldPacket) {
+	guid := packet.ReadUint64()
+	packet.ReadUint8()
+	x, y, z := packet.ReadFloat32(), packet.ReadFloat32(), packet.ReadFloat32()
+	packet.ReadUint32() // 样条ID
+	if packet.ReadUint8() == MONSTER_MOVE_STOP {
+		fmt.Printf("[客户端 %s] 单位 %d 停在 (%.1f, %.1f, %.1f)\n", cs.name, guid, x, y, z)
+		return
+	}
+	packet.ReadUint32() // 样条标志
+	duration := packet.ReadUint32()
+	count := packet.ReadUint32()
+	fmt.Printf("[客户端 %s] 单位 %d 从 (%.1f, %.1f, %.1f) 沿 %d 个路径点移动，耗时 %dms\n",
+		cs.name, guid, x, y, z, count, duration)
+}
+
 // simulateMovement 模拟移动
 func (cs *ClientSimulator) simulateMovement() {
 	if !cs.IsActive() {
@@ -234,16 +286,21 @@ func (cs *ClientSimulator) simulateMovement() {
 
 	// 随机移动
 	if rand.Float32() < 0.3 { // 30%概率开始移动
-		packet := NewWorldPacket(CMSG_MOVE_START_FORWARD)
-		cs.sendPacketWithStats(packet)
-
-		// 更新玩家位置（简化）
-		newX := player.GetX() + float32(rand.Intn(10)-5)
-		newY := player.GetY() + float32(rand.Intn(10)-5)
-		if player.Unit != nil {
-			player.Unit.SetPosition(newX, newY, player.GetZ())
+		// 客户端只上报新位置，由服务器校验后更新
+		newX := player.GetX() + rand.Float32()*2 - 1
+		newY := player.GetY() + rand.Float32()*2 - 1
+		movementInfo := MovementInfo{
+			flags: MOVEMENTFLAG_FORWARD,
+			time:  uint32(time.Now().UnixMilli()),
+			x:     newX,
+			y:     newY,
+			z:     player.GetZ(),
+			o:     calculateAngle(player.GetX(), player.GetY(), newX, newY),
 		}
-
+		packet := NewWorldPacket(MSG_MOVE_START_FORWARD)
+		packet.WriteUint64(player.GetGUID())
+		packet.WriteMovementInfo(&movementInfo)
+		cs.sendPacketWithStats(packet)
 	}
 }
 
@@ -356,18 +413,34 @@ func (cs *ClientSimulator) IsActive() bool {
 func (cs *ClientSimulator) GetStatistics() ClientStats {
 	cs.statistics.mutex.RLock()
 	defer cs.statistics.mutex.RUnlock()
-	return *cs.statistics
+	return ClientStats{
+		packetsSent:     cs.statistics.packetsSent,
+		packetsReceived: cs.statistics.packetsReceived,
+		spellsCast:      cs.statistics.spellsCast,
+		attacksLaunched: cs.statistics.attacksLaunched,
+		damageDealt:     cs.statistics.damageDealt,
+		damageTaken:     cs.statistics.damageTaken,
+		healingDone:     cs.statistics.healingDone,
+		healingReceived: cs.statistics.healingReceived,
+		healthUpdates:   cs.statistics.healthUpdates,
+	}
 }
 
 // 演示批量同步的优势
 func DemoBatchSyncAdvantage() {
 	fmt.Println("=== AzerothCore 批量同步机制演示 ===")
 	fmt.Println("模拟40个客户端与服务器的真实网络交互")
-	fmt.Println("展示批量同步 vs 传统同步的性能对比\n")
+	fmt.Println("展示批量同步 vs 传统同步的性能对比")
+	fmt.Println()
 
 	// 创建世界
 	world := NewWorld()
 
+	// 角色存档 - 断线重连后恢复法术冷却
+	if err := InitCharacterDatabase(filepath.Join(os.TempDir(), "azerothcore-characters")); err != nil {
+		fmt.Printf("%v\n", err)
+	}
+
 	// 创建服务器 - 使用client_server.go中的GameServer
 	server := NewGameServer(world)
 
@@ -448,17 +521,10 @@ func DemoBatchSyncAdvantage() {
 				}
 			}
 
-			// 模拟血量变化 - 通过服务器处理
+			// 模拟环境伤害 - 伤害由服务器计算，客户端只会收到结果
 			if rand.Float32() < 0.3 {
-				player := client.GetPlayer()
-				if player != nil {
-					damage := uint32(rand.Intn(200) + 50)
-
-					// 发送血量变化请求给服务器，而不是直接修改
-					packet := NewWorldPacket(CMSG_DAMAGE_TAKEN)
-					packet.WriteUint64(player.GetGUID())
-					packet.WriteUint32(damage)
-					client.sendPacketWithStats(packet)
+				if unit := getBaseUnit(client.GetPlayer()); unit != nil {
+					unit.EnvironmentalDamage(DAMAGE_FIRE, uint32(rand.Intn(200)+50))
 				}
 			}
 		}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// 首领技能目标 - 基于AzerothCore的SelectTargetMethod
+const (
+	BOSS_TARGET_VICTIM         = 0 // 当前攻击目标
+	BOSS_TARGET_SELF           = 1 // 自己
+	BOSS_TARGET_RANDOM         = 2 // 仇恨列表中的随机目标
+	BOSS_TARGET_RANDOM_NOT_TOP = 3 // 仇恨列表中除最高仇恨外的随机目标
+	BOSS_TARGET_BOTTOM_AGGRO   = 4 // 仇恨最低的目标
+)
+
+// 首领通用法术
+const (
+	SPELL_BERSERK = 26662 // 狂暴 - 到达时间限制后大幅提高伤害和攻击速度
+	SPELL_ENRAGE  = 8599  // 激怒 - 低生命值时提高伤害和攻击速度
+)
+
+// BossEvent 首领的定时技能 - 对应AzerothCore脚本中ScheduleEvent/ExecuteEvent的一个事件
+type BossEvent struct {
+	id        uint32
+	phase     uint8  // 所属阶段，0表示所有阶段
+	timer     uint32 // 进入战斗或进入阶段后首次触发的时间(毫秒)
+	repeatMin uint32 // 重复间隔，0表示只触发一次
+	repeatMax uint32 // 大于repeatMin时在区间内随机
+	spellId   uint32 // 施放的法术，0表示不施法
+	target    int    // 法术目标(BOSS_TARGET_*)
+	yell      string // 触发时的喊话
+	action    func(ai *BossAI, target IUnit)
+}
+
+// BossSummon 阶段转换时召唤的小怪，按生物模板创建
+type BossSummon struct {
+	entry uint32
+	count int
+}
+
+// BossPhase 阶段 - 第一阶段在进入战斗时开始，之后的阶段在生命值或战斗时间达到条件时进入
+type BossPhase struct {
+	phase     uint8
+	healthPct float32 // 生命值百分比低于等于此值时进入，0表示不按生命值
+	timer     uint32  // 战斗时间达到此值时进入，0表示不按时间
+	yell      string
+	spellId   uint32 // 进入阶段时给自己施加的光环法术
+	summons   []BossSummon
+}
+
+// BossScript 首领遭遇战脚本 - 声明式描述技能、阶段、召唤、狂暴和喊话
+type BossScript struct {
+	name         string
+	phases       []BossPhase
+	events       []BossEvent
+	berserkTimer uint32 // 战斗多久后狂暴，0表示没有狂暴
+	berserkSpell uint32
+	aggroYell    string
+	deathYell    string
+	resetYell    string
+	berserkYell  string
+}
+
+// BossAI 脚本驱动的首领AI - 基于AzerothCore的BossAI
+type BossAI struct {
+	owner      *Creature
+	script     *BossScript
+	events     EventMap
+	phase      uint8
+	engaged    bool
+	combatTime uint32
+	berserk    bool
+	summons    []*Creature // 召唤的小怪 - 对应AzerothCore的SummonList
+}
+
+// NewBossAI 创建首领AI
+func NewBossAI(owner *Creature, script *BossScript) *BossAI {
+	return &BossAI{owner: owner, script: script, phase: 1}
+}
+
+// GetPhase 当前阶段
+func (ai *BossAI) GetPhase() uint8 { return ai.phase }
+
+// GetSummons 召唤的小怪
+func (ai *BossAI) GetSummons() []*Creature { return ai.summons }
+
+// IsEngaged 是否在战斗中
+func (ai *BossAI) IsEngaged() bool { return ai.engaged }
+
+// IsBerserk 是否已狂暴
+func (ai *BossAI) IsBerserk() bool { return ai.berserk }
+
+// GetEvents 事件表
+func (ai *BossAI) GetEvents() *EventMap { return &ai.events }
+
+func (ai *BossAI) UpdateAI(diff uint32) {
+	if !ai.owner.IsAlive() || !ai.engaged {
+		return
+	}
+	if ai.owner.UpdateVictim() == nil {
+		return
+	}
+
+	ai.combatTime += diff
+	ai.checkPhaseTransition()
+	ai.checkBerserk()
+
+	ai.events.Update(diff)
+
+	// 施法期间不触发新的技能，到期的事件留到施法结束后
+	if ai.owner.isCurrentlySpellCasting() {
+		return
+	}
+	for eventId := ai.events.ExecuteEvent(); eventId != 0; eventId = ai.events.ExecuteEvent() {
+		ai.executeEvent(eventId)
+		if ai.owner.isCurrentlySpellCasting() {
+			return
+		}
+	}
+}
+
+// engage 开始遭遇战 - 基于AzerothCore的BossAI::_JustEngagedWith
+func (ai *BossAI) engage() {
+	ai.engaged = true
+	ai.combatTime = 0
+	ai.events.Reset()
+	if ai.script.aggroYell != "" {
+		ai.owner.Yell(ai.script.aggroYell)
+	}
+	ai.scheduleEvents(0)
+	ai.enterPhase(1)
+}
+
+// scheduleEvents 调度指定阶段的事件
+func (ai *BossAI) scheduleEvents(phase uint8) {
+	for _, event := range ai.script.events {
+		if event.phase == phase {
+			ai.events.ScheduleEvent(event.id, event.timer, 0, phase)
+		}
+	}
+}
+
+// getEvent 根据ID查找事件
+func (ai *BossAI) getEvent(eventId uint32) *BossEvent {
+	for i := range ai.script.events {
+		if ai.script.events[i].id == eventId {
+			return &ai.script.events[i]
+		}
+	}
+	return nil
+}
+
+// executeEvent 执行到期的事件并按间隔重复
+func (ai *BossAI) executeEvent(eventId uint32) {
+	event := ai.getEvent(eventId)
+	if event == nil {
+		return
+	}
+
+	target := ai.SelectTarget(event.target)
+	if event.yell != "" {
+		ai.owner.Yell(event.yell)
+	}
+	if event.spellId != 0 && target != nil {
+		ai.owner.CastSpell(target, event.spellId)
+	}
+	if event.action != nil {
+		event.action(ai, target)
+	}
+
+	if event.repeatMin > 0 {
+		repeat := event.repeatMin
+		if event.repeatMax > event.repeatMin {
+			repeat += uint32(rand.Intn(int(event.repeatMax-event.repeatMin) + 1))
+		}
+		ai.events.RepeatEvent(repeat)
+	}
+}
+
+// getPhase 查找阶段定义
+func (ai *BossAI) getPhase(phase uint8) *BossPhase {
+	for i := range ai.script.phases {
+		if ai.script.phases[i].phase == phase {
+			return &ai.script.phases[i]
+		}
+	}
+	return nil
+}
+
+// checkPhaseTransition 检查是否满足进入下一阶段的条件
+func (ai *BossAI) checkPhaseTransition() {
+	next := ai.getPhase(ai.phase + 1)
+	if next == nil {
+		return
+	}
+	healthPct := float32(ai.owner.GetHealth()) / float32(ai.owner.GetMaxHealth()) * 100
+	if (next.healthPct > 0 && healthPct <= next.healthPct) || (next.timer > 0 && ai.combatTime >= next.timer) {
+		ai.enterPhase(next.phase)
+	}
+}
+
+// SetPhase 切换阶段 - 脚本和副本可以直接指定
+func (ai *BossAI) SetPhase(phase uint8) {
+	ai.enterPhase(phase)
+}
+
+// enterPhase 进入阶段：切换事件表阶段，调度本阶段技能，喊话、施加光环并召唤小怪
+func (ai *BossAI) enterPhase(phase uint8) {
+	ai.phase = phase
+	ai.events.SetPhase(phase)
+	ai.scheduleEvents(phase)
+
+	def := ai.getPhase(phase)
+	if def == nil {
+		return
+	}
+	if phase > 1 {
+		fmt.Printf("[首领] %s 进入第%d阶段\n", ai.owner.GetName(), phase)
+	}
+	if def.yell != "" {
+		ai.owner.Yell(def.yell)
+	}
+	if def.spellId != 0 {
+		ai.owner.AddSpellAura(def.spellId, ai.owner)
+	}
+	for _, summon := range def.summons {
+		for i := 0; i < summon.count; i++ {
+			ai.Summon(summon)
+		}
+	}
+}
+
+// checkBerserk 战斗时间到达后狂暴 - 基于AzerothCore脚本中的EVENT_BERSERK
+func (ai *BossAI) checkBerserk() {
+	if ai.berserk || ai.script.berserkTimer == 0 || ai.combatTime < ai.script.berserkTimer {
+		return
+	}
+	ai.berserk = true
+	if ai.script.berserkYell != "" {
+		ai.owner.Yell(ai.script.berserkYell)
+	}
+	ai.owner.AddSpellAura(ai.script.berserkSpell, ai.owner)
+	fmt.Printf("[首领] %s 狂暴了！\n", ai.owner.GetName())
+}
+
+// SelectTarget 按方式从仇恨列表选择目标 - 基于AzerothCore的UnitAI::SelectTarget
+func (ai *BossAI) SelectTarget(method int) IUnit {
+	switch method {
+	case BOSS_TARGET_SELF:
+		return ai.owner
+	case BOSS_TARGET_VICTIM:
+		return ai.owner.GetVictim()
+	}
+
+	var candidates []IUnit
+	for _, info := range ai.owner.threatManager.GetSortedThreatList() {
+		if info.unit.IsAlive() {
+			candidates = append(candidates, info.unit)
+		}
+	}
+	if method == BOSS_TARGET_RANDOM_NOT_TOP && len(candidates) > 1 {
+		candidates = candidates[1:]
+	}
+	if len(candidates) == 0 {
+		return ai.owner.GetVictim()
+	}
+	if method == BOSS_TARGET_BOTTOM_AGGRO {
+		return candidates[len(candidates)-1]
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+// Summon 在首领周围召唤小怪并攻击随机目标 - 基于AzerothCore的BossAI::JustSummoned
+func (ai *BossAI) Summon(summon BossSummon) *Creature {
+	creature := ai.owner.SummonCreature(summon.entry)
+	if creature == nil {
+		return nil
+	}
+	ai.summons = append(ai.summons, creature)
+
+	// 召唤物直接进入战斗并追向目标 - 对应AzerothCore的DoZoneInCombat
+	if target := ai.SelectTarget(BOSS_TARGET_RANDOM); target != nil {
+		creature.CombatStart(target)
+		creature.UpdateVictim()
+	}
+	fmt.Printf("[首领] %s 召唤了 %s\n", ai.owner.GetName(), creature.GetName())
+	return creature
+}
+
+// DespawnSummons 移除所有召唤的小怪 - 基于AzerothCore的SummonList::DespawnAll
+func (ai *BossAI) DespawnSummons() {
+	for _, summon := range ai.summons {
+		if summon.IsAlive() {
+			summon.EnterEvadeMode()
+		}
+		if ai.owner.world != nil {
+			ai.owner.world.RemoveUnit(summon.GetGUID())
+		}
+	}
+	ai.summons = nil
+}
+
+// Reset 脱战重置 - 清空事件、回到第一阶段、移除召唤物和狂暴 - 基于AzerothCore的BossAI::_Reset
+func (ai *BossAI) Reset() {
+	ai.engaged = false
+	ai.combatTime = 0
+	ai.berserk = false
+	ai.phase = 1
+	ai.events.Reset()
+	ai.DespawnSummons()
+	if ai.script.resetYell != "" {
+		ai.owner.Yell(ai.script.resetYell)
+	}
+}
+
+func (ai *BossAI) AttackStart(target IUnit) {}
+
+func (ai *BossAI) EnterCombat(target IUnit) {
+	if !ai.engaged {
+		ai.engage()
+	}
+}
+
+func (ai *BossAI) JustDied(killer IUnit) {
+	ai.engaged = false
+	ai.events.Reset()
+	ai.DespawnSummons()
+	if ai.script.deathYell != "" {
+		ai.owner.Yell(ai.script.deathYell)
+	}
+}
+
+func (ai *BossAI) DamageTaken(attacker IUnit, damage uint32) {}
+
+func (ai *BossAI) DamageDealt(victim IUnit, damage uint32) {}
+
+// === 喊话 ===
+
+// Yell 向附近玩家喊话 - 基于AzerothCore的Creature::Yell(CHAT_MSG_MONSTER_YELL)
+func (u *Unit) Yell(text string) {
+	fmt.Printf("%s 喊道：\"%s\"\n", u.GetName(), text)
+	u.sendMonsterMessage(CHAT_MSG_MONSTER_YELL, YELL_RANGE, text)
+}
+
+// Say 对附近玩家说话 - 基于AzerothCore的Creature::Say(CHAT_MSG_MONSTER_SAY)
+func (u *Unit) Say(text string) {
+	fmt.Printf("%s 说：\"%s\"\n", u.GetName(), text)
+	u.sendMonsterMessage(CHAT_MSG_MONSTER_SAY, SAY_RANGE, text)
+}
+
+// sendMonsterMessage 向范围内的玩家发送生物聊天消息
+func (u *Unit) sendMonsterMessage(msgType uint8, textRange float32, text string) {
+	if u.world == nil {
+		return
+	}
+	packet := NewWorldPacket(SMSG_MESSAGECHAT)
+	packet.WriteUint8(msgType)
+	packet.WriteUint32(0) // language
+	packet.WriteUint64(u.GetGUID())
+	packet.WriteString(u.GetName())
+	packet.WriteString(text)
+	u.world.BroadcastToPlayersInRange(u.x, u.y, u.z, textRange, packet)
+}
@@ -0,0 +1,89 @@
+package main
+
+import "testing"
+
+func TestEventMapPhasesRepeatAndDelay(t *testing.T) {
+	var events EventMap
+	events.SetPhase(1)
+	events.ScheduleEvent(1, 1000, 0, 0)
+	events.ScheduleEvent(2, 500, 0, 2)
+	events.ScheduleEvent(3, 2000, 7, 1)
+
+	events.Update(1000)
+	if id := events.ExecuteEvent(); id != 1 {
+		t.Fatalf("expected event 1, events of other phases should be dropped, got %d", id)
+	}
+	events.RepeatEvent(1500)
+	if id := events.ExecuteEvent(); id != 0 {
+		t.Fatalf("no event should be due yet, got %d", id)
+	}
+	if remaining, ok := events.GetTimeUntilEvent(1); !ok || remaining != 1500 {
+		t.Fatalf("repeated event should be due in 1500ms, got %d", remaining)
+	}
+
+	events.DelayEvents(1000)
+	events.Update(1000)
+	if id := events.ExecuteEvent(); id != 0 {
+		t.Fatalf("delayed events should not fire early, got %d", id)
+	}
+	events.CancelEventGroup(7)
+	events.Update(2000)
+	if id := events.ExecuteEvent(); id != 1 {
+		t.Fatalf("expected repeated event 1, got %d", id)
+	}
+	if !events.Empty() {
+		t.Fatalf("cancelled group should leave no events")
+	}
+}
+
+func TestBossScriptPhasesSummonsAndBerserk(t *testing.T) {
+	if GlobalObjectMgr == nil {
+		InitObjectMgr()
+	}
+	boss := NewCreature("范克里夫", 26, CREATURE_TYPE_HUMANOID)
+	boss.SetMaxHealth(10000)
+	boss.SetHealth(10000)
+	ai := NewBossAI(boss, vanCleefScript)
+	boss.SetAI(ai)
+	tank := newThreatTestUnit("tank", 2)
+
+	boss.CombatStart(tank)
+	if !ai.IsEngaged() || ai.GetPhase() != PHASE_VANCLEEF_DUEL {
+		t.Fatalf("boss should engage in phase 1")
+	}
+	if remaining, ok := ai.GetEvents().GetTimeUntilEvent(EVENT_VANCLEEF_MORTAL_STRIKE); !ok || remaining != 5000 {
+		t.Fatalf("mortal strike should be scheduled at 5s, got %d", remaining)
+	}
+
+	boss.SetHealth(6000)
+	boss.Update(100)
+	if ai.GetPhase() != PHASE_VANCLEEF_GUARDS || len(ai.GetSummons()) != 2 {
+		t.Fatalf("boss should summon two guards at 66%%, phase %d summons %d", ai.GetPhase(), len(ai.GetSummons()))
+	}
+	if ai.GetSummons()[0].GetVictim() != IUnit(tank) {
+		t.Fatalf("guards should attack a target from the boss threat list")
+	}
+	if _, ok := ai.GetEvents().GetTimeUntilEvent(EVENT_VANCLEEF_WHIRLWIND); !ok {
+		t.Fatalf("whirlwind should be scheduled in phase 2")
+	}
+
+	boss.SetHealth(3000)
+	boss.Update(100)
+	if ai.GetPhase() != PHASE_VANCLEEF_ENRAGE || boss.GetAttackTime() >= BASE_ATTACK_TIME {
+		t.Fatalf("enrage should speed up the boss's attacks")
+	}
+
+	ai.combatTime = vanCleefScript.berserkTimer
+	boss.Update(100)
+	if !ai.IsBerserk() || boss.GetDamageDoneMultiplier() != 6.25 {
+		t.Fatalf("berserk should multiply damage, got %.2f", boss.GetDamageDoneMultiplier())
+	}
+
+	boss.EnterEvadeMode()
+	if ai.IsEngaged() || ai.GetPhase() != PHASE_VANCLEEF_DUEL || len(ai.GetSummons()) != 0 || ai.IsBerserk() {
+		t.Fatalf("evade should reset the encounter")
+	}
+	if boss.GetDamageDoneMultiplier() != 1 {
+		t.Fatalf("evade should remove enrage and berserk")
+	}
+}
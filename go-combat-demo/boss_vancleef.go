@@ -0,0 +1,64 @@
+package main
+
+// 埃德温·范克里夫 - 基于AzerothCore的boss_edwin_vancleef脚本
+
+func init() {
+	RegisterCreatureScript("boss_edwin_vancleef", func(creature *Creature) IAI { return NewBossAI(creature, vanCleefScript) })
+}
+
+// 范克里夫召唤的生物
+const NPC_DEFIAS_BLACKGUARD = 636 // 迪菲亚保镖
+
+// 范克里夫的法术
+const (
+	SPELL_MORTAL_STRIKE = 16856 // 致命打击
+	SPELL_WHIRLWIND     = 15589 // 旋风斩
+)
+
+// 范克里夫的事件
+const (
+	EVENT_VANCLEEF_MORTAL_STRIKE = 1
+	EVENT_VANCLEEF_WHIRLWIND     = 2
+	EVENT_VANCLEEF_FRENZY_STRIKE = 3
+)
+
+// 范克里夫的阶段
+const (
+	PHASE_VANCLEEF_DUEL   = 1 // 单挑
+	PHASE_VANCLEEF_GUARDS = 2 // 66%召唤保镖
+	PHASE_VANCLEEF_ENRAGE = 3 // 33%激怒
+)
+
+// vanCleefScript 三个阶段：致命打击 → 召唤保镖并使用旋风斩 → 激怒后高频致命打击，10分钟狂暴
+var vanCleefScript = &BossScript{
+	name: "埃德温·范克里夫",
+	phases: []BossPhase{
+		{phase: PHASE_VANCLEEF_DUEL},
+		{
+			phase:     PHASE_VANCLEEF_GUARDS,
+			healthPct: 66,
+			yell:      "兄弟们，来帮我解决这些入侵者！",
+			summons:   []BossSummon{{entry: NPC_DEFIAS_BLACKGUARD, count: 2}},
+		},
+		{
+			phase:     PHASE_VANCLEEF_ENRAGE,
+			healthPct: 33,
+			yell:      "你们会为此付出血的代价！",
+			spellId:   SPELL_ENRAGE,
+		},
+	},
+	events: []BossEvent{
+		{id: EVENT_VANCLEEF_MORTAL_STRIKE, phase: PHASE_VANCLEEF_DUEL, timer: 5000, repeatMin: 8000,
+			spellId: SPELL_MORTAL_STRIKE, target: BOSS_TARGET_VICTIM},
+		{id: EVENT_VANCLEEF_WHIRLWIND, phase: PHASE_VANCLEEF_GUARDS, timer: 3000, repeatMin: 6000, repeatMax: 8000,
+			spellId: SPELL_WHIRLWIND, target: BOSS_TARGET_SELF},
+		{id: EVENT_VANCLEEF_FRENZY_STRIKE, phase: PHASE_VANCLEEF_ENRAGE, timer: 1000, repeatMin: 4000,
+			spellId: SPELL_MORTAL_STRIKE, target: BOSS_TARGET_VICTIM},
+	},
+	berserkTimer: 600000,
+	berserkSpell: SPELL_BERSERK,
+	aggroYell:    "迪菲亚兄弟会的力量，你们永远不会理解！",
+	deathYell:    "这...不可能...迪菲亚兄弟会...永远不会消失...",
+	resetYell:    "不自量力的家伙...",
+	berserkYell:  "够了！你们都得死！",
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CharacterSpellCooldown 角色法术冷却存档 - 基于AzerothCore的character_spell_cooldown表
+type CharacterSpellCooldown struct {
+	SpellId  uint32 `json:"spell"`
+	Category uint32 `json:"category,omitempty"` // 非0表示类别冷却
+	End      int64  `json:"end"`                // 冷却结束时间(Unix毫秒)
+}
+
+// CharacterData 角色存档 - 基于AzerothCore的characters表
+type CharacterData struct {
+	GUID           uint64                   `json:"guid"`
+	Name           string                   `json:"name"`
+	Level          uint8                    `json:"level"`
+	Class          uint8                    `json:"class"`
+	SpellCooldowns []CharacterSpellCooldown `json:"spell_cooldowns"`
+	Reputation     map[uint32]int32         `json:"reputation,omitempty"` // 各阵营的声望值 - 对应character_reputation表
+}
+
+// CharacterDatabase 角色数据库 - 以JSON文件保存角色数据，每个角色一个文件
+type CharacterDatabase struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// 全局角色数据库
+var GlobalCharacterDatabase *CharacterDatabase
+
+// InitCharacterDatabase 初始化角色数据库
+func InitCharacterDatabase(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建角色数据目录失败: %v", err)
+	}
+	GlobalCharacterDatabase = &CharacterDatabase{dir: dir}
+	return nil
+}
+
+func (db *CharacterDatabase) characterPath(guid uint64) string {
+	return filepath.Join(db.dir, fmt.Sprintf("character_%d.json", guid))
+}
+
+// SaveCharacter 保存角色数据
+func (db *CharacterDatabase) SaveCharacter(data *CharacterData) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化角色 %s 失败: %v", data.Name, err)
+	}
+	if err := os.WriteFile(db.characterPath(data.GUID), content, 0644); err != nil {
+		return fmt.Errorf("保存角色 %s 失败: %v", data.Name, err)
+	}
+	return nil
+}
+
+// LoadCharacter 加载角色数据，角色不存在时返回nil
+func (db *CharacterDatabase) LoadCharacter(guid uint64) (*CharacterData, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	content, err := os.ReadFile(db.characterPath(guid))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取角色 %d 失败: %v", guid, err)
+	}
+
+	data := &CharacterData{}
+	if err := json.Unmarshal(content, data); err != nil {
+		return nil, fmt.Errorf("解析角色 %d 失败: %v", guid, err)
+	}
+	return data, nil
+}
+
+// BuildCharacterData 生成玩家的存档数据
+func (p *Player) BuildCharacterData() *CharacterData {
+	return &CharacterData{
+		GUID:           p.GetGUID(),
+		Name:           p.GetName(),
+		Level:          p.GetLevel(),
+		Class:          p.class,
+		SpellCooldowns: p.saveSpellCooldowns(),
+		Reputation:     p.reputation,
+	}
+}
+
+// LoadFromCharacterData 从存档恢复玩家数据
+func (p *Player) LoadFromCharacterData(data *CharacterData) {
+	p.loadSpellCooldowns(data.SpellCooldowns)
+	for faction, standing := range data.Reputation {
+		p.SetReputation(faction, standing)
+	}
+}
+
+// saveSpellCooldowns 导出未结束的法术冷却和类别冷却 - 公共冷却不保存
+func (u *Unit) saveSpellCooldowns() []CharacterSpellCooldown {
+	now := time.Now()
+	var cooldowns []CharacterSpellCooldown
+	for spellId, end := range u.spellCooldowns {
+		if end.After(now) {
+			cooldowns = append(cooldowns, CharacterSpellCooldown{SpellId: spellId, End: end.UnixMilli()})
+		}
+	}
+	for category, end := range u.categoryCooldowns {
+		if end.After(now) {
+			cooldowns = append(cooldowns, CharacterSpellCooldown{Category: category, End: end.UnixMilli()})
+		}
+	}
+	return cooldowns
+}
+
+// loadSpellCooldowns 恢复存档中的冷却，已经结束的冷却直接丢弃
+func (u *Unit) loadSpellCooldowns(cooldowns []CharacterSpellCooldown) {
+	now := time.Now()
+	for _, cooldown := range cooldowns {
+		end := time.UnixMilli(cooldown.End)
+		if !end.After(now) {
+			continue
+		}
+		if cooldown.Category != 0 {
+			u.categoryCooldowns[cooldown.Category] = end
+		} else {
+			u.spellCooldowns[cooldown.SpellId] = end
+		}
+	}
+}
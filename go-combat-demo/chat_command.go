@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 账号权限等级 - 基于AzerothCore的AccountTypes
+const (
+	SEC_PLAYER        = 0 // 普通玩家
+	SEC_MODERATOR     = 1 // 管理员
+	SEC_GAMEMASTER    = 2 // 游戏管理员
+	SEC_ADMINISTRATOR = 3 // 超级管理员
+)
+
+// 聊天消息类型 - 基于AzerothCore的ChatMsg
+const (
+	CHAT_MSG_SYSTEM       = 0x00 // 系统消息
+	CHAT_MSG_MONSTER_SAY  = 0x0C // 生物说话
+	CHAT_MSG_MONSTER_YELL = 0x0E // 生物喊话
+)
+
+// 生物说话和喊话的传播距离 - 基于AzerothCore的CONFIG_LISTEN_RANGE_SAY和CONFIG_LISTEN_RANGE_YELL
+const (
+	SAY_RANGE  = 25.0
+	YELL_RANGE = 300.0
+)
+
+// ChatCommand GM命令 - 基于AzerothCore的ChatCommand
+type ChatCommand struct {
+	name        string
+	security    uint8
+	handler     func(ws *WorldSession, args string) bool
+	subCommands []*ChatCommand
+}
+
+// chatCommandTable GM命令表
+var chatCommandTable = []*ChatCommand{
+	{
+		name:     "reload",
+		security: SEC_ADMINISTRATOR,
+		subCommands: []*ChatCommand{
+			{name: "spell_template", security: SEC_ADMINISTRATOR, handler: handleReloadSpellTemplateCommand},
+		},
+	},
+}
+
+// HandleChatCommand 解析并执行GM命令 - 基于AzerothCore的ChatHandler::ParseCommands
+func (ws *WorldSession) HandleChatCommand(text string) bool {
+	commands := chatCommandTable
+	args := strings.TrimSpace(text)
+
+	for {
+		name, rest, _ := strings.Cut(args, " ")
+		command := findChatCommand(commands, name)
+		if command == nil {
+			ws.SendSysMessage(fmt.Sprintf("未知命令: .%s", text))
+			return false
+		}
+		if ws.GetSecurity() < command.security {
+			ws.SendSysMessage("你没有权限使用该命令")
+			fmt.Printf("[命令] %s 权限不足，拒绝执行 .%s\n", ws.GetPlayerInfo(), text)
+			return false
+		}
+
+		args = strings.TrimSpace(rest)
+		if command.handler != nil {
+			fmt.Printf("[命令] %s 执行 .%s\n", ws.GetPlayerInfo(), text)
+			return command.handler(ws, args)
+		}
+		commands = command.subCommands
+	}
+}
+
+func findChatCommand(commands []*ChatCommand, name string) *ChatCommand {
+	for _, command := range commands {
+		if command.name == name {
+			return command
+		}
+	}
+	return nil
+}
+
+// handleReloadSpellTemplateCommand .reload spell_template - 重新读取法术数据，无需重启服务器
+func handleReloadSpellTemplateCommand(ws *WorldSession, args string) bool {
+	if GlobalSpellManager == nil {
+		ws.SendSysMessage("法术管理器未初始化")
+		return false
+	}
+	if err := GlobalSpellManager.LoadSpells(); err != nil {
+		ws.SendSysMessage(fmt.Sprintf("重载法术数据失败，保留原有数据: %v", err))
+		return false
+	}
+	ws.SendSysMessage("法术数据已重新加载")
+	return true
+}
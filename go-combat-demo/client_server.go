@@ -53,7 +53,7 @@ func (gc *GameClient) Login(player *Player) {
 	defer gc.mutex.Unlock()
 
 	gc.player = player
-	gc.session.SetPlayer(player)
+	gc.session.LoginPlayer(player)
 
 	fmt.Printf("客户端 %s 登录玩家: %s\n", gc.name, player.GetName())
 }
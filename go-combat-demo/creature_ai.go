@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// CreatureAIFactory 为生物创建AI
+type CreatureAIFactory func(creature *Creature) IAI
+
+// AI注册表 - 基于AzerothCore的CreatureAIRegistry和ScriptMgr
+// creature_template的ScriptName优先于AIName，都没有注册时使用默认的CreatureAI
+var (
+	creatureAIRegistry     = make(map[string]CreatureAIFactory)
+	creatureScriptRegistry = make(map[string]CreatureAIFactory)
+)
+
+// RegisterCreatureAI 注册通用AI，按creature_template.AIName选择
+func RegisterCreatureAI(name string, factory CreatureAIFactory) {
+	creatureAIRegistry[name] = factory
+}
+
+// RegisterCreatureScript 注册生物脚本，按creature_template.ScriptName选择
+func RegisterCreatureScript(name string, factory CreatureAIFactory) {
+	creatureScriptRegistry[name] = factory
+}
+
+// selectCreatureAI 为模板创建的生物选择AI - 基于AzerothCore的FactorySelector::SelectAI
+func selectCreatureAI(creature *Creature, template *CreatureTemplate) IAI {
+	if template.ScriptName != "" {
+		if factory, ok := creatureScriptRegistry[template.ScriptName]; ok {
+			return factory(creature)
+		}
+		fmt.Printf("生物 %d 的脚本 %s 没有注册\n", template.Entry, template.ScriptName)
+	}
+	if template.AIName != "" {
+		if factory, ok := creatureAIRegistry[template.AIName]; ok {
+			return factory(creature)
+		}
+		fmt.Printf("生物 %d 的AI %s 没有注册\n", template.Entry, template.AIName)
+	}
+	return NewCreatureAI(creature)
+}
+
+// ISummonerAI 会召唤生物的AI - 召唤物跟随召唤者一起更新
+type ISummonerAI interface {
+	GetSummons() []*Creature
+}
+
+// 通用AI
+func init() {
+	RegisterCreatureAI("AggressorAI", func(creature *Creature) IAI { return NewCreatureAI(creature) })
+	RegisterCreatureAI("CombatAI", func(creature *Creature) IAI { return NewCombatAI(creature) })
+}
+
+// 战斗AI施法间隔
+const (
+	COMBAT_AI_SPELL_TIMER_MIN = 5000
+	COMBAT_AI_SPELL_TIMER_MAX = 10000
+)
+
+// CombatAI 轮流施放模板法术的战斗AI - 基于AzerothCore的CombatAI
+// 每个法术一个事件，间隔为法术冷却，没有冷却的法术每5到10秒施放一次
+type CombatAI struct {
+	owner  *Creature
+	events EventMap
+}
+
+// NewCombatAI 创建战斗AI
+func NewCombatAI(owner *Creature) *CombatAI {
+	return &CombatAI{owner: owner}
+}
+
+// spellTimer 法术的施放间隔
+func (ai *CombatAI) spellTimer(spellId uint32) uint32 {
+	if GlobalSpellManager != nil {
+		if spellInfo := GlobalSpellManager.GetSpell(spellId); spellInfo != nil && spellInfo.Cooldown > 0 {
+			return uint32(spellInfo.Cooldown.Milliseconds())
+		}
+	}
+	return COMBAT_AI_SPELL_TIMER_MIN + uint32(rand.Intn(COMBAT_AI_SPELL_TIMER_MAX-COMBAT_AI_SPELL_TIMER_MIN+1))
+}
+
+func (ai *CombatAI) UpdateAI(diff uint32) {
+	if !ai.owner.IsAlive() {
+		return
+	}
+	victim := ai.owner.UpdateVictim()
+	if victim == nil {
+		return
+	}
+
+	ai.events.Update(diff)
+	if ai.owner.isCurrentlySpellCasting() {
+		return
+	}
+	if eventId := ai.events.ExecuteEvent(); eventId != 0 {
+		spellId := ai.owner.spells[eventId-1]
+		ai.owner.CastSpell(victim, spellId)
+		ai.events.RepeatEvent(ai.spellTimer(spellId))
+	}
+}
+
+// Reset 脱战时清空施法计时
+func (ai *CombatAI) Reset() {
+	ai.events.Reset()
+}
+
+func (ai *CombatAI) AttackStart(target IUnit) {}
+
+// EnterCombat 进入战斗时为每个模板法术调度首次施放
+func (ai *CombatAI) EnterCombat(target IUnit) {
+	ai.events.Reset()
+	for i, spellId := range ai.owner.spells {
+		if spellId != 0 {
+			ai.events.ScheduleEvent(uint32(i+1), ai.spellTimer(spellId), 0, 0)
+		}
+	}
+}
+
+func (ai *CombatAI) JustDied(killer IUnit) {
+	ai.events.Reset()
+}
+
+func (ai *CombatAI) DamageTaken(attacker IUnit, damage uint32) {}
+
+func (ai *CombatAI) DamageDealt(victim IUnit, damage uint32) {}
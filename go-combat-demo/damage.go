@@ -21,12 +21,15 @@ const (
 	DIRECT_DAMAGE       = 0 // 直接伤害
 	SPELL_DIRECT_DAMAGE = 1 // 法术直接伤害
 	NODAMAGE            = 2 // 无伤害
+	SELF_DAMAGE         = 5 // 自身伤害(摔落、溺水等环境伤害)
 )
 
 // 伤害处理实现 - 对应AzerothCore的Unit::DealDamage函数
 func (u *Unit) DealDamage(attacker IUnit, damage uint32, damageType int, schoolMask int) uint32 {
 	// 脚本钩子 - 允许修改伤害
-	damage = u.scriptHookDamage(attacker, damage, damageType)
+	if u.script != nil && u.script.OnDamageTaken != nil {
+		damage = u.script.OnDamageTaken(u, attacker, damage, damageType)
+	}
 
 	// 保存用于怒气计算的伤害值
 	rageDamage := damage
@@ -44,6 +47,17 @@ func (u *Unit) DealDamage(attacker IUnit, damage uint32, damageType int, schoolM
 		return 0
 	}
 
+	// 脱战返回中免疫所有伤害
+	if u.HasUnitState(UNIT_STATE_EVADE) {
+		fmt.Printf("%s 正在脱离战斗，免疫伤害\n", u.name)
+		return 0
+	}
+
+	// 生物被玩家伤害时记录拾取权
+	if u.unitType == UNIT_TYPE_CREATURE && attacker != nil {
+		u.SetLootRecipient(attacker)
+	}
+
 	// 如果伤害为0，仍然处理怒气奖励
 	if damage == 0 {
 		if unitSelf, ok := IUnit(u).(*Unit); ok {
@@ -52,9 +66,11 @@ func (u *Unit) DealDamage(attacker IUnit, damage uint32, damageType int, schoolM
 		return 0
 	}
 
-	// 决斗特殊处理（简化版）
-	if u.isDueling() && damage >= u.health {
+	// 决斗中对手造成的致命伤害只把生命值降到1点，伤害处理完后判负
+	duelLost := false
+	if u.isDueling(attacker) && damage >= u.health {
 		damage = u.health - 1 // 决斗中不会真正死亡
+		duelLost = true
 		fmt.Printf("决斗中 %s 的生命值被限制为1点\n", u.name)
 	}
 
@@ -131,18 +147,10 @@ func (u *Unit) DealDamage(attacker IUnit, damage uint32, damageType int, schoolM
 		u.handleSpellPushback(damage)
 	}
 
-	return damage
-}
-
-// 脚本钩子 - 允许脚本修改伤害
-func (u *Unit) scriptHookDamage(attacker IUnit, damage uint32, damageType int) uint32 {
-	// 这里可以添加各种脚本逻辑
-	// 例如：训练假人设置伤害为0
-	if u.name == "TrainingDummy" {
-		return 0
+	if duelLost {
+		u.player.DuelComplete(DUEL_WON)
 	}
 
-	// 其他脚本修改...
 	return damage
 }
 
@@ -152,21 +160,31 @@ func (u *Unit) isGMGodMode() bool {
 	return false
 }
 
-// 决斗检查
-func (u *Unit) isDueling() bool {
-	// 简化版：假设没有决斗
-	return false
+// isDueling 伤害是否来自决斗对手
+func (u *Unit) isDueling(attacker IUnit) bool {
+	if u.player == nil || attacker == nil {
+		return false
+	}
+	return u.player.IsInDuelWith(getPlayer(attacker))
 }
 
-// 切磋检查
+// isSparring 是否为不受玩家控制的生物之间的切磋 - 基于AzerothCore的Creature::CanSparringWith，
+// 互不敌对的生物之间的伤害不会致死
 func (u *Unit) isSparring(attacker IUnit) bool {
-	// 简化版：假设没有切磋
-	return false
+	other := getBaseUnit(attacker)
+	if other == nil || u.unitType != UNIT_TYPE_CREATURE || other.unitType != UNIT_TYPE_CREATURE {
+		return false
+	}
+	return u.player == nil && other.player == nil && !u.IsHostileTo(attacker)
 }
 
 // 处理死亡
 func (u *Unit) handleDeath(killer IUnit, damageType int, schoolMask int) {
-	fmt.Printf("%s 死于 %s 的攻击\n", u.name, killer.GetName())
+	if killer != nil {
+		fmt.Printf("%s 死于 %s 的攻击\n", u.name, killer.GetName())
+	} else {
+		fmt.Printf("%s 死亡\n", u.name)
+	}
 
 	// 设置生命值为0
 	u.health = 0
@@ -174,8 +192,9 @@ func (u *Unit) handleDeath(killer IUnit, damageType int, schoolMask int) {
 	// 调用死亡处理
 	u.setDeathState()
 
-	// 停止所有攻击
+	// 停止所有攻击和移动
 	u.AttackStop()
+	u.motionMaster.Clear()
 
 	// 清除所有攻击者的目标
 	for _, attacker := range u.attackers {
@@ -184,14 +203,34 @@ func (u *Unit) handleDeath(killer IUnit, damageType int, schoolMask int) {
 		}
 	}
 
+	// 从所有攻击者的仇恨列表中移除自己，并清空自己的仇恨列表
+	for _, attacker := range u.attackers {
+		if tm := getThreatManager(attacker); tm != nil {
+			tm.RemoveThreat(u)
+		}
+	}
+	u.threatManager.ClearAllThreat()
+
 	// 清空攻击者列表
 	u.attackers = make(map[uint64]IUnit)
+
+	// 生物死亡时分配经验，在尸体上生成拾取
+	if u.unitType == UNIT_TYPE_CREATURE {
+		u.rewardKill()
+		if u.creature != nil {
+			u.creature.generateLoot()
+		}
+	}
+
+	// 击杀者脚本
+	if killerUnit := getBaseUnit(killer); killerUnit != nil && killerUnit.script != nil && killerUnit.script.OnKill != nil {
+		killerUnit.script.OnKill(killerUnit, u)
+	}
 }
 
-// 移除直接伤害光环
+// 移除直接伤害光环 - 恐惧、变形等受到伤害即打破的控制效果
 func (u *Unit) removeDirectDamageAuras() {
-	// 简化版：假设移除了一些光环
-	// 在真实实现中，这里会移除具有AURA_INTERRUPT_FLAG_TAKE_DAMAGE标志的光环
+	u.RemoveAurasWithInterruptFlags(AURA_INTERRUPT_FLAG_TAKE_DAMAGE)
 }
 
 // 处理装备耐久度损失
@@ -202,13 +241,13 @@ func (u *Unit) handleDurabilityLoss() {
 	}
 }
 
-// 处理法术推迟
+// 处理法术推迟 - 读条法术推迟施法时间，引导法术缩短引导时间
 func (u *Unit) handleSpellPushback(damage uint32) {
-	// 简化版：如果正在施法，可能被推迟
-	if u.HasUnitState(UNIT_STATE_CASTING) {
-		if damage > 100 {
-			fmt.Printf("%s 的法术施放被推迟\n", u.name)
-		}
+	if spell := u.currentSpells[CURRENT_GENERIC_SPELL]; spell != nil {
+		spell.Delayed()
+	}
+	if spell := u.currentSpells[CURRENT_CHANNELED_SPELL]; spell != nil {
+		spell.DelayedChannel()
 	}
 }
 
@@ -222,69 +261,6 @@ func (u *Unit) rewardRageFromAbsorbedDamage(absorbedDamage uint32) {
 	}
 }
 
-// 威胁管理器
-type ThreatManager struct {
-	threatList map[uint64]*ThreatInfo
-}
-
-type ThreatInfo struct {
-	unit   IUnit
-	threat float32
-}
-
-func NewThreatManager() *ThreatManager {
-	return &ThreatManager{
-		threatList: make(map[uint64]*ThreatInfo),
-	}
-}
-
-func (tm *ThreatManager) AddThreat(unit IUnit, threat float32) {
-	guid := unit.GetGUID()
-	if info, exists := tm.threatList[guid]; exists {
-		info.threat += threat
-	} else {
-		tm.threatList[guid] = &ThreatInfo{
-			unit:   unit,
-			threat: threat,
-		}
-	}
-
-	if threat > 50 {
-		fmt.Printf("威胁值更新: %s 对目标的威胁值增加 %.1f\n", unit.GetName(), threat)
-	}
-}
-
-func (tm *ThreatManager) GetHighestThreatTarget() IUnit {
-	var highestThreat float32 = 0
-	var target IUnit = nil
-
-	for _, info := range tm.threatList {
-		if info.unit.IsAlive() && info.threat > highestThreat {
-			highestThreat = info.threat
-			target = info.unit
-		}
-	}
-
-	return target
-}
-
-func (tm *ThreatManager) RemoveThreat(unit IUnit) {
-	delete(tm.threatList, unit.GetGUID())
-}
-
-func (tm *ThreatManager) ClearAllThreat() {
-	tm.threatList = make(map[uint64]*ThreatInfo)
-}
-
-func (tm *ThreatManager) IsEmpty() bool {
-	for _, info := range tm.threatList {
-		if info.unit.IsAlive() {
-			return false
-		}
-	}
-	return true
-}
-
 // 伤害计算辅助函数
 func calculateArmorReduction(armor uint32, attackerLevel uint8) float32 {
 	// 简化的护甲减伤计算
@@ -346,12 +322,9 @@ func (u *Unit) absorbDamage(damage uint32, schoolMask int) (uint32, uint32) {
 
 // 获取单位所在的世界引用 - 辅助函数
 func getWorldFromUnit(unit IUnit) *World {
-	if u, ok := unit.(*Unit); ok {
+	if u := getBaseUnit(unit); u != nil {
 		return u.world
 	}
-	if p, ok := unit.(*Player); ok {
-		return p.world
-	}
 	return nil
 }
 
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// 死亡和复活 - 基于AzerothCore的Player::BuildPlayerRepop、Player::RepopAtGraveyard和Player::ResurrectPlayer
+
+// 玩家死亡状态 - 基于AzerothCore的DeathState和PLAYER_FLAGS_GHOST
+const (
+	DEATH_STATE_ALIVE = 0 // 存活
+	DEATH_STATE_DEAD  = 1 // 死亡，留在尸体上等待复活或释放灵魂
+	DEATH_STATE_GHOST = 2 // 释放灵魂后的灵魂形态，尸体留在死亡地点
+)
+
+// 死亡和复活参数
+const (
+	MAP_EASTERN_KINGDOMS = 0 // 开放世界地图，不在副本中的玩家都在这张地图上
+
+	CORPSE_RECLAIM_RADIUS = 39.0             // 灵魂在尸体多远以内可以复活
+	CORPSE_RECLAIM_DELAY  = 30 * time.Second // 释放灵魂后多久才能找回尸体
+	CORPSE_RESURRECT_PCT  = 50               // 找回尸体和灵魂医者复活恢复的生命和法力百分比
+	INTERACTION_DISTANCE  = 5.0              // 与NPC交互的距离
+
+	SPELL_RESURRECTION_SICKNESS       = 15007 // 复活虚弱
+	RESURRECTION_SICKNESS_START_LEVEL = 10    // 10级以上使用灵魂医者复活才会虚弱
+	RESURRECTION_SICKNESS_FULL_LEVEL  = 20    // 20级以下每级1分钟，20级起为法术的完整持续时间
+)
+
+// Corpse 玩家释放灵魂后留在死亡地点的尸体 - 基于AzerothCore的Corpse
+type Corpse struct {
+	guid      uint64
+	owner     *Player
+	x, y, z   float32
+	ghostTime time.Time // 释放灵魂的时间
+}
+
+// GetGUID 尸体的GUID
+func (c *Corpse) GetGUID() uint64 { return c.guid }
+
+// GetPosition 尸体的位置
+func (c *Corpse) GetPosition() (float32, float32, float32) { return c.x, c.y, c.z }
+
+// GetDistanceTo 尸体到单位的距离
+func (c *Corpse) GetDistanceTo(unit IUnit) float32 {
+	dx := c.x - unit.GetX()
+	dy := c.y - unit.GetY()
+	dz := c.z - unit.GetZ()
+	return float32(math.Sqrt(float64(dx*dx + dy*dy + dz*dz)))
+}
+
+// GetReclaimDelay 还需等待多久才能找回尸体
+func (c *Corpse) GetReclaimDelay(now time.Time) time.Duration {
+	if delay := CORPSE_RECLAIM_DELAY - now.Sub(c.ghostTime); delay > 0 {
+		return delay
+	}
+	return 0
+}
+
+// resurrectRequest 等待玩家确认的复活请求 - 基于AzerothCore的Player::SetResurrectRequestData
+type resurrectRequest struct {
+	caster     uint64
+	x, y, z    float32 // 接受后在施法者的位置复活
+	restorePct uint32
+}
+
+// GetDeathState 死亡状态
+func (p *Player) GetDeathState() uint8 { return p.deathState }
+
+// IsGhost 是否为灵魂形态
+func (p *Player) IsGhost() bool { return p.deathState == DEATH_STATE_GHOST }
+
+// GetCorpse 释放灵魂后留下的尸体，没有时返回nil
+func (p *Player) GetCorpse() *Corpse { return p.corpse }
+
+// ReleaseSpirit 释放灵魂：在死亡地点留下尸体，灵魂传送到墓地 - 基于AzerothCore的Player::BuildPlayerRepop
+func (p *Player) ReleaseSpirit() bool {
+	if p.IsAlive() || p.deathState == DEATH_STATE_GHOST {
+		return false
+	}
+	p.corpse = &Corpse{guid: generateGUID(), owner: p, x: p.x, y: p.y, z: p.z, ghostTime: time.Now()}
+	p.deathState = DEATH_STATE_GHOST
+	fmt.Printf("%s 释放了灵魂\n", p.GetName())
+
+	p.RepopAtGraveyard()
+	return true
+}
+
+// RepopAtGraveyard 灵魂传送到同一地图最近的墓地，副本中的玩家回到副本入口 - 基于AzerothCore的Player::RepopAtGraveyard
+func (p *Player) RepopAtGraveyard() {
+	if p.world != nil {
+		if dungeon := p.world.GetPlayerDungeon(p); dungeon != nil {
+			dungeon.RepopPlayer(p)
+			return
+		}
+	}
+	if GlobalObjectMgr == nil {
+		return
+	}
+	graveyard := GlobalObjectMgr.GetClosestGraveyard(MAP_EASTERN_KINGDOMS, p.x, p.y, p.z)
+	if graveyard == nil {
+		fmt.Printf("没有找到离 %s 最近的墓地\n", p.GetName())
+		return
+	}
+	p.SetPosition(graveyard.X, graveyard.Y, graveyard.Z)
+
+	packet := NewWorldPacket(SMSG_DEATH_RELEASE_LOC)
+	packet.WriteUint32(graveyard.MapId)
+	packet.WriteFloat32(graveyard.X)
+	packet.WriteFloat32(graveyard.Y)
+	packet.WriteFloat32(graveyard.Z)
+	p.SendDirectMessage(packet)
+	fmt.Printf("%s 的灵魂来到了%s\n", p.GetName(), graveyard.Name)
+}
+
+// ReclaimCorpse 灵魂回到尸体附近复活 - 基于AzerothCore的WorldSession::HandleReclaimCorpseOpcode
+func (p *Player) ReclaimCorpse() bool {
+	corpse := p.corpse
+	if p.deathState != DEATH_STATE_GHOST || corpse == nil {
+		return false
+	}
+	if delay := corpse.GetReclaimDelay(time.Now()); delay > 0 {
+		packet := NewWorldPacket(SMSG_CORPSE_RECLAIM_DELAY)
+		packet.WriteUint32(uint32(delay.Milliseconds()))
+		p.SendDirectMessage(packet)
+		fmt.Printf("%s 还需要等待 %.0f 秒才能复活\n", p.GetName(), delay.Seconds())
+		return false
+	}
+	if corpse.GetDistanceTo(p) > CORPSE_RECLAIM_RADIUS {
+		fmt.Printf("%s 距离尸体太远，无法复活\n", p.GetName())
+		return false
+	}
+	p.ResurrectPlayer(CORPSE_RESURRECT_PCT)
+	return true
+}
+
+// ResurrectPlayer 复活并按百分比恢复生命和法力，移除尸体 - 基于AzerothCore的Player::ResurrectPlayer
+func (p *Player) ResurrectPlayer(restorePct uint32) {
+	if p.IsAlive() {
+		return
+	}
+	p.deathState = DEATH_STATE_ALIVE
+	p.corpse = nil
+	p.resurrect = nil
+	p.ClearUnitState(UNIT_STATE_DIED)
+
+	p.SetHealth(max(calculatePct(p.GetMaxHealth(), float32(restorePct)), 1))
+	switch powerType := p.GetPowerType(); powerType {
+	case POWER_MANA:
+		p.SetPower(powerType, calculatePct(p.GetMaxPower(powerType), float32(restorePct)))
+	case POWER_RAGE:
+		p.SetPower(powerType, 0)
+	case POWER_ENERGY:
+		p.SetPower(powerType, p.GetMaxPower(powerType))
+	}
+	fmt.Printf("%s 复活了 (%d/%d)\n", p.GetName(), p.GetHealth(), p.GetMaxHealth())
+}
+
+// ResurrectUsingSpiritHealer 灵魂医者复活，10级以上获得复活虚弱 - 基于AzerothCore的WorldSession::HandleSpiritHealerActivateOpcode
+func (p *Player) ResurrectUsingSpiritHealer(healer *Creature) bool {
+	if p.deathState != DEATH_STATE_GHOST || healer == nil || !healer.HasNpcFlag(UNIT_NPC_FLAG_SPIRITHEALER) {
+		return false
+	}
+	if p.GetDistanceTo(healer) > INTERACTION_DISTANCE {
+		fmt.Printf("%s 距离 %s 太远\n", p.GetName(), healer.GetName())
+		return false
+	}
+	p.ResurrectPlayer(CORPSE_RESURRECT_PCT)
+	p.applyResurrectionSickness()
+	return true
+}
+
+// applyResurrectionSickness 复活虚弱 - 11到19级每级持续1分钟
+func (p *Player) applyResurrectionSickness() {
+	if p.level <= RESURRECTION_SICKNESS_START_LEVEL || GlobalSpellManager == nil {
+		return
+	}
+	info := GlobalSpellManager.GetSpell(SPELL_RESURRECTION_SICKNESS)
+	if info == nil {
+		return
+	}
+	duration := uint32(info.Duration.Milliseconds())
+	if p.level < RESURRECTION_SICKNESS_FULL_LEVEL {
+		duration = uint32(p.level-RESURRECTION_SICKNESS_START_LEVEL) * uint32(time.Minute.Milliseconds())
+	}
+	for i := range info.Effects {
+		if info.Effects[i].EffectType == SPELL_EFFECT_APPLY_AURA {
+			p.AddAura(NewAura(info, &info.Effects[i], p, p, duration))
+		}
+	}
+}
+
+// SetResurrectRequest 收到复活法术的请求，已有待确认的请求时返回false
+func (p *Player) SetResurrectRequest(caster IUnit, restorePct uint32) bool {
+	if p.IsAlive() || p.resurrect != nil {
+		return false
+	}
+	x, y, z := caster.GetPosition()
+	p.resurrect = &resurrectRequest{caster: caster.GetGUID(), x: x, y: y, z: z, restorePct: restorePct}
+
+	packet := NewWorldPacket(SMSG_RESURRECT_REQUEST)
+	packet.WriteUint64(caster.GetGUID())
+	packet.WriteString(caster.GetName())
+	p.SendDirectMessage(packet)
+	fmt.Printf("%s 想要复活 %s\n", caster.GetName(), p.GetName())
+	return true
+}
+
+// HasResurrectRequest 是否有待确认的复活请求
+func (p *Player) HasResurrectRequest() bool { return p.resurrect != nil }
+
+// ResurrectResponse 接受或拒绝复活请求 - 基于AzerothCore的WorldSession::HandleResurrectResponseOpcode
+func (p *Player) ResurrectResponse(casterGuid uint64, accept bool) bool {
+	request := p.resurrect
+	if request == nil || request.caster != casterGuid || p.IsAlive() {
+		return false
+	}
+	if !accept {
+		p.resurrect = nil
+		fmt.Printf("%s 拒绝了复活\n", p.GetName())
+		return false
+	}
+	p.SetPosition(request.x, request.y, request.z)
+	p.ResurrectPlayer(request.restorePct)
+	return true
+}
+
+// === 死亡和复活操作码 ===
+
+// HandleRepopRequestOpcode 释放灵魂 - 基于AzerothCore的WorldSession::HandleRepopRequestOpcode
+func (ws *WorldSession) HandleRepopRequestOpcode(packet *WorldPacket) {
+	if player := ws.sessionPlayer(); player != nil {
+		player.ReleaseSpirit()
+	}
+}
+
+// HandleReclaimCorpseOpcode 找回尸体，数据为尸体GUID
+func (ws *WorldSession) HandleReclaimCorpseOpcode(packet *WorldPacket) {
+	guid := packet.ReadUint64()
+	player := ws.sessionPlayer()
+	if player == nil || player.corpse == nil || player.corpse.guid != guid {
+		return
+	}
+	player.ReclaimCorpse()
+}
+
+// HandleResurrectResponseOpcode 回应复活请求，数据为施法者GUID和是否接受(1接受，0拒绝)
+func (ws *WorldSession) HandleResurrectResponseOpcode(packet *WorldPacket) {
+	guid := packet.ReadUint64()
+	status := packet.ReadUint8()
+	if player := ws.sessionPlayer(); player != nil {
+		player.ResurrectResponse(guid, status == 1)
+	}
+}
+
+// HandleSpiritHealerActivateOpcode 请求灵魂医者复活，数据为灵魂医者GUID
+func (ws *WorldSession) HandleSpiritHealerActivateOpcode(packet *WorldPacket) {
+	guid := packet.ReadUint64()
+	player := ws.sessionPlayer()
+	if player == nil || ws.world == nil {
+		return
+	}
+	healer, _ := ws.world.GetUnit(guid).(*Creature)
+	player.ResurrectUsingSpiritHealer(healer)
+}
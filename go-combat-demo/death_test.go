@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeathReleaseResurrectAndSpiritHealer(t *testing.T) {
+	if GlobalObjectMgr == nil {
+		InitObjectMgr()
+	}
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	priest, _ := newGroupTestPlayer(world, 1, "priest", CLASS_PRIEST, 0)
+	warrior, warriorSession := newGroupTestPlayer(world, 2, "warrior", CLASS_WARRIOR, 10)
+	mob := newThreatTestUnit("mob", 12)
+
+	warrior.DealDamage(mob, warrior.GetHealth(), DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	if warrior.IsAlive() || warrior.GetDeathState() != DEATH_STATE_DEAD {
+		t.Fatal("killed player should stay dead on the corpse")
+	}
+
+	// 复活术只能对死亡的玩家施放，目标可以拒绝，接受后在牧师身边复活
+	if NewSpell(priest, GlobalSpellManager.GetSpell(SPELL_RESURRECTION), nil).isValidTarget(priest) {
+		t.Fatal("resurrection should require a dead target")
+	}
+	respond := func(accept uint8) {
+		packet := NewWorldPacket(CMSG_RESURRECT_RESPONSE)
+		packet.WriteUint64(priest.GetGUID())
+		packet.WriteUint8(accept)
+		warriorSession.handlePacket(packet)
+	}
+	priest.CastSpell(warrior, SPELL_RESURRECTION)
+	priest.Update(10000)
+	if !warrior.HasResurrectRequest() {
+		t.Fatal("resurrection should ask the dead player to accept")
+	}
+	respond(0)
+	if warrior.HasResurrectRequest() || warrior.IsAlive() {
+		t.Fatal("declined resurrection should keep the player dead")
+	}
+	priest.globalCooldowns = make(map[uint32]time.Time)
+	priest.CastSpell(warrior, SPELL_RESURRECTION)
+	priest.Update(10000)
+	respond(1)
+	if !warrior.IsAlive() || warrior.GetHealth() != 350 || warrior.GetX() != priest.GetX() {
+		t.Fatalf("accepted resurrection should revive beside the priest, health %d x %.0f", warrior.GetHealth(), warrior.GetX())
+	}
+
+	// 释放灵魂后尸体留在原地，灵魂到最近的墓地，等待时间过后在尸体附近复活
+	warrior.DealDamage(mob, warrior.GetHealth(), DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	warriorSession.handlePacket(NewWorldPacket(CMSG_REPOP_REQUEST))
+	corpse := warrior.GetCorpse()
+	if !warrior.IsGhost() || corpse == nil || corpse.x != 0 || warrior.GetX() != -100 {
+		t.Fatalf("released spirit should leave a corpse and appear at the graveyard, x %.0f", warrior.GetX())
+	}
+	reclaim := func() {
+		packet := NewWorldPacket(CMSG_RECLAIM_CORPSE)
+		packet.WriteUint64(corpse.GetGUID())
+		warriorSession.handlePacket(packet)
+	}
+	warrior.SetPosition(20, 0, 0)
+	reclaim()
+	if warrior.IsAlive() {
+		t.Fatal("corpse should not be reclaimed before the delay")
+	}
+	corpse.ghostTime = corpse.ghostTime.Add(-CORPSE_RECLAIM_DELAY)
+	reclaim()
+	if !warrior.IsAlive() || warrior.GetCorpse() != nil || warrior.GetHealth() != 500 || warrior.HasAura(SPELL_RESURRECTION_SICKNESS) {
+		t.Fatal("ghost near the corpse should revive with half health")
+	}
+
+	// 灵魂医者复活会获得复活虚弱，造成的伤害降低
+	warrior.DealDamage(mob, warrior.GetHealth(), DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	warrior.ReleaseSpirit()
+	healer := GlobalObjectMgr.CreateCreature(6491)
+	healer.SetPosition(-100, 3, 0)
+	world.AddUnit(healer)
+	activate := NewWorldPacket(CMSG_SPIRIT_HEALER_ACTIVATE)
+	activate.WriteUint64(healer.GetGUID())
+	warriorSession.handlePacket(activate)
+	if !warrior.IsAlive() || !warrior.HasAura(SPELL_RESURRECTION_SICKNESS) || warrior.GetDamageDoneMultiplier() != 0.25 {
+		t.Fatal("spirit healer should revive with resurrection sickness")
+	}
+}
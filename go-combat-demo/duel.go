@@ -0,0 +1,311 @@
+package main
+
+import "fmt"
+
+// 决斗 - 基于AzerothCore的Spell::EffectDuel、Player::CheckDuelDistance和Player::DuelComplete
+
+// 决斗参数
+const (
+	SPELL_DUEL              = 7266  // 决斗 - 客户端通过CMSG_CAST_SPELL施放
+	DUEL_REQUEST_RANGE      = 10.0  // 发起决斗的距离 - 对应决斗法术7266的射程
+	DUEL_AREA_RADIUS        = 50.0  // 决斗区域半径，以决斗旗帜为中心
+	DUEL_COUNTDOWN_TIME     = 3000  // 接受后到开始的倒计时(毫秒)
+	DUEL_OUT_OF_BOUNDS_TIME = 10000 // 离开决斗区域后返回的时限(毫秒)
+	DUEL_ANNOUNCE_RANGE     = 100.0 // 胜负公告的广播范围
+)
+
+// 决斗结束类型 - 基于AzerothCore的DuelCompleteType
+const (
+	DUEL_INTERRUPTED = 0 // 开始前被拒绝或取消
+	DUEL_WON         = 1 // 生命值降到1点或认输
+	DUEL_FLED        = 2 // 离开决斗区域或下线
+)
+
+// DuelInfo 决斗状态 - 基于AzerothCore的DuelInfo，双方各持有一份
+type DuelInfo struct {
+	initiator           *Player
+	opponent            *Player
+	arbiter             uint64  // 决斗旗帜的GUID
+	flagX, flagY, flagZ float32 // 决斗旗帜插在双方中间
+	accepted            bool
+	startTimer          uint32 // 接受后的剩余倒计时
+	started             bool   // 倒计时结束后开始，开始前双方不能互相攻击
+	outOfBoundsTimer    uint32 // 离开决斗区域后的剩余时限，0表示在区域内
+}
+
+// GetDuelOpponent 决斗对手，没有决斗时返回nil
+func (p *Player) GetDuelOpponent() *Player {
+	if p.duel == nil {
+		return nil
+	}
+	return p.duel.opponent
+}
+
+// IsInDuelWith 是否正在与目标决斗
+func (p *Player) IsInDuelWith(target *Player) bool {
+	return p.duel != nil && p.duel.started && target != nil && p.duel.opponent == target
+}
+
+// SendDirectMessage 发送数据包给玩家自己的客户端 - 基于AzerothCore的Player::SendDirectMessage
+func (p *Player) SendDirectMessage(packet *WorldPacket) {
+	if p.world == nil {
+		return
+	}
+	if session := p.world.GetSessionByPlayerGUID(p.GetGUID()); session != nil {
+		session.SendPacket(packet)
+	}
+}
+
+// effectDuel 决斗效果 - 基于AzerothCore的Spell::EffectDuel，只能在玩家之间发起
+func (s *Spell) effectDuel(target IUnit) {
+	caster, opponent := getPlayer(s.caster), getPlayer(target)
+	if caster == nil || opponent == nil {
+		return
+	}
+	caster.ProposeDuel(opponent)
+}
+
+// ProposeDuel 向目标发起决斗 - 基于AzerothCore的Spell::EffectDuel
+func (p *Player) ProposeDuel(target *Player) bool {
+	if target == nil || target == p || p.world == nil {
+		return false
+	}
+	if !p.IsAlive() || !target.IsAlive() {
+		return false
+	}
+	if p.duel != nil || target.duel != nil {
+		fmt.Printf("[决斗] %s 或 %s 已经在决斗中\n", p.GetName(), target.GetName())
+		return false
+	}
+	if p.GetDistanceTo(target) > DUEL_REQUEST_RANGE {
+		fmt.Printf("[决斗] %s 距离 %s 太远，无法发起决斗\n", p.GetName(), target.GetName())
+		return false
+	}
+
+	arbiter := p.world.GenerateGUID()
+	flagX, flagY, flagZ := (p.x+target.x)/2, (p.y+target.y)/2, (p.z+target.z)/2
+	p.duel = &DuelInfo{initiator: p, opponent: target, arbiter: arbiter, flagX: flagX, flagY: flagY, flagZ: flagZ}
+	target.duel = &DuelInfo{initiator: p, opponent: p, arbiter: arbiter, flagX: flagX, flagY: flagY, flagZ: flagZ}
+	p.world.addDuelPlayers(p, target)
+
+	packet := NewWorldPacket(SMSG_DUEL_REQUESTED)
+	packet.WriteUint64(arbiter)
+	packet.WriteUint64(p.GetGUID())
+	p.SendDirectMessage(packet)
+	target.SendDirectMessage(packet)
+
+	fmt.Printf("[决斗] %s 向 %s 发起了决斗\n", p.GetName(), target.GetName())
+	return true
+}
+
+// AcceptDuel 接受决斗，双方开始倒计时 - 基于AzerothCore的WorldSession::HandleDuelAcceptedOpcode
+func (p *Player) AcceptDuel() bool {
+	duel := p.duel
+	if duel == nil || duel.accepted || duel.initiator == p {
+		return false
+	}
+	for _, info := range []*DuelInfo{duel, duel.opponent.duel} {
+		info.accepted = true
+		info.startTimer = DUEL_COUNTDOWN_TIME
+	}
+
+	packet := NewWorldPacket(SMSG_DUEL_COUNTDOWN)
+	packet.WriteUint32(DUEL_COUNTDOWN_TIME)
+	p.SendDirectMessage(packet)
+	duel.opponent.SendDirectMessage(packet)
+
+	fmt.Printf("[决斗] %s 接受了 %s 的决斗\n", p.GetName(), duel.opponent.GetName())
+	return true
+}
+
+// CancelDuel 拒绝或取消决斗 - 开始前视为中断，开始后视为认输
+func (p *Player) CancelDuel() {
+	if p.duel == nil {
+		return
+	}
+	if p.duel.started {
+		p.DuelComplete(DUEL_WON)
+	} else {
+		p.DuelComplete(DUEL_INTERRUPTED)
+	}
+}
+
+// DuelComplete 结束决斗，p为输的一方 - 基于AzerothCore的Player::DuelComplete
+func (p *Player) DuelComplete(completeType uint8) {
+	duel := p.duel
+	if duel == nil {
+		return
+	}
+	opponent := duel.opponent
+
+	complete := NewWorldPacket(SMSG_DUEL_COMPLETE)
+	if completeType == DUEL_INTERRUPTED {
+		complete.WriteUint8(0)
+	} else {
+		complete.WriteUint8(1)
+	}
+	p.SendDirectMessage(complete)
+	opponent.SendDirectMessage(complete)
+
+	switch completeType {
+	case DUEL_INTERRUPTED:
+		fmt.Printf("[决斗] %s 和 %s 的决斗被取消\n", p.GetName(), opponent.GetName())
+	default:
+		winner := NewWorldPacket(SMSG_DUEL_WINNER)
+		if completeType == DUEL_WON {
+			winner.WriteUint8(0)
+			fmt.Printf("[决斗] %s 在决斗中战胜了 %s\n", opponent.GetName(), p.GetName())
+		} else {
+			winner.WriteUint8(1)
+			fmt.Printf("[决斗] %s 逃离了决斗，%s 获胜\n", p.GetName(), opponent.GetName())
+		}
+		winner.WriteString(p.GetName())
+		winner.WriteString(opponent.GetName())
+		if p.world != nil {
+			p.world.BroadcastToPlayersInRange(duel.flagX, duel.flagY, duel.flagZ, DUEL_ANNOUNCE_RANGE, winner)
+		}
+	}
+
+	// 双方停止战斗，移除对方施加的光环
+	p.stopDuelCombat(opponent)
+	opponent.stopDuelCombat(p)
+	p.removeDuelAuras(opponent)
+	opponent.removeDuelAuras(p)
+
+	p.duel = nil
+	opponent.duel = nil
+	if p.world != nil {
+		p.world.removeDuelPlayers(p, opponent)
+	}
+}
+
+// stopDuelCombat 停止攻击决斗对手并清除相互的仇恨 - 对应AzerothCore的CombatStopWithPets
+func (p *Player) stopDuelCombat(opponent *Player) {
+	if victim := p.GetVictim(); victim != nil && victim.GetGUID() == opponent.GetGUID() {
+		p.AttackStop()
+	}
+	p.threatManager.RemoveThreat(opponent)
+	delete(p.attackers, opponent.GetGUID())
+	if len(p.attackers) == 0 && p.GetVictim() == nil {
+		p.SetInCombat(false)
+	}
+}
+
+// removeDuelAuras 移除决斗对手施加的光环
+func (p *Player) removeDuelAuras(opponent *Player) {
+	for _, aura := range append([]*Aura(nil), p.GetAuras()...) {
+		if caster := aura.GetCaster(); caster != nil && caster.GetGUID() == opponent.GetGUID() {
+			p.RemoveAura(aura, AURA_REMOVE_BY_DEFAULT)
+		}
+	}
+}
+
+// updateDuel 决斗倒计时和区域检查
+func (p *Player) updateDuel(diff uint32) {
+	duel := p.duel
+	if duel == nil {
+		return
+	}
+	if !p.IsAlive() {
+		p.DuelComplete(DUEL_INTERRUPTED)
+		return
+	}
+
+	if duel.accepted && !duel.started {
+		if duel.startTimer > diff {
+			duel.startTimer -= diff
+		} else {
+			duel.startTimer = 0
+			duel.started = true
+			if duel.initiator == p {
+				fmt.Printf("[决斗] %s 和 %s 的决斗开始了\n", p.GetName(), duel.opponent.GetName())
+			}
+		}
+	}
+
+	p.checkDuelDistance(diff)
+}
+
+// checkDuelDistance 离开决斗区域超过时限判负 - 基于AzerothCore的Player::CheckDuelDistance
+func (p *Player) checkDuelDistance(diff uint32) {
+	duel := p.duel
+	if calculateDistance2D(p.x, p.y, duel.flagX, duel.flagY) <= DUEL_AREA_RADIUS {
+		if duel.outOfBoundsTimer > 0 {
+			duel.outOfBoundsTimer = 0
+			p.SendDirectMessage(NewWorldPacket(SMSG_DUEL_INBOUNDS))
+			fmt.Printf("[决斗] %s 回到了决斗区域\n", p.GetName())
+		}
+		return
+	}
+
+	if duel.outOfBoundsTimer == 0 {
+		duel.outOfBoundsTimer = DUEL_OUT_OF_BOUNDS_TIME
+		packet := NewWorldPacket(SMSG_DUEL_OUTOFBOUNDS)
+		packet.WriteUint32(DUEL_OUT_OF_BOUNDS_TIME)
+		p.SendDirectMessage(packet)
+		fmt.Printf("[决斗] %s 离开了决斗区域\n", p.GetName())
+		return
+	}
+	if duel.outOfBoundsTimer > diff {
+		duel.outOfBoundsTimer -= diff
+		return
+	}
+	if duel.accepted {
+		p.DuelComplete(DUEL_FLED)
+	} else {
+		p.DuelComplete(DUEL_INTERRUPTED)
+	}
+}
+
+// addDuelPlayers 记录有决斗的玩家，由世界更新驱动倒计时
+func (w *World) addDuelPlayers(players ...*Player) {
+	w.duelMutex.Lock()
+	defer w.duelMutex.Unlock()
+	for _, player := range players {
+		w.duelPlayers[player.GetGUID()] = player
+	}
+}
+
+func (w *World) removeDuelPlayers(players ...*Player) {
+	w.duelMutex.Lock()
+	defer w.duelMutex.Unlock()
+	for _, player := range players {
+		delete(w.duelPlayers, player.GetGUID())
+	}
+}
+
+// updateDuels 更新所有决斗
+func (w *World) updateDuels(diff uint32) {
+	w.duelMutex.Lock()
+	players := make([]*Player, 0, len(w.duelPlayers))
+	for _, player := range w.duelPlayers {
+		players = append(players, player)
+	}
+	w.duelMutex.Unlock()
+
+	for _, player := range players {
+		player.updateDuel(diff)
+	}
+}
+
+// === 决斗操作码 ===
+
+// HandleDuelAcceptedOpcode 接受决斗，数据为决斗旗帜GUID - 基于AzerothCore的WorldSession::HandleDuelAcceptedOpcode
+func (ws *WorldSession) HandleDuelAcceptedOpcode(packet *WorldPacket) {
+	arbiter := packet.ReadUint64()
+	player := ws.sessionPlayer()
+	if player == nil || player.duel == nil || player.duel.arbiter != arbiter {
+		return
+	}
+	player.AcceptDuel()
+}
+
+// HandleDuelCancelledOpcode 拒绝或取消决斗，数据为决斗旗帜GUID - 基于AzerothCore的WorldSession::HandleDuelCancelledOpcode
+func (ws *WorldSession) HandleDuelCancelledOpcode(packet *WorldPacket) {
+	arbiter := packet.ReadUint64()
+	player := ws.sessionPlayer()
+	if player == nil || player.duel == nil || player.duel.arbiter != arbiter {
+		return
+	}
+	player.CancelDuel()
+}
@@ -0,0 +1,97 @@
+package main
+
+import "testing"
+
+func sendDuelPacket(session *WorldSession, opcode uint16, guid uint64) {
+	packet := NewWorldPacket(opcode)
+	packet.WriteUint64(guid)
+	session.handlePacket(packet)
+}
+
+// sendDuelRequest 客户端通过施放决斗法术发起决斗
+func sendDuelRequest(session *WorldSession, target *Player) {
+	packet := NewWorldPacket(CMSG_CAST_SPELL)
+	packet.WriteUint32(SPELL_DUEL)
+	packet.WriteUint64(target.GetGUID())
+	session.handlePacket(packet)
+	// 即时法术在下一次更新时才从施法列表中移除
+	session.sessionPlayer().updateSpells(0)
+}
+
+func TestDuelRequestCountdownFinishAndForfeit(t *testing.T) {
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	alice, aliceSession := newGroupTestPlayer(world, 1, "alice", CLASS_WARRIOR, 0)
+	bob, bobSession := newGroupTestPlayer(world, 2, "bob", CLASS_PRIEST, 4)
+	far, _ := newGroupTestPlayer(world, 3, "far", CLASS_MAGE, 40)
+	for _, player := range []*Player{alice, bob, far} {
+		world.AddUnit(player)
+	}
+
+	// 距离太远不能发起决斗，拒绝后双方都没有决斗状态
+	sendDuelRequest(aliceSession, far)
+	if alice.duel != nil || far.duel != nil {
+		t.Fatal("duel should require the target to be in range")
+	}
+	sendDuelRequest(aliceSession, bob)
+	if bob.GetDuelOpponent() != alice || alice.GetDuelOpponent() != bob {
+		t.Fatal("duel request should link both players")
+	}
+	sendDuelPacket(bobSession, CMSG_DUEL_CANCELLED, bob.duel.arbiter)
+	if alice.duel != nil || bob.duel != nil {
+		t.Fatal("declined duel should be cleared")
+	}
+
+	// 接受后倒计时结束才能互相攻击
+	sendDuelRequest(aliceSession, bob)
+	sendDuelPacket(bobSession, CMSG_DUEL_ACCEPTED, bob.duel.arbiter)
+	world.updateDuels(DUEL_COUNTDOWN_TIME - 1)
+	if alice.IsValidAttackTarget(bob) {
+		t.Fatal("duel should not start before the countdown ends")
+	}
+	world.updateDuels(1)
+	if !alice.IsInDuelWith(bob) || !alice.IsValidAttackTarget(bob) {
+		t.Fatal("duel should start after the countdown")
+	}
+
+	// 致命伤害只降到1点并结束决斗，对手的光环和相互的仇恨被清除
+	painInfo := &SpellInfo{ID: 589, Name: "暗言术：痛"}
+	alice.AddAura(NewAura(painInfo, &SpellEffect{ApplyAuraName: int(SPELL_AURA_PERIODIC_DAMAGE)}, bob, alice, 18000))
+	alice.Attack(bob)
+	alice.DealDamage(bob, 5000, DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	if !alice.IsAlive() || alice.GetHealth() != 1 {
+		t.Fatalf("duel loser should survive with 1 health, got %d", alice.GetHealth())
+	}
+	if alice.duel != nil || bob.duel != nil || len(world.duelPlayers) != 0 {
+		t.Fatal("duel should end when the loser drops to 1 health")
+	}
+	if len(alice.GetAuras()) != 0 || alice.GetVictim() != nil || alice.IsInCombat() || bob.IsInCombat() {
+		t.Fatal("duel auras and combat should be cleared")
+	}
+	if alice.threatManager.GetThreat(bob) != 0 || bob.threatManager.GetThreat(alice) != 0 {
+		t.Fatal("duel threat should be cleared")
+	}
+	if alice.IsValidAttackTarget(bob) {
+		t.Fatal("players should be friendly again after the duel")
+	}
+
+	// 离开决斗区域后返回可以继续，超过时限判负
+	alice.SetHealth(1000)
+	sendDuelRequest(aliceSession, bob)
+	sendDuelPacket(bobSession, CMSG_DUEL_ACCEPTED, bob.duel.arbiter)
+	world.updateDuels(DUEL_COUNTDOWN_TIME)
+	bob.SetPosition(DUEL_AREA_RADIUS+10, 0, 0)
+	world.updateDuels(100)
+	world.updateDuels(DUEL_OUT_OF_BOUNDS_TIME - 1000)
+	bob.SetPosition(4, 0, 0)
+	world.updateDuels(100)
+	if bob.duel == nil || bob.duel.outOfBoundsTimer != 0 {
+		t.Fatal("returning to the duel area should reset the forfeit timer")
+	}
+	bob.SetPosition(DUEL_AREA_RADIUS+10, 0, 0)
+	world.updateDuels(100)
+	world.updateDuels(DUEL_OUT_OF_BOUNDS_TIME)
+	if bob.duel != nil || alice.duel != nil {
+		t.Fatal("leaving the duel area for too long should forfeit the duel")
+	}
+}
@@ -2,13 +2,13 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
+	"math"
 	"time"
 )
 
-// 副本类型
+// 副本地图 - instance_template.map
 const (
-	DUNGEON_DEADMINES = 1
+	DUNGEON_DEADMINES = 36
 )
 
 // 副本难度
@@ -17,9 +17,46 @@ const (
 	DIFFICULTY_HEROIC = 1
 )
 
-// 副本特定常量
+// 遭遇战状态 - 基于AzerothCore的EncounterState
+const (
+	NOT_STARTED = 0
+	IN_PROGRESS = 1
+	FAIL        = 2
+	DONE        = 3
+)
+
+// 门的类型 - 基于AzerothCore的DoorType
+const (
+	DOOR_TYPE_ROOM    = 0 // 首领战斗中关闭，防止进出
+	DOOR_TYPE_PASSAGE = 1 // 击杀首领后打开
+)
+
+// 副本实例的布局
+const (
+	DUNGEON_INSTANCE_SPACING = 10000.0 // 所有副本共用一张世界地图，每个实例在x轴上错开，互不影响
+	DUNGEON_DOOR_STOP_DIST   = 2.0     // 被关闭的门挡住时停在门前的距离
+)
+
+// DoorData 首领控制的门 - 基于AzerothCore的DoorData，副本沿x轴展开，门挡住x坐标两侧的通行
+type DoorData struct {
+	name     string
+	x        float32
+	bossId   uint32
+	doorType uint8
+}
+
+// InstanceData 副本数据 - 基于AzerothCore的instance_template，刷新点从creature表按地图加载
+type InstanceData struct {
+	mapId      uint32
+	name       string
+	minLevel   uint8
+	maxLevel   uint8
+	maxPlayers uint8
+	entrance   Vector3
+	doors      []DoorData
+}
 
-// 副本结构
+// 副本结构 - 副本地图实例，由世界更新驱动
 type Dungeon struct {
 	id         uint32
 	name       string
@@ -27,196 +64,258 @@ type Dungeon struct {
 	minLevel   uint8
 	maxLevel   uint8
 	maxPlayers uint8
+	data       *InstanceData
+	originX    float32 // 实例在世界地图上的原点
 	encounters []*Encounter
 	trash      []*TrashGroup
-	players    []*Player
+	doors      []*Door
+	group      *Group        // 副本队伍，第一个进入的玩家为队长
+	save       *InstanceSave // 副本实例存档，第一个玩家进入时绑定
+	wiped      bool          // 团灭后等待玩家复活
+	completed  bool
 	world      *World
 }
 
-// 遭遇战结构
+// 遭遇战结构 - 阶段、技能和召唤由首领的BossAI脚本驱动
 type Encounter struct {
-	id       uint32
-	name     string
-	boss     *Creature
-	adds     []*Creature
-	phase    uint8
-	maxPhase uint8
-	isActive bool
+	id    uint32
+	name  string
+	boss  *Creature
+	state uint8
+}
+
+// GetBossAI 首领的脚本AI，首领没有使用BossAI时返回nil
+func (e *Encounter) GetBossAI() *BossAI {
+	ai, _ := e.boss.GetAI().(*BossAI)
+	return ai
 }
 
-// 小怪组结构
+// GetSummons 首领召唤的小怪
+func (e *Encounter) GetSummons() []*Creature {
+	if ai := e.GetBossAI(); ai != nil {
+		return ai.GetSummons()
+	}
+	return nil
+}
+
+// GetPhase 首领当前阶段
+func (e *Encounter) GetPhase() uint8 {
+	if ai := e.GetBossAI(); ai != nil {
+		return ai.GetPhase()
+	}
+	return 1
+}
+
+// GetState 遭遇战状态
+func (e *Encounter) GetState() uint8 { return e.state }
+
+// 小怪组结构 - 同一编队(creature.formation)的小怪一起被拉，没有编队的小怪单独成组
 type TrashGroup struct {
 	id        uint32
 	creatures []*Creature
 	isCleared bool
 }
 
+// Door 副本中的门
+type Door struct {
+	data *DoorData
+	x    float32 // 世界坐标
+	open bool
+}
+
+// IsOpen 门是否打开
+func (door *Door) IsOpen() bool { return door.open }
+
 // 创建死亡矿井副本
-func NewDeadminesDungeon(world *World) *Dungeon {
+func NewDeadminesDungeon(world *World, difficulty uint8) *Dungeon {
+	return NewDungeon(world, DUNGEON_DEADMINES, difficulty)
+}
+
+// NewDungeon 按副本数据创建实例并加入世界，生物在创建时按刷新点刷新
+// 副本、刷新点和生物模板都来自ObjectMgr，没有副本数据时返回nil
+func NewDungeon(world *World, mapId uint32, difficulty uint8) *Dungeon {
+	if GlobalObjectMgr == nil {
+		fmt.Printf("世界数据没有加载，无法创建副本 %d\n", mapId)
+		return nil
+	}
+	data := GlobalObjectMgr.GetInstanceData(mapId)
+	if data == nil {
+		fmt.Printf("副本 %d 不存在\n", mapId)
+		return nil
+	}
+
 	dungeon := &Dungeon{
-		id:         DUNGEON_DEADMINES,
-		name:       "死亡矿井",
-		difficulty: DIFFICULTY_NORMAL,
-		minLevel:   15,
-		maxLevel:   25,
-		maxPlayers: 5,
+		id:         data.mapId,
+		name:       data.name,
+		difficulty: difficulty,
+		minLevel:   data.minLevel,
+		maxLevel:   data.maxLevel,
+		maxPlayers: data.maxPlayers,
+		data:       data,
 		world:      world,
 	}
+	world.AddDungeon(dungeon)
 
-	// 创建小怪组
-	dungeon.createTrashGroups()
+	// 按刷新点创建小怪组和BOSS遭遇战
+	dungeon.spawnCreatures(GlobalObjectMgr.GetCreatureSpawns(mapId))
 
-	// 创建BOSS遭遇战
-	dungeon.createEncounters()
+	// 门的初始状态
+	dungeon.createDoors()
+
+	// 按难度调整生物
+	dungeon.applyDifficulty()
+
+	for _, creature := range dungeon.getCreatures() {
+		world.AddUnit(creature)
+	}
 
 	return dungeon
 }
 
-// 创建小怪组
-func (d *Dungeon) createTrashGroups() {
-	// 第一组小怪：矿工
-	group1 := &TrashGroup{
-		id: 1,
-		creatures: []*Creature{
-			d.createDefiasMiner("迪菲亚矿工", 18),
-			d.createDefiasMiner("迪菲亚矿工", 18),
-			d.createDefiasOverseer("迪菲亚监工", 20),
-		},
-	}
+// spawnCreatures 按刷新点创建生物：首领成为遭遇战，小怪按编队分组
+func (d *Dungeon) spawnCreatures(spawns []*CreatureData) {
+	for _, spawn := range spawns {
+		creature := d.spawnCreature(spawn)
+		if creature == nil {
+			continue
+		}
+		if spawn.Boss > 0 {
+			d.encounters = append(d.encounters, &Encounter{
+				id:    spawn.Boss,
+				name:  creature.GetName(),
+				boss:  creature,
+				state: NOT_STARTED,
+			})
+			continue
+		}
 
-	// 第二组小怪：盗贼
-	group2 := &TrashGroup{
-		id: 2,
-		creatures: []*Creature{
-			d.createDefiasThug("迪菲亚暴徒", 19),
-			d.createDefiasThug("迪菲亚暴徒", 19),
-			d.createDefiasConjurer("迪菲亚咒术师", 20),
-		},
+		var group *TrashGroup
+		if spawn.Formation > 0 {
+			group = d.GetTrashGroup(spawn.Formation)
+		}
+		if group == nil {
+			group = &TrashGroup{id: spawn.Formation}
+			d.trash = append(d.trash, group)
+		}
+		group.creatures = append(group.creatures, creature)
 	}
+}
 
-	// 第三组小怪：精英守卫
-	group3 := &TrashGroup{
-		id: 3,
-		creatures: []*Creature{
-			d.createDefiasElite("迪菲亚精英", 22),
-			d.createDefiasElite("迪菲亚精英", 22),
-		},
+// spawnCreature 在刷新点创建生物，坐标相对于副本原点
+func (d *Dungeon) spawnCreature(spawn *CreatureData) *Creature {
+	creature := GlobalObjectMgr.CreateCreature(spawn.Entry)
+	if creature == nil {
+		return nil
 	}
-
-	d.trash = []*TrashGroup{group1, group2, group3}
+	creature.Relocate(d.originX+spawn.X, spawn.Y, spawn.Z, spawn.Orientation)
+	creature.SetRespawnDelay(spawn.SpawnTimeSecs * 1000)
+	return creature
 }
 
-// 创建BOSS遭遇战
-func (d *Dungeon) createEncounters() {
-	// 范克里夫
-	vancleef := d.createVanCleef()
-	encounter1 := &Encounter{
-		id:       1,
-		name:     "埃德温·范克里夫",
-		boss:     vancleef,
-		adds:     []*Creature{},
-		phase:    1,
-		maxPhase: 3,
-		isActive: false,
+// createDoors 创建门并按首领状态设置开关
+func (d *Dungeon) createDoors() {
+	for i := range d.data.doors {
+		data := &d.data.doors[i]
+		d.doors = append(d.doors, &Door{data: data, x: d.originX + data.x})
 	}
-
-	d.encounters = []*Encounter{encounter1}
+	d.updateDoors()
 }
 
-// 创建迪菲亚矿工
-func (d *Dungeon) createDefiasMiner(name string, level uint8) *Creature {
-	miner := NewCreature(name, level, CREATURE_TYPE_HUMANOID)
-	miner.SetMaxHealth(1200)
-	miner.SetHealth(1200)
-	miner.SetMaxPower(POWER_MANA, 800)
-	miner.SetPower(POWER_MANA, 800)
-
-	// 设置基础属性（简化处理）
-
-	// 设置专门的矿工AI
-	miner.SetAI(NewMinerAI(miner))
-
-	return miner
+// applyDifficulty 英雄难度提高生物的生命值和伤害
+func (d *Dungeon) applyDifficulty() {
+	if d.difficulty != DIFFICULTY_HEROIC {
+		return
+	}
+	for _, creature := range d.getCreatures() {
+		maxHealth := uint32(math.Round(float64(creature.GetMaxHealth()) * HEROIC_HEALTH_MODIFIER))
+		creature.SetMaxHealth(maxHealth)
+		creature.SetHealth(maxHealth)
+		creature.SetDamageModifier(creature.GetDamageModifier() * HEROIC_DAMAGE_MODIFIER)
+	}
 }
 
-// 创建迪菲亚监工
-func (d *Dungeon) createDefiasOverseer(name string, level uint8) *Creature {
-	overseer := NewCreature(name, level, CREATURE_TYPE_HUMANOID)
-	overseer.SetMaxHealth(1800)
-	overseer.SetHealth(1800)
-	overseer.SetMaxPower(POWER_MANA, 1200)
-	overseer.SetPower(POWER_MANA, 1200)
-
-	// 设置基础属性（简化处理）
-
-	overseer.SetAI(NewOverseerAI(overseer))
-
-	return overseer
+// getCreatures 副本中刷新的所有生物(小怪和首领)
+func (d *Dungeon) getCreatures() []*Creature {
+	creatures := make([]*Creature, 0)
+	for _, group := range d.trash {
+		creatures = append(creatures, group.creatures...)
+	}
+	for _, encounter := range d.encounters {
+		creatures = append(creatures, encounter.boss)
+	}
+	return creatures
 }
 
-// 创建迪菲亚暴徒
-func (d *Dungeon) createDefiasThug(name string, level uint8) *Creature {
-	thug := NewCreature(name, level, CREATURE_TYPE_HUMANOID)
-	thug.SetMaxHealth(1500)
-	thug.SetHealth(1500)
-	thug.SetMaxPower(POWER_ENERGY, 100)
-	thug.SetPower(POWER_ENERGY, 100)
-
-	// 设置基础属性（简化处理）
-
-	thug.SetAI(NewThugAI(thug))
-
-	return thug
+// GetPlayers 副本中的玩家(副本队伍的成员)
+func (d *Dungeon) GetPlayers() []*Player {
+	if d.group == nil {
+		return nil
+	}
+	return d.group.GetMembers()
 }
 
-// 创建迪菲亚咒术师
-func (d *Dungeon) createDefiasConjurer(name string, level uint8) *Creature {
-	conjurer := NewCreature(name, level, CREATURE_TYPE_HUMANOID)
-	conjurer.SetMaxHealth(1000)
-	conjurer.SetHealth(1000)
-	conjurer.SetMaxPower(POWER_MANA, 2000)
-	conjurer.SetPower(POWER_MANA, 2000)
-
-	// 设置基础属性（简化处理）
-
-	conjurer.SetAI(NewConjurerAI(conjurer))
-
-	return conjurer
+// GetGroup 副本队伍
+func (d *Dungeon) GetGroup() *Group {
+	return d.group
 }
 
-// 创建迪菲亚精英
-func (d *Dungeon) createDefiasElite(name string, level uint8) *Creature {
-	elite := NewCreature(name, level, CREATURE_TYPE_HUMANOID)
-	elite.SetMaxHealth(2500)
-	elite.SetHealth(2500)
-	elite.SetMaxPower(POWER_RAGE, 100)
-	elite.SetPower(POWER_RAGE, 0)
-
-	// 设置基础属性（简化处理）
-
-	elite.SetAI(NewEliteAI(elite))
+// GetInstanceSave 副本实例存档，还没有玩家进入时为nil
+func (d *Dungeon) GetInstanceSave() *InstanceSave {
+	return d.save
+}
 
-	return elite
+// GetInstanceId 副本实例ID，还没有玩家进入时为0
+func (d *Dungeon) GetInstanceId() uint32 {
+	if d.save == nil {
+		return 0
+	}
+	return d.save.GetInstanceId()
 }
 
-// 创建范克里夫BOSS
-func (d *Dungeon) createVanCleef() *Creature {
-	vancleef := NewCreature("埃德温·范克里夫", 26, CREATURE_TYPE_HUMANOID)
-	vancleef.SetMaxHealth(8000)
-	vancleef.SetHealth(8000)
-	vancleef.SetMaxPower(POWER_ENERGY, 100)
-	vancleef.SetPower(POWER_ENERGY, 100)
+// GetEncounter 按首领ID查找遭遇战
+func (d *Dungeon) GetEncounter(id uint32) *Encounter {
+	for _, encounter := range d.encounters {
+		if encounter.id == id {
+			return encounter
+		}
+	}
+	return nil
+}
 
-	// BOSS级别属性（简化处理）
+// GetTrashGroup 按ID查找小怪组
+func (d *Dungeon) GetTrashGroup(id uint32) *TrashGroup {
+	for _, group := range d.trash {
+		if group.id == id {
+			return group
+		}
+	}
+	return nil
+}
 
-	vancleef.SetAI(NewVanCleefAI(vancleef))
+// GetDoor 按名字查找门
+func (d *Dungeon) GetDoor(name string) *Door {
+	for _, door := range d.doors {
+		if door.data.name == name {
+			return door
+		}
+	}
+	return nil
+}
 
-	return vancleef
+// IsCompleted 所有首领是否都已击杀
+func (d *Dungeon) IsCompleted() bool {
+	for _, encounter := range d.encounters {
+		if encounter.state != DONE {
+			return false
+		}
+	}
+	return true
 }
 
-// 添加玩家到副本
+// 添加玩家到副本 - 玩家加入副本队伍，已有队伍的第一个玩家带队伍进入
+// 第一个玩家决定进入的实例：队伍绑定的实例 > 玩家锁定的实例 > 新建实例 - 基于AzerothCore的MapInstanced::CreateInstanceForPlayer
 func (d *Dungeon) AddPlayer(player *Player) bool {
-	if len(d.players) >= int(d.maxPlayers) {
+	if len(d.GetPlayers()) >= int(d.maxPlayers) {
 		fmt.Printf("副本 %s 已满员\n", d.name)
 		return false
 	}
@@ -226,219 +325,341 @@ func (d *Dungeon) AddPlayer(player *Player) bool {
 		return false
 	}
 
-	d.players = append(d.players, player)
-	fmt.Printf("玩家 %s 进入副本 %s\n", player.GetName(), d.name)
-	return true
-}
+	mgr := d.world.GetInstanceSaveManager()
+	save := d.save
+	if save == nil && player.group != nil {
+		save = mgr.GetGroupBind(player.group.GetGUID(), d.id, d.difficulty)
+	}
+	bind := mgr.GetPlayerBind(player.GetGUID(), d.id, d.difficulty)
+	if save == nil && bind != nil {
+		save = mgr.GetInstanceSave(bind.InstanceId)
+	}
+	// 玩家已被锁定在同一难度的另一个实例
+	if save != nil && bind != nil && bind.InstanceId != save.InstanceId {
+		fmt.Printf("玩家 %s 已被锁定在副本 %s 的实例 %d 中\n", player.GetName(), d.name, bind.InstanceId)
+		return false
+	}
 
-// 开始副本
-func (d *Dungeon) Start() {
-	fmt.Printf("\n=== 副本 %s 开始 ===\n", d.name)
-	fmt.Printf("参与玩家: ")
-	for i, player := range d.players {
-		if i > 0 {
-			fmt.Print(", ")
+	switch {
+	case d.group == nil && player.group != nil:
+		d.group = player.group
+	case d.group == nil:
+		d.group = NewGroup(player)
+	case player.group != d.group:
+		if player.group != nil {
+			fmt.Printf("玩家 %s 已在其他队伍中，无法进入副本 %s\n", player.GetName(), d.name)
+			return false
 		}
-		fmt.Printf("%s(%s)", player.GetName(), d.getClassName(player.GetClass()))
+		d.group.AddMember(player)
 	}
-	fmt.Println()
 
-	// 清理小怪组
-	for i, group := range d.trash {
-		fmt.Printf("\n--- 第%d组小怪 ---\n", i+1)
-		d.fightTrashGroup(group)
-		if !d.allPlayersAlive() {
-			fmt.Println("团队全灭，副本失败！")
-			return
+	if d.save == nil {
+		if save == nil {
+			save = mgr.CreateInstanceSave(d.id, d.difficulty, time.Now())
 		}
-
-		// 战斗间隙恢复
-		d.restorePlayers()
-		time.Sleep(1 * time.Second)
+		d.loadInstanceSave(save)
 	}
+	mgr.BindGroup(d.group.GetGUID(), d.save)
+
+	// 传送到副本入口
+	entrance := d.data.entrance
+	player.SetPosition(d.originX+entrance.x, entrance.y, entrance.z)
+	fmt.Printf("玩家 %s(%s) 进入副本 %s (实例 %d)\n", player.GetName(), d.getClassName(player.GetClass()), d.name, d.save.InstanceId)
+	return true
+}
 
-	// BOSS战
+// loadInstanceSave 进入已有进度的实例时，已击杀的首领保持死亡
+func (d *Dungeon) loadInstanceSave(save *InstanceSave) {
+	d.save = save
 	for _, encounter := range d.encounters {
-		fmt.Printf("\n=== BOSS战：%s ===\n", encounter.name)
-		d.fightBoss(encounter)
-		if !d.allPlayersAlive() {
-			fmt.Println("团队全灭，副本失败！")
-			return
+		if !save.IsEncounterDone(encounter.id) {
+			continue
 		}
+		encounter.boss.health = 0
+		encounter.boss.AddUnitState(UNIT_STATE_DIED)
+		encounter.state = DONE
+		fmt.Printf("首领 %s 已在实例 %d 中被击杀\n", encounter.name, save.InstanceId)
 	}
-
-	fmt.Printf("\n🎉 恭喜！副本 %s 通关成功！\n", d.name)
+	d.updateDoors()
 }
 
-// 战斗小怪组
-func (d *Dungeon) fightTrashGroup(group *TrashGroup) {
-	fmt.Printf("遭遇小怪组：")
-	for i, creature := range group.creatures {
-		if i > 0 {
-			fmt.Print(", ")
-		}
-		fmt.Print(creature.GetName())
+// completeEncounter 记录首领击杀，英雄难度下副本中的玩家被锁定到该实例 - 基于AzerothCore的InstanceScript::SetBossState
+func (d *Dungeon) completeEncounter(encounter *Encounter) {
+	if d.save == nil {
+		return
 	}
-	fmt.Println()
+	mgr := d.world.GetInstanceSaveManager()
+	mgr.SetEncounterDone(d.save.InstanceId, encounter.id)
+	if d.difficulty != DIFFICULTY_HEROIC {
+		return
+	}
+	for _, player := range d.GetPlayers() {
+		mgr.BindPlayer(player.GetGUID(), d.save)
+	}
+}
 
-	// 开始战斗
-	for _, creature := range group.creatures {
-		d.world.AddUnit(creature)
+// announceBossLoot 首领被击败后公布尸体上的掉落，这里只输出列表，拾取由有拾取权的成员打开尸体完成
+func (d *Dungeon) announceBossLoot(encounter *Encounter) {
+	loot := encounter.boss.GetLoot()
+	if loot == nil {
+		return
 	}
+	fmt.Printf("💰 %s 掉落了: %s\n", encounter.name, loot)
+}
 
-	// 玩家开始攻击
-	tank := d.getTank()
-	if tank != nil {
-		// 坦克拉怪
-		for _, creature := range group.creatures {
-			tank.Attack(creature)
+// MoveGroupTo 队伍移动到副本内的位置(相对副本原点)，玩家靠近小怪时按仇恨范围拉怪，由此决定拉怪顺序
+// 被关闭的门挡住时停在门前
+func (d *Dungeon) MoveGroupTo(x, y, z float32) {
+	for i, player := range d.GetPlayers() {
+		if !player.IsAlive() {
+			continue
 		}
+		destX := d.clampToDoors(player.x, d.originX+x)
+		player.GetMotionMaster().MovePoint(destX, y+float32(i)*2, z)
 	}
+}
 
-	// 战斗循环
-	combatTime := 0
-	maxCombatTime := 30000 // 30秒超时
-
-	for d.hasAliveEnemies(group.creatures) && d.allPlayersAlive() && combatTime < maxCombatTime {
-		// 更新所有单位
-		for _, player := range d.players {
-			if player.IsAlive() {
-				player.Update(100)
-			}
+// clampToDoors 从fromX移动到toX时被关闭的门挡住的位置
+func (d *Dungeon) clampToDoors(fromX, toX float32) float32 {
+	for _, door := range d.doors {
+		if door.open {
+			continue
+		}
+		if fromX < door.x && toX > door.x-DUNGEON_DOOR_STOP_DIST {
+			toX = door.x - DUNGEON_DOOR_STOP_DIST
+		} else if fromX > door.x && toX < door.x+DUNGEON_DOOR_STOP_DIST {
+			toX = door.x + DUNGEON_DOOR_STOP_DIST
 		}
+	}
+	return toX
+}
 
-		for _, creature := range group.creatures {
-			if creature.IsAlive() {
-				creature.Update(100)
-			}
+// isPassable 两个位置之间没有关闭的门
+func (d *Dungeon) isPassable(a, b IUnit) bool {
+	ax, _, _ := a.GetPosition()
+	bx, _, _ := b.GetPosition()
+	for _, door := range d.doors {
+		if !door.open && (ax < door.x) != (bx < door.x) {
+			return false
 		}
+	}
+	return true
+}
 
-		combatTime += 100
-		time.Sleep(200 * time.Millisecond)
+// Update 副本更新 - 基于AzerothCore的InstanceMap::Update，由世界更新驱动
+// 更新玩家和生物、按仇恨范围拉怪、推进遭遇战状态并处理团灭
+func (d *Dungeon) Update(diff uint32) {
+	players := d.GetPlayers()
+	if len(players) == 0 {
+		return
+	}
 
-		// 每5秒显示一次战斗状态
-		if combatTime%5000 == 0 {
-			aliveEnemies := 0
-			for _, creature := range group.creatures {
-				if creature.IsAlive() {
-					aliveEnemies++
+	for _, player := range players {
+		if player.IsAlive() {
+			player.Update(diff)
+		}
+	}
+	for _, creature := range d.getCreatures() {
+		if creature.IsAlive() {
+			creature.Update(diff)
+		}
+		if summoner, ok := creature.GetAI().(ISummonerAI); ok {
+			for _, summon := range summoner.GetSummons() {
+				if summon.IsAlive() {
+					summon.Update(diff)
 				}
 			}
-			fmt.Printf("战斗进行中... 剩余敌人: %d\n", aliveEnemies)
 		}
 	}
 
-	if combatTime >= maxCombatTime {
-		fmt.Println("战斗超时！")
-	}
+	d.updateRespawns(diff)
 
-	group.isCleared = true
-	fmt.Printf("小怪组清理完成！\n")
+	d.checkWipe()
+	if d.wiped {
+		return
+	}
+	d.updateAggro()
+	d.updateTrashGroups()
+	d.updateEncounters()
 }
 
-// BOSS战
-func (d *Dungeon) fightBoss(encounter *Encounter) {
-	boss := encounter.boss
-	d.world.AddUnit(boss)
-
-	fmt.Printf("BOSS %s 出现！生命值：%d/%d\n",
-		boss.GetName(), boss.GetHealth(), boss.GetMaxHealth())
-
-	// 坦克开怪
-	tank := d.getTank()
-	if tank != nil {
-		tank.Attack(boss)
+// updateAggro 玩家进入仇恨范围时拉怪，同组小怪一起进入战斗 - 基于AzerothCore的CreatureAI::MoveInLineOfSight
+func (d *Dungeon) updateAggro() {
+	for _, group := range d.trash {
+		if group.isCleared {
+			continue
+		}
+		if target := d.findPullTarget(group.creatures); target != nil {
+			d.pull(group.creatures, target)
+		}
 	}
+	for _, encounter := range d.encounters {
+		boss := []*Creature{encounter.boss}
+		if target := d.findPullTarget(boss); target != nil {
+			d.pull(boss, target)
+		}
+	}
+}
 
-	encounter.isActive = true
-
-	// BOSS战循环
-	combatTime := 0
-	maxCombatTime := 60000 // 60秒超时
-
-	for boss.IsAlive() && d.allPlayersAlive() && combatTime < maxCombatTime {
-		// 检查阶段转换
-		d.checkPhaseTransition(encounter)
-
-		// 更新所有单位
-		for _, player := range d.players {
-			if player.IsAlive() {
-				player.Update(100)
+// findPullTarget 拉怪的目标：已在战斗中的同伴的目标，或者进入仇恨范围的玩家
+func (d *Dungeon) findPullTarget(creatures []*Creature) IUnit {
+	for _, creature := range creatures {
+		if creature.IsAlive() && creature.IsInCombat() {
+			if victim := creature.GetVictim(); victim != nil && victim.IsAlive() {
+				return victim
 			}
 		}
+	}
+	for _, creature := range creatures {
+		if !creature.IsAlive() || creature.IsInCombat() || creature.HasUnitState(UNIT_STATE_EVADE) {
+			continue
+		}
+		for _, player := range d.GetPlayers() {
+			if player.IsAlive() && creature.IsHostileTo(player) && creature.GetDistanceTo(player) <= creature.GetAggroRange(player) && d.isPassable(creature, player) {
+				return player
+			}
+		}
+	}
+	return nil
+}
 
-		boss.Update(100)
+// pull 没有进入战斗的生物攻击目标
+func (d *Dungeon) pull(creatures []*Creature, target IUnit) {
+	for _, creature := range creatures {
+		if !creature.IsAlive() || creature.IsInCombat() || creature.HasUnitState(UNIT_STATE_EVADE) {
+			continue
+		}
+		fmt.Printf("%s 发现了 %s\n", creature.GetName(), target.GetName())
+		creature.CombatStart(target)
+		creature.UpdateVictim()
+	}
+}
 
-		// 更新小怪
-		for _, add := range encounter.adds {
-			if add.IsAlive() {
-				add.Update(100)
+// updateRespawns 死亡的小怪按刷新点的重生时间重生，重生的小怪组重新需要清理 - 首领不会重生
+func (d *Dungeon) updateRespawns(diff uint32) {
+	for _, group := range d.trash {
+		for _, creature := range group.creatures {
+			if creature.updateRespawn(diff) {
+				group.isCleared = false
 			}
 		}
+	}
+}
 
-		combatTime += 100
-		time.Sleep(200 * time.Millisecond)
-
-		// 每10秒显示一次BOSS状态
-		if combatTime%10000 == 0 {
-			healthPercent := float64(boss.GetHealth()) / float64(boss.GetMaxHealth()) * 100
-			fmt.Printf("BOSS %s 生命值: %.1f%%\n", boss.GetName(), healthPercent)
+// updateTrashGroups 小怪组全部死亡时标记清除
+func (d *Dungeon) updateTrashGroups() {
+	for _, group := range d.trash {
+		if !group.isCleared && !d.hasAliveEnemies(group.creatures) {
+			group.isCleared = true
+			fmt.Printf("小怪组 %d 清理完成！\n", group.id)
 		}
 	}
+}
 
-	if combatTime >= maxCombatTime {
-		fmt.Println("BOSS战超时！")
+// updateEncounters 根据首领状态推进遭遇战，状态变化时更新门
+func (d *Dungeon) updateEncounters() {
+	for _, encounter := range d.encounters {
+		boss := encounter.boss
+		switch encounter.state {
+		case NOT_STARTED, FAIL:
+			if boss.IsAlive() && boss.IsInCombat() {
+				d.setBossState(encounter, IN_PROGRESS)
+			}
+		case IN_PROGRESS:
+			if !boss.IsAlive() {
+				d.setBossState(encounter, DONE)
+			} else if !boss.IsInCombat() {
+				d.setBossState(encounter, FAIL)
+			}
+		}
 	}
 
-	encounter.isActive = false
-	fmt.Printf("🎉 BOSS %s 被击败！\n", boss.GetName())
+	if !d.completed && d.IsCompleted() {
+		d.completed = true
+		fmt.Printf("\n🎉 恭喜！副本 %s 通关成功！\n", d.name)
+	}
 }
 
-// 检查阶段转换
-func (d *Dungeon) checkPhaseTransition(encounter *Encounter) {
-	boss := encounter.boss
-	healthPercent := float64(boss.GetHealth()) / float64(boss.GetMaxHealth()) * 100
+// setBossState 设置遭遇战状态 - 基于AzerothCore的InstanceScript::SetBossState
+func (d *Dungeon) setBossState(encounter *Encounter, state uint8) {
+	encounter.state = state
+	switch state {
+	case IN_PROGRESS:
+		fmt.Printf("\n=== BOSS战：%s ===\n", encounter.name)
+	case DONE:
+		fmt.Printf("🎉 BOSS %s 被击败！\n", encounter.name)
+		d.completeEncounter(encounter)
+		d.announceBossLoot(encounter)
+	case FAIL:
+		fmt.Printf("遭遇战 %s 失败，重置中...\n", encounter.name)
+	}
+	d.updateDoors()
+}
 
-	// 范克里夫的阶段转换
-	if encounter.name == "埃德温·范克里夫" {
-		if encounter.phase == 1 && healthPercent <= 66 {
-			encounter.phase = 2
-			fmt.Printf("🔥 %s 进入第二阶段！召唤小弟！\n", boss.GetName())
-			d.spawnVanCleefAdds(encounter)
-		} else if encounter.phase == 2 && healthPercent <= 33 {
-			encounter.phase = 3
-			fmt.Printf("⚡ %s 进入第三阶段！狂暴状态！\n", boss.GetName())
-			// 增加攻击力和攻击速度
+// updateDoors 按首领状态开关门 - 基于AzerothCore的InstanceScript::UpdateDoorState
+func (d *Dungeon) updateDoors() {
+	for _, door := range d.doors {
+		encounter := d.GetEncounter(door.data.bossId)
+		if encounter == nil {
+			continue
+		}
+		open := door.open
+		switch door.data.doorType {
+		case DOOR_TYPE_ROOM:
+			open = encounter.state != IN_PROGRESS
+		case DOOR_TYPE_PASSAGE:
+			open = encounter.state == DONE
+		}
+		if open != door.open {
+			door.open = open
+			if open {
+				fmt.Printf("[副本] %s 打开了\n", door.data.name)
+			} else {
+				fmt.Printf("[副本] %s 关闭了\n", door.data.name)
+			}
 		}
 	}
 }
 
-// 召唤范克里夫的小弟
-func (d *Dungeon) spawnVanCleefAdds(encounter *Encounter) {
-	add1 := d.createDefiasThug("迪菲亚保镖", 24)
-	add2 := d.createDefiasThug("迪菲亚保镖", 24)
-
-	encounter.adds = append(encounter.adds, add1, add2)
-	d.world.AddUnit(add1)
-	d.world.AddUnit(add2)
-
-	// 小弟攻击随机玩家
-	if len(d.players) > 0 {
-		target1 := d.players[rand.Intn(len(d.players))]
-		target2 := d.players[rand.Intn(len(d.players))]
-		add1.Attack(target1)
-		add2.Attack(target2)
+// checkWipe 所有玩家死亡时团灭：战斗中的生物脱战，没有清完的小怪组重生
+func (d *Dungeon) checkWipe() {
+	if d.anyPlayerAlive() {
+		d.wiped = false
+		return
 	}
-}
+	if d.wiped {
+		return
+	}
+	d.wiped = true
+	fmt.Println("团队全灭！")
 
-// 获取坦克
-func (d *Dungeon) getTank() *Player {
-	for _, player := range d.players {
-		if player.GetClass() == CLASS_WARRIOR && player.IsAlive() {
-			return player
+	for _, creature := range d.getCreatures() {
+		if creature.IsAlive() && creature.IsInCombat() {
+			creature.EnterEvadeMode()
+		}
+	}
+	for _, group := range d.trash {
+		if group.isCleared {
+			continue
+		}
+		for _, creature := range group.creatures {
+			creature.Respawn()
+		}
+	}
+	for _, encounter := range d.encounters {
+		if encounter.state == IN_PROGRESS {
+			d.setBossState(encounter, FAIL)
 		}
 	}
-	return nil
+}
+
+// RepopPlayer 副本中释放灵魂的玩家回到入口复活 - 对应AzerothCore中灵魂进入有自己尸体的副本时在入口复活
+// 团灭后全队释放灵魂跑回入口，脱战重置的首领可以重新挑战
+func (d *Dungeon) RepopPlayer(player *Player) {
+	entrance := d.data.entrance
+	player.SetPosition(d.originX+entrance.x, entrance.y, entrance.z)
+	fmt.Printf("%s 的灵魂回到了副本 %s 的入口\n", player.GetName(), d.name)
+	player.ResurrectPlayer(CORPSE_RESURRECT_PCT)
 }
 
 // 检查是否还有存活的敌人
@@ -451,9 +672,9 @@ func (d *Dungeon) hasAliveEnemies(creatures []*Creature) bool {
 	return false
 }
 
-// 检查所有玩家是否存活
-func (d *Dungeon) allPlayersAlive() bool {
-	for _, player := range d.players {
+// anyPlayerAlive 是否还有存活的玩家
+func (d *Dungeon) anyPlayerAlive() bool {
+	for _, player := range d.GetPlayers() {
 		if player.IsAlive() {
 			return true
 		}
@@ -461,26 +682,6 @@ func (d *Dungeon) allPlayersAlive() bool {
 	return false
 }
 
-// 恢复玩家状态
-func (d *Dungeon) restorePlayers() {
-	fmt.Println("战斗间隙，队伍休整...")
-	for _, player := range d.players {
-		if player.IsAlive() {
-			// 恢复生命值
-			player.SetHealth(player.GetMaxHealth())
-			// 恢复能量
-			switch player.GetClass() {
-			case CLASS_WARRIOR:
-				player.SetPower(POWER_RAGE, 0)
-			case CLASS_MAGE, CLASS_PRIEST, CLASS_WARLOCK, CLASS_HUNTER:
-				player.SetPower(POWER_MANA, player.GetMaxPower(POWER_MANA))
-			case CLASS_ROGUE:
-				player.SetPower(POWER_ENERGY, player.GetMaxPower(POWER_ENERGY))
-			}
-		}
-	}
-}
-
 // 获取职业名称
 func (d *Dungeon) getClassName(class uint8) string {
 	switch class {
@@ -500,3 +701,57 @@ func (d *Dungeon) getClassName(class uint8) string {
 		return "未知"
 	}
 }
+
+// === 世界中的副本实例 ===
+
+// AddDungeon 加入副本实例并分配在世界地图上的原点
+func (w *World) AddDungeon(dungeon *Dungeon) {
+	w.dungeonMutex.Lock()
+	defer w.dungeonMutex.Unlock()
+	w.nextDungeonSlot++
+	dungeon.originX = float32(w.nextDungeonSlot) * DUNGEON_INSTANCE_SPACING
+	w.dungeons = append(w.dungeons, dungeon)
+}
+
+// RemoveDungeon 移除副本实例及其生物
+func (w *World) RemoveDungeon(dungeon *Dungeon) {
+	w.dungeonMutex.Lock()
+	for i, d := range w.dungeons {
+		if d == dungeon {
+			w.dungeons = append(w.dungeons[:i], w.dungeons[i+1:]...)
+			break
+		}
+	}
+	w.dungeonMutex.Unlock()
+
+	for _, creature := range dungeon.getCreatures() {
+		w.RemoveUnit(creature.GetGUID())
+	}
+}
+
+// GetDungeons 所有副本实例
+func (w *World) GetDungeons() []*Dungeon {
+	w.dungeonMutex.Lock()
+	defer w.dungeonMutex.Unlock()
+	return append([]*Dungeon(nil), w.dungeons...)
+}
+
+// GetPlayerDungeon 玩家所在的副本实例，不在副本中时返回nil
+func (w *World) GetPlayerDungeon(player *Player) *Dungeon {
+	if player.group == nil {
+		return nil
+	}
+	for _, dungeon := range w.GetDungeons() {
+		if dungeon.group == player.group {
+			return dungeon
+		}
+	}
+	return nil
+}
+
+// updateDungeons 更新所有副本实例 - 不持有世界锁，副本更新中可以召唤生物加入世界
+func (w *World) updateDungeons(diff uint32) {
+	for _, dungeon := range w.GetDungeons() {
+		dungeon.Update(diff)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import "testing"
+
+func TestDungeonTickPullsDoorsAndWipe(t *testing.T) {
+	if GlobalObjectMgr == nil {
+		InitObjectMgr()
+	}
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+
+	tank, tankSession := newGroupTestPlayer(world, 1, "tank", CLASS_WARRIOR, 0)
+	healer, healerSession := newGroupTestPlayer(world, 2, "healer", CLASS_PRIEST, 0)
+	mage, _ := newGroupTestPlayer(world, 3, "mage", CLASS_MAGE, 0)
+
+	dm := NewDeadminesDungeon(world, DIFFICULTY_NORMAL)
+	other := NewDeadminesDungeon(world, DIFFICULTY_NORMAL)
+	if len(world.GetDungeons()) != 2 || other.originX-dm.originX != DUNGEON_INSTANCE_SPACING {
+		t.Fatalf("dungeons should run side by side on one world, origins %.0f and %.0f", dm.originX, other.originX)
+	}
+	if !dm.AddPlayer(tank) || !dm.AddPlayer(healer) || !other.AddPlayer(mage) {
+		t.Fatal("players should enter their dungeons")
+	}
+
+	// 走到暴徒一侧，只拉暴徒组，另一侧的矿工和另一个副本不受影响
+	tank.SetPosition(dm.originX+40, -8, 0)
+	healer.SetPosition(dm.originX+40, -6, 0)
+	world.Update(100)
+	for _, thug := range dm.GetTrashGroup(2).creatures {
+		if !thug.IsInCombat() {
+			t.Fatalf("%s should be pulled with its pack", thug.GetName())
+		}
+	}
+	for _, creature := range append(dm.GetTrashGroup(1).creatures, other.GetTrashGroup(2).creatures...) {
+		if creature.IsInCombat() {
+			t.Fatalf("%s should not be pulled", creature.GetName())
+		}
+	}
+
+	// 工厂大门在拉克佐死亡前挡住去路
+	factoryDoor := dm.GetDoor("工厂大门")
+	if factoryDoor.IsOpen() || dm.clampToDoors(dm.originX, dm.originX+150) != factoryDoor.x-DUNGEON_DOOR_STOP_DIST {
+		t.Fatal("factory door should block the way before Rhahk'Zor dies")
+	}
+	rhahkzor := dm.GetEncounter(DATA_RHAHKZOR)
+	rhahkzor.boss.CombatStart(tank)
+	world.Update(100)
+	if rhahkzor.GetState() != IN_PROGRESS {
+		t.Fatalf("engaged boss should be in progress, got %d", rhahkzor.GetState())
+	}
+	rhahkzor.boss.DealDamage(tank, rhahkzor.boss.GetHealth(), DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	world.Update(100)
+	if rhahkzor.GetState() != DONE || !factoryDoor.IsOpen() || !dm.GetInstanceSave().IsEncounterDone(DATA_RHAHKZOR) {
+		t.Fatal("killing Rhahk'Zor should save progress and open the factory door")
+	}
+
+	// 首领的装备由队伍掷骰分配，金钱由打开尸体的成员拾取后平分
+	boss := rhahkzor.boss
+	drop := boss.GetLoot().GetItems()[0].GetItemId()
+	sendLootRoll(tankSession, boss.GetGUID(), 0, ROLL_NEED)
+	sendLootRoll(healerSession, boss.GetGUID(), 0, ROLL_PASS)
+	tankX, tankY, tankZ := tank.GetPosition()
+	tank.SetPosition(boss.GetPosition())
+	sendLootPacket(tankSession, CMSG_LOOT, boss.GetGUID(), 0)
+	tankSession.handlePacket(NewWorldPacket(CMSG_LOOT_MONEY))
+	sendLootPacket(tankSession, CMSG_LOOT_RELEASE, boss.GetGUID(), 0)
+	tank.SetPosition(tankX, tankY, tankZ)
+	if tank.GetItemCount(drop) != 1 || !boss.GetLoot().IsLooted() || tank.GetMoney() == 0 || healer.GetMoney() == 0 {
+		t.Fatal("Rhahk'Zor's corpse should be looted by the group")
+	}
+
+	// 范克里夫战斗中船长室的门关闭
+	vancleef := dm.GetEncounter(DATA_VANCLEEF)
+	vancleef.boss.CombatStart(tank)
+	world.Update(100)
+	if dm.GetDoor("船长室的门").IsOpen() {
+		t.Fatal("room door should close during the encounter")
+	}
+
+	// 团灭：战斗中的生物脱战，没有清完的暴徒组重生，首领遭遇战失败
+	thug := dm.GetTrashGroup(2).creatures[0]
+	thug.DealDamage(tank, thug.GetHealth(), DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	tank.DealDamage(thug, tank.GetHealth(), DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	healer.DealDamage(thug, healer.GetHealth(), DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	world.Update(100)
+	if !thug.IsAlive() || thug.IsInCombat() {
+		t.Fatal("unfinished trash pack should respawn on wipe")
+	}
+	if vancleef.GetState() != FAIL || !dm.GetDoor("船长室的门").IsOpen() || !vancleef.boss.HasUnitState(UNIT_STATE_EVADE) {
+		t.Fatal("wipe should fail the encounter, evade the boss and open the room door")
+	}
+	if !factoryDoor.IsOpen() {
+		t.Fatal("killed boss should keep its passage open after a wipe")
+	}
+
+	// 释放灵魂后在副本入口复活，可以重新开始
+	for _, player := range []*Player{tank, healer} {
+		if !player.ReleaseSpirit() || !player.IsAlive() || player.GetX() != dm.originX {
+			t.Fatalf("%s should run back to the dungeon entrance", player.GetName())
+		}
+	}
+	world.Update(100)
+	if dm.wiped {
+		t.Fatal("revived group should be able to try again")
+	}
+}
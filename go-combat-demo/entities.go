@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 )
 
@@ -9,14 +10,39 @@ import (
 type Player struct {
 	*Unit
 	class uint8
+
+	// 队伍
+	group       *Group // 所在队伍
+	groupInvite *Group // 收到但尚未处理的邀请
+
+	xp uint32 // 当前等级已获得的经验
+
+	// 阵营关系
+	pvp        bool             // PvP标记
+	reputation map[uint32]int32 // 各阵营的声望值
+	duel       *DuelInfo        // 进行中的决斗
+
+	// 死亡和复活
+	deathState uint8             // DEATH_STATE_*
+	corpse     *Corpse           // 释放灵魂后留下的尸体
+	resurrect  *resurrectRequest // 等待确认的复活请求
+
+	// 物品和拾取
+	items    map[uint32]uint32 // 背包中的物品数量
+	money    uint32            // 金钱(铜币)
+	lootGuid uint64            // 打开了拾取窗口的尸体
 }
 
 // 创建玩家
 func NewPlayer(name string, level uint8, class uint8) *Player {
 	player := &Player{
-		Unit:  NewUnit(generateGUID(), name, level, UNIT_TYPE_PLAYER),
-		class: class,
+		Unit:       NewUnit(generateGUID(), name, level, UNIT_TYPE_PLAYER),
+		class:      class,
+		reputation: make(map[uint32]int32),
+		items:      make(map[uint32]uint32),
 	}
+	player.Unit.player = player
+	player.SetFaction(FACTION_TEMPLATE_HUMAN)
 
 	// 设置基础AI
 	player.SetAI(NewPlayerAI(player))
@@ -44,19 +70,42 @@ func (p *Player) GetClass() uint8 {
 	return p.class
 }
 
+// GetPowerType 职业的主要能量类型
+func (p *Player) GetPowerType() uint8 {
+	switch p.class {
+	case CLASS_WARRIOR:
+		return POWER_RAGE
+	case CLASS_ROGUE:
+		return POWER_ENERGY
+	}
+	return POWER_MANA
+}
+
 // 生物结构
 type Creature struct {
 	*Unit
-	creatureType uint8
-	ai           IAI
+	ai IAI
+
+	// 出生点 - 脱战后返回的位置
+	homeX, homeY, homeZ float32
+	homeOrientation     float32
+
+	entry        uint32                      // 生物模板编号，0表示不是由模板创建
+	spells       [CREATURE_MAX_SPELLS]uint32 // 模板中的法术 - 对应AzerothCore的Creature::m_spells
+	respawnDelay uint32                      // 死亡后重生的时间(毫秒)，0表示不重生
+	respawnTimer uint32                      // 已死亡的时间
+	npcFlags     uint32                      // NPC功能(UNIT_NPC_FLAG_*)
+	loot         *Loot                       // 尸体上的拾取
 }
 
 // 创建生物
 func NewCreature(name string, level uint8, creatureType uint8) *Creature {
 	creature := &Creature{
-		Unit:         NewUnit(generateGUID(), name, level, UNIT_TYPE_CREATURE),
-		creatureType: creatureType,
+		Unit: NewUnit(generateGUID(), name, level, UNIT_TYPE_CREATURE),
 	}
+	creature.Unit.creature = creature
+	creature.creatureType = creatureType
+	creature.SetFaction(FACTION_TEMPLATE_MONSTER)
 
 	// 设置基础AI
 	creature.SetAI(NewCreatureAI(creature))
@@ -64,8 +113,250 @@ func NewCreature(name string, level uint8, creatureType uint8) *Creature {
 	return creature
 }
 
-func (c *Creature) GetCreatureType() uint8 {
-	return c.creatureType
+// SetHomePosition 设置出生点
+func (c *Creature) SetHomePosition(x, y, z, orientation float32) {
+	c.homeX, c.homeY, c.homeZ = x, y, z
+	c.homeOrientation = orientation
+}
+
+// GetHomePosition 获取出生点
+func (c *Creature) GetHomePosition() (float32, float32, float32) {
+	return c.homeX, c.homeY, c.homeZ
+}
+
+// Relocate 将生物放置到指定位置并设为出生点
+func (c *Creature) Relocate(x, y, z, orientation float32) {
+	c.SetPosition(x, y, z)
+	c.orientation = orientation
+	c.SetHomePosition(x, y, z, orientation)
+}
+
+// GetDistanceToHome 获取与出生点的距离
+func (c *Creature) GetDistanceToHome() float32 {
+	dx := c.x - c.homeX
+	dy := c.y - c.homeY
+	dz := c.z - c.homeZ
+	return float32(math.Sqrt(float64(dx*dx + dy*dy + dz*dz)))
+}
+
+// Update 更新生物 - 在单位更新之后检查拉扯和脱战条件
+func (c *Creature) Update(diff uint32) {
+	if c.HasUnitState(UNIT_STATE_EVADE) {
+		c.updateEvade(diff)
+		return
+	}
+
+	c.Unit.Update(diff)
+
+	if c.IsAlive() && c.IsInCombat() && c.shouldEvade() {
+		c.EnterEvadeMode()
+	}
+}
+
+// shouldEvade 检查是否需要脱战 - 被拉出拉扯范围或仇恨列表为空
+func (c *Creature) shouldEvade() bool {
+	if c.GetDistanceToHome() > CREATURE_LEASH_RANGE {
+		fmt.Printf("%s 被拉离出生点过远 (%.1f码)\n", c.GetName(), c.GetDistanceToHome())
+		return true
+	}
+
+	if !c.threatManager.IsEmpty() {
+		return false
+	}
+	for _, attacker := range c.attackers {
+		if attacker.IsAlive() {
+			return false
+		}
+	}
+	return true
+}
+
+// EnterEvadeMode 进入脱战模式 - 基于AzerothCore的CreatureAI::EnterEvadeMode
+// 停止攻击、清空仇恨、离开战斗并返回出生点，返回途中免疫伤害并恢复生命值
+func (c *Creature) EnterEvadeMode() {
+	if !c.IsAlive() || c.HasUnitState(UNIT_STATE_EVADE) {
+		return
+	}
+
+	fmt.Printf("%s 脱离战斗，返回出生点\n", c.GetName())
+
+	c.InterruptNonMeleeSpells(false)
+	c.AttackStop()
+	c.RemoveAllAuras(AURA_REMOVE_BY_DEFAULT) // 脱战清除身上的控制效果
+
+	// 从所有攻击者的仇恨列表和攻击者列表中移除自己
+	for _, attacker := range c.attackers {
+		if tm := getThreatManager(attacker); tm != nil {
+			tm.RemoveThreat(c)
+		}
+		if base := getBaseUnit(attacker); base != nil {
+			delete(base.attackers, c.GetGUID())
+		}
+		if victim := attacker.GetVictim(); victim != nil && victim.GetGUID() == c.GetGUID() {
+			attacker.AttackStop()
+		}
+	}
+	c.attackers = make(map[uint64]IUnit)
+	c.threatManager.ClearAllThreat()
+	c.SetInCombat(false)
+	c.SetLootRecipient(nil)
+
+	c.AddUnitState(UNIT_STATE_EVADE)
+	c.motionMaster.MoveTargetedHome(c.homeX, c.homeY, c.homeZ, c.homeOrientation, c.reachedHome)
+
+	// 重置AI状态(BOSS阶段、技能计时器等)
+	if ai, ok := c.GetAI().(IResettableAI); ok {
+		ai.Reset()
+	}
+}
+
+// updateEvade 脱战返回 - 由返回出生点的移动生成器以奔跑速度移动，途中恢复生命值
+func (c *Creature) updateEvade(diff uint32) {
+	if !c.IsAlive() {
+		c.ClearUnitState(UNIT_STATE_EVADE)
+		return
+	}
+
+	// 恢复生命值
+	regen := c.GetMaxHealth() * EVADE_REGEN_PCT_PER_SEC / 100 * diff / 1000
+	if regen == 0 {
+		regen = 1
+	}
+	c.SetHealth(min(c.GetHealth()+regen, c.GetMaxHealth()))
+
+	c.updateMovement(diff)
+}
+
+// reachedHome 回到出生点 - 恢复满状态并结束脱战
+func (c *Creature) reachedHome() {
+	c.SetPosition(c.homeX, c.homeY, c.homeZ)
+	c.orientation = c.homeOrientation
+	c.SetHealth(c.GetMaxHealth())
+	for powerType, maxPower := range c.maxPowers {
+		if powerType == POWER_RAGE {
+			c.SetPower(powerType, 0)
+		} else {
+			c.SetPower(powerType, maxPower)
+		}
+	}
+	c.ClearUnitState(UNIT_STATE_EVADE)
+	c.AddBatchUpdateForMovement()
+
+	fmt.Printf("%s 回到出生点，状态已重置 (%d/%d)\n", c.GetName(), c.GetHealth(), c.GetMaxHealth())
+}
+
+// GetEntry 生物模板编号
+func (c *Creature) GetEntry() uint32 { return c.entry }
+
+// GetSpells 模板中的法术
+func (c *Creature) GetSpells() [CREATURE_MAX_SPELLS]uint32 { return c.spells }
+
+// HasNpcFlag 是否有指定的NPC功能
+func (c *Creature) HasNpcFlag(flag uint32) bool { return c.npcFlags&flag != 0 }
+
+// SetRespawnDelay 设置重生时间
+func (c *Creature) SetRespawnDelay(delay uint32) { c.respawnDelay = delay }
+
+// updateRespawn 死亡后经过重生时间时重生，返回是否重生
+func (c *Creature) updateRespawn(diff uint32) bool {
+	if c.IsAlive() || c.respawnDelay == 0 {
+		return false
+	}
+	c.respawnTimer += diff
+	if c.respawnTimer < c.respawnDelay {
+		return false
+	}
+	c.Respawn()
+	return true
+}
+
+// GetAggroRange 对目标的仇恨范围 - 目标等级每高1级减少1码，每低1级增加1码
+func (c *Creature) GetAggroRange(target IUnit) float32 {
+	radius := float32(CREATURE_AGGRO_RADIUS_BASE) + float32(c.GetLevel()) - float32(target.GetLevel())
+	if radius < CREATURE_AGGRO_RADIUS_MIN {
+		return CREATURE_AGGRO_RADIUS_MIN
+	}
+	if radius > CREATURE_AGGRO_RADIUS_MAX {
+		return CREATURE_AGGRO_RADIUS_MAX
+	}
+	return radius
+}
+
+// Respawn 重生 - 基于AzerothCore的Creature::Respawn，在出生点以满状态复活
+func (c *Creature) Respawn() {
+	if c.IsAlive() {
+		return
+	}
+
+	c.ClearUnitState(UNIT_STATE_DIED)
+	c.respawnTimer = 0
+	c.attackers = make(map[uint64]IUnit)
+	c.threatManager.ClearAllThreat()
+	c.SetVictim(nil)
+	c.SetInCombat(false)
+	c.SetLootRecipient(nil)
+	c.loot = nil
+	c.motionMaster.Clear()
+	c.StopMoving()
+	c.reachedHome()
+
+	if ai, ok := c.GetAI().(IResettableAI); ok {
+		ai.Reset()
+	}
+	fmt.Printf("%s 重生了\n", c.GetName())
+}
+
+// SummonCreature 在身边按模板召唤生物并加入世界 - 基于AzerothCore的WorldObject::SummonCreature
+func (c *Creature) SummonCreature(entry uint32) *Creature {
+	if GlobalObjectMgr == nil {
+		return nil
+	}
+	summon := GlobalObjectMgr.CreateCreature(entry)
+	if summon == nil {
+		return nil
+	}
+
+	angle := rand.Float64() * 2 * math.Pi
+	x := c.GetX() + float32(math.Cos(angle))*CREATURE_SUMMON_DISTANCE
+	y := c.GetY() + float32(math.Sin(angle))*CREATURE_SUMMON_DISTANCE
+	summon.SetPosition(x, y, c.GetZ())
+	summon.SetHomePosition(x, y, c.GetZ(), c.orientation)
+
+	if c.world != nil {
+		summon.SetWorld(c.world)
+		c.world.AddUnit(summon)
+	}
+	return summon
+}
+
+// UpdateVictim 根据仇恨列表选择攻击目标 - 基于AzerothCore的CreatureAI::UpdateVictim
+func (c *Creature) UpdateVictim() IUnit {
+	victim := c.threatManager.SelectVictim()
+	if victim == nil {
+		// 仇恨列表为空时回退到攻击者列表
+		for _, attacker := range c.attackers {
+			if attacker.IsAlive() {
+				victim = attacker
+				break
+			}
+		}
+	}
+	if victim == nil {
+		return nil
+	}
+
+	if current := c.GetVictim(); current == nil || current.GetGUID() != victim.GetGUID() {
+		if !c.Attack(victim) {
+			// 不在近战范围内也要锁定目标
+			c.SetVictim(victim)
+		}
+	}
+
+	// 追向目标 - 基于AzerothCore的CreatureAI::AttackStart中的MoveChase，冲锋途中不打断
+	if !c.HasUnitState(UNIT_STATE_CHARGING) {
+		c.motionMaster.MoveChase(victim, 0)
+	}
+	return c.GetVictim()
 }
 
 // === Player网络支持方法 ===
@@ -138,6 +429,7 @@ type PlayerAI struct {
 	lastActionTime  uint32
 	lastHealTime    uint32
 	lastSpecialTime uint32
+	lastBuffTime    uint32
 }
 
 func NewPlayerAI(owner *Player) *PlayerAI {
@@ -152,6 +444,7 @@ func (ai *PlayerAI) UpdateAI(diff uint32) {
 	ai.lastActionTime += diff
 	ai.lastHealTime += diff
 	ai.lastSpecialTime += diff
+	ai.lastBuffTime += diff
 
 	// 根据职业执行不同的AI逻辑
 	switch ai.owner.GetClass() {
@@ -184,6 +477,14 @@ func (ai *PlayerAI) updateWarriorAI() {
 		}
 	}
 
+	// 战斗怒吼 - 有队友缺少时补上
+	if ai.lastBuffTime >= 3000 {
+		ai.lastBuffTime = 0
+		if ai.findBuffTarget(SPELL_BATTLE_SHOUT) != nil && !ai.owner.isCurrentlySpellCasting() {
+			ai.owner.CastSpell(ai.owner, SPELL_BATTLE_SHOUT)
+		}
+	}
+
 	// 嘲讽技能 - 每8秒
 	if ai.lastSpecialTime >= 8000 {
 		ai.lastSpecialTime = 0
@@ -329,20 +630,67 @@ func (ai *PlayerAI) selectTarget() {
 	}
 }
 
-// 寻找治疗目标
+// getSupportTargets 施法距离内的小队成员(含自己)
+func (ai *PlayerAI) getSupportTargets(spellId uint32) []*Player {
+	var spellRange float32
+	if GlobalSpellManager != nil {
+		if spellInfo := GlobalSpellManager.GetSpell(spellId); spellInfo != nil {
+			spellRange = spellInfo.Range
+		}
+	}
+	var targets []*Player
+	for _, member := range ai.owner.GetPartyMembers() {
+		if member == ai.owner || spellRange == 0 || ai.owner.GetDistanceTo(member) <= spellRange {
+			targets = append(targets, member)
+		}
+	}
+	return targets
+}
+
+// 寻找治疗目标 - 生命值百分比最低且低于2/3的队友
 func (ai *PlayerAI) findHealTarget() IUnit {
-	// 这里需要访问团队成员，简化处理
-	if ai.owner.GetHealth() < ai.owner.GetMaxHealth()*2/3 {
-		return ai.owner
+	var target IUnit
+	lowestPct := float32(2.0 / 3.0)
+	for _, member := range ai.getSupportTargets(SPELL_HEAL) {
+		if pct := float32(member.GetHealth()) / float32(member.GetMaxHealth()); pct < lowestPct {
+			lowestPct = pct
+			target = member
+		}
 	}
-	return nil
+	return target
 }
 
-// 寻找护盾目标
+// 寻找护盾目标 - 生命值低于80%且没有护盾的队友，优先承受攻击最多的(坦克)
 func (ai *PlayerAI) findShieldTarget() IUnit {
-	// 简化处理：为自己或生命值较低的目标施加护盾
-	if ai.owner.GetHealth() < ai.owner.GetMaxHealth()*4/5 {
-		return ai.owner
+	var target IUnit
+	mostAttackers := -1
+	for _, member := range ai.getSupportTargets(SPELL_POWER_WORD_SHIELD) {
+		if member.GetHealth() >= member.GetMaxHealth()*4/5 || member.HasAura(SPELL_POWER_WORD_SHIELD) {
+			continue
+		}
+		if attackers := len(member.attackers); attackers > mostAttackers {
+			mostAttackers = attackers
+			target = member
+		}
+	}
+	return target
+}
+
+// 寻找缺少增益的队友
+func (ai *PlayerAI) findBuffTarget(spellId uint32) IUnit {
+	radius := float32(0)
+	if GlobalSpellManager != nil {
+		if spellInfo := GlobalSpellManager.GetSpell(spellId); spellInfo != nil && len(spellInfo.Effects) > 0 {
+			radius = GetSpellRadius(spellInfo.Effects[0].RadiusIndex)
+		}
+	}
+	for _, member := range ai.owner.GetPartyMembers() {
+		if member.HasAura(spellId) {
+			continue
+		}
+		if member == ai.owner || ai.owner.GetDistanceTo(member) <= radius {
+			return member
+		}
 	}
 	return nil
 }
@@ -375,7 +723,7 @@ func (ai *PlayerAI) JustDied(killer IUnit) {
 }
 
 func (ai *PlayerAI) DamageTaken(attacker IUnit, damage uint32) {
-	if damage > 500 {
+	if damage > 500 && attacker != nil {
 		fmt.Printf("[PlayerAI] %s 受到来自 %s 的大量伤害: %d\n",
 			ai.owner.GetName(), attacker.GetName(), damage)
 	}
@@ -402,15 +750,10 @@ func (ai *CreatureAI) UpdateAI(diff uint32) {
 		return
 	}
 
-	// 如果没有目标，寻找攻击者
-	if ai.owner.GetVictim() == nil {
-		for _, attacker := range ai.owner.attackers {
-			if attacker.IsAlive() {
-				ai.owner.Attack(attacker)
-				fmt.Printf("[CreatureAI] %s 开始反击 %s\n", ai.owner.GetName(), attacker.GetName())
-				break
-			}
-		}
+	// 根据仇恨选择目标
+	hadVictim := ai.owner.GetVictim() != nil
+	if victim := ai.owner.UpdateVictim(); victim != nil && !hadVictim {
+		fmt.Printf("[CreatureAI] %s 开始反击 %s\n", ai.owner.GetName(), victim.GetName())
 	}
 
 	// 如果有目标但目标死亡，清除目标
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// 环境伤害类型 - 基于AzerothCore的EnviromentalDamage
+const (
+	DAMAGE_EXHAUSTED    = 0 // 疲劳
+	DAMAGE_DROWNING     = 1 // 溺水
+	DAMAGE_FALL         = 2 // 摔落
+	DAMAGE_LAVA         = 3 // 岩浆
+	DAMAGE_SLIME        = 4 // 软泥
+	DAMAGE_FIRE         = 5 // 火焰
+	DAMAGE_FALL_TO_VOID = 6 // 掉出地图
+)
+
+// 液体状态 - 基于AzerothCore的ZLiquidStatus/MirrorTimer
+const (
+	LIQUID_NO_WATER    = 0 // 不在液体中
+	LIQUID_IN_WATER    = 1 // 在水面
+	LIQUID_UNDER_WATER = 2 // 在水下
+	LIQUID_IN_LAVA     = 3 // 在岩浆中
+	LIQUID_IN_SLIME    = 4 // 在软泥中
+)
+
+// 环境计时 - 基于AzerothCore的Player::HandleDrowning/HandleFall
+const (
+	BREATH_TIMER_MAX            = 60000 // 水下呼吸时间(毫秒)
+	ENVIRONMENT_DAMAGE_INTERVAL = 2000  // 溺水、岩浆、软泥的伤害间隔(毫秒)
+	FALL_DAMAGE_SAFE_HEIGHT     = 14.57 // 安全摔落高度(码)
+	FALL_DAMAGE_PCT_PER_YARD    = 0.018 // 超过安全高度后每码的伤害比例
+	FALL_DAMAGE_PCT_OFFSET      = 0.2426
+	LAVA_DAMAGE_MIN             = 600 // 岩浆伤害范围
+	LAVA_DAMAGE_MAX             = 700
+)
+
+// LiquidStatusChecker 液体检查 - 对应AzerothCore中基于地图数据的Map::GetLiquidStatus
+type LiquidStatusChecker func(x, y, z float32) uint8
+
+// SetLiquidStatusChecker 设置液体检查
+func (w *World) SetLiquidStatusChecker(checker LiquidStatusChecker) {
+	w.liquidChecker = checker
+}
+
+// GetLiquidStatus 获取坐标的液体状态，未设置检查时视为不在液体中
+func (w *World) GetLiquidStatus(x, y, z float32) uint8 {
+	if w.liquidChecker == nil {
+		return LIQUID_NO_WATER
+	}
+	return w.liquidChecker(x, y, z)
+}
+
+// getEnvironmentalDamageSchool 环境伤害的学派 - 岩浆为火焰，软泥为自然
+func getEnvironmentalDamageSchool(damageType uint8) int {
+	switch damageType {
+	case DAMAGE_LAVA, DAMAGE_FIRE:
+		return SPELL_SCHOOL_FIRE
+	case DAMAGE_SLIME:
+		return SPELL_SCHOOL_NATURE
+	}
+	return SPELL_SCHOOL_NORMAL
+}
+
+// EnvironmentalDamage 受到环境伤害 - 基于AzerothCore的Player::EnvironmentalDamage
+// 伤害由服务器计算，经过抗性后通过DealDamage结算，保证吸收、死亡等流程一致
+func (u *Unit) EnvironmentalDamage(damageType uint8, damage uint32) uint32 {
+	if !u.IsAlive() || u.isGMGodMode() {
+		return 0
+	}
+
+	schoolMask := getEnvironmentalDamageSchool(damageType)
+	resist := calcPartialResist(u, u, schoolMask, damage)
+	damage -= resist
+
+	if u.world != nil {
+		u.world.BroadcastEnvironmentalDamage(u, damageType, damage, resist)
+	}
+	fmt.Printf("%s 受到 %d 点环境伤害(类型%d)\n", u.name, damage, damageType)
+
+	return u.DealDamage(nil, damage, SELF_DAMAGE, schoolMask)
+}
+
+// HandleFall 落地时根据下落高度计算摔落伤害 - 基于AzerothCore的Player::HandleFall
+func (u *Unit) HandleFall(zDiff float32) uint32 {
+	if zDiff < FALL_DAMAGE_SAFE_HEIGHT || !u.IsAlive() {
+		return 0
+	}
+
+	damagePct := FALL_DAMAGE_PCT_PER_YARD*zDiff - FALL_DAMAGE_PCT_OFFSET
+	if damagePct <= 0 {
+		return 0
+	}
+	damage := uint32(damagePct * float32(u.GetMaxHealth()))
+	if damage > u.GetMaxHealth() {
+		damage = u.GetMaxHealth()
+	}
+	if damage == 0 {
+		return 0
+	}
+	return u.EnvironmentalDamage(DAMAGE_FALL, damage)
+}
+
+// updateEnvironment 更新呼吸和液体伤害 - 基于AzerothCore的Player::HandleDrowning
+func (u *Unit) updateEnvironment(diff uint32) {
+	if u.world == nil || u.unitType != UNIT_TYPE_PLAYER || !u.IsAlive() {
+		return
+	}
+
+	liquid := u.world.GetLiquidStatus(u.x, u.y, u.z)
+
+	// 离开水下后立即恢复呼吸
+	if liquid != LIQUID_UNDER_WATER {
+		u.breathTimer = BREATH_TIMER_MAX
+	} else if u.breathTimer > diff {
+		u.breathTimer -= diff
+	} else {
+		u.breathTimer = 0
+	}
+
+	if liquid == LIQUID_NO_WATER || liquid == LIQUID_IN_WATER ||
+		(liquid == LIQUID_UNDER_WATER && u.breathTimer > 0) {
+		u.environmentTimer = 0
+		return
+	}
+
+	u.environmentTimer += diff
+	for u.environmentTimer >= ENVIRONMENT_DAMAGE_INTERVAL && u.IsAlive() {
+		u.environmentTimer -= ENVIRONMENT_DAMAGE_INTERVAL
+		switch liquid {
+		case LIQUID_UNDER_WATER:
+			u.EnvironmentalDamage(DAMAGE_DROWNING, u.GetMaxHealth()/5+uint32(rand.Intn(int(u.level)+1)))
+		case LIQUID_IN_LAVA:
+			u.EnvironmentalDamage(DAMAGE_LAVA, uint32(LAVA_DAMAGE_MIN+rand.Intn(LAVA_DAMAGE_MAX-LAVA_DAMAGE_MIN+1)))
+		case LIQUID_IN_SLIME:
+			u.EnvironmentalDamage(DAMAGE_SLIME, uint32(LAVA_DAMAGE_MIN+rand.Intn(LAVA_DAMAGE_MAX-LAVA_DAMAGE_MIN+1)))
+		}
+	}
+}
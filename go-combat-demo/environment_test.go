@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestEnvironmentalDamageGoesThroughDealDamage(t *testing.T) {
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	player := newThreatTestUnit("player", 0)
+	player.SetWorld(world)
+
+	if damage := player.HandleFall(10); damage != 0 {
+		t.Fatalf("falls below the safe height should not hurt, got %d", damage)
+	}
+	// 0.018*30-0.2426 = 29.74%
+	if damage := player.HandleFall(30); damage != 297 || player.GetHealth() != 703 {
+		t.Fatalf("30 yard fall should deal 297 damage, got %d", damage)
+	}
+	if player.IsInCombat() {
+		t.Fatalf("environmental damage should not start combat")
+	}
+
+	world.SetLiquidStatusChecker(func(x, y, z float32) uint8 { return LIQUID_UNDER_WATER })
+	player.SetHealth(1000)
+	player.Update(BREATH_TIMER_MAX - 1)
+	if player.GetHealth() != 1000 {
+		t.Fatalf("player should not drown while breath remains")
+	}
+	player.Update(1)
+	player.Update(ENVIRONMENT_DAMAGE_INTERVAL)
+	if player.GetHealth() > 800 {
+		t.Fatalf("drowning should deal a fifth of max health per tick, health %d", player.GetHealth())
+	}
+
+	world.SetLiquidStatusChecker(func(x, y, z float32) uint8 { return LIQUID_IN_LAVA })
+	for i := 0; i < 10 && player.IsAlive(); i++ {
+		player.Update(ENVIRONMENT_DAMAGE_INTERVAL)
+	}
+	if player.IsAlive() || !player.HasUnitState(UNIT_STATE_DIED) {
+		t.Fatalf("lava should be able to kill the player through normal death handling")
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import "testing"
+
+func TestLeashEvadeRegenAndReturnHome(t *testing.T) {
+	mob := NewCreature("mob", 20, CREATURE_TYPE_HUMANOID)
+	mob.SetMaxHealth(1000)
+	mob.SetHealth(1000)
+	mob.Relocate(0, 0, 0, 0)
+	player := newThreatTestUnit("player", CREATURE_LEASH_RANGE+20)
+
+	mob.CombatStart(player)
+	mob.SetPosition(CREATURE_LEASH_RANGE-1, 0, 0)
+	mob.Update(100)
+	if mob.HasUnitState(UNIT_STATE_EVADE) || !mob.IsInCombat() {
+		t.Fatal("mob inside the leash range should keep fighting")
+	}
+
+	// 被拉出拉扯范围：清空仇恨、离开战斗并返回出生点
+	mob.SetPosition(CREATURE_LEASH_RANGE+10, 0, 0)
+	mob.SetHealth(100)
+	mob.Update(100)
+	if !mob.HasUnitState(UNIT_STATE_EVADE) || mob.IsInCombat() || !mob.threatManager.IsEmpty() {
+		t.Fatal("mob pulled past the leash range should evade")
+	}
+	if mob.DealDamage(player, 50, DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL) != 0 {
+		t.Fatal("evading mob should be immune to damage")
+	}
+
+	// 返回途中每秒恢复20%生命值
+	mob.Update(1000)
+	if expected := uint32(100 + 1000*EVADE_REGEN_PCT_PER_SEC/100); mob.GetHealth() != expected {
+		t.Fatalf("evade should regenerate %d%% per second, health %d, want %d", EVADE_REGEN_PCT_PER_SEC, mob.GetHealth(), expected)
+	}
+	if mob.GetDistanceToHome() == 0 || mob.GetDistanceToHome() >= CREATURE_LEASH_RANGE+10 {
+		t.Fatalf("mob should be running home, distance %.1f", mob.GetDistanceToHome())
+	}
+
+	// 由自身的更新走回出生点，回到后状态重置
+	for i := 0; i < 200 && mob.HasUnitState(UNIT_STATE_EVADE); i++ {
+		mob.Update(100)
+	}
+	if mob.HasUnitState(UNIT_STATE_EVADE) || mob.GetDistanceToHome() != 0 || mob.GetHealth() != mob.GetMaxHealth() {
+		t.Fatalf("mob should be back home at full health, distance %.1f health %d", mob.GetDistanceToHome(), mob.GetHealth())
+	}
+}
+
+func TestWipeResetsEncounterThroughWorldTick(t *testing.T) {
+	if GlobalObjectMgr == nil {
+		InitObjectMgr()
+	}
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+
+	tank, _ := newGroupTestPlayer(world, 1, "tank", CLASS_WARRIOR, 0)
+	dm := NewDeadminesDungeon(world, DIFFICULTY_NORMAL)
+	if !dm.AddPlayer(tank) {
+		t.Fatal("tank should enter the dungeon")
+	}
+
+	rhahkzor := dm.GetEncounter(DATA_RHAHKZOR)
+	boss := rhahkzor.boss
+	homeX, _, _ := boss.GetHomePosition()
+	boss.CombatStart(tank)
+	world.Update(100)
+	if rhahkzor.GetState() != IN_PROGRESS {
+		t.Fatalf("engaged boss should be in progress, got %d", rhahkzor.GetState())
+	}
+	boss.DealDamage(tank, boss.GetHealth()/2, DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	boss.SetPosition(homeX-20, 0, 0)
+
+	// 团灭：首领遭遇战失败并脱战
+	tank.DealDamage(boss, tank.GetHealth(), DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	world.Update(100)
+	if rhahkzor.GetState() != FAIL || !boss.HasUnitState(UNIT_STATE_EVADE) || boss.IsInCombat() {
+		t.Fatal("wipe should fail the encounter and evade the boss")
+	}
+
+	// 世界更新驱动首领走回出生点并恢复满状态
+	for i := 0; i < 100 && boss.HasUnitState(UNIT_STATE_EVADE); i++ {
+		world.Update(100)
+	}
+	if boss.HasUnitState(UNIT_STATE_EVADE) || boss.GetDistanceToHome() != 0 || boss.GetHealth() != boss.GetMaxHealth() {
+		t.Fatalf("boss should reset at home, distance %.1f health %d/%d", boss.GetDistanceToHome(), boss.GetHealth(), boss.GetMaxHealth())
+	}
+	if rhahkzor.GetState() != FAIL || dm.GetInstanceSave().IsEncounterDone(DATA_RHAHKZOR) {
+		t.Fatal("failed encounter should not be saved as done")
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import "sort"
+
+// EventMap 按时间调度的事件表 - 基于AzerothCore的EventMap
+// 事件数据打包为 事件ID(低16位) | 分组(16-23位) | 阶段掩码(高8位)，阶段为0表示所有阶段
+type EventMap struct {
+	time      uint32
+	phase     uint8 // 当前阶段掩码
+	lastEvent uint32
+	events    []scheduledEvent // 按触发时间排序
+}
+
+// scheduledEvent 已调度的事件
+type scheduledEvent struct {
+	time uint32
+	data uint32
+}
+
+// Reset 清空所有事件并重置时间和阶段
+func (em *EventMap) Reset() {
+	em.events = nil
+	em.time = 0
+	em.phase = 0
+	em.lastEvent = 0
+}
+
+// Update 推进时间
+func (em *EventMap) Update(diff uint32) {
+	em.time += diff
+}
+
+// GetPhaseMask 当前阶段掩码
+func (em *EventMap) GetPhaseMask() uint8 {
+	return em.phase
+}
+
+// Empty 是否没有待触发的事件
+func (em *EventMap) Empty() bool {
+	return len(em.events) == 0
+}
+
+// SetPhase 切换到指定阶段，阶段从1开始，0表示清除阶段
+func (em *EventMap) SetPhase(phase uint8) {
+	if phase == 0 {
+		em.phase = 0
+	} else if phase <= 8 {
+		em.phase = 1 << (phase - 1)
+	}
+}
+
+// AddPhase 加入阶段
+func (em *EventMap) AddPhase(phase uint8) {
+	if phase > 0 && phase <= 8 {
+		em.phase |= 1 << (phase - 1)
+	}
+}
+
+// IsInPhase 是否处于指定阶段
+func (em *EventMap) IsInPhase(phase uint8) bool {
+	return phase > 0 && phase <= 8 && em.phase&(1<<(phase-1)) != 0
+}
+
+// ScheduleEvent 在time毫秒后触发事件，phase为0时任何阶段都会触发
+func (em *EventMap) ScheduleEvent(eventId uint32, time uint32, group uint8, phase uint8) {
+	data := eventId & 0x0000FFFF
+	if group > 0 {
+		data |= uint32(group) << 16
+	}
+	if phase > 0 && phase <= 8 {
+		data |= uint32(1<<(phase-1)) << 24
+	}
+	em.insert(em.time+time, data)
+}
+
+// insert 按触发时间插入，同一时间的事件保持调度顺序
+func (em *EventMap) insert(time, data uint32) {
+	index := sort.Search(len(em.events), func(i int) bool { return em.events[i].time > time })
+	em.events = append(em.events, scheduledEvent{})
+	copy(em.events[index+1:], em.events[index:])
+	em.events[index] = scheduledEvent{time: time, data: data}
+}
+
+// RescheduleEvent 取消后重新调度事件
+func (em *EventMap) RescheduleEvent(eventId uint32, time uint32, group uint8, phase uint8) {
+	em.CancelEvent(eventId)
+	em.ScheduleEvent(eventId, time, group, phase)
+}
+
+// RepeatEvent 把上一个触发的事件再次调度到time毫秒后
+func (em *EventMap) RepeatEvent(time uint32) {
+	if em.lastEvent == 0 {
+		return
+	}
+	em.insert(em.time+time, em.lastEvent)
+}
+
+// ExecuteEvent 取出一个到期且属于当前阶段的事件，返回事件ID，没有时返回0
+// 到期但不属于当前阶段的事件直接丢弃
+func (em *EventMap) ExecuteEvent() uint32 {
+	for len(em.events) > 0 {
+		event := em.events[0]
+		if event.time > em.time {
+			return 0
+		}
+		em.events = em.events[1:]
+
+		phaseMask := uint8(event.data >> 24)
+		if em.phase != 0 && phaseMask != 0 && phaseMask&em.phase == 0 {
+			continue
+		}
+		em.lastEvent = event.data
+		return event.data & 0x0000FFFF
+	}
+	return 0
+}
+
+// DelayEvents 推迟所有事件
+func (em *EventMap) DelayEvents(delay uint32) {
+	delayed := em.events
+	em.events = nil
+	for _, event := range delayed {
+		em.insert(event.time+delay, event.data)
+	}
+}
+
+// CancelEvent 取消事件
+func (em *EventMap) CancelEvent(eventId uint32) {
+	em.removeIf(func(data uint32) bool { return data&0x0000FFFF == eventId })
+}
+
+// CancelEventGroup 取消分组内的所有事件
+func (em *EventMap) CancelEventGroup(group uint8) {
+	if group == 0 {
+		return
+	}
+	em.removeIf(func(data uint32) bool { return (data>>16)&0xFF == uint32(group) })
+}
+
+// removeIf 移除满足条件的事件
+func (em *EventMap) removeIf(match func(data uint32) bool) {
+	remaining := em.events[:0]
+	for _, event := range em.events {
+		if !match(event.data) {
+			remaining = append(remaining, event)
+		}
+	}
+	em.events = remaining
+}
+
+// GetTimeUntilEvent 距离事件触发的时间，没有调度时返回false
+func (em *EventMap) GetTimeUntilEvent(eventId uint32) (uint32, bool) {
+	for _, event := range em.events {
+		if event.data&0x0000FFFF == eventId {
+			if event.time <= em.time {
+				return 0, true
+			}
+			return event.time - em.time, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// 经验常量 - 基于AzerothCore的Formulas.h
+const (
+	DEFAULT_MAX_LEVEL = 80 // 等级上限
+
+	// 各资料片怪物的基础经验 - 基于AzerothCore的BaseGain中的nBaseExp
+	BASE_EXP_CLASSIC = 45
+	BASE_EXP_TBC     = 235
+	BASE_EXP_WOTLK   = 580
+)
+
+// getGrayLevel 低于等于此等级的怪物不给经验 - 基于AzerothCore的Acore::XP::GetGrayLevel
+func getGrayLevel(playerLevel uint8) uint8 {
+	switch {
+	case playerLevel <= 5:
+		return 0
+	case playerLevel <= 39:
+		return playerLevel - 5 - playerLevel/10
+	case playerLevel <= 59:
+		return playerLevel - 1 - playerLevel/5
+	}
+	return playerLevel - 9
+}
+
+// getZeroDifference 经验衰减到零的等级差 - 基于AzerothCore的Acore::XP::GetZeroDifference
+func getZeroDifference(playerLevel uint8) uint32 {
+	switch {
+	case playerLevel < 7:
+		return 5
+	case playerLevel < 10:
+		return 6
+	case playerLevel < 12:
+		return 7
+	case playerLevel < 16:
+		return 8
+	case playerLevel < 20:
+		return 9
+	case playerLevel < 30:
+		return 11
+	case playerLevel < 40:
+		return 12
+	case playerLevel < 45:
+		return 13
+	case playerLevel < 50:
+		return 14
+	case playerLevel < 55:
+		return 15
+	case playerLevel < 60:
+		return 16
+	}
+	return 17
+}
+
+// getBaseExp 怪物所属资料片的基础经验
+func getBaseExp(mobLevel uint8) uint32 {
+	switch {
+	case mobLevel <= 60:
+		return BASE_EXP_CLASSIC
+	case mobLevel <= 70:
+		return BASE_EXP_TBC
+	}
+	return BASE_EXP_WOTLK
+}
+
+// BaseGain 击杀怪物的基础经验 - 基于AzerothCore的Acore::XP::BaseGain
+func BaseGain(playerLevel, mobLevel uint8) uint32 {
+	baseExp := getBaseExp(mobLevel)
+	if mobLevel >= playerLevel {
+		levelDiff := uint32(mobLevel - playerLevel)
+		if levelDiff > 4 {
+			levelDiff = 4
+		}
+		return ((uint32(playerLevel)*5+baseExp)*(20+levelDiff)/10 + 1) / 2
+	}
+
+	if mobLevel <= getGrayLevel(playerLevel) {
+		return 0
+	}
+	zeroDiff := getZeroDifference(playerLevel)
+	return (uint32(playerLevel)*5 + baseExp) * (zeroDiff + uint32(mobLevel) - uint32(playerLevel)) / zeroDiff
+}
+
+// xpInGroupRate 队伍经验加成 - 基于AzerothCore的Acore::XP::xp_in_group_rate
+func xpInGroupRate(count int, isRaid bool) float32 {
+	if isRaid {
+		return 1.0
+	}
+	switch {
+	case count <= 2:
+		return 1.0
+	case count == 3:
+		return 1.166
+	case count == 4:
+		return 1.3
+	}
+	return 1.4
+}
+
+// GetXPForLevel 升到下一级所需经验 - 基于player_xp_for_level的经典公式
+// ((8 × 等级) + 等级差修正) × (45 + 5 × 等级)，四舍五入到百位
+func GetXPForLevel(level uint8) uint32 {
+	if level >= DEFAULT_MAX_LEVEL {
+		return 0
+	}
+	var diff uint32
+	switch {
+	case level <= 28:
+		diff = 0
+	case level == 29:
+		diff = 1
+	case level == 30:
+		diff = 3
+	case level == 31:
+		diff = 6
+	default:
+		diff = 5 * (uint32(level) - 30)
+	}
+	xp := float64((8*uint32(level) + diff) * (45 + 5*uint32(level)))
+	return uint32(math.Round(xp/100) * 100)
+}
+
+// GetXP 当前经验
+func (p *Player) GetXP() uint32 { return p.xp }
+
+// GiveXP 获得经验并处理升级 - 基于AzerothCore的Player::GiveXP
+func (p *Player) GiveXP(xp uint32, victim IUnit) {
+	if xp == 0 || !p.IsAlive() || p.GetLevel() >= DEFAULT_MAX_LEVEL {
+		return
+	}
+
+	p.sendLogXPGain(xp, victim)
+
+	p.xp += xp
+	for p.GetLevel() < DEFAULT_MAX_LEVEL {
+		needed := GetXPForLevel(p.GetLevel())
+		if p.xp < needed {
+			break
+		}
+		p.xp -= needed
+		p.GiveLevel(p.GetLevel() + 1)
+	}
+	if p.GetLevel() >= DEFAULT_MAX_LEVEL {
+		p.xp = 0
+	}
+}
+
+// GiveLevel 升级 - 基于AzerothCore的Player::GiveLevel，简化为回满生命和能量
+func (p *Player) GiveLevel(level uint8) {
+	p.SetLevel(level)
+	p.SetHealth(p.GetMaxHealth())
+	powerType := p.GetPowerType()
+	if powerType != POWER_RAGE {
+		p.SetPower(powerType, p.GetMaxPower(powerType))
+	}
+
+	if p.world != nil {
+		packet := NewWorldPacket(SMSG_LEVELUP_INFO)
+		packet.WriteUint32(uint32(level))
+		packet.WriteUint32(p.GetMaxHealth())
+		if session := p.world.GetSessionByPlayerGUID(p.GetGUID()); session != nil {
+			session.SendPacket(packet)
+		}
+	}
+	fmt.Printf("[升级] %s 升到了 %d 级\n", p.GetName(), level)
+}
+
+// sendLogXPGain 通知客户端获得经验 - 基于AzerothCore的Player::SendLogXPGain
+func (p *Player) sendLogXPGain(xp uint32, victim IUnit) {
+	var victimGuid uint64
+	victimName := ""
+	if victim != nil {
+		victimGuid = victim.GetGUID()
+		victimName = victim.GetName()
+	}
+	if p.world != nil {
+		packet := NewWorldPacket(SMSG_LOG_XPGAIN)
+		packet.WriteUint64(victimGuid)
+		packet.WriteUint32(xp)
+		if session := p.world.GetSessionByPlayerGUID(p.GetGUID()); session != nil {
+			session.SendPacket(packet)
+		}
+	}
+	fmt.Printf("[经验] %s 击杀 %s 获得 %d 点经验\n", p.GetName(), victimName, xp)
+}
+
+// === 击杀奖励 ===
+
+// GetLootRecipient 拥有拾取权的玩家
+func (u *Unit) GetLootRecipient() *Player { return u.lootRecipient }
+
+// GetLootRecipientGroup 拥有拾取权的队伍
+func (u *Unit) GetLootRecipientGroup() *Group { return u.lootRecipientGroup }
+
+// SetLootRecipient 设置拾取权，第一个造成伤害的玩家及其队伍获得 - 基于AzerothCore的Creature::SetLootRecipient
+func (u *Unit) SetLootRecipient(attacker IUnit) {
+	if attacker == nil {
+		u.lootRecipient = nil
+		u.lootRecipientGroup = nil
+		return
+	}
+	if u.lootRecipient != nil {
+		return
+	}
+	player := getPlayer(attacker)
+	if player == nil {
+		return
+	}
+	u.lootRecipient = player
+	u.lootRecipientGroup = player.group
+}
+
+// rewardKill 分配击杀经验 - 基于AzerothCore的KillRewarder
+// 有队伍时按等级比例分给奖励距离内的成员，经验按队伍最高等级计算并乘以队伍加成
+func (u *Unit) rewardKill() {
+	recipient := u.lootRecipient
+	if recipient == nil {
+		return
+	}
+
+	group := u.lootRecipientGroup
+	if group == nil || !group.IsMember(recipient.GetGUID()) {
+		recipient.GiveXP(BaseGain(recipient.GetLevel(), u.level), u)
+		return
+	}
+
+	members := group.GetMembersInRewardRange(u)
+	if len(members) == 0 {
+		return
+	}
+	var sumLevel uint32
+	var maxLevel uint8
+	for _, member := range members {
+		sumLevel += uint32(member.GetLevel())
+		if member.GetLevel() > maxLevel {
+			maxLevel = member.GetLevel()
+		}
+	}
+
+	// 对队伍最高等级的成员是灰色怪物时，所有人都没有经验
+	xp := float32(BaseGain(maxLevel, u.level)) * xpInGroupRate(len(members), group.IsRaidGroup())
+	for _, member := range members {
+		member.GiveXP(uint32(xp*float32(member.GetLevel())/float32(sumLevel)), u)
+	}
+
+	// 除自由拾取外，品质门槛以下的物品都轮流拾取
+	if group.GetLootMethod() != FREE_FOR_ALL {
+		group.UpdateLooterGuid(u)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"sync"
+)
+
+// 阵营 - 基于AzerothCore的FactionTemplateEntry和Unit::GetReactionTo
+// 单位的faction是阵营模板ID，阵营模板决定敌对和友好关系；玩家对有声望的阵营按声望等级判断，
+// 玩家之间还要考虑队伍、决斗和PvP标记
+
+// 阵营模板数据 - 对应客户端的FactionTemplate.dbc，第一次使用时加载
+const FACTION_TEMPLATE_PATH = "data/faction_template.csv"
+
+//go:embed data/faction_template.csv
+var defaultFactionTemplates []byte
+
+// 阵营组掩码 - 基于AzerothCore的FactionMasks
+const (
+	FACTION_MASK_PLAYER   = 1 // 所有玩家
+	FACTION_MASK_ALLIANCE = 2 // 联盟玩家
+	FACTION_MASK_HORDE    = 4 // 部落玩家
+	FACTION_MASK_MONSTER  = 8 // 对所有人敌对的怪物
+)
+
+// 常用阵营模板
+const (
+	FACTION_TEMPLATE_HUMAN    = 1  // 人类玩家(联盟)
+	FACTION_TEMPLATE_ORC      = 2  // 兽人玩家(部落)
+	FACTION_TEMPLATE_CREATURE = 7  // 中立的野生动物
+	FACTION_TEMPLATE_MONSTER  = 14 // 敌对的怪物
+	FACTION_TEMPLATE_FRIENDLY = 35 // 对所有人友好
+)
+
+// 声望等级 - 基于AzerothCore的ReputationRank，也用作单位之间的反应
+const (
+	REP_HATED      = 0
+	REP_HOSTILE    = 1
+	REP_UNFRIENDLY = 2
+	REP_NEUTRAL    = 3
+	REP_FRIENDLY   = 4
+	REP_HONORED    = 5
+	REP_REVERED    = 6
+	REP_EXALTED    = 7
+)
+
+// 各声望等级的最低声望值 - 基于AzerothCore的ReputationMgr::PointsInRank
+var reputationRankThresholds = [...]int32{-42000, -6000, -3000, 0, 3000, 9000, 21000, 42000}
+
+// FactionTemplate 阵营模板
+type FactionTemplate struct {
+	ID           uint32
+	Faction      uint32 // 声望阵营
+	FactionGroup uint32 // 自己所属的阵营组
+	FriendGroup  uint32 // 友好的阵营组
+	EnemyGroup   uint32 // 敌对的阵营组
+	Enemies      [2]uint32
+	Friends      [2]uint32
+}
+
+// IsFriendlyTo 是否对另一个阵营模板友好 - 基于AzerothCore的FactionTemplateEntry::IsFriendlyTo
+func (t *FactionTemplate) IsFriendlyTo(other *FactionTemplate) bool {
+	if t.ID == other.ID {
+		return true
+	}
+	if other.Faction != 0 {
+		for _, enemy := range t.Enemies {
+			if enemy == other.Faction {
+				return false
+			}
+		}
+		for _, friend := range t.Friends {
+			if friend == other.Faction {
+				return true
+			}
+		}
+	}
+	return t.FriendGroup&other.FactionGroup != 0 || t.FactionGroup&other.FriendGroup != 0
+}
+
+// IsHostileTo 是否对另一个阵营模板敌对 - 基于AzerothCore的FactionTemplateEntry::IsHostileTo
+func (t *FactionTemplate) IsHostileTo(other *FactionTemplate) bool {
+	if t.ID == other.ID {
+		return false
+	}
+	if other.Faction != 0 {
+		for _, enemy := range t.Enemies {
+			if enemy == other.Faction {
+				return true
+			}
+		}
+		for _, friend := range t.Friends {
+			if friend == other.Faction {
+				return false
+			}
+		}
+	}
+	return t.EnemyGroup&other.FactionGroup != 0
+}
+
+var (
+	factionTemplates     map[uint32]*FactionTemplate
+	factionTemplatesOnce sync.Once
+)
+
+// GetFactionTemplate 按ID查找阵营模板
+func GetFactionTemplate(id uint32) *FactionTemplate {
+	factionTemplatesOnce.Do(func() {
+		templates, err := loadFactionTemplates(FACTION_TEMPLATE_PATH)
+		if err != nil {
+			fmt.Printf("加载阵营模板失败: %v\n", err)
+		}
+		factionTemplates = templates
+	})
+	return factionTemplates[id]
+}
+
+// loadFactionTemplates 加载阵营模板
+func loadFactionTemplates(path string) (map[uint32]*FactionTemplate, error) {
+	content, source, err := readDataFile(path, defaultFactionTemplates)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readDataTable(content)
+	if err != nil {
+		return nil, fmt.Errorf("解析阵营模板失败: %v", err)
+	}
+
+	templates := make(map[uint32]*FactionTemplate, len(rows))
+	for _, row := range rows {
+		template := &FactionTemplate{
+			ID:           row.uint32("ID"),
+			Faction:      row.uint32("Faction"),
+			FactionGroup: row.uint32("FactionGroup"),
+			FriendGroup:  row.uint32("FriendGroup"),
+			EnemyGroup:   row.uint32("EnemyGroup"),
+			Enemies:      [2]uint32{row.uint32("Enemies1"), row.uint32("Enemies2")},
+			Friends:      [2]uint32{row.uint32("Friend1"), row.uint32("Friend2")},
+		}
+		if row.err == nil && template.ID == 0 {
+			row.err = fmt.Errorf("阵营模板ID不能为0")
+		}
+		if row.err != nil {
+			fmt.Printf("[faction_template] 跳过无效的阵营模板 %d: %v\n", template.ID, row.err)
+			continue
+		}
+		templates[template.ID] = template
+	}
+	fmt.Printf("从%s加载了 %d 个阵营模板\n", source, len(templates))
+	return templates, nil
+}
+
+// === 单位之间的反应 ===
+
+// GetReactionTo 对目标的反应 - 基于AzerothCore的Unit::GetReactionTo
+// 决斗中的对手敌对，同一队伍友好；玩家和生物之间优先使用玩家在生物阵营的声望，其余按阵营模板判断
+func (u *Unit) GetReactionTo(target IUnit) uint8 {
+	other := getBaseUnit(target)
+	if other == nil {
+		return REP_NEUTRAL
+	}
+	if other.GetGUID() == u.GetGUID() {
+		return REP_FRIENDLY
+	}
+
+	selfPlayer, targetPlayer := u.player, other.player
+	if selfPlayer != nil && targetPlayer != nil {
+		if selfPlayer.IsInDuelWith(targetPlayer) {
+			return REP_HOSTILE
+		}
+		if selfPlayer.IsInSameRaidWith(targetPlayer) {
+			return REP_FRIENDLY
+		}
+	}
+
+	selfTemplate, targetTemplate := GetFactionTemplate(u.faction), GetFactionTemplate(other.faction)
+	if selfTemplate == nil || targetTemplate == nil {
+		return REP_NEUTRAL
+	}
+
+	// 玩家和生物之间按玩家的声望
+	if selfPlayer != nil && targetPlayer == nil {
+		if rank, ok := selfPlayer.getReputationRank(targetTemplate.Faction); ok {
+			return rank
+		}
+	}
+	if targetPlayer != nil && selfPlayer == nil {
+		if rank, ok := targetPlayer.getReputationRank(selfTemplate.Faction); ok {
+			return rank
+		}
+	}
+
+	switch {
+	case selfTemplate.IsHostileTo(targetTemplate):
+		return REP_HOSTILE
+	case selfTemplate.IsFriendlyTo(targetTemplate):
+		return REP_FRIENDLY
+	}
+	return REP_NEUTRAL
+}
+
+// IsHostileTo 是否敌对 - 基于AzerothCore的Unit::IsHostileTo
+func (u *Unit) IsHostileTo(target IUnit) bool {
+	return u.GetReactionTo(target) <= REP_HOSTILE
+}
+
+// IsFriendlyTo 是否友好 - 基于AzerothCore的Unit::IsFriendlyTo
+func (u *Unit) IsFriendlyTo(target IUnit) bool {
+	return u.GetReactionTo(target) >= REP_FRIENDLY
+}
+
+// === 玩家的PvP标记和声望 ===
+
+// IsPvP 是否开启了PvP标记 - 只有开启PvP的玩家可以被敌对阵营的玩家攻击
+func (p *Player) IsPvP() bool { return p.pvp }
+
+// SetPvP 设置PvP标记
+func (p *Player) SetPvP(pvp bool) {
+	if p.pvp == pvp {
+		return
+	}
+	p.pvp = pvp
+	if pvp {
+		fmt.Printf("%s 开启了PvP\n", p.GetName())
+	} else {
+		fmt.Printf("%s 关闭了PvP\n", p.GetName())
+	}
+}
+
+// GetReputation 在阵营的声望值
+func (p *Player) GetReputation(faction uint32) int32 {
+	return p.reputation[faction]
+}
+
+// SetReputation 设置在阵营的声望值 - 基于AzerothCore的ReputationMgr::SetReputation
+func (p *Player) SetReputation(faction uint32, standing int32) {
+	if standing < reputationRankThresholds[REP_HATED] {
+		standing = reputationRankThresholds[REP_HATED]
+	}
+	p.reputation[faction] = standing
+}
+
+// ModifyReputation 增减在阵营的声望值
+func (p *Player) ModifyReputation(faction uint32, delta int32) {
+	p.SetReputation(faction, p.reputation[faction]+delta)
+}
+
+// GetReputationRank 在阵营的声望等级，没有声望记录时为中立
+func (p *Player) GetReputationRank(faction uint32) uint8 {
+	rank, ok := p.getReputationRank(faction)
+	if !ok {
+		return REP_NEUTRAL
+	}
+	return rank
+}
+
+// getReputationRank 按声望值计算声望等级，没有声望记录时返回false
+func (p *Player) getReputationRank(faction uint32) (uint8, bool) {
+	standing, ok := p.reputation[faction]
+	if !ok || faction == 0 {
+		return REP_NEUTRAL, false
+	}
+	rank := uint8(REP_HATED)
+	for i, threshold := range reputationRankThresholds {
+		if standing >= threshold {
+			rank = uint8(i)
+		}
+	}
+	return rank, true
+}
+
+// HandleTogglePvPOpcode 切换PvP标记 - 基于AzerothCore的WorldSession::HandleTogglePvP
+func (ws *WorldSession) HandleTogglePvPOpcode(packet *WorldPacket) {
+	player := ws.sessionPlayer()
+	if player == nil {
+		return
+	}
+	player.SetPvP(!player.IsPvP())
+}
@@ -0,0 +1,85 @@
+package main
+
+import "testing"
+
+func TestFactionReactionsPvPDuelAndReputation(t *testing.T) {
+	human := NewPlayer("human", 20, CLASS_PRIEST)
+	friend := NewPlayer("friend", 20, CLASS_WARRIOR)
+	orc := NewPlayer("orc", 20, CLASS_WARRIOR)
+	orc.SetFaction(FACTION_TEMPLATE_ORC)
+	for _, p := range []*Player{human, friend, orc} {
+		p.SetMaxHealth(1000)
+		p.SetHealth(1000)
+	}
+
+	// 敌对阵营的玩家只有开启PvP后才能被攻击
+	if !human.IsHostileTo(orc) || human.IsValidAttackTarget(orc) {
+		t.Fatal("hostile players without PvP should not be attackable")
+	}
+	orc.SetPvP(true)
+	if !human.IsValidAttackTarget(orc) {
+		t.Fatal("PvP flagged enemy should be attackable")
+	}
+
+	// 同阵营的玩家友好，可以治疗但不能攻击；决斗开始后互相敌对，致命伤害只降到1点
+	heal := GlobalSpellManager.GetSpell(2050)
+	if !human.IsFriendlyTo(friend) || human.IsValidAttackTarget(friend) || !NewSpell(human, heal, nil).isValidTarget(friend) {
+		t.Fatal("same faction players should be friendly")
+	}
+	if NewSpell(human, heal, nil).isValidTarget(orc) {
+		t.Fatal("enemy players should not be healed")
+	}
+	human.duel = &DuelInfo{opponent: friend}
+	friend.duel = &DuelInfo{opponent: human}
+	if human.IsValidAttackTarget(friend) {
+		t.Fatal("duel should not allow attacks before the countdown ends")
+	}
+	human.duel.started, friend.duel.started = true, true
+	if !human.IsHostileTo(friend) || !human.IsValidAttackTarget(friend) {
+		t.Fatal("duel opponents should be hostile")
+	}
+	friend.DealDamage(human, 5000, DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	if !friend.IsAlive() || friend.GetHealth() != 1 {
+		t.Fatalf("duel damage should not kill, health %d", friend.GetHealth())
+	}
+
+	// 生物：怪物敌对，野生动物中立但可以攻击，暴风城守卫对联盟友好、对部落敌对
+	monster := NewCreature("monster", 20, CREATURE_TYPE_HUMANOID)
+	beast := NewCreature("beast", 20, CREATURE_TYPE_BEAST)
+	beast.SetFaction(FACTION_TEMPLATE_CREATURE)
+	guard := NewCreature("guard", 20, CREATURE_TYPE_HUMANOID)
+	guard.SetFaction(11)
+	for _, c := range []*Creature{monster, beast, guard} {
+		c.SetMaxHealth(1000)
+		c.SetHealth(1000)
+	}
+	if !monster.IsHostileTo(human) || !human.IsValidAttackTarget(monster) {
+		t.Fatal("monsters should be hostile to players")
+	}
+	if beast.IsHostileTo(human) || beast.IsFriendlyTo(human) || !human.IsValidAttackTarget(beast) {
+		t.Fatal("neutral beasts should be attackable but not hostile")
+	}
+	if !guard.IsFriendlyTo(human) || human.IsValidAttackTarget(guard) || !guard.IsHostileTo(orc) {
+		t.Fatal("Stormwind guards should protect the Alliance and attack the Horde")
+	}
+
+	// 声望低于敌对时守卫也会攻击联盟玩家
+	guardFaction := GetFactionTemplate(11).Faction
+	human.SetReputation(guardFaction, -4000)
+	if human.GetReputationRank(guardFaction) != REP_HOSTILE || !guard.IsHostileTo(human) || !human.IsValidAttackTarget(guard) {
+		t.Fatal("hostile reputation should turn the guards hostile")
+	}
+	human.ModifyReputation(guardFaction, 13000)
+	if human.GetReputationRank(guardFaction) != REP_HONORED || !guard.IsFriendlyTo(human) {
+		t.Fatal("honored reputation should be friendly")
+	}
+
+	// 互不敌对的生物之间的伤害不会致死
+	other := NewCreature("other", 20, CREATURE_TYPE_HUMANOID)
+	other.SetMaxHealth(100)
+	other.SetHealth(100)
+	other.DealDamage(monster, 500, DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	if !other.IsAlive() {
+		t.Fatal("sparring creatures should not kill each other")
+	}
+}
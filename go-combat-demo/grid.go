@@ -0,0 +1,116 @@
+package main
+
+import (
+	"math"
+	"sync"
+)
+
+// 网格常量 - 基于AzerothCore的GridDefines.h
+const (
+	SIZE_OF_GRIDS       = 533.3333                            // 一个网格的边长(码)
+	MAX_NUMBER_OF_CELLS = 8                                   // 每个网格划分的单元格数
+	SIZE_OF_GRID_CELL   = SIZE_OF_GRIDS / MAX_NUMBER_OF_CELLS // 单元格边长(码)
+	CENTER_GRID_CELL_ID = 256                                 // 坐标原点所在的单元格
+)
+
+// CellCoord 单元格坐标 - 基于AzerothCore的CellCoord
+type CellCoord struct {
+	x, y int
+}
+
+// computeCellCoord 计算坐标所在的单元格 - 基于AzerothCore的Acore::ComputeCellCoord
+func computeCellCoord(x, y float32) CellCoord {
+	cx := float64(x)/SIZE_OF_GRID_CELL + CENTER_GRID_CELL_ID + 0.5
+	cy := float64(y)/SIZE_OF_GRID_CELL + CENTER_GRID_CELL_ID + 0.5
+	return CellCoord{x: int(math.Floor(cx)), y: int(math.Floor(cy))}
+}
+
+// GridMap 单元格空间索引 - 按单元格保存单位，范围查询只检查覆盖到的单元格
+type GridMap struct {
+	cells     map[CellCoord]map[uint64]IUnit // 单元格中的单位
+	unitCells map[uint64]CellCoord           // 单位当前所在单元格
+	mutex     sync.RWMutex
+}
+
+// NewGridMap 创建空间索引
+func NewGridMap() *GridMap {
+	return &GridMap{
+		cells:     make(map[CellCoord]map[uint64]IUnit),
+		unitCells: make(map[uint64]CellCoord),
+	}
+}
+
+// AddUnit 将单位加入所在单元格
+func (g *GridMap) AddUnit(unit IUnit) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.addLocked(unit, computeCellCoord(unit.GetX(), unit.GetY()))
+}
+
+func (g *GridMap) addLocked(unit IUnit, coord CellCoord) {
+	cell, exists := g.cells[coord]
+	if !exists {
+		cell = make(map[uint64]IUnit)
+		g.cells[coord] = cell
+	}
+	cell[unit.GetGUID()] = unit
+	g.unitCells[unit.GetGUID()] = coord
+}
+
+// RemoveUnit 将单位移出空间索引
+func (g *GridMap) RemoveUnit(guid uint64) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.removeLocked(guid)
+}
+
+func (g *GridMap) removeLocked(guid uint64) {
+	coord, exists := g.unitCells[guid]
+	if !exists {
+		return
+	}
+	delete(g.unitCells, guid)
+	if cell := g.cells[coord]; cell != nil {
+		delete(cell, guid)
+		if len(cell) == 0 {
+			delete(g.cells, coord)
+		}
+	}
+}
+
+// Relocate 单位移动后更新所在单元格 - 基于AzerothCore的Map::CreatureRelocation
+func (g *GridMap) Relocate(unit IUnit) {
+	coord := computeCellCoord(unit.GetX(), unit.GetY())
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if current, exists := g.unitCells[unit.GetGUID()]; exists && current == coord {
+		return
+	}
+	g.removeLocked(unit.GetGUID())
+	g.addLocked(unit, coord)
+}
+
+// GetUnitsInRadius 查找圆形范围内的单位 - 基于AzerothCore的Cell::VisitAllObjects
+func (g *GridMap) GetUnitsInRadius(x, y, z, radius float32) []IUnit {
+	low := computeCellCoord(x-radius, y-radius)
+	high := computeCellCoord(x+radius, y+radius)
+
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	var units []IUnit
+	for cx := low.x; cx <= high.x; cx++ {
+		for cy := low.y; cy <= high.y; cy++ {
+			for _, unit := range g.cells[CellCoord{x: cx, y: cy}] {
+				dx := unit.GetX() - x
+				dy := unit.GetY() - y
+				dz := unit.GetZ() - z
+				if dx*dx+dy*dy+dz*dz <= radius*radius {
+					units = append(units, unit)
+				}
+			}
+		}
+	}
+	return units
+}
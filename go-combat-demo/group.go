@@ -0,0 +1,850 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+)
+
+// 队伍常量 - 基于AzerothCore的Group.h
+const (
+	MAX_GROUP_SIZE     = 5  // 小队人数上限，也是团队中每个小组的人数上限
+	MAX_RAID_SIZE      = 40 // 团队人数上限
+	MAX_RAID_SUBGROUPS = MAX_RAID_SIZE / MAX_GROUP_SIZE
+
+	GROUP_UPDATE_INTERVAL  = 1000 // 队伍成员状态同步间隔(毫秒)
+	DEFAULT_LOOT_THRESHOLD = 2    // 优秀(绿色)及以上品质的物品参与队伍拾取
+	GROUP_REWARD_DISTANCE  = 74.0 // 分享经验和拾取的距离(码) - 基于AzerothCore的sWorld->getFloatConfig(CONFIG_GROUP_XP_DISTANCE)
+)
+
+// 队伍类型 - 基于AzerothCore的GroupType
+const (
+	GROUPTYPE_NORMAL = 0x00 // 小队
+	GROUPTYPE_RAID   = 0x02 // 团队
+)
+
+// 拾取方式 - 基于AzerothCore的LootMethod
+const (
+	FREE_FOR_ALL      = 0 // 自由拾取
+	ROUND_ROBIN       = 1 // 轮流拾取
+	MASTER_LOOT       = 2 // 队长分配
+	GROUP_LOOT        = 3 // 队伍拾取
+	NEED_BEFORE_GREED = 4 // 需求优先
+)
+
+// 队伍操作 - 基于AzerothCore的PartyOperation
+const (
+	PARTY_OP_INVITE   = 0
+	PARTY_OP_UNINVITE = 1
+	PARTY_OP_LEAVE    = 2
+)
+
+// 队伍操作结果 - 基于AzerothCore的PartyResult
+const (
+	ERR_PARTY_RESULT_OK       = 0 // 成功
+	ERR_BAD_PLAYER_NAME_S     = 1 // 找不到玩家
+	ERR_TARGET_NOT_IN_GROUP_S = 2 // 目标不在队伍中
+	ERR_GROUP_FULL            = 4 // 队伍已满
+	ERR_ALREADY_IN_GROUP_S    = 5 // 目标已在队伍中
+	ERR_NOT_IN_GROUP          = 6 // 自己不在队伍中
+	ERR_NOT_LEADER            = 7 // 不是队长
+)
+
+// 成员状态同步标志 - 基于AzerothCore的GroupUpdateFlags
+const (
+	GROUP_UPDATE_FLAG_STATUS     = 0x00000001 // 在线、死亡状态
+	GROUP_UPDATE_FLAG_CUR_HP     = 0x00000002 // 当前生命值
+	GROUP_UPDATE_FLAG_MAX_HP     = 0x00000004 // 最大生命值
+	GROUP_UPDATE_FLAG_POWER_TYPE = 0x00000008 // 能量类型
+	GROUP_UPDATE_FLAG_CUR_POWER  = 0x00000010 // 当前能量
+	GROUP_UPDATE_FLAG_MAX_POWER  = 0x00000020 // 最大能量
+	GROUP_UPDATE_FLAG_LEVEL      = 0x00000040 // 等级
+	GROUP_UPDATE_FLAG_POSITION   = 0x00000100 // 位置
+
+	GROUP_UPDATE_FULL = GROUP_UPDATE_FLAG_STATUS | GROUP_UPDATE_FLAG_CUR_HP | GROUP_UPDATE_FLAG_MAX_HP |
+		GROUP_UPDATE_FLAG_POWER_TYPE | GROUP_UPDATE_FLAG_CUR_POWER | GROUP_UPDATE_FLAG_MAX_POWER |
+		GROUP_UPDATE_FLAG_LEVEL | GROUP_UPDATE_FLAG_POSITION
+)
+
+// 成员状态 - 基于AzerothCore的GroupMemberOnlineStatus
+const (
+	MEMBER_STATUS_OFFLINE = 0x0000
+	MEMBER_STATUS_ONLINE  = 0x0001
+	MEMBER_STATUS_DEAD    = 0x0004
+)
+
+var nextGroupGuid uint64
+
+// GroupMember 队伍成员
+type GroupMember struct {
+	player    *Player
+	subGroup  uint8
+	assistant bool
+	lastStats partyMemberStats // 上次同步给队友的状态
+}
+
+// partyMemberStats 成员状态快照，用于计算需要同步的字段
+type partyMemberStats struct {
+	status    uint16
+	health    uint32
+	maxHealth uint32
+	powerType uint8
+	power     uint32
+	maxPower  uint32
+	level     uint8
+	x, y      int16
+}
+
+// Group 队伍 - 基于AzerothCore的Group
+type Group struct {
+	guid          uint64
+	leaderGuid    uint64
+	groupType     uint8
+	members       []*GroupMember
+	invitees      map[uint64]*Player
+	lootMethod    uint8
+	lootThreshold uint8
+	looterGuid    uint64      // 轮流拾取时上一个拾取者
+	masterLooter  uint64      // 队长分配时的分配者
+	rolls         []*lootRoll // 进行中的掷骰
+	updateTimer   uint32
+	world         *World
+}
+
+// NewGroup 创建队伍，创建者为队长 - 基于AzerothCore的Group::Create
+func NewGroup(leader *Player) *Group {
+	group := &Group{
+		guid:          atomic.AddUint64(&nextGroupGuid, 1),
+		leaderGuid:    leader.GetGUID(),
+		groupType:     GROUPTYPE_NORMAL,
+		invitees:      make(map[uint64]*Player),
+		lootMethod:    GROUP_LOOT,
+		lootThreshold: DEFAULT_LOOT_THRESHOLD,
+		masterLooter:  leader.GetGUID(),
+		world:         leader.world,
+	}
+	group.addMember(leader)
+	if group.world != nil {
+		group.world.AddGroup(group)
+	}
+	fmt.Printf("[队伍] %s 创建了队伍\n", leader.GetName())
+	return group
+}
+
+// GetGUID 队伍GUID
+func (g *Group) GetGUID() uint64 { return g.guid }
+
+// GetLeaderGUID 队长GUID
+func (g *Group) GetLeaderGUID() uint64 { return g.leaderGuid }
+
+// IsLeader 是否为队长
+func (g *Group) IsLeader(guid uint64) bool { return g.leaderGuid == guid }
+
+// IsRaidGroup 是否为团队
+func (g *Group) IsRaidGroup() bool { return g.groupType&GROUPTYPE_RAID != 0 }
+
+// GetMembersCount 成员数量
+func (g *Group) GetMembersCount() int { return len(g.members) }
+
+// IsFull 是否已满
+func (g *Group) IsFull() bool {
+	if g.IsRaidGroup() {
+		return len(g.members) >= MAX_RAID_SIZE
+	}
+	return len(g.members) >= MAX_GROUP_SIZE
+}
+
+// GetMembers 所有成员
+func (g *Group) GetMembers() []*Player {
+	players := make([]*Player, 0, len(g.members))
+	for _, member := range g.members {
+		players = append(players, member.player)
+	}
+	return players
+}
+
+// getMember 查找成员
+func (g *Group) getMember(guid uint64) *GroupMember {
+	for _, member := range g.members {
+		if member.player.GetGUID() == guid {
+			return member
+		}
+	}
+	return nil
+}
+
+// IsMember 是否为成员
+func (g *Group) IsMember(guid uint64) bool {
+	return g.getMember(guid) != nil
+}
+
+// GetMemberSubGroup 成员所在小组，不是成员时返回MAX_RAID_SUBGROUPS
+func (g *Group) GetMemberSubGroup(guid uint64) uint8 {
+	if member := g.getMember(guid); member != nil {
+		return member.subGroup
+	}
+	return MAX_RAID_SUBGROUPS
+}
+
+// SameSubGroup 两个成员是否在同一小组
+func (g *Group) SameSubGroup(guid1, guid2 uint64) bool {
+	m1, m2 := g.getMember(guid1), g.getMember(guid2)
+	return m1 != nil && m2 != nil && m1.subGroup == m2.subGroup
+}
+
+// getSubGroupCount 小组人数
+func (g *Group) getSubGroupCount(subGroup uint8) int {
+	count := 0
+	for _, member := range g.members {
+		if member.subGroup == subGroup {
+			count++
+		}
+	}
+	return count
+}
+
+// AddInvite 记录邀请
+func (g *Group) AddInvite(player *Player) bool {
+	if player.GetGroupInvite() != nil {
+		return false
+	}
+	g.invitees[player.GetGUID()] = player
+	player.groupInvite = g
+	return true
+}
+
+// RemoveInvite 移除邀请
+func (g *Group) RemoveInvite(player *Player) {
+	delete(g.invitees, player.GetGUID())
+	if player.groupInvite == g {
+		player.groupInvite = nil
+	}
+}
+
+// IsInvited 是否已被邀请
+func (g *Group) IsInvited(guid uint64) bool {
+	_, exists := g.invitees[guid]
+	return exists
+}
+
+// addMember 加入成员，放入第一个未满的小组
+func (g *Group) addMember(player *Player) bool {
+	if g.IsFull() || g.IsMember(player.GetGUID()) {
+		return false
+	}
+
+	subGroup := uint8(0)
+	for g.getSubGroupCount(subGroup) >= MAX_GROUP_SIZE {
+		subGroup++
+	}
+	g.members = append(g.members, &GroupMember{player: player, subGroup: subGroup})
+	player.group = g
+	return true
+}
+
+// AddMember 接受邀请后加入队伍 - 基于AzerothCore的Group::AddMember
+func (g *Group) AddMember(player *Player) bool {
+	g.RemoveInvite(player)
+	if !g.addMember(player) {
+		return false
+	}
+	fmt.Printf("[队伍] %s 加入了队伍 (%d人)\n", player.GetName(), len(g.members))
+
+	g.SendUpdate()
+	// 新成员立即获得所有队友的完整状态
+	for _, member := range g.members {
+		if member.player != player {
+			g.sendMemberStats(member, GROUP_UPDATE_FULL, player)
+			g.sendMemberStats(g.getMember(player.GetGUID()), GROUP_UPDATE_FULL, member.player)
+		}
+	}
+	return true
+}
+
+// RemoveMember 移除成员，剩余不足两人时解散 - 基于AzerothCore的Group::RemoveMember
+func (g *Group) RemoveMember(guid uint64, method int) bool {
+	for i, member := range g.members {
+		if member.player.GetGUID() != guid {
+			continue
+		}
+
+		g.members = append(g.members[:i], g.members[i+1:]...)
+		member.player.group = nil
+		if method == PARTY_OP_UNINVITE {
+			fmt.Printf("[队伍] %s 被移出队伍\n", member.player.GetName())
+		} else {
+			fmt.Printf("[队伍] %s 离开了队伍\n", member.player.GetName())
+		}
+		g.sendToPlayer(member.player, NewWorldPacket(SMSG_GROUP_UNINVITE))
+
+		if len(g.members) < 2 {
+			g.Disband()
+			return true
+		}
+		if g.leaderGuid == guid {
+			g.ChangeLeader(g.members[0].player.GetGUID())
+		}
+		if g.masterLooter == guid {
+			g.masterLooter = g.leaderGuid
+		}
+		g.SendUpdate()
+		return true
+	}
+	return false
+}
+
+// ChangeLeader 转移队长 - 基于AzerothCore的Group::ChangeLeader
+func (g *Group) ChangeLeader(guid uint64) bool {
+	member := g.getMember(guid)
+	if member == nil {
+		return false
+	}
+	g.leaderGuid = guid
+
+	packet := NewWorldPacket(SMSG_GROUP_SET_LEADER)
+	packet.WriteString(member.player.GetName())
+	g.BroadcastPacket(packet, 0)
+	fmt.Printf("[队伍] %s 成为队长\n", member.player.GetName())
+	return true
+}
+
+// ConvertToRaid 转换为团队 - 基于AzerothCore的Group::ConvertToRaid
+func (g *Group) ConvertToRaid() {
+	g.groupType |= GROUPTYPE_RAID
+	g.SendUpdate()
+	fmt.Printf("[队伍] 队伍已转换为团队\n")
+}
+
+// ChangeMembersGroup 把成员移到指定小组 - 基于AzerothCore的Group::ChangeMembersGroup
+func (g *Group) ChangeMembersGroup(guid uint64, subGroup uint8) bool {
+	member := g.getMember(guid)
+	if member == nil || !g.IsRaidGroup() || subGroup >= MAX_RAID_SUBGROUPS ||
+		member.subGroup == subGroup || g.getSubGroupCount(subGroup) >= MAX_GROUP_SIZE {
+		return false
+	}
+	member.subGroup = subGroup
+	g.SendUpdate()
+	return true
+}
+
+// SetLootMethod 设置拾取方式
+func (g *Group) SetLootMethod(method uint8, masterLooter uint64, threshold uint8) {
+	g.lootMethod = method
+	g.lootThreshold = threshold
+	if g.IsMember(masterLooter) {
+		g.masterLooter = masterLooter
+	}
+	g.SendUpdate()
+}
+
+// GetLootMethod 拾取方式
+func (g *Group) GetLootMethod() uint8 { return g.lootMethod }
+
+// GetLooterGuid 轮流拾取时当前的拾取者
+func (g *Group) GetLooterGuid() uint64 { return g.looterGuid }
+
+// GetMasterLooterGuid 队长分配时的分配者
+func (g *Group) GetMasterLooterGuid() uint64 { return g.masterLooter }
+
+// UpdateLooterGuid 轮到下一个在拾取距离内的成员拾取 - 基于AzerothCore的Group::UpdateLooterGuid
+func (g *Group) UpdateLooterGuid(corpse IUnit) uint64 {
+	if len(g.members) == 0 {
+		return 0
+	}
+
+	start := 0
+	for i, member := range g.members {
+		if member.player.GetGUID() == g.looterGuid {
+			start = i + 1
+			break
+		}
+	}
+	for i := 0; i < len(g.members); i++ {
+		player := g.members[(start+i)%len(g.members)].player
+		if player.IsAlive() && player.GetDistanceTo(corpse) <= GROUP_REWARD_DISTANCE {
+			g.looterGuid = player.GetGUID()
+			return g.looterGuid
+		}
+	}
+	return 0
+}
+
+// GetMembersInRewardRange 在奖励距离内的存活成员
+func (g *Group) GetMembersInRewardRange(victim IUnit) []*Player {
+	var players []*Player
+	for _, member := range g.members {
+		if member.player.IsAlive() && member.player.GetDistanceTo(victim) <= GROUP_REWARD_DISTANCE {
+			players = append(players, member.player)
+		}
+	}
+	return players
+}
+
+// Disband 解散队伍 - 基于AzerothCore的Group::Disband
+func (g *Group) Disband() {
+	for _, member := range g.members {
+		member.player.group = nil
+		g.sendToPlayer(member.player, NewWorldPacket(SMSG_GROUP_DESTROYED))
+	}
+	for _, invitee := range g.invitees {
+		if invitee.groupInvite == g {
+			invitee.groupInvite = nil
+		}
+	}
+	g.members = nil
+	g.invitees = make(map[uint64]*Player)
+	if g.world != nil {
+		g.world.RemoveGroup(g.guid)
+		g.world.GetInstanceSaveManager().UnbindGroup(g.guid)
+	}
+	fmt.Printf("[队伍] 队伍已解散\n")
+}
+
+// sendToPlayer 发送数据包给玩家
+func (g *Group) sendToPlayer(player *Player, packet *WorldPacket) {
+	if g.world == nil {
+		return
+	}
+	if session := g.world.GetSessionByPlayerGUID(player.GetGUID()); session != nil {
+		session.SendPacket(packet)
+	}
+}
+
+// BroadcastPacket 发送数据包给所有成员，ignore为0时不排除任何人
+func (g *Group) BroadcastPacket(packet *WorldPacket, ignore uint64) {
+	for _, member := range g.members {
+		if member.player.GetGUID() != ignore {
+			g.sendToPlayer(member.player, packet)
+		}
+	}
+}
+
+// SendUpdate 发送队伍列表给每个成员 - 基于AzerothCore的Group::SendUpdate(SMSG_GROUP_LIST)
+func (g *Group) SendUpdate() {
+	for _, receiver := range g.members {
+		packet := NewWorldPacket(SMSG_GROUP_LIST)
+		packet.WriteUint8(g.groupType)
+		packet.WriteUint8(receiver.subGroup)
+		packet.WriteUint8(boolToUint8(receiver.assistant))
+		packet.WriteUint64(g.guid)
+		packet.WriteUint32(uint32(len(g.members) - 1))
+		for _, member := range g.members {
+			if member == receiver {
+				continue
+			}
+			packet.WriteString(member.player.GetName())
+			packet.WriteUint64(member.player.GetGUID())
+			packet.WriteUint8(uint8(getMemberStatus(member.player)))
+			packet.WriteUint8(member.subGroup)
+			packet.WriteUint8(boolToUint8(member.assistant))
+		}
+		packet.WriteUint64(g.leaderGuid)
+		packet.WriteUint8(g.lootMethod)
+		packet.WriteUint64(g.masterLooter)
+		packet.WriteUint8(g.lootThreshold)
+		g.sendToPlayer(receiver.player, packet)
+	}
+}
+
+// boolToUint8 布尔值转为字节
+func boolToUint8(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// getMemberStatus 成员在线和死亡状态
+func getMemberStatus(player *Player) uint16 {
+	status := uint16(MEMBER_STATUS_OFFLINE)
+	if player.world == nil || player.world.GetSessionByPlayerGUID(player.GetGUID()) != nil {
+		status |= MEMBER_STATUS_ONLINE
+	}
+	if !player.IsAlive() {
+		status |= MEMBER_STATUS_DEAD
+	}
+	return status
+}
+
+// snapshotMemberStats 获取成员当前状态
+func snapshotMemberStats(player *Player) partyMemberStats {
+	powerType := player.GetPowerType()
+	return partyMemberStats{
+		status:    getMemberStatus(player),
+		health:    player.GetHealth(),
+		maxHealth: player.GetMaxHealth(),
+		powerType: powerType,
+		power:     player.GetPower(powerType),
+		maxPower:  player.GetMaxPower(powerType),
+		level:     player.GetLevel(),
+		x:         int16(math.Round(float64(player.GetX()))),
+		y:         int16(math.Round(float64(player.GetY()))),
+	}
+}
+
+// changedFields 与上次同步相比变化的字段
+func (s partyMemberStats) changedFields(last partyMemberStats) uint32 {
+	var mask uint32
+	if s.status != last.status {
+		mask |= GROUP_UPDATE_FLAG_STATUS
+	}
+	if s.health != last.health {
+		mask |= GROUP_UPDATE_FLAG_CUR_HP
+	}
+	if s.maxHealth != last.maxHealth {
+		mask |= GROUP_UPDATE_FLAG_MAX_HP
+	}
+	if s.powerType != last.powerType {
+		mask |= GROUP_UPDATE_FLAG_POWER_TYPE
+	}
+	if s.power != last.power {
+		mask |= GROUP_UPDATE_FLAG_CUR_POWER
+	}
+	if s.maxPower != last.maxPower {
+		mask |= GROUP_UPDATE_FLAG_MAX_POWER
+	}
+	if s.level != last.level {
+		mask |= GROUP_UPDATE_FLAG_LEVEL
+	}
+	if s.x != last.x || s.y != last.y {
+		mask |= GROUP_UPDATE_FLAG_POSITION
+	}
+	return mask
+}
+
+// buildPartyMemberStats 构建成员状态数据包 - 基于AzerothCore的BuildPartyMemberStatsChangedPacket
+func buildPartyMemberStats(player *Player, mask uint32) *WorldPacket {
+	stats := snapshotMemberStats(player)
+
+	packet := NewWorldPacket(SMSG_PARTY_MEMBER_STATS)
+	packet.WriteUint64(player.GetGUID())
+	packet.WriteUint32(mask)
+	if mask&GROUP_UPDATE_FLAG_STATUS != 0 {
+		packet.WriteUint16(stats.status)
+	}
+	if mask&GROUP_UPDATE_FLAG_CUR_HP != 0 {
+		packet.WriteUint32(stats.health)
+	}
+	if mask&GROUP_UPDATE_FLAG_MAX_HP != 0 {
+		packet.WriteUint32(stats.maxHealth)
+	}
+	if mask&GROUP_UPDATE_FLAG_POWER_TYPE != 0 {
+		packet.WriteUint8(stats.powerType)
+	}
+	if mask&GROUP_UPDATE_FLAG_CUR_POWER != 0 {
+		packet.WriteUint16(uint16(stats.power))
+	}
+	if mask&GROUP_UPDATE_FLAG_MAX_POWER != 0 {
+		packet.WriteUint16(uint16(stats.maxPower))
+	}
+	if mask&GROUP_UPDATE_FLAG_LEVEL != 0 {
+		packet.WriteUint16(uint16(stats.level))
+	}
+	if mask&GROUP_UPDATE_FLAG_POSITION != 0 {
+		packet.WriteUint16(uint16(stats.x))
+		packet.WriteUint16(uint16(stats.y))
+	}
+	return packet
+}
+
+// sendMemberStats 把成员状态发给指定玩家
+func (g *Group) sendMemberStats(member *GroupMember, mask uint32, receiver *Player) {
+	g.sendToPlayer(receiver, buildPartyMemberStats(member.player, mask))
+}
+
+// Update 推进掷骰，并定期把成员变化的状态同步给其他成员 - 基于AzerothCore的Player::SendUpdateToOutOfRangeGroupMembers
+func (g *Group) Update(diff uint32) {
+	g.updateLootRolls(diff)
+
+	g.updateTimer += diff
+	if g.updateTimer < GROUP_UPDATE_INTERVAL {
+		return
+	}
+	g.updateTimer = 0
+
+	for _, member := range g.members {
+		stats := snapshotMemberStats(member.player)
+		mask := stats.changedFields(member.lastStats)
+		member.lastStats = stats
+		if mask == 0 {
+			continue
+		}
+		g.BroadcastPacket(buildPartyMemberStats(member.player, mask), member.player.GetGUID())
+	}
+}
+
+// === 玩家队伍接口 ===
+
+// getPlayer 获取单位对应的玩家，非玩家时返回nil
+func getPlayer(unit IUnit) *Player {
+	if base := getBaseUnit(unit); base != nil {
+		return base.player
+	}
+	return nil
+}
+
+// GetGroup 所在队伍
+func (p *Player) GetGroup() *Group { return p.group }
+
+// GetGroupInvite 收到的队伍邀请
+func (p *Player) GetGroupInvite() *Group { return p.groupInvite }
+
+// IsInSameGroupWith 是否在同一小队(团队中为同一小组) - 基于AzerothCore的Player::IsInSameGroupWith
+func (p *Player) IsInSameGroupWith(other IUnit) bool {
+	if other.GetGUID() == p.GetGUID() {
+		return true
+	}
+	return p.group != nil && p.group.SameSubGroup(p.GetGUID(), other.GetGUID())
+}
+
+// IsInSameRaidWith 是否在同一队伍或团队 - 基于AzerothCore的Player::IsInSameRaidWith
+func (p *Player) IsInSameRaidWith(other IUnit) bool {
+	if other.GetGUID() == p.GetGUID() {
+		return true
+	}
+	return p.group != nil && p.group.IsMember(other.GetGUID())
+}
+
+// GetPartyMembers 同一小队的存活队友(含自己)，没有队伍时只有自己
+func (p *Player) GetPartyMembers() []*Player {
+	if p.group == nil {
+		return []*Player{p}
+	}
+	var members []*Player
+	for _, member := range p.group.GetMembers() {
+		if member.IsAlive() && p.IsInSameGroupWith(member) {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// === 队伍操作码 ===
+
+// sendPartyResult 发送队伍操作结果 - 基于AzerothCore的WorldSession::SendPartyResult
+func (ws *WorldSession) sendPartyResult(operation uint32, member string, result uint32) {
+	packet := NewWorldPacket(SMSG_PARTY_COMMAND_RESULT)
+	packet.WriteUint32(operation)
+	packet.WriteString(member)
+	packet.WriteUint32(result)
+	ws.SendPacket(packet)
+	if result != ERR_PARTY_RESULT_OK {
+		fmt.Printf("[队伍] 操作%d失败 %s: 错误%d\n", operation, member, result)
+	}
+}
+
+// sessionPlayer 会话对应的玩家
+func (ws *WorldSession) sessionPlayer() *Player {
+	if unit := ws.GetPlayer(); unit != nil {
+		return getPlayer(unit)
+	}
+	return nil
+}
+
+// HandleGroupInviteOpcode 邀请玩家 - 基于AzerothCore的WorldSession::HandleGroupInviteOpcode
+func (ws *WorldSession) HandleGroupInviteOpcode(packet *WorldPacket) {
+	name := packet.ReadString()
+	player := ws.sessionPlayer()
+	if player == nil || ws.world == nil {
+		return
+	}
+
+	targetSession := ws.world.GetSessionByPlayerName(name)
+	if targetSession == nil || targetSession.sessionPlayer() == nil {
+		ws.sendPartyResult(PARTY_OP_INVITE, name, ERR_BAD_PLAYER_NAME_S)
+		return
+	}
+	invited := targetSession.sessionPlayer()
+	if invited.GetGroup() != nil || invited.GetGroupInvite() != nil {
+		ws.sendPartyResult(PARTY_OP_INVITE, name, ERR_ALREADY_IN_GROUP_S)
+		return
+	}
+
+	group := player.GetGroup()
+	if group != nil && !group.IsLeader(player.GetGUID()) {
+		ws.sendPartyResult(PARTY_OP_INVITE, "", ERR_NOT_LEADER)
+		return
+	}
+	if group != nil && group.IsFull() {
+		ws.sendPartyResult(PARTY_OP_INVITE, "", ERR_GROUP_FULL)
+		return
+	}
+	if group == nil {
+		group = NewGroup(player)
+	}
+	group.AddInvite(invited)
+
+	invite := NewWorldPacket(SMSG_GROUP_INVITE)
+	invite.WriteUint8(1)
+	invite.WriteString(player.GetName())
+	targetSession.SendPacket(invite)
+
+	ws.sendPartyResult(PARTY_OP_INVITE, name, ERR_PARTY_RESULT_OK)
+	fmt.Printf("[队伍] %s 邀请 %s 加入队伍\n", player.GetName(), name)
+}
+
+// HandleGroupAcceptOpcode 接受邀请 - 基于AzerothCore的WorldSession::HandleGroupAcceptOpcode
+func (ws *WorldSession) HandleGroupAcceptOpcode(packet *WorldPacket) {
+	player := ws.sessionPlayer()
+	if player == nil {
+		return
+	}
+	group := player.GetGroupInvite()
+	if group == nil {
+		return
+	}
+	if group.IsFull() || len(group.members) == 0 {
+		group.RemoveInvite(player)
+		ws.sendPartyResult(PARTY_OP_INVITE, "", ERR_GROUP_FULL)
+		return
+	}
+	group.AddMember(player)
+}
+
+// HandleGroupDeclineOpcode 拒绝邀请 - 基于AzerothCore的WorldSession::HandleGroupDeclineOpcode
+func (ws *WorldSession) HandleGroupDeclineOpcode(packet *WorldPacket) {
+	player := ws.sessionPlayer()
+	if player == nil {
+		return
+	}
+	group := player.GetGroupInvite()
+	if group == nil {
+		return
+	}
+	group.RemoveInvite(player)
+
+	decline := NewWorldPacket(SMSG_GROUP_DECLINE)
+	decline.WriteString(player.GetName())
+	group.BroadcastPacket(decline, 0)
+
+	// 只有队长一人的队伍在邀请被拒绝后解散
+	if len(group.members) < 2 && len(group.invitees) == 0 {
+		group.Disband()
+	}
+}
+
+// HandleGroupUninviteGuidOpcode 队长踢出成员 - 基于AzerothCore的WorldSession::HandleGroupUninviteGuidOpcode
+func (ws *WorldSession) HandleGroupUninviteGuidOpcode(packet *WorldPacket) {
+	guid := packet.ReadUint64()
+	player := ws.sessionPlayer()
+	if player == nil {
+		return
+	}
+	group := player.GetGroup()
+	if group == nil {
+		ws.sendPartyResult(PARTY_OP_UNINVITE, "", ERR_NOT_IN_GROUP)
+		return
+	}
+	if !group.IsLeader(player.GetGUID()) {
+		ws.sendPartyResult(PARTY_OP_UNINVITE, "", ERR_NOT_LEADER)
+		return
+	}
+	if guid == player.GetGUID() || !group.RemoveMember(guid, PARTY_OP_UNINVITE) {
+		ws.sendPartyResult(PARTY_OP_UNINVITE, "", ERR_TARGET_NOT_IN_GROUP_S)
+	}
+}
+
+// HandleGroupDisbandOpcode 离开队伍 - 基于AzerothCore的WorldSession::HandleGroupDisbandOpcode
+func (ws *WorldSession) HandleGroupDisbandOpcode(packet *WorldPacket) {
+	player := ws.sessionPlayer()
+	if player == nil || player.GetGroup() == nil {
+		return
+	}
+	ws.sendPartyResult(PARTY_OP_LEAVE, player.GetName(), ERR_PARTY_RESULT_OK)
+	player.GetGroup().RemoveMember(player.GetGUID(), PARTY_OP_LEAVE)
+}
+
+// HandleGroupSetLeaderOpcode 转移队长 - 基于AzerothCore的WorldSession::HandleGroupSetLeaderOpcode
+func (ws *WorldSession) HandleGroupSetLeaderOpcode(packet *WorldPacket) {
+	guid := packet.ReadUint64()
+	player := ws.sessionPlayer()
+	if player == nil || player.GetGroup() == nil || !player.GetGroup().IsLeader(player.GetGUID()) {
+		return
+	}
+	player.GetGroup().ChangeLeader(guid)
+}
+
+// HandleGroupRaidConvertOpcode 转换为团队 - 基于AzerothCore的WorldSession::HandleGroupRaidConvertOpcode
+func (ws *WorldSession) HandleGroupRaidConvertOpcode(packet *WorldPacket) {
+	player := ws.sessionPlayer()
+	if player == nil || player.GetGroup() == nil {
+		return
+	}
+	group := player.GetGroup()
+	if !group.IsLeader(player.GetGUID()) {
+		ws.sendPartyResult(PARTY_OP_INVITE, "", ERR_NOT_LEADER)
+		return
+	}
+	if !group.IsRaidGroup() {
+		group.ConvertToRaid()
+	}
+}
+
+// HandleGroupChangeSubGroupOpcode 调整团队小组 - 基于AzerothCore的WorldSession::HandleGroupChangeSubGroupOpcode
+func (ws *WorldSession) HandleGroupChangeSubGroupOpcode(packet *WorldPacket) {
+	name := packet.ReadString()
+	subGroup := packet.ReadUint8()
+	player := ws.sessionPlayer()
+	if player == nil || player.GetGroup() == nil || !player.GetGroup().IsLeader(player.GetGUID()) {
+		return
+	}
+	group := player.GetGroup()
+	for _, member := range group.GetMembers() {
+		if member.GetName() == name {
+			group.ChangeMembersGroup(member.GetGUID(), subGroup)
+			return
+		}
+	}
+}
+
+// HandleLootMethodOpcode 设置拾取方式 - 基于AzerothCore的WorldSession::HandleLootMethodOpcode
+func (ws *WorldSession) HandleLootMethodOpcode(packet *WorldPacket) {
+	method := packet.ReadUint32()
+	masterLooter := packet.ReadUint64()
+	threshold := packet.ReadUint32()
+	player := ws.sessionPlayer()
+	if player == nil || player.GetGroup() == nil || !player.GetGroup().IsLeader(player.GetGUID()) {
+		return
+	}
+	if method > NEED_BEFORE_GREED {
+		return
+	}
+	player.GetGroup().SetLootMethod(uint8(method), masterLooter, uint8(threshold))
+}
+
+// === 世界中的队伍 ===
+
+// AddGroup 登记队伍，由世界定期同步成员状态
+func (w *World) AddGroup(group *Group) {
+	w.groupMutex.Lock()
+	defer w.groupMutex.Unlock()
+	w.groups[group.guid] = group
+}
+
+// RemoveGroup 移除队伍
+func (w *World) RemoveGroup(guid uint64) {
+	w.groupMutex.Lock()
+	defer w.groupMutex.Unlock()
+	delete(w.groups, guid)
+}
+
+// GetGroup 获取队伍
+func (w *World) GetGroup(guid uint64) *Group {
+	w.groupMutex.Lock()
+	defer w.groupMutex.Unlock()
+	return w.groups[guid]
+}
+
+// updateGroups 更新所有队伍
+func (w *World) updateGroups(diff uint32) {
+	w.groupMutex.Lock()
+	groups := make([]*Group, 0, len(w.groups))
+	for _, group := range w.groups {
+		groups = append(groups, group)
+	}
+	w.groupMutex.Unlock()
+
+	for _, group := range groups {
+		group.Update(diff)
+	}
+}
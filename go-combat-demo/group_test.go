@@ -0,0 +1,163 @@
+package main
+
+import "testing"
+
+func newGroupTestPlayer(world *World, id uint32, name string, class uint8, x float32) (*Player, *WorldSession) {
+	player := NewPlayer(name, 20, class)
+	player.SetMaxHealth(1000)
+	player.SetHealth(1000)
+	player.SetPosition(x, 0, 0)
+	player.SetWorld(world)
+	session := NewWorldSession(id, name, nil, world)
+	session.SetPlayer(player)
+	world.AddSession(session)
+	return player, session
+}
+
+func sendGroupInvite(session *WorldSession, name string) {
+	packet := NewWorldPacket(CMSG_GROUP_INVITE)
+	packet.WriteString(name)
+	session.handlePacket(packet)
+}
+
+func TestGroupInviteAcceptKickAndLeave(t *testing.T) {
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	leader, leaderSession := newGroupTestPlayer(world, 1, "leader", CLASS_WARRIOR, 0)
+	bob, bobSession := newGroupTestPlayer(world, 2, "bob", CLASS_PRIEST, 5)
+	carl, carlSession := newGroupTestPlayer(world, 3, "carl", CLASS_MAGE, 10)
+
+	sendGroupInvite(leaderSession, "bob")
+	if bob.GetGroupInvite() == nil || bob.GetGroup() != nil {
+		t.Fatalf("bob should have a pending invite")
+	}
+	bobSession.handlePacket(NewWorldPacket(CMSG_GROUP_ACCEPT))
+	group := leader.GetGroup()
+	if group == nil || bob.GetGroup() != group || group.GetMembersCount() != 2 || !group.IsLeader(leader.GetGUID()) {
+		t.Fatalf("bob should join the leader's group")
+	}
+	if world.GetGroup(group.GetGUID()) != group {
+		t.Fatalf("group should be registered with the world")
+	}
+
+	sendGroupInvite(bobSession, "carl")
+	if carl.GetGroupInvite() != nil {
+		t.Fatalf("only the leader can invite")
+	}
+	sendGroupInvite(leaderSession, "carl")
+	carlSession.handlePacket(NewWorldPacket(CMSG_GROUP_DECLINE))
+	if carl.GetGroup() != nil || group.IsInvited(carl.GetGUID()) {
+		t.Fatalf("declined invite should be removed")
+	}
+	sendGroupInvite(leaderSession, "carl")
+	carlSession.handlePacket(NewWorldPacket(CMSG_GROUP_ACCEPT))
+	if group.GetMembersCount() != 3 || !leader.IsInSameGroupWith(carl) {
+		t.Fatalf("carl should join after accepting")
+	}
+
+	kick := NewWorldPacket(CMSG_GROUP_UNINVITE_GUID)
+	kick.WriteUint64(bob.GetGUID())
+	leaderSession.handlePacket(kick)
+	if bob.GetGroup() != nil || group.IsMember(bob.GetGUID()) {
+		t.Fatalf("bob should be kicked")
+	}
+
+	carlSession.handlePacket(NewWorldPacket(CMSG_GROUP_DISBAND))
+	if carl.GetGroup() != nil || leader.GetGroup() != nil || world.GetGroup(group.GetGUID()) != nil {
+		t.Fatalf("group should disband when fewer than two members remain")
+	}
+}
+
+func TestGroupKillSplitsExperienceByLevel(t *testing.T) {
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	leader, _ := newGroupTestPlayer(world, 1, "leader", CLASS_WARRIOR, 0)
+	low, _ := newGroupTestPlayer(world, 2, "low", CLASS_PRIEST, 5)
+	low.SetLevel(10)
+	group := NewGroup(leader)
+	group.AddMember(low)
+	group.SetLootMethod(ROUND_ROBIN, 0, DEFAULT_LOOT_THRESHOLD)
+
+	mob := NewCreature("mob", 20, CREATURE_TYPE_HUMANOID)
+	mob.SetMaxHealth(100)
+	mob.SetHealth(100)
+	mob.SetPosition(3, 0, 0)
+	mob.DealDamage(leader, 50, DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	mob.DealDamage(low, 50, DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+
+	// BaseGain(20, 20) = 145，按等级 20:10 分配
+	if leader.GetXP() != 96 || low.GetXP() != 48 {
+		t.Fatalf("expected xp split 96/48, got %d/%d", leader.GetXP(), low.GetXP())
+	}
+	if group.GetLooterGuid() != leader.GetGUID() {
+		t.Fatalf("round robin should pick the first member in range")
+	}
+
+	solo, _ := newGroupTestPlayer(world, 3, "solo", CLASS_MAGE, 0)
+	solo.xp = GetXPForLevel(20) - 100
+	other := NewCreature("other", 20, CREATURE_TYPE_HUMANOID)
+	other.SetMaxHealth(100)
+	other.SetHealth(100)
+	other.DealDamage(solo, 100, DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	if solo.GetLevel() != 21 || solo.GetXP() != 45 {
+		t.Fatalf("solo kill should level up with 45 xp left over, got level %d xp %d", solo.GetLevel(), solo.GetXP())
+	}
+	if BaseGain(60, 40) != 0 {
+		t.Fatalf("gray mobs should give no experience")
+	}
+}
+
+func TestPartyAreaTargetsAndGroupAwareHealer(t *testing.T) {
+	world := NewWorld()
+	defer world.GetBatchSyncManager().Stop()
+
+	warrior := NewPlayer("warrior", 20, CLASS_WARRIOR)
+	priest := NewPlayer("priest", 20, CLASS_PRIEST)
+	stranger := NewPlayer("stranger", 20, CLASS_MAGE)
+	for i, player := range []*Player{warrior, priest, stranger} {
+		player.SetMaxHealth(1000)
+		player.SetHealth(1000)
+		player.SetPosition(float32(i*3), 0, 0)
+		world.AddUnit(player)
+	}
+
+	if priest.GetAI().(*PlayerAI).findHealTarget() != nil {
+		t.Fatalf("healthy solo priest should have nothing to heal")
+	}
+	group := NewGroup(warrior)
+	group.AddMember(priest)
+
+	shout := NewSpell(warrior.Unit, GlobalSpellManager.GetSpell(SPELL_BATTLE_SHOUT), world)
+	shout.setExplicitTarget(warrior)
+	shout.selectSpellTargets()
+	if got := len(shout.effectTargets[0]); got != 2 {
+		t.Fatalf("battle shout should hit the two party members only, got %d", got)
+	}
+
+	group.ConvertToRaid()
+	group.ChangeMembersGroup(priest.GetGUID(), 1)
+	shout.selectSpellTargets()
+	if got := len(shout.effectTargets[0]); got != 1 {
+		t.Fatalf("party spells should not reach other raid subgroups, got %d", got)
+	}
+	raidBuff := &SpellInfo{ID: 2, Name: "raid", Effects: []SpellEffect{
+		{EffectType: SPELL_EFFECT_APPLY_AURA, ImplicitTargetA: TARGET_UNIT_CASTER_AREA_RAID, RadiusIndex: 9},
+	}}
+	raid := NewSpell(warrior.Unit, raidBuff, world)
+	raid.setExplicitTarget(warrior)
+	raid.selectSpellTargets()
+	if got := len(raid.effectTargets[0]); got != 2 {
+		t.Fatalf("raid spells should reach the whole raid, got %d", got)
+	}
+
+	group.ChangeMembersGroup(priest.GetGUID(), 0)
+	warrior.SetHealth(400)
+	warrior.attackers[1] = stranger
+	ai := priest.GetAI().(*PlayerAI)
+	if ai.findHealTarget() != IUnit(warrior) || ai.findShieldTarget() != IUnit(warrior) {
+		t.Fatalf("healer should heal and shield the wounded tank")
+	}
+	if warriorAI := warrior.GetAI().(*PlayerAI); warriorAI.findBuffTarget(SPELL_BATTLE_SHOUT) == nil {
+		t.Fatalf("party members without battle shout should need the buff")
+	}
+}
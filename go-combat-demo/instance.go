@@ -0,0 +1,358 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 副本重置 - 基于AzerothCore的InstanceSaveMgr
+const (
+	INSTANCE_RESET_HOUR         = 4              // 英雄难度每天凌晨4点统一重置
+	INSTANCE_HEROIC_RESET_DELAY = 24 * time.Hour // 英雄难度的重置周期
+	INSTANCE_SAVE_FILE          = "instances.json"
+)
+
+// 难度对生物的缩放 - 对应AzerothCore中英雄难度生物模板的ModHealth和DamageModifier
+const (
+	HEROIC_HEALTH_MODIFIER = 1.5
+	HEROIC_DAMAGE_MODIFIER = 1.3
+)
+
+// InstanceSave 副本存档 - 基于AzerothCore的InstanceSave和instance表
+type InstanceSave struct {
+	InstanceId          uint32 `json:"instance"`
+	MapId               uint32 `json:"map"`
+	Difficulty          uint8  `json:"difficulty"`
+	ResetTime           int64  `json:"reset_time"`           // 重置时间(Unix秒)，0表示没有重置计划
+	CompletedEncounters uint32 `json:"completed_encounters"` // 已击杀首领的位掩码，第N个遭遇战对应第N-1位
+}
+
+// GetInstanceId 副本实例ID
+func (s *InstanceSave) GetInstanceId() uint32 { return s.InstanceId }
+
+// GetResetTime 重置时间，没有重置计划时返回零值
+func (s *InstanceSave) GetResetTime() time.Time {
+	if s.ResetTime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(s.ResetTime, 0)
+}
+
+// IsEncounterDone 遭遇战是否已完成
+func (s *InstanceSave) IsEncounterDone(encounterId uint32) bool {
+	return encounterId > 0 && encounterId <= 32 && s.CompletedEncounters&(1<<(encounterId-1)) != 0
+}
+
+// InstancePlayerBind 玩家的副本锁定 - 基于AzerothCore的character_instance表
+type InstancePlayerBind struct {
+	Guid       uint64 `json:"guid"`
+	InstanceId uint32 `json:"instance"`
+}
+
+// instanceStore 副本存档文件的内容
+type instanceStore struct {
+	NextInstanceId uint32                `json:"next_instance"`
+	Saves          []*InstanceSave       `json:"saves"`
+	PlayerBinds    []*InstancePlayerBind `json:"player_binds"`
+}
+
+// instanceBindKey 绑定按地图和难度区分
+type instanceBindKey struct {
+	mapId      uint32
+	difficulty uint8
+}
+
+// InstanceSaveManager 副本存档管理器 - 基于AzerothCore的InstanceSaveMgr
+// 队伍绑定决定队伍进入哪个实例，只在内存中保存；玩家锁定在英雄难度击杀首领时产生，和存档一起写入文件
+type InstanceSaveManager struct {
+	path           string // 存档文件路径，为空时只保存在内存中
+	nextInstanceId uint32
+	saves          map[uint32]*InstanceSave
+	playerBinds    map[uint64]map[instanceBindKey]*InstancePlayerBind
+	groupBinds     map[uint64]map[instanceBindKey]uint32
+	mutex          sync.Mutex
+}
+
+// NewInstanceSaveManager 创建只保存在内存中的副本存档管理器
+func NewInstanceSaveManager() *InstanceSaveManager {
+	return &InstanceSaveManager{
+		nextInstanceId: 1,
+		saves:          make(map[uint32]*InstanceSave),
+		playerBinds:    make(map[uint64]map[instanceBindKey]*InstancePlayerBind),
+		groupBinds:     make(map[uint64]map[instanceBindKey]uint32),
+	}
+}
+
+// LoadInstanceSaveManager 从目录加载副本存档，文件不存在时从空存档开始
+func LoadInstanceSaveManager(dir string) (*InstanceSaveManager, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建副本存档目录失败: %v", err)
+	}
+	mgr := NewInstanceSaveManager()
+	mgr.path = filepath.Join(dir, INSTANCE_SAVE_FILE)
+
+	content, err := os.ReadFile(mgr.path)
+	if os.IsNotExist(err) {
+		return mgr, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取副本存档失败: %v", err)
+	}
+	var store instanceStore
+	if err := json.Unmarshal(content, &store); err != nil {
+		return nil, fmt.Errorf("解析副本存档失败: %v", err)
+	}
+
+	if store.NextInstanceId > mgr.nextInstanceId {
+		mgr.nextInstanceId = store.NextInstanceId
+	}
+	for _, save := range store.Saves {
+		mgr.saves[save.InstanceId] = save
+	}
+	for _, bind := range store.PlayerBinds {
+		if save, ok := mgr.saves[bind.InstanceId]; ok {
+			mgr.playerBindsOf(bind.Guid)[instanceBindKey{save.MapId, save.Difficulty}] = bind
+		}
+	}
+	// 队伍绑定不保存，没有重置计划又没有玩家锁定的存档已经无法进入
+	for id, save := range mgr.saves {
+		if save.ResetTime == 0 && !mgr.isBound(id) {
+			delete(mgr.saves, id)
+		}
+	}
+	return mgr, nil
+}
+
+// save 写入存档文件，调用时必须持有锁
+func (m *InstanceSaveManager) save() {
+	if m.path == "" {
+		return
+	}
+	store := instanceStore{NextInstanceId: m.nextInstanceId}
+	for _, save := range m.saves {
+		store.Saves = append(store.Saves, save)
+	}
+	for _, binds := range m.playerBinds {
+		for _, bind := range binds {
+			store.PlayerBinds = append(store.PlayerBinds, bind)
+		}
+	}
+
+	content, err := json.MarshalIndent(&store, "", "  ")
+	if err != nil {
+		fmt.Printf("[副本] 序列化副本存档失败: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(m.path, content, 0644); err != nil {
+		fmt.Printf("[副本] 保存副本存档失败: %v\n", err)
+	}
+}
+
+// getResetTime 新存档的重置时间 - 英雄难度在下一个重置时刻重置，普通难度没有重置计划，队伍解绑后删除
+func getResetTime(difficulty uint8, now time.Time) int64 {
+	if difficulty != DIFFICULTY_HEROIC {
+		return 0
+	}
+	reset := time.Date(now.Year(), now.Month(), now.Day(), INSTANCE_RESET_HOUR, 0, 0, 0, now.Location())
+	if !reset.After(now) {
+		reset = reset.Add(INSTANCE_HEROIC_RESET_DELAY)
+	}
+	return reset.Unix()
+}
+
+// CreateInstanceSave 创建新的副本实例存档
+func (m *InstanceSaveManager) CreateInstanceSave(mapId uint32, difficulty uint8, now time.Time) *InstanceSave {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	save := &InstanceSave{
+		InstanceId: m.nextInstanceId,
+		MapId:      mapId,
+		Difficulty: difficulty,
+		ResetTime:  getResetTime(difficulty, now),
+	}
+	m.nextInstanceId++
+	m.saves[save.InstanceId] = save
+	m.save()
+	fmt.Printf("[副本] 创建副本实例 %d (地图 %d, 难度 %d)\n", save.InstanceId, mapId, difficulty)
+	return save
+}
+
+// GetInstanceSave 按实例ID查找存档
+func (m *InstanceSaveManager) GetInstanceSave(instanceId uint32) *InstanceSave {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.saves[instanceId]
+}
+
+// SetEncounterDone 记录遭遇战完成并写入存档
+func (m *InstanceSaveManager) SetEncounterDone(instanceId, encounterId uint32) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	save, ok := m.saves[instanceId]
+	if !ok || encounterId == 0 || encounterId > 32 {
+		return
+	}
+	save.CompletedEncounters |= 1 << (encounterId - 1)
+	m.save()
+}
+
+// playerBindsOf 玩家的锁定列表，调用时必须持有锁
+func (m *InstanceSaveManager) playerBindsOf(guid uint64) map[instanceBindKey]*InstancePlayerBind {
+	binds, ok := m.playerBinds[guid]
+	if !ok {
+		binds = make(map[instanceBindKey]*InstancePlayerBind)
+		m.playerBinds[guid] = binds
+	}
+	return binds
+}
+
+// GetPlayerBind 玩家在指定地图和难度上的锁定
+func (m *InstanceSaveManager) GetPlayerBind(guid uint64, mapId uint32, difficulty uint8) *InstancePlayerBind {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.playerBinds[guid][instanceBindKey{mapId, difficulty}]
+}
+
+// GetPlayerBinds 玩家的所有锁定
+func (m *InstanceSaveManager) GetPlayerBinds(guid uint64) []*InstancePlayerBind {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	binds := make([]*InstancePlayerBind, 0, len(m.playerBinds[guid]))
+	for _, bind := range m.playerBinds[guid] {
+		binds = append(binds, bind)
+	}
+	return binds
+}
+
+// BindPlayer 把玩家锁定到副本实例 - 基于AzerothCore的Player::BindToInstance
+func (m *InstanceSaveManager) BindPlayer(guid uint64, save *InstanceSave) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := instanceBindKey{save.MapId, save.Difficulty}
+	if bind, ok := m.playerBinds[guid][key]; ok && bind.InstanceId == save.InstanceId {
+		return
+	}
+	m.playerBindsOf(guid)[key] = &InstancePlayerBind{Guid: guid, InstanceId: save.InstanceId}
+	m.save()
+}
+
+// GetGroupBind 队伍在指定地图和难度上绑定的副本实例
+func (m *InstanceSaveManager) GetGroupBind(groupGuid uint64, mapId uint32, difficulty uint8) *InstanceSave {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	instanceId, ok := m.groupBinds[groupGuid][instanceBindKey{mapId, difficulty}]
+	if !ok {
+		return nil
+	}
+	return m.saves[instanceId]
+}
+
+// BindGroup 把队伍绑定到副本实例 - 基于AzerothCore的Group::BindToInstance
+func (m *InstanceSaveManager) BindGroup(groupGuid uint64, save *InstanceSave) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	binds, ok := m.groupBinds[groupGuid]
+	if !ok {
+		binds = make(map[instanceBindKey]uint32)
+		m.groupBinds[groupGuid] = binds
+	}
+	binds[instanceBindKey{save.MapId, save.Difficulty}] = save.InstanceId
+}
+
+// UnbindGroup 队伍解散时解除所有绑定，没有重置计划又没有人绑定的实例随之删除
+func (m *InstanceSaveManager) UnbindGroup(groupGuid uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	binds, ok := m.groupBinds[groupGuid]
+	if !ok {
+		return
+	}
+	delete(m.groupBinds, groupGuid)
+	for _, instanceId := range binds {
+		if save, ok := m.saves[instanceId]; ok && save.ResetTime == 0 && !m.isBound(instanceId) {
+			m.deleteInstance(instanceId)
+		}
+	}
+	m.save()
+}
+
+// isBound 是否还有队伍或玩家绑定到实例，调用时必须持有锁
+func (m *InstanceSaveManager) isBound(instanceId uint32) bool {
+	for _, binds := range m.groupBinds {
+		for _, id := range binds {
+			if id == instanceId {
+				return true
+			}
+		}
+	}
+	for _, binds := range m.playerBinds {
+		for _, bind := range binds {
+			if bind.InstanceId == instanceId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deleteInstance 删除实例存档和所有绑定，调用时必须持有锁
+func (m *InstanceSaveManager) deleteInstance(instanceId uint32) {
+	delete(m.saves, instanceId)
+	for groupGuid, binds := range m.groupBinds {
+		for key, id := range binds {
+			if id == instanceId {
+				delete(binds, key)
+			}
+		}
+		if len(binds) == 0 {
+			delete(m.groupBinds, groupGuid)
+		}
+	}
+	for guid, binds := range m.playerBinds {
+		for key, bind := range binds {
+			if bind.InstanceId == instanceId {
+				delete(binds, key)
+			}
+		}
+		if len(binds) == 0 {
+			delete(m.playerBinds, guid)
+		}
+	}
+	fmt.Printf("[副本] 副本实例 %d 已重置\n", instanceId)
+}
+
+// Update 重置到期的实例 - 基于AzerothCore的InstanceSaveMgr::Update
+func (m *InstanceSaveManager) Update(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	reset := false
+	for id, save := range m.saves {
+		if save.ResetTime != 0 && now.Unix() >= save.ResetTime {
+			m.deleteInstance(id)
+			reset = true
+		}
+	}
+	if reset {
+		m.save()
+	}
+}
+
+// SetInstanceSaveManager 设置副本存档管理器
+func (w *World) SetInstanceSaveManager(mgr *InstanceSaveManager) {
+	w.instanceSaves = mgr
+}
+
+// GetInstanceSaveManager 副本存档管理器
+func (w *World) GetInstanceSaveManager() *InstanceSaveManager {
+	return w.instanceSaves
+}
@@ -0,0 +1,30 @@
+package main
+
+// 死亡矿井 - 基于AzerothCore的instance_deadmines脚本
+
+func init() {
+	RegisterCreatureScript("boss_rhahkzor", func(creature *Creature) IAI { return NewBossAI(creature, rhahkZorScript) })
+}
+
+// 死亡矿井的首领
+const (
+	DATA_RHAHKZOR = 1
+	DATA_VANCLEEF = 2
+)
+
+// 拉克佐的法术和事件
+const (
+	SPELL_RHAHKZOR_SLAM = 6304 // 拉克佐猛击
+
+	EVENT_RHAHKZOR_SLAM = 1
+)
+
+// rhahkZorScript 拉克佐 - 基于AzerothCore的boss_rhahkzor，击杀后打开工厂大门
+var rhahkZorScript = &BossScript{
+	name: "拉克佐",
+	events: []BossEvent{
+		{id: EVENT_RHAHKZOR_SLAM, timer: 4000, repeatMin: 12000, repeatMax: 15000,
+			spellId: SPELL_RHAHKZOR_SLAM, target: BOSS_TARGET_VICTIM},
+	},
+	aggroYell: "范克里夫老大会为你们的脑袋付大钱的！",
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestInstanceSaveLockoutAndDifficulty(t *testing.T) {
+	if GlobalObjectMgr == nil {
+		InitObjectMgr()
+	}
+	dir := t.TempDir()
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	mgr, err := LoadInstanceSaveManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	world.SetInstanceSaveManager(mgr)
+
+	tank, _ := newGroupTestPlayer(world, 1, "tank", CLASS_WARRIOR, 0)
+	healer, _ := newGroupTestPlayer(world, 2, "healer", CLASS_PRIEST, 5)
+
+	heroic := NewDeadminesDungeon(world, DIFFICULTY_HEROIC)
+	boss := heroic.GetEncounter(DATA_VANCLEEF).boss
+	normalBoss := NewDeadminesDungeon(world, DIFFICULTY_NORMAL).GetEncounter(DATA_VANCLEEF).boss
+	template := GlobalObjectMgr.GetCreatureTemplate(boss.GetEntry())
+	if baseHealth := uint32(math.Round(26 * CREATURE_BASE_HEALTH_PER_LEVEL * float64(template.HealthModifier))); normalBoss.GetMaxHealth() != baseHealth {
+		t.Fatalf("normal boss should keep template health %d, got %d", baseHealth, normalBoss.GetMaxHealth())
+	}
+	if boss.GetMaxHealth() != uint32(math.Round(float64(normalBoss.GetMaxHealth())*HEROIC_HEALTH_MODIFIER)) ||
+		boss.GetDamageDoneMultiplier() != template.DamageModifier*HEROIC_DAMAGE_MODIFIER {
+		t.Fatalf("heroic boss should be scaled, got %d health x%.2f damage", boss.GetMaxHealth(), boss.GetDamageDoneMultiplier())
+	}
+
+	if !heroic.AddPlayer(tank) || !heroic.AddPlayer(healer) {
+		t.Fatal("players should enter the dungeon")
+	}
+	save := heroic.GetInstanceSave()
+	if save == nil || mgr.GetGroupBind(tank.group.GetGUID(), DUNGEON_DEADMINES, DIFFICULTY_HEROIC) != save {
+		t.Fatal("dungeon group should be bound to the new instance")
+	}
+	if save.GetResetTime().Hour() != INSTANCE_RESET_HOUR || !save.GetResetTime().After(time.Now()) {
+		t.Fatalf("heroic instance should reset at the next daily reset, got %v", save.GetResetTime())
+	}
+
+	boss.SetHealth(0)
+	heroic.completeEncounter(heroic.GetEncounter(DATA_VANCLEEF))
+	if mgr.GetPlayerBind(tank.GetGUID(), DUNGEON_DEADMINES, DIFFICULTY_HEROIC) == nil {
+		t.Fatal("killing a heroic boss should lock the players to the instance")
+	}
+
+	// 重新加载存档，队伍绑定不保存，玩家锁定把队伍带回原来的实例
+	reloaded, err := LoadInstanceSaveManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := reloaded.GetInstanceSave(save.InstanceId); s == nil || !s.IsEncounterDone(DATA_VANCLEEF) {
+		t.Fatal("encounter progress should be saved to disk")
+	}
+	world.SetInstanceSaveManager(reloaded)
+	again := NewDeadminesDungeon(world, DIFFICULTY_HEROIC)
+	if !again.AddPlayer(tank) || again.GetInstanceId() != save.InstanceId {
+		t.Fatalf("locked player should re-enter instance %d, got %d", save.InstanceId, again.GetInstanceId())
+	}
+	if again.GetEncounter(DATA_VANCLEEF).boss.IsAlive() {
+		t.Fatal("killed boss should stay dead in the saved instance")
+	}
+
+	// 另一个队伍的新实例不接受被锁定的玩家
+	other, _ := newGroupTestPlayer(world, 3, "other", CLASS_MAGE, 10)
+	fresh := NewDeadminesDungeon(world, DIFFICULTY_HEROIC)
+	if !fresh.AddPlayer(other) || fresh.GetInstanceId() == save.InstanceId {
+		t.Fatal("unbound player should get a new instance")
+	}
+	tank.group.RemoveMember(tank.GetGUID(), PARTY_OP_LEAVE)
+	if fresh.AddPlayer(tank) {
+		t.Fatal("player locked to another instance should not enter")
+	}
+
+	// 到达重置时间后锁定解除
+	reloaded.Update(save.GetResetTime())
+	if reloaded.GetPlayerBind(tank.GetGUID(), DUNGEON_DEADMINES, DIFFICULTY_HEROIC) != nil || reloaded.GetInstanceSave(save.InstanceId) != nil {
+		t.Fatal("instance should reset on schedule")
+	}
+
+	// 普通难度没有锁定，队伍解散后实例删除
+	normal := NewDeadminesDungeon(world, DIFFICULTY_NORMAL)
+	if !normal.AddPlayer(tank) || !normal.AddPlayer(healer) {
+		t.Fatal("players should enter the normal dungeon")
+	}
+	normal.completeEncounter(normal.GetEncounter(DATA_VANCLEEF))
+	if reloaded.GetPlayerBind(tank.GetGUID(), DUNGEON_DEADMINES, DIFFICULTY_NORMAL) != nil {
+		t.Fatal("normal difficulty should not lock players")
+	}
+	normalId := normal.GetInstanceId()
+	normal.GetGroup().Disband()
+	if reloaded.GetInstanceSave(normalId) != nil {
+		t.Fatal("unbound normal instance should be removed")
+	}
+}
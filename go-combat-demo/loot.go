@@ -0,0 +1,741 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// 拾取 - 基于AzerothCore的LootMgr、Loot和Group的掷骰分配
+
+// 物品品质 - 基于AzerothCore的ItemQualities
+const (
+	ITEM_QUALITY_POOR     = 0 // 粗糙(灰色)
+	ITEM_QUALITY_NORMAL   = 1 // 普通(白色)
+	ITEM_QUALITY_UNCOMMON = 2 // 优秀(绿色)
+	ITEM_QUALITY_RARE     = 3 // 精良(蓝色)
+	ITEM_QUALITY_EPIC     = 4 // 史诗(紫色)
+	MAX_ITEM_QUALITY      = 5
+)
+
+// 掷骰选择 - 基于AzerothCore的RollVote
+const (
+	ROLL_PASS  = 0 // 放弃
+	ROLL_NEED  = 1 // 需求
+	ROLL_GREED = 2 // 贪婪
+)
+
+// 拾取窗口中物品的状态 - 基于AzerothCore的LootSlotType
+const (
+	LOOT_SLOT_TYPE_ALLOW_LOOT   = 0 // 可以拾取
+	LOOT_SLOT_TYPE_ROLL_ONGOING = 1 // 掷骰中
+	LOOT_SLOT_TYPE_MASTER       = 2 // 等待队长分配
+	LOOT_SLOT_TYPE_LOCKED       = 3 // 轮到其他成员拾取
+)
+
+// 拾取参数
+const (
+	LOOT_TYPE_CORPSE  = 1     // 尸体拾取 - 基于AzerothCore的LootType
+	LOOT_ROLL_TIMEOUT = 60000 // 掷骰等待时间(毫秒)，超时未选择视为放弃
+	LOOT_ROLL_MAX     = 100   // 掷骰点数范围1-100
+)
+
+// ItemTemplate 物品模板 - 基于AzerothCore的item_template表
+type ItemTemplate struct {
+	Entry   uint32
+	Name    string
+	Quality uint8 // ITEM_QUALITY_*
+}
+
+// LootStoreItem 掉落表中的一条 - 基于AzerothCore的LootStoreItem
+type LootStoreItem struct {
+	ItemId   uint32
+	Chance   float32 // 掉落几率(百分比)，分组中为0表示平分剩余几率
+	GroupId  uint8   // 0表示不分组，各自按几率掉落
+	MinCount uint8
+	MaxCount uint8
+}
+
+// Validate 检查掉落条目 - 基于AzerothCore的LootStoreItem::IsValid
+func (i *LootStoreItem) Validate() error {
+	if i.Chance < 0 || i.Chance > 100 {
+		return fmt.Errorf("掉落几率 %.2f 无效", i.Chance)
+	}
+	if i.Chance == 0 && i.GroupId == 0 {
+		return fmt.Errorf("不分组的掉落几率不能为0")
+	}
+	if i.MinCount == 0 || i.MinCount > i.MaxCount {
+		return fmt.Errorf("数量范围 %d-%d 无效", i.MinCount, i.MaxCount)
+	}
+	return nil
+}
+
+// lootGroup 同一分组的掉落，每次最多掉落一件 - 基于AzerothCore的LootTemplate::LootGroup
+type lootGroup struct {
+	id                uint8
+	explicitlyChanced []*LootStoreItem // 有几率的物品
+	equalChanced      []*LootStoreItem // 几率为0的物品
+}
+
+// roll 按顺序累计有几率的物品，都没有选中时从几率为0的物品中平均选择一件 - 基于AzerothCore的LootGroup::Roll
+func (g *lootGroup) roll() *LootStoreItem {
+	roll := rand.Float32() * 100
+	for _, item := range g.explicitlyChanced {
+		if roll < item.Chance {
+			return item
+		}
+		roll -= item.Chance
+	}
+	if len(g.equalChanced) > 0 {
+		return g.equalChanced[rand.Intn(len(g.equalChanced))]
+	}
+	return nil
+}
+
+// LootTemplate 生物模板的掉落表 - 基于AzerothCore的LootTemplate
+type LootTemplate struct {
+	entries []*LootStoreItem // 不分组的物品
+	groups  []*lootGroup     // 按分组编号排序
+}
+
+// AddEntry 加入一条掉落
+func (t *LootTemplate) AddEntry(item *LootStoreItem) {
+	if item.GroupId == 0 {
+		t.entries = append(t.entries, item)
+		return
+	}
+	var group *lootGroup
+	for _, g := range t.groups {
+		if g.id == item.GroupId {
+			group = g
+			break
+		}
+	}
+	if group == nil {
+		group = &lootGroup{id: item.GroupId}
+		t.groups = append(t.groups, group)
+		sort.Slice(t.groups, func(i, j int) bool { return t.groups[i].id < t.groups[j].id })
+	}
+	if item.Chance > 0 {
+		group.explicitlyChanced = append(group.explicitlyChanced, item)
+	} else {
+		group.equalChanced = append(group.equalChanced, item)
+	}
+}
+
+// Process 按几率生成掉落放入拾取 - 基于AzerothCore的LootTemplate::Process
+func (t *LootTemplate) Process(loot *Loot) {
+	for _, item := range t.entries {
+		if rollChance(item.Chance) {
+			loot.AddItem(item)
+		}
+	}
+	for _, group := range t.groups {
+		if item := group.roll(); item != nil {
+			loot.AddItem(item)
+		}
+	}
+}
+
+// LootItem 拾取中的一件物品 - 基于AzerothCore的LootItem
+type LootItem struct {
+	itemId    uint32
+	count     uint8
+	quality   uint8
+	looted    bool
+	rolling   bool // 队伍正在掷骰
+	master    bool // 等待队长分配
+	allPassed bool // 所有人都放弃后，有拾取权的玩家都可以拾取
+}
+
+// GetItemId 物品编号
+func (i *LootItem) GetItemId() uint32 { return i.itemId }
+
+// GetCount 物品数量
+func (i *LootItem) GetCount() uint8 { return i.count }
+
+// IsLooted 是否已被拾取
+func (i *LootItem) IsLooted() bool { return i.looted }
+
+// Loot 尸体上的拾取 - 基于AzerothCore的Loot，物品在拾取窗口中的位置为其下标
+type Loot struct {
+	sourceGuid       uint64
+	items            []*LootItem
+	gold             uint32             // 金钱(铜币)
+	group            *Group             // 拾取权所属的队伍，单人拾取为nil
+	method           uint8              // 生成时队伍的拾取方式，单人为FREE_FOR_ALL
+	roundRobinPlayer uint64             // 轮到拾取品质门槛以下物品的玩家，0表示有拾取权的玩家都可以
+	allowed          map[uint64]bool    // 有拾取权的玩家
+	looters          map[uint64]*Player // 打开了拾取窗口的玩家
+}
+
+// newLoot 创建空的拾取
+func newLoot(sourceGuid uint64) *Loot {
+	return &Loot{
+		sourceGuid: sourceGuid,
+		method:     FREE_FOR_ALL,
+		allowed:    make(map[uint64]bool),
+		looters:    make(map[uint64]*Player),
+	}
+}
+
+// AddItem 按掉落条目的数量范围加入物品 - 基于AzerothCore的Loot::AddItem
+func (l *Loot) AddItem(store *LootStoreItem) {
+	count := store.MinCount
+	if store.MaxCount > store.MinCount {
+		count += uint8(rand.Intn(int(store.MaxCount-store.MinCount) + 1))
+	}
+	item := &LootItem{itemId: store.ItemId, count: count}
+	if template := GlobalObjectMgr.GetItemTemplate(store.ItemId); template != nil {
+		item.quality = template.Quality
+	}
+	l.items = append(l.items, item)
+}
+
+// GetItems 拾取中的物品，包括已被拾取的
+func (l *Loot) GetItems() []*LootItem { return l.items }
+
+// GetGold 剩余的金钱
+func (l *Loot) GetGold() uint32 { return l.gold }
+
+// IsLooted 物品和金钱是否都已被拾取
+func (l *Loot) IsLooted() bool {
+	if l.gold > 0 {
+		return false
+	}
+	for _, item := range l.items {
+		if !item.looted {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAllowedToLoot 玩家是否有拾取权
+func (l *Loot) IsAllowedToLoot(guid uint64) bool { return l.allowed[guid] }
+
+// getSlotType 物品对玩家的状态 - 基于AzerothCore的LootItem::GetUiType
+func (l *Loot) getSlotType(item *LootItem, guid uint64) uint8 {
+	switch {
+	case item.rolling:
+		return LOOT_SLOT_TYPE_ROLL_ONGOING
+	case item.master:
+		return LOOT_SLOT_TYPE_MASTER
+	case item.allPassed || l.roundRobinPlayer == 0 || l.roundRobinPlayer == guid:
+		return LOOT_SLOT_TYPE_ALLOW_LOOT
+	}
+	return LOOT_SLOT_TYPE_LOCKED
+}
+
+// setGroupLoot 按队伍的拾取方式分配拾取权：奖励距离内的成员都有拾取权，
+// 品质门槛以下的物品轮流拾取，门槛及以上的物品掷骰或由队长分配 - 基于AzerothCore的Group::GroupLoot和Group::MasterLoot
+func (l *Loot) setGroupLoot(group *Group, corpse IUnit) {
+	l.group = group
+	l.method = group.GetLootMethod()
+	for _, member := range group.GetMembersInRewardRange(corpse) {
+		l.allowed[member.GetGUID()] = true
+	}
+	if l.method != FREE_FOR_ALL {
+		l.roundRobinPlayer = group.GetLooterGuid()
+	}
+
+	for slot, item := range l.items {
+		if item.quality < group.lootThreshold {
+			continue
+		}
+		switch l.method {
+		case GROUP_LOOT, NEED_BEFORE_GREED:
+			item.rolling = true
+			group.startLootRoll(l, uint8(slot))
+		case MASTER_LOOT:
+			item.master = true
+		}
+	}
+}
+
+// removeItem 物品被拾取，通知打开了拾取窗口的玩家
+func (l *Loot) removeItem(slot uint8) {
+	l.items[slot].looted = true
+	packet := NewWorldPacket(SMSG_LOOT_REMOVED)
+	packet.WriteUint8(slot)
+	for _, looter := range l.looters {
+		looter.SendDirectMessage(packet)
+	}
+}
+
+// String 拾取内容的描述
+func (l *Loot) String() string {
+	var parts []string
+	for _, item := range l.items {
+		if !item.looted {
+			parts = append(parts, fmt.Sprintf("[%s]x%d", getItemName(item.itemId), item.count))
+		}
+	}
+	if l.gold > 0 {
+		parts = append(parts, formatMoney(l.gold))
+	}
+	if len(parts) == 0 {
+		return "无"
+	}
+	return strings.Join(parts, " ")
+}
+
+// getItemName 物品名称
+func getItemName(itemId uint32) string {
+	if GlobalObjectMgr != nil {
+		if template := GlobalObjectMgr.GetItemTemplate(itemId); template != nil {
+			return template.Name
+		}
+	}
+	return fmt.Sprintf("物品%d", itemId)
+}
+
+// formatMoney 把铜币格式化为金银铜
+func formatMoney(copper uint32) string {
+	gold, silver, rest := copper/10000, copper/100%100, copper%100
+	switch {
+	case gold > 0:
+		return fmt.Sprintf("%d金%d银%d铜", gold, silver, rest)
+	case silver > 0:
+		return fmt.Sprintf("%d银%d铜", silver, rest)
+	}
+	return fmt.Sprintf("%d铜", rest)
+}
+
+// === 生物的拾取 ===
+
+// GetLoot 尸体上的拾取，没有时返回nil
+func (c *Creature) GetLoot() *Loot { return c.loot }
+
+// generateLoot 死亡时按模板生成金钱和掉落，拾取权属于第一个造成伤害的玩家或其队伍
+func (c *Creature) generateLoot() {
+	c.loot = nil
+	recipient := c.lootRecipient
+	if recipient == nil || c.entry == 0 || GlobalObjectMgr == nil {
+		return
+	}
+	template := GlobalObjectMgr.GetCreatureTemplate(c.entry)
+	if template == nil {
+		return
+	}
+
+	loot := newLoot(c.GetGUID())
+	if template.MaxGold > 0 {
+		loot.gold = template.MinGold + uint32(rand.Intn(int(template.MaxGold-template.MinGold)+1))
+	}
+	if lootTemplate := GlobalObjectMgr.GetLootTemplate(c.entry); lootTemplate != nil {
+		lootTemplate.Process(loot)
+	}
+
+	if group := c.lootRecipientGroup; group != nil && group.IsMember(recipient.GetGUID()) {
+		loot.setGroupLoot(group, c)
+	}
+	if len(loot.allowed) == 0 {
+		loot.allowed[recipient.GetGUID()] = true
+	}
+	c.loot = loot
+	fmt.Printf("%s 的尸体上有: %s\n", c.GetName(), loot)
+}
+
+// === 队伍掷骰 ===
+
+// lootRoll 队伍对一件物品的掷骰 - 基于AzerothCore的Roll
+type lootRoll struct {
+	loot   *Loot
+	slot   uint8
+	voters map[uint64]*Player // 参与掷骰的成员
+	votes  map[uint64]uint8   // 已做出的选择(ROLL_*)
+	timer  uint32             // 剩余等待时间
+}
+
+// startLootRoll 门槛及以上品质的物品由有拾取权的成员掷骰 - 基于AzerothCore的Group::GroupLoot
+func (g *Group) startLootRoll(loot *Loot, slot uint8) {
+	item := loot.items[slot]
+	roll := &lootRoll{
+		loot:   loot,
+		slot:   slot,
+		voters: make(map[uint64]*Player),
+		votes:  make(map[uint64]uint8),
+		timer:  LOOT_ROLL_TIMEOUT,
+	}
+
+	packet := NewWorldPacket(SMSG_LOOT_START_ROLL)
+	packet.WriteUint64(loot.sourceGuid)
+	packet.WriteUint32(uint32(slot))
+	packet.WriteUint32(item.itemId)
+	packet.WriteUint32(uint32(item.count))
+	packet.WriteUint32(LOOT_ROLL_TIMEOUT)
+	for _, member := range g.members {
+		if loot.allowed[member.player.GetGUID()] {
+			roll.voters[member.player.GetGUID()] = member.player
+			g.sendToPlayer(member.player, packet)
+		}
+	}
+	g.rolls = append(g.rolls, roll)
+	fmt.Printf("[拾取] 开始为 [%s] 掷骰\n", getItemName(item.itemId))
+}
+
+// CountRollVote 记录成员的掷骰选择，所有人都选择后结束掷骰 - 基于AzerothCore的Group::CountRollVote
+// 物品位置按客户端发来的uint32比较，超出掉落列表的位置不会匹配任何掷骰
+func (g *Group) CountRollVote(player *Player, sourceGuid uint64, slot uint32, choice uint8) bool {
+	if choice > ROLL_GREED {
+		return false
+	}
+	for _, roll := range g.rolls {
+		if roll.loot.sourceGuid != sourceGuid || slot >= uint32(len(roll.loot.items)) || uint32(roll.slot) != slot {
+			continue
+		}
+		if _, ok := roll.voters[player.GetGUID()]; !ok {
+			return false
+		}
+		if _, voted := roll.votes[player.GetGUID()]; voted {
+			return false
+		}
+		roll.votes[player.GetGUID()] = choice
+
+		packet := NewWorldPacket(SMSG_LOOT_ROLL)
+		packet.WriteUint64(sourceGuid)
+		packet.WriteUint32(slot)
+		packet.WriteUint64(player.GetGUID())
+		packet.WriteUint32(roll.loot.items[roll.slot].itemId)
+		packet.WriteUint8(choice)
+		roll.broadcast(g, packet)
+
+		if len(roll.votes) == len(roll.voters) {
+			g.endLootRoll(roll)
+		}
+		return true
+	}
+	return false
+}
+
+// updateLootRolls 掷骰超时后未选择的成员视为放弃
+func (g *Group) updateLootRolls(diff uint32) {
+	for _, roll := range append([]*lootRoll(nil), g.rolls...) {
+		if roll.timer > diff {
+			roll.timer -= diff
+			continue
+		}
+		g.endLootRoll(roll)
+	}
+}
+
+// endLootRoll 需求优先于贪婪，点数最高的成员获得物品；所有人都放弃时物品对有拾取权的玩家开放 - 基于AzerothCore的Group::CountTheRoll
+func (g *Group) endLootRoll(roll *lootRoll) {
+	for i, r := range g.rolls {
+		if r == roll {
+			g.rolls = append(g.rolls[:i], g.rolls[i+1:]...)
+			break
+		}
+	}
+	item := roll.loot.items[roll.slot]
+	item.rolling = false
+
+	for _, choice := range []uint8{ROLL_NEED, ROLL_GREED} {
+		var winner *Player
+		var best int
+		for guid, vote := range roll.votes {
+			if vote != choice {
+				continue
+			}
+			number := rollDice(LOOT_ROLL_MAX)
+			fmt.Printf("[拾取] %s 为 [%s] 掷出了 %d\n", roll.voters[guid].GetName(), getItemName(item.itemId), number)
+			if number > best {
+				winner, best = roll.voters[guid], number
+			}
+		}
+		if winner == nil {
+			continue
+		}
+
+		packet := NewWorldPacket(SMSG_LOOT_ROLL_WON)
+		packet.WriteUint64(roll.loot.sourceGuid)
+		packet.WriteUint32(uint32(roll.slot))
+		packet.WriteUint32(item.itemId)
+		packet.WriteUint64(winner.GetGUID())
+		packet.WriteUint8(uint8(best))
+		packet.WriteUint8(choice)
+		roll.broadcast(g, packet)
+
+		winner.StoreNewItem(item.itemId, item.count)
+		roll.loot.removeItem(roll.slot)
+		return
+	}
+
+	item.allPassed = true
+	packet := NewWorldPacket(SMSG_LOOT_ALL_PASSED)
+	packet.WriteUint64(roll.loot.sourceGuid)
+	packet.WriteUint32(uint32(roll.slot))
+	packet.WriteUint32(item.itemId)
+	roll.broadcast(g, packet)
+	fmt.Printf("[拾取] 所有人都放弃了 [%s]\n", getItemName(item.itemId))
+}
+
+// broadcast 发送给参与掷骰的成员
+func (roll *lootRoll) broadcast(g *Group, packet *WorldPacket) {
+	for _, voter := range roll.voters {
+		g.sendToPlayer(voter, packet)
+	}
+}
+
+// === 玩家拾取 ===
+
+// GetItemCount 背包中物品的数量
+func (p *Player) GetItemCount(itemId uint32) uint32 { return p.items[itemId] }
+
+// GetMoney 金钱(铜币)
+func (p *Player) GetMoney() uint32 { return p.money }
+
+// ModifyMoney 增加或减少金钱，不会小于0
+func (p *Player) ModifyMoney(amount int32) {
+	if amount < 0 && uint32(-amount) > p.money {
+		p.money = 0
+		return
+	}
+	p.money = uint32(int64(p.money) + int64(amount))
+}
+
+// StoreNewItem 物品放入背包 - 基于AzerothCore的Player::StoreNewItem和SendNewItem
+func (p *Player) StoreNewItem(itemId uint32, count uint8) {
+	p.items[itemId] += uint32(count)
+
+	packet := NewWorldPacket(SMSG_ITEM_PUSH_RESULT)
+	packet.WriteUint64(p.GetGUID())
+	packet.WriteUint32(itemId)
+	packet.WriteUint32(uint32(count))
+	p.SendDirectMessage(packet)
+	fmt.Printf("%s 获得了物品 [%s]x%d\n", p.GetName(), getItemName(itemId), count)
+}
+
+// GetLootGUID 打开了拾取窗口的尸体，没有时为0
+func (p *Player) GetLootGUID() uint64 { return p.lootGuid }
+
+// getLootCreature 拾取来源的生物尸体
+func (p *Player) getLootCreature(guid uint64) *Creature {
+	if p.world == nil || guid == 0 {
+		return nil
+	}
+	creature, _ := p.world.GetUnit(guid).(*Creature)
+	return creature
+}
+
+// getCurrentLoot 打开了拾取窗口的尸体和其上的拾取
+func (p *Player) getCurrentLoot() (*Creature, *Loot) {
+	creature := p.getLootCreature(p.lootGuid)
+	if creature == nil || creature.loot == nil {
+		return nil, nil
+	}
+	return creature, creature.loot
+}
+
+// SendLoot 打开尸体的拾取窗口 - 基于AzerothCore的Player::SendLoot
+func (p *Player) SendLoot(guid uint64) bool {
+	creature := p.getLootCreature(guid)
+	if !p.IsAlive() || creature == nil || creature.IsAlive() || creature.loot == nil {
+		return false
+	}
+	loot := creature.loot
+	if !loot.IsAllowedToLoot(p.GetGUID()) {
+		fmt.Printf("%s 没有 %s 的拾取权\n", p.GetName(), creature.GetName())
+		return false
+	}
+	if p.GetDistanceTo(creature) > INTERACTION_DISTANCE {
+		fmt.Printf("%s 距离 %s 太远，无法拾取\n", p.GetName(), creature.GetName())
+		return false
+	}
+	if p.lootGuid != 0 && p.lootGuid != guid {
+		p.ReleaseLoot(p.lootGuid)
+	}
+	p.lootGuid = guid
+	loot.looters[p.GetGUID()] = p
+
+	var visible []uint8
+	for slot, item := range loot.items {
+		if !item.looted {
+			visible = append(visible, uint8(slot))
+		}
+	}
+	packet := NewWorldPacket(SMSG_LOOT_RESPONSE)
+	packet.WriteUint64(guid)
+	packet.WriteUint8(LOOT_TYPE_CORPSE)
+	packet.WriteUint32(loot.gold)
+	packet.WriteUint8(uint8(len(visible)))
+	for _, slot := range visible {
+		item := loot.items[slot]
+		packet.WriteUint8(slot)
+		packet.WriteUint32(item.itemId)
+		packet.WriteUint32(uint32(item.count))
+		packet.WriteUint8(loot.getSlotType(item, p.GetGUID()))
+	}
+	p.SendDirectMessage(packet)
+
+	if loot.method == MASTER_LOOT && loot.group != nil && loot.group.GetMasterLooterGuid() == p.GetGUID() {
+		p.sendMasterLootList(loot)
+	}
+	return true
+}
+
+// sendMasterLootList 队长分配时告诉分配者可以分配给哪些成员 - 基于AzerothCore的Group::MasterLoot
+func (p *Player) sendMasterLootList(loot *Loot) {
+	var candidates []uint64
+	for _, member := range loot.group.GetMembers() {
+		if loot.IsAllowedToLoot(member.GetGUID()) {
+			candidates = append(candidates, member.GetGUID())
+		}
+	}
+	packet := NewWorldPacket(SMSG_LOOT_MASTER_LIST)
+	packet.WriteUint8(uint8(len(candidates)))
+	for _, guid := range candidates {
+		packet.WriteUint64(guid)
+	}
+	p.SendDirectMessage(packet)
+}
+
+// StoreLootItem 拾取物品放入背包 - 基于AzerothCore的Player::StoreLootItem
+func (p *Player) StoreLootItem(slot uint8) bool {
+	_, loot := p.getCurrentLoot()
+	if loot == nil || int(slot) >= len(loot.items) || loot.items[slot].looted {
+		return false
+	}
+	item := loot.items[slot]
+	if loot.getSlotType(item, p.GetGUID()) != LOOT_SLOT_TYPE_ALLOW_LOOT {
+		fmt.Printf("%s 现在不能拾取 [%s]\n", p.GetName(), getItemName(item.itemId))
+		return false
+	}
+	p.StoreNewItem(item.itemId, item.count)
+	loot.removeItem(slot)
+	return true
+}
+
+// LootMoney 拾取金钱，队伍拾取时由奖励距离内有拾取权的成员平分 - 基于AzerothCore的WorldSession::HandleLootMoneyOpcode
+func (p *Player) LootMoney() bool {
+	creature, loot := p.getCurrentLoot()
+	if loot == nil || loot.gold == 0 {
+		return false
+	}
+	receivers := []*Player{p}
+	if loot.group != nil {
+		receivers = nil
+		for _, member := range loot.group.GetMembersInRewardRange(creature) {
+			if loot.IsAllowedToLoot(member.GetGUID()) {
+				receivers = append(receivers, member)
+			}
+		}
+		if len(receivers) == 0 {
+			receivers = []*Player{p}
+		}
+	}
+
+	share := loot.gold / uint32(len(receivers))
+	for _, receiver := range receivers {
+		receiver.ModifyMoney(int32(share))
+		packet := NewWorldPacket(SMSG_LOOT_MONEY_NOTIFY)
+		packet.WriteUint32(share)
+		receiver.SendDirectMessage(packet)
+		fmt.Printf("%s 分到了 %s\n", receiver.GetName(), formatMoney(share))
+	}
+	loot.gold = 0
+	for _, looter := range loot.looters {
+		looter.SendDirectMessage(NewWorldPacket(SMSG_LOOT_CLEAR_MONEY))
+	}
+	return true
+}
+
+// ReleaseLoot 关闭拾取窗口 - 基于AzerothCore的WorldSession::DoLootRelease
+func (p *Player) ReleaseLoot(guid uint64) bool {
+	if p.lootGuid == 0 || p.lootGuid != guid {
+		return false
+	}
+	creature, loot := p.getCurrentLoot()
+	p.lootGuid = 0
+
+	packet := NewWorldPacket(SMSG_LOOT_RELEASE_RESPONSE)
+	packet.WriteUint64(guid)
+	packet.WriteUint8(1)
+	p.SendDirectMessage(packet)
+
+	if loot != nil {
+		delete(loot.looters, p.GetGUID())
+		if loot.IsLooted() {
+			fmt.Printf("%s 的尸体已被拾取干净\n", creature.GetName())
+		}
+	}
+	return true
+}
+
+// MasterLootGive 分配者把物品分配给有拾取权的成员 - 基于AzerothCore的WorldSession::HandleLootMasterGiveOpcode
+func (p *Player) MasterLootGive(guid uint64, slot uint8, targetGuid uint64) bool {
+	_, loot := p.getCurrentLoot()
+	if loot == nil || p.lootGuid != guid || loot.group == nil || loot.method != MASTER_LOOT {
+		return false
+	}
+	if loot.group.GetMasterLooterGuid() != p.GetGUID() || int(slot) >= len(loot.items) {
+		return false
+	}
+	item := loot.items[slot]
+	member := loot.group.getMember(targetGuid)
+	if !item.master || item.looted || member == nil || !loot.IsAllowedToLoot(targetGuid) {
+		return false
+	}
+	item.master = false
+	member.player.StoreNewItem(item.itemId, item.count)
+	loot.removeItem(slot)
+	fmt.Printf("[拾取] %s 把 [%s] 分配给了 %s\n", p.GetName(), getItemName(item.itemId), member.player.GetName())
+	return true
+}
+
+// === 拾取操作码 ===
+
+// HandleLootOpcode 打开尸体的拾取窗口，数据为尸体GUID
+func (ws *WorldSession) HandleLootOpcode(packet *WorldPacket) {
+	guid := packet.ReadUint64()
+	if player := ws.sessionPlayer(); player != nil {
+		player.SendLoot(guid)
+	}
+}
+
+// HandleAutostoreLootItemOpcode 拾取物品，数据为物品位置
+func (ws *WorldSession) HandleAutostoreLootItemOpcode(packet *WorldPacket) {
+	slot := packet.ReadUint8()
+	if player := ws.sessionPlayer(); player != nil {
+		player.StoreLootItem(slot)
+	}
+}
+
+// HandleLootMoneyOpcode 拾取金钱
+func (ws *WorldSession) HandleLootMoneyOpcode(packet *WorldPacket) {
+	if player := ws.sessionPlayer(); player != nil {
+		player.LootMoney()
+	}
+}
+
+// HandleLootReleaseOpcode 关闭拾取窗口，数据为尸体GUID
+func (ws *WorldSession) HandleLootReleaseOpcode(packet *WorldPacket) {
+	guid := packet.ReadUint64()
+	if player := ws.sessionPlayer(); player != nil {
+		player.ReleaseLoot(guid)
+	}
+}
+
+// HandleLootRollOpcode 掷骰选择，数据为尸体GUID、物品位置和选择(ROLL_*)
+func (ws *WorldSession) HandleLootRollOpcode(packet *WorldPacket) {
+	guid := packet.ReadUint64()
+	slot := packet.ReadUint32()
+	choice := packet.ReadUint8()
+	player := ws.sessionPlayer()
+	if player == nil || player.GetGroup() == nil {
+		return
+	}
+	player.GetGroup().CountRollVote(player, guid, slot, choice)
+}
+
+// HandleLootMasterGiveOpcode 队长分配，数据为尸体GUID、物品位置和获得者GUID
+func (ws *WorldSession) HandleLootMasterGiveOpcode(packet *WorldPacket) {
+	guid := packet.ReadUint64()
+	slot := packet.ReadUint8()
+	target := packet.ReadUint64()
+	if player := ws.sessionPlayer(); player != nil {
+		player.MasterLootGive(guid, slot, target)
+	}
+}
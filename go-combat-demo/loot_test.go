@@ -0,0 +1,149 @@
+package main
+
+import "testing"
+
+func sendLootPacket(session *WorldSession, opcode uint16, guid uint64, slot uint8) {
+	packet := NewWorldPacket(opcode)
+	switch opcode {
+	case CMSG_AUTOSTORE_LOOT_ITEM:
+		packet.WriteUint8(slot)
+	case CMSG_LOOT, CMSG_LOOT_RELEASE:
+		packet.WriteUint64(guid)
+	}
+	session.handlePacket(packet)
+}
+
+func sendLootRoll(session *WorldSession, guid uint64, slot uint32, choice uint8) {
+	packet := NewWorldPacket(CMSG_LOOT_ROLL)
+	packet.WriteUint64(guid)
+	packet.WriteUint32(slot)
+	packet.WriteUint8(choice)
+	session.handlePacket(packet)
+}
+
+func TestCreatureLootGroupRollsRoundRobinAndMasterLoot(t *testing.T) {
+	if GlobalObjectMgr == nil {
+		InitObjectMgr()
+	}
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	leader, leaderSession := newGroupTestPlayer(world, 1, "leader", CLASS_WARRIOR, 0)
+	bob, bobSession := newGroupTestPlayer(world, 2, "bob", CLASS_PRIEST, 2)
+	group := NewGroup(leader)
+	group.AddMember(bob)
+
+	// 范克里夫必定掉落一封信(普通品质)和分组中的一件精良装备
+	killVanCleef := func() (*Creature, *Loot) {
+		boss := GlobalObjectMgr.CreateCreature(639)
+		boss.SetPosition(1, 0, 0)
+		world.AddUnit(boss)
+		boss.DealDamage(leader, boss.GetHealth(), DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+		loot := boss.GetLoot()
+		if loot == nil || len(loot.GetItems()) != 2 || loot.GetItems()[0].GetItemId() != 2874 {
+			t.Fatalf("Van Cleef should drop the letter and one item of his group, got %v", loot)
+		}
+		if loot.GetGold() < 2400 || loot.GetGold() > 3600 {
+			t.Fatalf("gold %d should be within the template range", loot.GetGold())
+		}
+		return boss, loot
+	}
+
+	// 队伍拾取：信轮流拾取，精良装备掷骰
+	boss, loot := killVanCleef()
+	rare := loot.GetItems()[1].GetItemId()
+	if group.GetLooterGuid() != leader.GetGUID() {
+		t.Fatal("round robin should start with the leader")
+	}
+	sendLootPacket(bobSession, CMSG_LOOT, boss.GetGUID(), 0)
+	sendLootPacket(bobSession, CMSG_AUTOSTORE_LOOT_ITEM, 0, 0)
+	sendLootPacket(bobSession, CMSG_AUTOSTORE_LOOT_ITEM, 0, 1)
+	if bob.GetLootGUID() != boss.GetGUID() || bob.GetItemCount(2874) != 0 || bob.GetItemCount(rare) != 0 {
+		t.Fatal("bob can open the corpse but not take the leader's turn or the item under roll")
+	}
+	sendLootPacket(leaderSession, CMSG_LOOT, boss.GetGUID(), 0)
+	sendLootPacket(leaderSession, CMSG_AUTOSTORE_LOOT_ITEM, 0, 0)
+	if leader.GetItemCount(2874) != 1 || !loot.GetItems()[0].IsLooted() {
+		t.Fatal("the round robin looter should take the letter")
+	}
+	sendLootRoll(bobSession, boss.GetGUID(), 256+1, ROLL_NEED)
+	sendLootRoll(leaderSession, boss.GetGUID(), 1, ROLL_PASS)
+	if bob.GetItemCount(rare) != 0 || leader.GetItemCount(rare) != 0 {
+		t.Fatal("a roll on an out-of-range slot should not count as a vote")
+	}
+	sendLootRoll(bobSession, boss.GetGUID(), 1, ROLL_NEED)
+	if bob.GetItemCount(rare) != 1 || leader.GetItemCount(rare) != 0 {
+		t.Fatal("the only need roll should win the item")
+	}
+
+	// 金钱由奖励距离内的成员平分
+	gold := loot.GetGold()
+	leaderSession.handlePacket(NewWorldPacket(CMSG_LOOT_MONEY))
+	if leader.GetMoney() != gold/2 || bob.GetMoney() != gold/2 || loot.GetGold() != 0 {
+		t.Fatalf("money should be split, got %d/%d of %d", leader.GetMoney(), bob.GetMoney(), gold)
+	}
+	sendLootPacket(leaderSession, CMSG_LOOT_RELEASE, boss.GetGUID(), 0)
+	sendLootPacket(bobSession, CMSG_LOOT_RELEASE, boss.GetGUID(), 0)
+	if !loot.IsLooted() || leader.GetLootGUID() != 0 || bob.GetLootGUID() != 0 {
+		t.Fatal("the corpse should be empty and released")
+	}
+
+	// 掷骰超时视为放弃，物品对有拾取权的成员开放
+	boss, loot = killVanCleef()
+	rare = loot.GetItems()[1].GetItemId()
+	if group.GetLooterGuid() != bob.GetGUID() {
+		t.Fatal("round robin should move on to bob")
+	}
+	before := leader.GetItemCount(rare)
+	group.Update(LOOT_ROLL_TIMEOUT)
+	sendLootPacket(leaderSession, CMSG_LOOT, boss.GetGUID(), 0)
+	sendLootPacket(leaderSession, CMSG_AUTOSTORE_LOOT_ITEM, 0, 1)
+	if leader.GetItemCount(rare) != before+1 {
+		t.Fatal("an item everybody passed on should be free to loot")
+	}
+	sendLootPacket(leaderSession, CMSG_LOOT_RELEASE, boss.GetGUID(), 0)
+
+	// 队长分配：只有分配者能把精良装备分给有拾取权的成员
+	group.SetLootMethod(MASTER_LOOT, leader.GetGUID(), DEFAULT_LOOT_THRESHOLD)
+	boss, loot = killVanCleef()
+	rare = loot.GetItems()[1].GetItemId()
+	before = bob.GetItemCount(rare)
+	give := func(session *WorldSession) {
+		packet := NewWorldPacket(CMSG_LOOT_MASTER_GIVE)
+		packet.WriteUint64(boss.GetGUID())
+		packet.WriteUint8(1)
+		packet.WriteUint64(bob.GetGUID())
+		session.handlePacket(packet)
+	}
+	sendLootPacket(bobSession, CMSG_LOOT, boss.GetGUID(), 0)
+	sendLootPacket(bobSession, CMSG_AUTOSTORE_LOOT_ITEM, 0, 1)
+	give(bobSession)
+	if bob.GetItemCount(rare) != before {
+		t.Fatal("only the master looter can hand out the item")
+	}
+	sendLootPacket(leaderSession, CMSG_LOOT, boss.GetGUID(), 0)
+	give(leaderSession)
+	if bob.GetItemCount(rare) != before+1 || !loot.GetItems()[1].IsLooted() {
+		t.Fatal("the master looter should give the item to bob")
+	}
+
+	// 自由拾取：有拾取权的成员都能拾取任何物品，队伍外的玩家没有拾取权
+	group.SetLootMethod(FREE_FOR_ALL, 0, DEFAULT_LOOT_THRESHOLD)
+	boss, loot = killVanCleef()
+	outsider, outsiderSession := newGroupTestPlayer(world, 3, "outsider", CLASS_MAGE, 1)
+	sendLootPacket(outsiderSession, CMSG_LOOT, boss.GetGUID(), 0)
+	if outsider.GetLootGUID() != 0 {
+		t.Fatal("players outside the group have no loot rights")
+	}
+	sendLootPacket(bobSession, CMSG_LOOT, boss.GetGUID(), 0)
+	sendLootPacket(bobSession, CMSG_AUTOSTORE_LOOT_ITEM, 0, 0)
+	sendLootPacket(bobSession, CMSG_AUTOSTORE_LOOT_ITEM, 0, 1)
+	if !loot.GetItems()[0].IsLooted() || !loot.GetItems()[1].IsLooted() {
+		t.Fatal("free for all should let any member take every item")
+	}
+
+	// 重生后尸体上的拾取被清除
+	boss.Respawn()
+	if boss.GetLoot() != nil {
+		t.Fatal("respawn should clear the loot")
+	}
+}
@@ -0,0 +1,573 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// 移动生成器类型 - 基于AzerothCore的MovementGeneratorType
+const (
+	IDLE_MOTION_TYPE     = 0  // 待机
+	RANDOM_MOTION_TYPE   = 1  // 随机游荡
+	WAYPOINT_MOTION_TYPE = 2  // 路点巡逻
+	CHASE_MOTION_TYPE    = 5  // 追击
+	HOME_MOTION_TYPE     = 6  // 返回出生点
+	POINT_MOTION_TYPE    = 8  // 移动到指定点(冲锋)
+	FLEEING_MOTION_TYPE  = 9  // 逃跑
+	FOLLOW_MOTION_TYPE   = 14 // 跟随
+)
+
+// 移动槽位 - 基于AzerothCore的MovementSlot，高槽位覆盖低槽位
+const (
+	MOTION_SLOT_IDLE       = 0 // 默认移动(待机、游荡、巡逻)
+	MOTION_SLOT_ACTIVE     = 1 // 战斗或脚本触发的移动(追击、跟随、回家、冲锋)
+	MOTION_SLOT_CONTROLLED = 2 // 失控移动(恐惧)
+	MAX_MOTION_SLOT        = 3
+)
+
+// 移动参数
+const (
+	RANDOM_MOVE_PAUSE_MIN   = 2000        // 随机游荡每次到达后的最短停顿(毫秒)
+	RANDOM_MOVE_PAUSE_MAX   = 10000       // 随机游荡每次到达后的最长停顿(毫秒)
+	CHASE_RANGE_LEEWAY      = 2.0         // 追击到位的容差，与IsWithinMeleeRange一致
+	CHASE_REPATH_DISTANCE   = 1.0         // 目标移动超过此距离时重新寻路(码)
+	FOLLOW_DISTANCE_LEEWAY  = 1.0         // 跟随到位的容差(码)
+	FLEE_DISTANCE_MIN       = 8.0         // 每段逃跑的最短距离(码)
+	FLEE_DISTANCE_MAX       = 15.0        // 每段逃跑的最长距离(码)
+	FLEE_ANGLE_SPREAD       = math.Pi / 2 // 逃跑方向的随机偏移范围
+	HOME_ARRIVE_DISTANCE    = 0.5         // 视为回到出生点的距离(码)
+	CHARGE_SPEED_MULTIPLIER = 3.5         // 冲锋速度为奔跑速度的倍数
+)
+
+// MovementGenerator 移动生成器 - 基于AzerothCore的MovementGenerator
+// 成为栈顶时Initialize，被覆盖或移除时Finalize，Update返回false表示移动结束
+type MovementGenerator interface {
+	Initialize(owner *Unit)
+	Update(owner *Unit, diff uint32) bool
+	Finalize(owner *Unit)
+	GetMovementGeneratorType() int
+}
+
+// MotionMaster 移动生成器栈 - 基于AzerothCore的MotionMaster
+// 每个槽位一个生成器，只有最高的非空槽位生效
+type MotionMaster struct {
+	owner  *Unit
+	slots  [MAX_MOTION_SLOT]MovementGenerator
+	active int // 当前生效的槽位，-1表示没有
+}
+
+// NewMotionMaster 创建移动生成器栈，默认待机
+func NewMotionMaster(owner *Unit) *MotionMaster {
+	m := &MotionMaster{owner: owner, active: -1}
+	m.slots[MOTION_SLOT_IDLE] = &IdleMovementGenerator{}
+	return m
+}
+
+// top 最高的非空槽位
+func (m *MotionMaster) top() int {
+	for slot := MAX_MOTION_SLOT - 1; slot >= 0; slot-- {
+		if m.slots[slot] != nil {
+			return slot
+		}
+	}
+	return -1
+}
+
+// Top 当前生效的生成器
+func (m *MotionMaster) Top() MovementGenerator {
+	if slot := m.top(); slot >= 0 {
+		return m.slots[slot]
+	}
+	return nil
+}
+
+// GetCurrentMovementGeneratorType 当前生效的生成器类型
+func (m *MotionMaster) GetCurrentMovementGeneratorType() int {
+	if gen := m.Top(); gen != nil {
+		return gen.GetMovementGeneratorType()
+	}
+	return IDLE_MOTION_TYPE
+}
+
+// GetMotionSlot 获取槽位中的生成器
+func (m *MotionMaster) GetMotionSlot(slot int) MovementGenerator {
+	return m.slots[slot]
+}
+
+// activate 切换到最高槽位，旧的生效生成器先Finalize
+func (m *MotionMaster) activate() {
+	slot := m.top()
+	if slot == m.active {
+		return
+	}
+	if m.active >= 0 && m.slots[m.active] != nil {
+		m.slots[m.active].Finalize(m.owner)
+	}
+	m.active = slot
+	if slot >= 0 {
+		m.slots[slot].Initialize(m.owner)
+	}
+}
+
+// Mutate 放入生成器，替换同槽位的旧生成器
+func (m *MotionMaster) Mutate(gen MovementGenerator, slot int) {
+	if old := m.slots[slot]; old != nil && m.active == slot {
+		old.Finalize(m.owner)
+		m.active = -1
+	}
+	m.slots[slot] = gen
+	m.activate()
+}
+
+// ClearSlot 移除槽位中的生成器，待机槽位恢复为待机
+func (m *MotionMaster) ClearSlot(slot int) {
+	old := m.slots[slot]
+	if old == nil {
+		return
+	}
+	if m.active == slot {
+		old.Finalize(m.owner)
+		m.active = -1
+	}
+	m.slots[slot] = nil
+	if slot == MOTION_SLOT_IDLE {
+		m.slots[slot] = &IdleMovementGenerator{}
+	}
+	m.activate()
+}
+
+// Clear 清空所有移动，恢复待机
+func (m *MotionMaster) Clear() {
+	for slot := MAX_MOTION_SLOT - 1; slot >= 0; slot-- {
+		m.ClearSlot(slot)
+	}
+	m.owner.StopMoving()
+}
+
+// MovementExpired 结束当前生效的移动 - 基于AzerothCore的MotionMaster::MovementExpired
+func (m *MotionMaster) MovementExpired() {
+	if slot := m.top(); slot > MOTION_SLOT_IDLE {
+		m.ClearSlot(slot)
+	}
+}
+
+// Update 更新当前生效的生成器 - 基于AzerothCore的MotionMaster::UpdateMotion
+// 定身、昏迷时停止移动但保留生成器，解除后继续
+func (m *MotionMaster) Update(diff uint32) {
+	if !m.owner.IsAlive() || m.owner.HasUnitState(UNIT_STATE_NOT_MOVE) {
+		m.owner.StopMoving()
+		return
+	}
+
+	m.activate()
+	if m.active < 0 {
+		return
+	}
+	if !m.slots[m.active].Update(m.owner, diff) {
+		m.ClearSlot(m.active)
+	}
+}
+
+// MoveIdle 待机
+func (m *MotionMaster) MoveIdle() {
+	m.Mutate(&IdleMovementGenerator{}, MOTION_SLOT_IDLE)
+}
+
+// MoveRandom 在当前位置周围随机游荡
+func (m *MotionMaster) MoveRandom(radius float32) {
+	m.Mutate(&RandomMovementGenerator{radius: radius}, MOTION_SLOT_IDLE)
+}
+
+// MoveWaypoint 沿路点巡逻，repeat为true时循环
+func (m *MotionMaster) MoveWaypoint(path []WaypointNode, repeat bool) {
+	if len(path) == 0 {
+		return
+	}
+	m.Mutate(&WaypointMovementGenerator{path: path, repeat: repeat}, MOTION_SLOT_IDLE)
+}
+
+// MoveChase 追击目标到指定距离，distance为0时追到近战范围；已在追击同一目标时不重复
+func (m *MotionMaster) MoveChase(target IUnit, distance float32) {
+	if target == nil {
+		return
+	}
+	if distance == 0 {
+		distance = MIN_MELEE_REACH
+	}
+	if chase, ok := m.slots[MOTION_SLOT_ACTIVE].(*ChaseMovementGenerator); ok &&
+		chase.target.GetGUID() == target.GetGUID() && chase.distance == distance {
+		return
+	}
+	m.Mutate(&ChaseMovementGenerator{target: target, distance: distance}, MOTION_SLOT_ACTIVE)
+}
+
+// MoveFollow 以指定距离和相对角度跟随目标
+func (m *MotionMaster) MoveFollow(target IUnit, distance, angle float32) {
+	if target == nil {
+		return
+	}
+	m.Mutate(&FollowMovementGenerator{target: target, distance: distance, angle: angle}, MOTION_SLOT_ACTIVE)
+}
+
+// MoveFleeing 逃离fright，duration为0时持续到恐惧效果结束
+func (m *MotionMaster) MoveFleeing(fright IUnit, duration uint32) {
+	m.Mutate(&FleeingMovementGenerator{fright: fright, timer: duration, timed: duration > 0}, MOTION_SLOT_CONTROLLED)
+}
+
+// MoveTargetedHome 返回出生点，到达后调用onArrive
+func (m *MotionMaster) MoveTargetedHome(x, y, z, orientation float32, onArrive func()) {
+	m.Mutate(&HomeMovementGenerator{home: Vector3{x, y, z}, orientation: orientation, onArrive: onArrive}, MOTION_SLOT_ACTIVE)
+}
+
+// MovePoint 以奔跑速度移动到指定点
+func (m *MotionMaster) MovePoint(x, y, z float32) {
+	m.Mutate(&PointMovementGenerator{dest: Vector3{x, y, z}}, MOTION_SLOT_ACTIVE)
+}
+
+// MoveCharge 以冲锋速度冲向指定点
+func (m *MotionMaster) MoveCharge(x, y, z float32) {
+	m.Mutate(&PointMovementGenerator{dest: Vector3{x, y, z}, charge: true}, MOTION_SLOT_ACTIVE)
+}
+
+// GetMotionMaster 获取移动生成器栈
+func (u *Unit) GetMotionMaster() *MotionMaster {
+	return u.motionMaster
+}
+
+// updateMovement 推进样条并更新移动生成器
+func (u *Unit) updateMovement(diff uint32) {
+	u.updateSplineMovement(diff)
+	u.motionMaster.Update(diff)
+}
+
+// faceTarget 面向目标
+func (u *Unit) faceTarget(target IUnit) {
+	u.orientation = calculateAngle(u.x, u.y, target.GetX(), target.GetY())
+}
+
+// IdleMovementGenerator 待机 - 原地不动
+type IdleMovementGenerator struct{}
+
+func (g *IdleMovementGenerator) Initialize(owner *Unit)               { owner.StopMoving() }
+func (g *IdleMovementGenerator) Update(owner *Unit, diff uint32) bool { return true }
+func (g *IdleMovementGenerator) Finalize(owner *Unit)                 {}
+func (g *IdleMovementGenerator) GetMovementGeneratorType() int        { return IDLE_MOTION_TYPE }
+
+// RandomMovementGenerator 随机游荡 - 基于AzerothCore的RandomMovementGenerator
+// 以行走速度在中心点半径内随机移动，每次到达后停顿一段时间
+type RandomMovementGenerator struct {
+	radius    float32
+	center    Vector3
+	hasCenter bool
+	waitTimer uint32
+}
+
+func (g *RandomMovementGenerator) Initialize(owner *Unit) {
+	if !g.hasCenter {
+		g.center = Vector3{owner.x, owner.y, owner.z}
+		g.hasCenter = true
+	}
+	owner.AddUnitState(UNIT_STATE_ROAMING)
+}
+
+func (g *RandomMovementGenerator) Update(owner *Unit, diff uint32) bool {
+	if owner.IsSplineMoving() {
+		return true
+	}
+	if g.waitTimer > diff {
+		g.waitTimer -= diff
+		return true
+	}
+
+	angle := rand.Float64() * 2 * math.Pi
+	dist := float64(g.radius) * rand.Float64()
+	x := g.center.x + float32(math.Cos(angle)*dist)
+	y := g.center.y + float32(math.Sin(angle)*dist)
+	owner.MovePoint(x, y, g.center.z, true)
+	g.waitTimer = uint32(RANDOM_MOVE_PAUSE_MIN + rand.Intn(RANDOM_MOVE_PAUSE_MAX-RANDOM_MOVE_PAUSE_MIN+1))
+	return true
+}
+
+func (g *RandomMovementGenerator) Finalize(owner *Unit) {
+	owner.ClearUnitState(UNIT_STATE_ROAMING)
+}
+
+func (g *RandomMovementGenerator) GetMovementGeneratorType() int { return RANDOM_MOTION_TYPE }
+
+// WaypointNode 路点 - 基于AzerothCore的waypoint_data
+type WaypointNode struct {
+	x, y, z float32
+	delay   uint32 // 到达后停留时间(毫秒)
+}
+
+// WaypointMovementGenerator 路点巡逻 - 基于AzerothCore的WaypointMovementGenerator
+// 被追击等打断后从当前路点继续
+type WaypointMovementGenerator struct {
+	path      []WaypointNode
+	repeat    bool
+	current   int
+	moving    bool
+	waitTimer uint32
+}
+
+func (g *WaypointMovementGenerator) Initialize(owner *Unit) {
+	owner.AddUnitState(UNIT_STATE_ROAMING)
+	g.moving = false
+}
+
+func (g *WaypointMovementGenerator) Update(owner *Unit, diff uint32) bool {
+	if owner.IsSplineMoving() {
+		return true
+	}
+
+	// 到达当前路点，被定身等打断时重新前往
+	if g.moving {
+		node := g.path[g.current]
+		if (Vector3{node.x, node.y, node.z}).distanceTo(Vector3{owner.x, owner.y, owner.z}) > HOME_ARRIVE_DISTANCE {
+			g.moving = owner.MovePoint(node.x, node.y, node.z, true)
+			return true
+		}
+		g.moving = false
+		g.waitTimer = node.delay
+		fmt.Printf("[移动] %s 到达路点 %d\n", owner.GetName(), g.current)
+		g.current++
+		if g.current >= len(g.path) {
+			if !g.repeat {
+				return false
+			}
+			g.current = 0
+		}
+	}
+
+	if g.waitTimer > diff {
+		g.waitTimer -= diff
+		return true
+	}
+	g.waitTimer = 0
+
+	node := g.path[g.current]
+	g.moving = owner.MovePoint(node.x, node.y, node.z, true)
+	return true
+}
+
+func (g *WaypointMovementGenerator) Finalize(owner *Unit) {
+	owner.ClearUnitState(UNIT_STATE_ROAMING)
+}
+
+func (g *WaypointMovementGenerator) GetMovementGeneratorType() int { return WAYPOINT_MOTION_TYPE }
+
+// GetCurrentNode 当前要前往的路点
+func (g *WaypointMovementGenerator) GetCurrentNode() int { return g.current }
+
+// ChaseMovementGenerator 追击 - 基于AzerothCore的ChaseMovementGenerator
+// 目标离开范围或移动时重新寻路，到达范围内停下并面向目标
+type ChaseMovementGenerator struct {
+	target     IUnit
+	distance   float32
+	lastTarget Vector3
+}
+
+func (g *ChaseMovementGenerator) Initialize(owner *Unit) {
+	owner.AddUnitState(UNIT_STATE_CHASE)
+	g.lastTarget = Vector3{float32(math.Inf(1)), 0, 0}
+}
+
+func (g *ChaseMovementGenerator) Update(owner *Unit, diff uint32) bool {
+	if g.target == nil || !g.target.IsAlive() {
+		return false
+	}
+
+	if owner.GetDistanceTo(g.target) <= g.distance+CHASE_RANGE_LEEWAY {
+		owner.StopMoving()
+		owner.faceTarget(g.target)
+		return true
+	}
+
+	targetPos := Vector3{g.target.GetX(), g.target.GetY(), g.target.GetZ()}
+	if owner.IsSplineMoving() && targetPos.distanceTo(g.lastTarget) < CHASE_REPATH_DISTANCE {
+		return true
+	}
+	g.lastTarget = targetPos
+
+	// 移动到目标与自己连线上距目标distance处
+	angle := float64(calculateAngle(targetPos.x, targetPos.y, owner.x, owner.y))
+	x := targetPos.x + float32(math.Cos(angle))*g.distance
+	y := targetPos.y + float32(math.Sin(angle))*g.distance
+	owner.MovePoint(x, y, targetPos.z, false)
+	return true
+}
+
+func (g *ChaseMovementGenerator) Finalize(owner *Unit) {
+	owner.ClearUnitState(UNIT_STATE_CHASE)
+}
+
+func (g *ChaseMovementGenerator) GetMovementGeneratorType() int { return CHASE_MOTION_TYPE }
+
+// GetTarget 追击目标
+func (g *ChaseMovementGenerator) GetTarget() IUnit { return g.target }
+
+// FollowMovementGenerator 跟随 - 基于AzerothCore的FollowMovementGenerator
+// 保持在目标朝向的相对角度和距离上
+type FollowMovementGenerator struct {
+	target   IUnit
+	distance float32
+	angle    float32
+	lastDest Vector3
+}
+
+func (g *FollowMovementGenerator) Initialize(owner *Unit) {
+	owner.AddUnitState(UNIT_STATE_FOLLOW)
+	g.lastDest = Vector3{float32(math.Inf(1)), 0, 0}
+}
+
+func (g *FollowMovementGenerator) Update(owner *Unit, diff uint32) bool {
+	if g.target == nil || !g.target.IsAlive() {
+		return false
+	}
+
+	angle := float64(getUnitOrientation(g.target) + g.angle)
+	dest := Vector3{
+		x: g.target.GetX() + float32(math.Cos(angle))*g.distance,
+		y: g.target.GetY() + float32(math.Sin(angle))*g.distance,
+		z: g.target.GetZ(),
+	}
+
+	if dest.distanceTo(Vector3{owner.x, owner.y, owner.z}) <= FOLLOW_DISTANCE_LEEWAY {
+		owner.StopMoving()
+		owner.orientation = getUnitOrientation(g.target)
+		return true
+	}
+	if owner.IsSplineMoving() && dest.distanceTo(g.lastDest) < CHASE_REPATH_DISTANCE {
+		return true
+	}
+	g.lastDest = dest
+	owner.MovePoint(dest.x, dest.y, dest.z, false)
+	return true
+}
+
+func (g *FollowMovementGenerator) Finalize(owner *Unit) {
+	owner.ClearUnitState(UNIT_STATE_FOLLOW)
+}
+
+func (g *FollowMovementGenerator) GetMovementGeneratorType() int { return FOLLOW_MOTION_TYPE }
+
+// getUnitOrientation 单位朝向
+func getUnitOrientation(unit IUnit) float32 {
+	if base := getBaseUnit(unit); base != nil {
+		return base.orientation
+	}
+	return 0
+}
+
+// FleeingMovementGenerator 逃跑 - 基于AzerothCore的FleeingMovementGenerator
+// 朝远离恐惧来源的方向分段奔跑，没有来源时随机方向
+type FleeingMovementGenerator struct {
+	fright IUnit
+	timer  uint32
+	timed  bool // 限时逃跑(如低血量逃跑)，否则由恐惧光环的移除结束
+}
+
+func (g *FleeingMovementGenerator) Initialize(owner *Unit) {
+	owner.AddUnitState(UNIT_STATE_FLEEING)
+	owner.StopMoving()
+}
+
+func (g *FleeingMovementGenerator) Update(owner *Unit, diff uint32) bool {
+	if g.timed {
+		if g.timer <= diff {
+			return false
+		}
+		g.timer -= diff
+	}
+	if owner.IsSplineMoving() {
+		return true
+	}
+
+	angle := rand.Float64() * 2 * math.Pi
+	if g.fright != nil && g.fright.IsAlive() {
+		angle = float64(calculateAngle(g.fright.GetX(), g.fright.GetY(), owner.x, owner.y)) +
+			(rand.Float64()-0.5)*FLEE_ANGLE_SPREAD
+	}
+	dist := FLEE_DISTANCE_MIN + rand.Float64()*(FLEE_DISTANCE_MAX-FLEE_DISTANCE_MIN)
+	x := owner.x + float32(math.Cos(angle)*dist)
+	y := owner.y + float32(math.Sin(angle)*dist)
+	owner.MovePoint(x, y, owner.z, false)
+	return true
+}
+
+func (g *FleeingMovementGenerator) Finalize(owner *Unit) {
+	owner.StopMoving()
+	if !owner.HasAuraType(SPELL_AURA_MOD_FEAR) {
+		owner.ClearUnitState(UNIT_STATE_FLEEING)
+	}
+}
+
+func (g *FleeingMovementGenerator) GetMovementGeneratorType() int { return FLEEING_MOTION_TYPE }
+
+// HomeMovementGenerator 返回出生点 - 基于AzerothCore的HomeMovementGenerator
+// 无法寻路时直接回到出生点
+type HomeMovementGenerator struct {
+	home        Vector3
+	orientation float32
+	onArrive    func()
+}
+
+func (g *HomeMovementGenerator) Initialize(owner *Unit) {
+	g.moveHome(owner)
+}
+
+func (g *HomeMovementGenerator) moveHome(owner *Unit) {
+	if !owner.MovePoint(g.home.x, g.home.y, g.home.z, false) {
+		owner.SetPosition(g.home.x, g.home.y, g.home.z)
+	}
+}
+
+func (g *HomeMovementGenerator) Update(owner *Unit, diff uint32) bool {
+	if owner.IsSplineMoving() {
+		return true
+	}
+	if g.home.distanceTo(Vector3{owner.x, owner.y, owner.z}) > HOME_ARRIVE_DISTANCE {
+		g.moveHome(owner)
+		return true
+	}
+
+	owner.orientation = g.orientation
+	if g.onArrive != nil {
+		g.onArrive()
+	}
+	return false
+}
+
+func (g *HomeMovementGenerator) Finalize(owner *Unit)          {}
+func (g *HomeMovementGenerator) GetMovementGeneratorType() int { return HOME_MOTION_TYPE }
+
+// PointMovementGenerator 移动到指定点 - 基于AzerothCore的PointMovementGenerator
+// 冲锋时速度提高并带有冲锋状态
+type PointMovementGenerator struct {
+	dest   Vector3
+	charge bool
+}
+
+func (g *PointMovementGenerator) Initialize(owner *Unit) {
+	if g.charge {
+		owner.AddUnitState(UNIT_STATE_CHARGING)
+	}
+	speedRate := float32(1)
+	if g.charge {
+		speedRate = CHARGE_SPEED_MULTIPLIER
+	}
+	if path := owner.calculatePath(g.dest.x, g.dest.y, g.dest.z); len(path) > 0 {
+		owner.launchMoveSpline(path, false, speedRate)
+	}
+}
+
+func (g *PointMovementGenerator) Update(owner *Unit, diff uint32) bool {
+	return owner.IsSplineMoving()
+}
+
+func (g *PointMovementGenerator) Finalize(owner *Unit) {
+	owner.ClearUnitState(UNIT_STATE_CHARGING)
+}
+
+func (g *PointMovementGenerator) GetMovementGeneratorType() int { return POINT_MOTION_TYPE }
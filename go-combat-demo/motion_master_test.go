@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+func TestCreatureChasesVictimAndWalksHomeOnEvade(t *testing.T) {
+	mob := NewCreature("mob", 20, CREATURE_TYPE_HUMANOID)
+	mob.SetMaxHealth(1000)
+	mob.SetHealth(1000)
+	mob.Relocate(0, 0, 0, 0)
+	player := newThreatTestUnit("player", 20)
+
+	mob.threatManager.AddThreat(player, 100)
+	mob.Update(100) // 选择目标并开始追击
+	mob.Update(100)
+	if !mob.HasUnitState(UNIT_STATE_CHASE) || !mob.IsSplineMoving() || !mob.IsMoving() {
+		t.Fatalf("mob should chase a victim out of melee range")
+	}
+
+	for i := 0; i < 40; i++ {
+		mob.Update(100)
+	}
+	if !mob.IsWithinMeleeRange(player) || mob.IsSplineMoving() || mob.GetVictim() != player {
+		t.Fatalf("mob should stop in melee range and attack, distance %.1f", mob.GetDistanceTo(player))
+	}
+
+	mob.EnterEvadeMode()
+	if mob.GetMotionMaster().GetCurrentMovementGeneratorType() != HOME_MOTION_TYPE || mob.HasUnitState(UNIT_STATE_CHASE) {
+		t.Fatalf("evade should replace chase with the home movement")
+	}
+	for i := 0; i < 40 && mob.HasUnitState(UNIT_STATE_EVADE); i++ {
+		mob.Update(100)
+	}
+	if mob.HasUnitState(UNIT_STATE_EVADE) || mob.GetDistanceToHome() != 0 ||
+		mob.GetMotionMaster().GetCurrentMovementGeneratorType() != IDLE_MOTION_TYPE {
+		t.Fatalf("mob should be back home and idle, distance %.1f", mob.GetDistanceToHome())
+	}
+}
+
+func TestFearOverridesWaypointsAndRootPausesMovement(t *testing.T) {
+	patrol := newThreatTestUnit("patrol", 0)
+	caster := newThreatTestUnit("caster", -5)
+	mm := patrol.GetMotionMaster()
+	mm.MoveWaypoint([]WaypointNode{{x: 10}, {x: 10, y: 10}}, true)
+
+	for i := 0; i < 10; i++ {
+		patrol.Update(100)
+	}
+	if mm.GetCurrentMovementGeneratorType() != WAYPOINT_MOTION_TYPE || !patrol.HasUnitState(UNIT_STATE_ROAMING) || patrol.GetX() <= 0 {
+		t.Fatalf("patrol should walk toward the first waypoint, x %.1f", patrol.GetX())
+	}
+
+	fearInfo := &SpellInfo{ID: 5782, Name: "恐惧"}
+	fear := NewAura(fearInfo, &SpellEffect{ApplyAuraName: int(SPELL_AURA_MOD_FEAR)}, caster, patrol, 3600000)
+	patrol.AddAura(fear)
+	if mm.GetCurrentMovementGeneratorType() != FLEEING_MOTION_TYPE || patrol.HasUnitState(UNIT_STATE_ROAMING) {
+		t.Fatalf("fear should take over movement")
+	}
+	before := patrol.GetDistanceTo(caster)
+	for i := 0; i < 10; i++ {
+		patrol.Update(100)
+	}
+	if patrol.GetDistanceTo(caster) <= before {
+		t.Fatalf("feared unit should run away from the caster")
+	}
+
+	patrol.RemoveAura(fear, AURA_REMOVE_BY_DEFAULT)
+	if mm.GetCurrentMovementGeneratorType() != WAYPOINT_MOTION_TYPE || patrol.HasUnitState(UNIT_STATE_FLEEING) {
+		t.Fatalf("waypoints should resume when fear ends")
+	}
+
+	patrol.Update(100)
+	patrol.AddUnitState(UNIT_STATE_ROOTED)
+	patrol.Update(100)
+	x, y := patrol.GetX(), patrol.GetY()
+	patrol.Update(100)
+	if patrol.IsSplineMoving() || patrol.GetX() != x || patrol.GetY() != y {
+		t.Fatalf("rooted unit should not move")
+	}
+	patrol.ClearUnitState(UNIT_STATE_ROOTED)
+	patrol.Update(100)
+	if !patrol.IsSplineMoving() {
+		t.Fatalf("patrol should continue after the root ends")
+	}
+}
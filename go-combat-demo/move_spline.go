@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+)
+
+// 样条移动类型 - 基于AzerothCore的MonsterMoveType
+const (
+	MONSTER_MOVE_NORMAL = 0 // 沿路径移动
+	MONSTER_MOVE_STOP   = 1 // 停止移动
+)
+
+// 样条标志 - 基于AzerothCore的MoveSplineFlag
+const (
+	SPLINEFLAG_NONE     = 0x00000000
+	SPLINEFLAG_WALKMODE = 0x00001000 // 行走(否则为奔跑)
+)
+
+// Vector3 三维坐标点
+type Vector3 struct {
+	x, y, z float32
+}
+
+// distanceTo 两点之间的距离
+func (v Vector3) distanceTo(o Vector3) float32 {
+	dx, dy, dz := o.x-v.x, o.y-v.y, o.z-v.z
+	return float32(math.Sqrt(float64(dx*dx + dy*dy + dz*dz)))
+}
+
+// PathGenerator 寻路 - 对应AzerothCore中基于导航网格的PathGenerator::CalculatePath
+// 返回从起点到终点的路径点(不含起点)，无法到达时返回nil
+type PathGenerator func(start, end Vector3) []Vector3
+
+// SetPathGenerator 设置寻路
+func (w *World) SetPathGenerator(generator PathGenerator) {
+	w.pathGenerator = generator
+}
+
+// CalculatePath 计算路径，未设置寻路时走直线，终点不可行走或视线被阻挡时视为无法到达
+func (w *World) CalculatePath(start, end Vector3) []Vector3 {
+	if w.pathGenerator != nil {
+		return w.pathGenerator(start, end)
+	}
+	if !w.IsWalkable(end.x, end.y, end.z) || !w.IsInLineOfSight(start.x, start.y, start.z, end.x, end.y, end.z) {
+		return nil
+	}
+	return []Vector3{end}
+}
+
+// MoveSpline 服务器端样条移动 - 基于AzerothCore的Movement::MoveSpline
+// 按时间在路径点之间线性插值，客户端收到SMSG_MONSTER_MOVE后做同样的插值
+type MoveSpline struct {
+	id       uint32
+	path     []Vector3 // path[0]为起点
+	nodeTime []uint32  // 到达每个路径点的累计时间(毫秒)
+	walk     bool
+	speed    float32
+	elapsed  uint32
+}
+
+var nextSplineId uint32
+
+// newMoveSpline 按速度计算每段耗时
+func newMoveSpline(start Vector3, path []Vector3, speed float32, walk bool) *MoveSpline {
+	spline := &MoveSpline{
+		id:       atomic.AddUint32(&nextSplineId, 1),
+		path:     append([]Vector3{start}, path...),
+		nodeTime: make([]uint32, len(path)+1),
+		walk:     walk,
+		speed:    speed,
+	}
+	var total float32
+	for i := 1; i < len(spline.path); i++ {
+		total += spline.path[i-1].distanceTo(spline.path[i])
+		spline.nodeTime[i] = uint32(total / speed * 1000)
+	}
+	return spline
+}
+
+// Duration 总时长(毫秒)
+func (s *MoveSpline) Duration() uint32 {
+	return s.nodeTime[len(s.nodeTime)-1]
+}
+
+// Finalized 是否已到达终点
+func (s *MoveSpline) Finalized() bool {
+	return s.elapsed >= s.Duration()
+}
+
+// Destination 终点
+func (s *MoveSpline) Destination() Vector3 {
+	return s.path[len(s.path)-1]
+}
+
+// computePosition 当前时间对应的位置和朝向
+func (s *MoveSpline) computePosition() (Vector3, float32) {
+	last := len(s.path) - 1
+	if s.Finalized() {
+		from, to := s.path[last-1], s.path[last]
+		return to, calculateAngle(from.x, from.y, to.x, to.y)
+	}
+
+	seg := 1
+	for seg < last && s.nodeTime[seg] <= s.elapsed {
+		seg++
+	}
+	from, to := s.path[seg-1], s.path[seg]
+	ratio := float32(1)
+	if segTime := s.nodeTime[seg] - s.nodeTime[seg-1]; segTime > 0 {
+		ratio = float32(s.elapsed-s.nodeTime[seg-1]) / float32(segTime)
+	}
+	pos := Vector3{
+		x: from.x + (to.x-from.x)*ratio,
+		y: from.y + (to.y-from.y)*ratio,
+		z: from.z + (to.z-from.z)*ratio,
+	}
+	return pos, calculateAngle(from.x, from.y, to.x, to.y)
+}
+
+// IsSplineMoving 是否正在沿样条移动
+func (u *Unit) IsSplineMoving() bool {
+	return u.moveSpline != nil
+}
+
+// GetMoveSpline 获取当前样条
+func (u *Unit) GetMoveSpline() *MoveSpline {
+	return u.moveSpline
+}
+
+// calculatePath 计算从当前位置到目标点的路径
+func (u *Unit) calculatePath(x, y, z float32) []Vector3 {
+	start, end := Vector3{u.x, u.y, u.z}, Vector3{x, y, z}
+	if u.world == nil {
+		return []Vector3{end}
+	}
+	return u.world.CalculatePath(start, end)
+}
+
+// MovePoint 寻路到目标点并开始样条移动，无法到达时返回false
+func (u *Unit) MovePoint(x, y, z float32, walk bool) bool {
+	path := u.calculatePath(x, y, z)
+	if len(path) == 0 {
+		return false
+	}
+	return u.LaunchMoveSpline(path, walk)
+}
+
+// LaunchMoveSpline 沿路径开始移动并通知客户端 - 基于AzerothCore的Movement::MoveSplineInit::Launch
+func (u *Unit) LaunchMoveSpline(path []Vector3, walk bool) bool {
+	return u.launchMoveSpline(path, walk, 1)
+}
+
+// launchMoveSpline 以当前速度的speedRate倍开始移动
+func (u *Unit) launchMoveSpline(path []Vector3, walk bool, speedRate float32) bool {
+	flags := u.movementInfo.flags&^MOVEMENTFLAG_WALKING | MOVEMENTFLAG_FORWARD
+	if walk {
+		flags |= MOVEMENTFLAG_WALKING
+	}
+	u.movementInfo.flags = flags
+
+	speed := u.GetSpeed() * speedRate
+	if speed <= 0 || len(path) == 0 {
+		u.movementInfo.flags &^= MOVEMENTFLAG_FORWARD
+		return false
+	}
+
+	u.moveSpline = newMoveSpline(Vector3{u.x, u.y, u.z}, path, speed, walk)
+	if u.world != nil {
+		u.world.BroadcastMonsterMove(u, u.moveSpline)
+	}
+	return true
+}
+
+// StopMoving 停止样条移动并通知客户端 - 基于AzerothCore的Unit::StopMoving
+func (u *Unit) StopMoving() {
+	if u.moveSpline == nil {
+		return
+	}
+	u.moveSpline = nil
+	u.movementInfo.flags &^= MOVEMENTFLAG_FORWARD
+	if u.world != nil {
+		u.world.BroadcastMonsterMove(u, nil)
+	}
+	u.AddBatchUpdateForMovement()
+}
+
+// updateSplineMovement 推进样条移动 - 基于AzerothCore的Unit::UpdateSplineMovement
+func (u *Unit) updateSplineMovement(diff uint32) {
+	if u.moveSpline == nil {
+		return
+	}
+
+	u.moveSpline.elapsed += diff
+	pos, orientation := u.moveSpline.computePosition()
+	u.SetPosition(pos.x, pos.y, pos.z)
+	u.orientation = orientation
+	u.movementInfo.x, u.movementInfo.y, u.movementInfo.z, u.movementInfo.o = pos.x, pos.y, pos.z, orientation
+
+	if u.moveSpline.Finalized() {
+		u.moveSpline = nil
+		u.movementInfo.flags &^= MOVEMENTFLAG_FORWARD
+		u.AddBatchUpdateForMovement()
+	}
+}
+
+// BroadcastMonsterMove 广播样条移动 - 基于AzerothCore的SMSG_MONSTER_MOVE
+// spline为nil时广播停止移动；路径点简化为完整坐标
+func (w *World) BroadcastMonsterMove(mover IUnit, spline *MoveSpline) {
+	x, y, z := mover.GetPosition()
+
+	packet := NewWorldPacket(SMSG_MONSTER_MOVE)
+	packet.WriteUint64(mover.GetGUID())
+	packet.WriteUint8(0)
+	packet.WriteFloat32(x)
+	packet.WriteFloat32(y)
+	packet.WriteFloat32(z)
+	if spline == nil {
+		packet.WriteUint32(0)
+		packet.WriteUint8(MONSTER_MOVE_STOP)
+	} else {
+		flags := uint32(SPLINEFLAG_NONE)
+		if spline.walk {
+			flags |= SPLINEFLAG_WALKMODE
+		}
+		packet.WriteUint32(spline.id)
+		packet.WriteUint8(MONSTER_MOVE_NORMAL)
+		packet.WriteUint32(flags)
+		packet.WriteUint32(spline.Duration())
+		packet.WriteUint32(uint32(len(spline.path) - 1))
+		for _, point := range spline.path[1:] {
+			packet.WriteFloat32(point.x)
+			packet.WriteFloat32(point.y)
+			packet.WriteFloat32(point.z)
+		}
+	}
+
+	for _, session := range w.GetPlayersInRange(x, y, z, 100.0) {
+		if session.IsConnected() {
+			session.SendPacket(packet)
+		}
+	}
+
+	if spline != nil {
+		dest := spline.Destination()
+		fmt.Printf("[移动] %s 移动到 (%.1f, %.1f, %.1f)，耗时 %dms\n",
+			mover.GetName(), dest.x, dest.y, dest.z, spline.Duration())
+	}
+}
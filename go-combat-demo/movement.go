@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// 移动校验常量 - 基于AzerothCore的反作弊移动检查
+const (
+	MOVEMENT_SPEED_TOLERANCE   = 1.2  // 速度容差，允许网络抖动造成的偏差
+	MOVEMENT_DISTANCE_LEEWAY   = 2.0  // 每次移动额外允许的距离(码)
+	MOVEMENT_MAX_ELAPSED       = 5000 // 计算速度时最多计入的间隔(毫秒)
+	MOVEMENT_TELEPORT_DISTANCE = 50.0 // 单次移动超过此距离视为瞬移(码)
+)
+
+// 作弊类型 - 移动
+const (
+	CHEAT_SPEED_HACK      = 2 // 移动速度超过上限
+	CHEAT_TELEPORT        = 3 // 单次移动距离过大
+	CHEAT_WALL_CLIP       = 4 // 穿墙或移动到不可行走的位置
+	CHEAT_MOVE_WHILE_ROOT = 5 // 定身、昏迷时移动
+)
+
+func init() {
+	cheatTypeNames[CHEAT_SPEED_HACK] = "加速"
+	cheatTypeNames[CHEAT_TELEPORT] = "瞬移"
+	cheatTypeNames[CHEAT_WALL_CLIP] = "穿墙"
+	cheatTypeNames[CHEAT_MOVE_WHILE_ROOT] = "无法移动时移动"
+}
+
+// WalkabilityChecker 可行走检查 - 对应AzerothCore中基于导航网格的PathGenerator
+type WalkabilityChecker func(x, y, z float32) bool
+
+// SetWalkabilityChecker 设置可行走检查
+func (w *World) SetWalkabilityChecker(checker WalkabilityChecker) {
+	w.walkabilityChecker = checker
+}
+
+// IsWalkable 检查坐标是否可行走，未设置检查时视为可行走
+func (w *World) IsWalkable(x, y, z float32) bool {
+	if w.walkabilityChecker == nil {
+		return true
+	}
+	return w.walkabilityChecker(x, y, z)
+}
+
+// GetSpeed 当前移动速度(码/秒) - 按行走、游泳、奔跑选择基础速度，受移动速度光环影响
+func (u *Unit) GetSpeed() float32 {
+	base := float32(BASE_RUN_SPEED)
+	if u.HasMovementFlag(MOVEMENTFLAG_SWIMMING) {
+		base = BASE_SWIM_SPEED
+	} else if u.HasMovementFlag(MOVEMENTFLAG_WALKING) {
+		base = BASE_WALK_SPEED
+	}
+
+	pct := float32(100)
+	for _, aura := range u.auras {
+		switch aura.auraType {
+		case SPELL_AURA_MOD_INCREASE_SPEED:
+			pct += float32(aura.value)
+		case SPELL_AURA_MOD_DECREASE_SPEED:
+			pct -= float32(aura.value)
+		}
+	}
+	if pct < 0 {
+		pct = 0
+	}
+	return base * pct / 100
+}
+
+// validateMovement 校验客户端上报的新位置，返回违规类型，0表示合法
+// 速度只按水平距离计算，下落造成的高度变化由瞬移检查兜底
+func (ws *WorldSession) validateMovement(unit *Unit, x, y, z float32, now time.Time) uint8 {
+	dx, dy, dz := x-unit.x, y-unit.y, z-unit.z
+	distance2d := float32(math.Sqrt(float64(dx*dx + dy*dy)))
+	distance3d := float32(math.Sqrt(float64(dx*dx + dy*dy + dz*dz)))
+
+	// 原地转身不受定身影响
+	if distance3d > 0 && !unit.CanMove() {
+		return CHEAT_MOVE_WHILE_ROOT
+	}
+	if distance3d > MOVEMENT_TELEPORT_DISTANCE {
+		return CHEAT_TELEPORT
+	}
+
+	elapsed := float32(MOVEMENT_MAX_ELAPSED)
+	if !unit.lastMoveTime.IsZero() {
+		if ms := float32(now.Sub(unit.lastMoveTime).Milliseconds()); ms < elapsed {
+			elapsed = ms
+		}
+	}
+	maxDistance := unit.GetSpeed()*elapsed/1000*MOVEMENT_SPEED_TOLERANCE + MOVEMENT_DISTANCE_LEEWAY
+	if distance2d > maxDistance {
+		return CHEAT_SPEED_HACK
+	}
+
+	if ws.world != nil && distance3d > 0 {
+		if !ws.world.IsWalkable(x, y, z) || !ws.world.IsInLineOfSight(unit.x, unit.y, unit.z, x, y, z) {
+			return CHEAT_WALL_CLIP
+		}
+	}
+	return 0
+}
+
+// HandleMovementOpcodes 处理所有MSG_MOVE_*操作码 - 基于AzerothCore的WorldSession::HandleMovementOpcodes
+// 校验通过后更新位置和移动标志，并原样转发给附近的其他玩家
+func (ws *WorldSession) HandleMovementOpcodes(packet *WorldPacket) {
+	opcode := packet.GetOpcode()
+	moverGuid := packet.ReadUint64()
+	movementInfo := packet.ReadMovementInfo()
+
+	unit := getBaseUnit(ws.GetPlayer())
+	if unit == nil {
+		return
+	}
+	// 只能移动自己控制的单位
+	if moverGuid != unit.GetGUID() {
+		fmt.Printf("[移动拒绝] %s 试图移动单位 %d\n", unit.GetName(), moverGuid)
+		return
+	}
+
+	if !ws.applyMovementInfo(unit, opcode, &movementInfo) {
+		return
+	}
+	if ws.world != nil {
+		ws.world.BroadcastMovement(unit, opcode, &movementInfo, ws)
+	}
+}
+
+// applyMovementInfo 校验并应用移动信息，不合法时拉回
+func (ws *WorldSession) applyMovementInfo(unit *Unit, opcode uint16, mi *MovementInfo) bool {
+	now := time.Now()
+	if cheat := ws.validateMovement(unit, mi.x, mi.y, mi.z, now); cheat != 0 {
+		ws.ReportCheat(cheat, fmt.Sprintf("%s (%.2f, %.2f, %.2f) -> (%.2f, %.2f, %.2f)",
+			movementOpcodeNames[opcode], unit.x, unit.y, unit.z, mi.x, mi.y, mi.z))
+		ws.rubberBand(unit)
+		return false
+	}
+
+	unit.SetPosition(mi.x, mi.y, mi.z)
+	unit.orientation = mi.o
+	unit.movementInfo = *mi
+	unit.lastMoveTime = now
+
+	// 摔落伤害由服务器根据起跳高度计算 - 基于AzerothCore的Player::HandleFall/SetFallInformation
+	if opcode == MSG_MOVE_FALL_LAND {
+		unit.HandleFall(unit.lastFallZ - mi.z)
+	}
+	if opcode == MSG_MOVE_FALL_LAND || !mi.HasMovementFlag(MOVEMENTFLAG_FALLING) || unit.lastFallZ < mi.z {
+		unit.lastFallZ = mi.z
+	}
+
+	// 添加批量更新 - 基于AzerothCore的移动同步
+	unit.AddBatchUpdateForMovement()
+	return true
+}
+
+// BroadcastMovement 把移动转发给附近的其他玩家，不发回给移动者自己
+func (w *World) BroadcastMovement(mover IUnit, opcode uint16, mi *MovementInfo, except *WorldSession) {
+	packet := NewWorldPacket(opcode)
+	packet.WriteUint64(mover.GetGUID())
+	packet.WriteMovementInfo(mi)
+
+	x, y, z := mover.GetPosition()
+	for _, session := range w.GetPlayersInRange(x, y, z, 100.0) {
+		if session != except && session.IsConnected() {
+			session.SendPacket(packet)
+		}
+	}
+}
+
+// rubberBand 把客户端拉回服务器记录的最后合法位置 - 基于AzerothCore的MSG_MOVE_TELEPORT_ACK
+func (ws *WorldSession) rubberBand(unit *Unit) {
+	packet := NewWorldPacket(MSG_MOVE_TELEPORT_ACK)
+	packet.WriteUint64(unit.GetGUID())
+	packet.WriteFloat32(unit.x)
+	packet.WriteFloat32(unit.y)
+	packet.WriteFloat32(unit.z)
+	packet.WriteFloat32(unit.orientation)
+	packet.SetPriority(0)
+	ws.SendPacket(packet)
+
+	unit.AddBatchUpdateForMovement()
+	fmt.Printf("[移动拒绝] %s 被拉回 (%.2f, %.2f, %.2f)\n", unit.GetName(), unit.x, unit.y, unit.z)
+}
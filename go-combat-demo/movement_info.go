@@ -0,0 +1,165 @@
+package main
+
+// 移动标志 - 基于AzerothCore的MovementFlags
+const (
+	MOVEMENTFLAG_NONE            = 0x00000000
+	MOVEMENTFLAG_FORWARD         = 0x00000001 // 前进
+	MOVEMENTFLAG_BACKWARD        = 0x00000002 // 后退
+	MOVEMENTFLAG_STRAFE_LEFT     = 0x00000004 // 左平移
+	MOVEMENTFLAG_STRAFE_RIGHT    = 0x00000008 // 右平移
+	MOVEMENTFLAG_LEFT            = 0x00000010 // 左转
+	MOVEMENTFLAG_RIGHT           = 0x00000020 // 右转
+	MOVEMENTFLAG_PITCH_UP        = 0x00000040 // 抬头
+	MOVEMENTFLAG_PITCH_DOWN      = 0x00000080 // 低头
+	MOVEMENTFLAG_WALKING         = 0x00000100 // 行走模式
+	MOVEMENTFLAG_ONTRANSPORT     = 0x00000200 // 在载具上(船、飞艇)
+	MOVEMENTFLAG_DISABLE_GRAVITY = 0x00000400 // 无重力
+	MOVEMENTFLAG_ROOT            = 0x00000800 // 定身
+	MOVEMENTFLAG_FALLING         = 0x00001000 // 跳跃或下落中
+	MOVEMENTFLAG_FALLING_FAR     = 0x00002000 // 长距离下落
+	MOVEMENTFLAG_SWIMMING        = 0x00200000 // 游泳
+	MOVEMENTFLAG_FLYING          = 0x02000000 // 飞行
+
+	// 组合标志
+	MOVEMENTFLAG_MASK_MOVING = MOVEMENTFLAG_FORWARD | MOVEMENTFLAG_BACKWARD | MOVEMENTFLAG_STRAFE_LEFT |
+		MOVEMENTFLAG_STRAFE_RIGHT | MOVEMENTFLAG_FALLING | MOVEMENTFLAG_FALLING_FAR
+	MOVEMENTFLAG_MASK_TURNING = MOVEMENTFLAG_LEFT | MOVEMENTFLAG_RIGHT | MOVEMENTFLAG_PITCH_UP | MOVEMENTFLAG_PITCH_DOWN
+)
+
+// 移动速度 - 基于AzerothCore的baseMoveSpeed
+const (
+	BASE_WALK_SPEED = 2.5   // 行走速度
+	BASE_SWIM_SPEED = 4.722 // 游泳速度
+)
+
+// MovementTransport 载具上的相对位置
+type MovementTransport struct {
+	guid       uint64
+	x, y, z, o float32
+	time       uint32
+	seat       uint8
+}
+
+// MovementJump 跳跃数据
+type MovementJump struct {
+	zspeed   float32
+	sinAngle float32
+	cosAngle float32
+	xyspeed  float32
+}
+
+// MovementInfo 移动信息 - 基于AzerothCore的MovementInfo，所有移动操作码共用
+type MovementInfo struct {
+	flags      uint32            // 移动标志(MOVEMENTFLAG_*)
+	flags2     uint16            // 额外移动标志
+	time       uint32            // 客户端时间戳
+	x, y, z, o float32           // 位置和朝向
+	transport  MovementTransport // 载具数据，flags含ONTRANSPORT时有效
+	pitch      float32           // 俯仰角，游泳和飞行时有效
+	fallTime   uint32            // 已下落时间(毫秒)
+	jump       MovementJump      // 跳跃数据，flags含FALLING时有效
+}
+
+// HasMovementFlag 是否含有移动标志
+func (mi *MovementInfo) HasMovementFlag(flag uint32) bool {
+	return mi.flags&flag != 0
+}
+
+// ReadMovementInfo 读取移动信息 - 基于AzerothCore的WorldSession::ReadMovementInfo
+func (wp *WorldPacket) ReadMovementInfo() MovementInfo {
+	var mi MovementInfo
+	mi.flags = wp.ReadUint32()
+	mi.flags2 = wp.ReadUint16()
+	mi.time = wp.ReadUint32()
+	mi.x = wp.ReadFloat32()
+	mi.y = wp.ReadFloat32()
+	mi.z = wp.ReadFloat32()
+	mi.o = wp.ReadFloat32()
+
+	if mi.HasMovementFlag(MOVEMENTFLAG_ONTRANSPORT) {
+		mi.transport.guid = wp.ReadUint64()
+		mi.transport.x = wp.ReadFloat32()
+		mi.transport.y = wp.ReadFloat32()
+		mi.transport.z = wp.ReadFloat32()
+		mi.transport.o = wp.ReadFloat32()
+		mi.transport.time = wp.ReadUint32()
+		mi.transport.seat = wp.ReadUint8()
+	}
+	if mi.HasMovementFlag(MOVEMENTFLAG_SWIMMING | MOVEMENTFLAG_FLYING) {
+		mi.pitch = wp.ReadFloat32()
+	}
+	mi.fallTime = wp.ReadUint32()
+	if mi.HasMovementFlag(MOVEMENTFLAG_FALLING) {
+		mi.jump.zspeed = wp.ReadFloat32()
+		mi.jump.sinAngle = wp.ReadFloat32()
+		mi.jump.cosAngle = wp.ReadFloat32()
+		mi.jump.xyspeed = wp.ReadFloat32()
+	}
+	return mi
+}
+
+// WriteMovementInfo 写入移动信息 - 基于AzerothCore的WorldSession::WriteMovementInfo
+func (wp *WorldPacket) WriteMovementInfo(mi *MovementInfo) {
+	wp.WriteUint32(mi.flags)
+	wp.WriteUint16(mi.flags2)
+	wp.WriteUint32(mi.time)
+	wp.WriteFloat32(mi.x)
+	wp.WriteFloat32(mi.y)
+	wp.WriteFloat32(mi.z)
+	wp.WriteFloat32(mi.o)
+
+	if mi.HasMovementFlag(MOVEMENTFLAG_ONTRANSPORT) {
+		wp.WriteUint64(mi.transport.guid)
+		wp.WriteFloat32(mi.transport.x)
+		wp.WriteFloat32(mi.transport.y)
+		wp.WriteFloat32(mi.transport.z)
+		wp.WriteFloat32(mi.transport.o)
+		wp.WriteUint32(mi.transport.time)
+		wp.WriteUint8(mi.transport.seat)
+	}
+	if mi.HasMovementFlag(MOVEMENTFLAG_SWIMMING | MOVEMENTFLAG_FLYING) {
+		wp.WriteFloat32(mi.pitch)
+	}
+	wp.WriteUint32(mi.fallTime)
+	if mi.HasMovementFlag(MOVEMENTFLAG_FALLING) {
+		wp.WriteFloat32(mi.jump.zspeed)
+		wp.WriteFloat32(mi.jump.sinAngle)
+		wp.WriteFloat32(mi.jump.cosAngle)
+		wp.WriteFloat32(mi.jump.xyspeed)
+	}
+}
+
+// GetMovementInfo 获取最后一次的移动信息
+func (u *Unit) GetMovementInfo() MovementInfo {
+	return u.movementInfo
+}
+
+// GetMovementFlags 获取移动标志
+func (u *Unit) GetMovementFlags() uint32 {
+	return u.movementInfo.flags
+}
+
+// SetMovementFlags 设置移动标志
+func (u *Unit) SetMovementFlags(flags uint32) {
+	u.movementInfo.flags = flags
+}
+
+// HasMovementFlag 是否含有移动标志
+func (u *Unit) HasMovementFlag(flag uint32) bool {
+	return u.movementInfo.flags&flag != 0
+}
+
+// IsMoving 是否在移动(不含原地转身)
+func (u *Unit) IsMoving() bool {
+	return u.HasMovementFlag(MOVEMENTFLAG_MASK_MOVING)
+}
+
+// IsSwimming 是否在游泳
+func (u *Unit) IsSwimming() bool {
+	return u.HasMovementFlag(MOVEMENTFLAG_SWIMMING)
+}
+
+// IsFalling 是否在跳跃或下落中
+func (u *Unit) IsFalling() bool {
+	return u.HasMovementFlag(MOVEMENTFLAG_FALLING | MOVEMENTFLAG_FALLING_FAR)
+}
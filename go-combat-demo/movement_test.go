@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMovementValidationRejectsCheats(t *testing.T) {
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	player := newThreatTestUnit("player", 0)
+	player.SetWorld(world)
+	session := NewWorldSession(1, "runner", nil, world)
+	session.SetPlayer(player)
+
+	now := time.Now()
+	player.lastMoveTime = now.Add(-time.Second)
+	if cheat := session.validateMovement(player, 7, 0, 0, now); cheat != 0 {
+		t.Fatalf("running 7 yards in one second should be valid, got %d", cheat)
+	}
+	if cheat := session.validateMovement(player, 20, 0, 0, now); cheat != CHEAT_SPEED_HACK {
+		t.Fatalf("running 20 yards in one second should be a speed hack, got %d", cheat)
+	}
+	if cheat := session.validateMovement(player, 0, 0, -60, now); cheat != CHEAT_TELEPORT {
+		t.Fatalf("moving 60 yards in one packet should be a teleport, got %d", cheat)
+	}
+
+	world.SetWalkabilityChecker(func(x, y, z float32) bool { return x < 5 })
+	if cheat := session.validateMovement(player, 6, 0, 0, now); cheat != CHEAT_WALL_CLIP {
+		t.Fatalf("moving onto an unwalkable position should be rejected, got %d", cheat)
+	}
+	world.SetWalkabilityChecker(nil)
+
+	player.AddUnitState(UNIT_STATE_ROOTED)
+	if sendTestMovement(session, player, MSG_MOVE_START_FORWARD, MovementInfo{flags: MOVEMENTFLAG_FORWARD, x: 3}) {
+		t.Fatalf("rooted player should not move")
+	}
+	if player.GetX() != 0 || session.GetCheatReports(CHEAT_MOVE_WHILE_ROOT) != 1 {
+		t.Fatalf("rooted movement should be flagged and rubber-banded")
+	}
+	player.ClearUnitState(UNIT_STATE_ROOTED)
+
+	player.lastMoveTime = time.Now().Add(-time.Second)
+	if !sendTestMovement(session, player, MSG_MOVE_START_FORWARD, MovementInfo{flags: MOVEMENTFLAG_FORWARD, x: 3}) || player.GetX() != 3 {
+		t.Fatalf("valid movement should update the server position")
+	}
+}
+
+func sendTestMovement(session *WorldSession, unit *Unit, opcode uint16, mi MovementInfo) bool {
+	before := unit.lastMoveTime
+	packet := NewWorldPacket(opcode)
+	packet.WriteUint64(unit.GetGUID())
+	packet.WriteMovementInfo(&mi)
+	session.handlePacket(packet)
+	return unit.lastMoveTime != before
+}
+
+func TestMovementOpcodesTrackFlagsAndFallDamage(t *testing.T) {
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	player := newThreatTestUnit("player", 0)
+	player.SetPosition(0, 0, 40)
+	player.SetWorld(world)
+	session := NewWorldSession(1, "jumper", nil, world)
+	session.SetPlayer(player)
+
+	mi := MovementInfo{flags: MOVEMENTFLAG_FORWARD, x: 1, z: 40}
+	if !sendTestMovement(session, player, MSG_MOVE_START_FORWARD, mi) || !player.IsMoving() {
+		t.Fatalf("start forward should set the moving flag")
+	}
+
+	// 跳下悬崖，从40码落到10码
+	mi = MovementInfo{flags: MOVEMENTFLAG_FORWARD | MOVEMENTFLAG_FALLING, x: 2, z: 40, jump: MovementJump{zspeed: -7.9}}
+	sendTestMovement(session, player, MSG_MOVE_JUMP, mi)
+	if !player.IsFalling() {
+		t.Fatalf("jump should set the falling flag")
+	}
+	mi = MovementInfo{flags: MOVEMENTFLAG_FALLING, x: 3, z: 25, fallTime: 1500}
+	sendTestMovement(session, player, MSG_MOVE_HEARTBEAT, mi)
+	mi = MovementInfo{x: 3, z: 10}
+	sendTestMovement(session, player, MSG_MOVE_FALL_LAND, mi)
+	if player.GetHealth() != 703 || player.IsFalling() || player.IsMoving() {
+		t.Fatalf("landing after a 30 yard fall should deal 297 damage, health %d", player.GetHealth())
+	}
+
+	mi = MovementInfo{flags: MOVEMENTFLAG_SWIMMING, x: 3, z: 10, pitch: 0.5}
+	sendTestMovement(session, player, MSG_MOVE_START_SWIM, mi)
+	if !player.IsSwimming() || player.GetMovementInfo().pitch != 0.5 || player.GetSpeed() != BASE_SWIM_SPEED {
+		t.Fatalf("start swim should set the swimming flag and pitch")
+	}
+
+	// 不能移动其他单位
+	other := newThreatTestUnit("other", 0)
+	packet := NewWorldPacket(MSG_MOVE_STOP)
+	packet.WriteUint64(other.GetGUID())
+	packet.WriteMovementInfo(&MovementInfo{x: 4})
+	session.handlePacket(packet)
+	if player.GetX() != 3 || other.GetX() != 0 {
+		t.Fatalf("movement for another unit's guid should be ignored")
+	}
+}
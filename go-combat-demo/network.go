@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,19 +20,49 @@ const (
 	CMSG_CAST_SPELL         = 0x12E // 施放法术
 	CMSG_CANCEL_CAST        = 0x12F // 取消施法
 	CMSG_CANCEL_CHANNELLING = 0x130 // 取消引导
-	CMSG_MOVE_START_FORWARD = 0x0B1 // 开始前进
-	CMSG_MOVE_STOP          = 0x0B7 // 停止移动
 	CMSG_KEEP_ALIVE         = 0x406 // 保持连接
-	CMSG_DAMAGE_TAKEN       = 0x200 // 自定义：客户端报告受到伤害
+	CMSG_MESSAGECHAT        = 0x095 // 聊天消息(含GM命令)
+	CMSG_DAMAGE_TAKEN       = 0x200 // 自定义：旧版客户端上报伤害，服务器不再信任，只用于标记作弊
+	CMSG_TOGGLE_PVP         = 0x253 // 切换PvP标记
+	CMSG_DUEL_ACCEPTED      = 0x16C // 接受决斗
+	CMSG_DUEL_CANCELLED     = 0x16D // 拒绝、取消决斗或认输
+
+	// 死亡和复活操作码 - 基于AzerothCore的死亡系统
+	CMSG_REPOP_REQUEST          = 0x15A // 释放灵魂
+	CMSG_RESURRECT_RESPONSE     = 0x15C // 接受或拒绝复活
+	CMSG_RECLAIM_CORPSE         = 0x1D2 // 找回尸体
+	CMSG_SPIRIT_HEALER_ACTIVATE = 0x21C // 灵魂医者复活
+
+	// 拾取操作码 - 基于AzerothCore的拾取系统
+	CMSG_AUTOSTORE_LOOT_ITEM = 0x108 // 拾取物品
+	CMSG_LOOT                = 0x15D // 打开拾取窗口
+	CMSG_LOOT_MONEY          = 0x15E // 拾取金钱
+	CMSG_LOOT_RELEASE        = 0x15F // 关闭拾取窗口
+	CMSG_LOOT_ROLL           = 0x2A0 // 掷骰选择
+	CMSG_LOOT_MASTER_GIVE    = 0x2A3 // 队长分配
+
+	// 队伍操作码 - 基于AzerothCore的Group系统
+	CMSG_GROUP_INVITE           = 0x06E // 邀请玩家
+	CMSG_GROUP_ACCEPT           = 0x072 // 接受邀请
+	CMSG_GROUP_DECLINE          = 0x073 // 拒绝邀请
+	CMSG_GROUP_UNINVITE_GUID    = 0x076 // 踢出成员
+	CMSG_GROUP_SET_LEADER       = 0x078 // 转移队长
+	CMSG_LOOT_METHOD            = 0x07A // 设置拾取方式
+	CMSG_GROUP_DISBAND          = 0x07B // 离开队伍
+	CMSG_GROUP_CHANGE_SUB_GROUP = 0x27E // 调整团队小组
+	CMSG_GROUP_RAID_CONVERT     = 0x28E // 转换为团队
 
 	// 服务器到客户端的操作码 (SMSG)
 	SMSG_ATTACKSTART              = 0x143 // 攻击开始
 	SMSG_ATTACKSTOP               = 0x144 // 攻击停止
 	SMSG_ATTACKERSTATEUPDATE      = 0x14A // 攻击者状态更新
+	SMSG_MESSAGECHAT              = 0x096 // 聊天消息
 	SMSG_SPELL_START              = 0x131 // 法术开始
 	SMSG_SPELLGO                  = 0x132 // 法术施放
 	SMSG_SPELL_FAILURE            = 0x133 // 法术失败
 	SMSG_SPELL_COOLDOWN           = 0x134 // 法术冷却
+	MSG_CHANNEL_UPDATE            = 0x13A // 引导时间更新
+	SMSG_SPELL_DELAYED            = 0x1E2 // 施法推迟
 	SMSG_AURA_UPDATE              = 0x495 // 光环更新
 	SMSG_UPDATE_OBJECT            = 0x0A9 // 对象更新
 	SMSG_POWER_UPDATE             = 0x480 // 能量更新 - 基于AzerothCore
@@ -39,8 +70,94 @@ const (
 	SMSG_SPELL_HEAL_LOG           = 0x150 // 治疗日志
 	SMSG_SPELL_ENERGIZE_LOG       = 0x151 // 能量恢复日志
 	SMSG_COMPRESSED_UPDATE_OBJECT = 0x1F6 // 压缩的对象更新
+	SMSG_HIGHEST_THREAT_UPDATE    = 0x482 // 最高仇恨目标变化
+	SMSG_THREAT_UPDATE            = 0x483 // 仇恨表更新
+	SMSG_THREAT_REMOVE            = 0x484 // 从仇恨表移除
+	SMSG_THREAT_CLEAR             = 0x485 // 清空仇恨表
+	SMSG_SPELLLOGMISS             = 0x24B // 法术未命中
+	SMSG_ENVIRONMENTALDAMAGELOG   = 0x1FC // 环境伤害日志
+	SMSG_MONSTER_MOVE             = 0x0DD // 服务器驱动的样条移动，客户端据此插值
+	SMSG_GROUP_INVITE             = 0x06F // 收到队伍邀请
+	SMSG_GROUP_DECLINE            = 0x074 // 邀请被拒绝
+	SMSG_GROUP_UNINVITE           = 0x077 // 被移出队伍
+	SMSG_GROUP_SET_LEADER         = 0x079 // 队长变更
+	SMSG_GROUP_DESTROYED          = 0x07C // 队伍解散
+	SMSG_GROUP_LIST               = 0x07D // 队伍成员列表
+	SMSG_PARTY_MEMBER_STATS       = 0x07E // 队友状态(生命、能量、位置)
+	SMSG_PARTY_COMMAND_RESULT     = 0x07F // 队伍操作结果
+	SMSG_LOG_XPGAIN               = 0x1D0 // 获得经验
+	SMSG_LEVELUP_INFO             = 0x1D4 // 升级
+	SMSG_DUEL_REQUESTED           = 0x167 // 收到决斗请求
+	SMSG_DUEL_OUTOFBOUNDS         = 0x168 // 离开决斗区域
+	SMSG_DUEL_INBOUNDS            = 0x169 // 回到决斗区域
+	SMSG_DUEL_COMPLETE            = 0x16A // 决斗结束
+	SMSG_DUEL_WINNER              = 0x16B // 决斗胜负公告
+	SMSG_DUEL_COUNTDOWN           = 0x2B7 // 决斗倒计时
+	SMSG_RESURRECT_REQUEST        = 0x15B // 收到复活请求
+	SMSG_CORPSE_RECLAIM_DELAY     = 0x269 // 还需等待多久才能找回尸体
+	SMSG_DEATH_RELEASE_LOC        = 0x378 // 灵魂被传送到的墓地
+	SMSG_LOOT_RESPONSE            = 0x160 // 拾取窗口内容
+	SMSG_LOOT_RELEASE_RESPONSE    = 0x161 // 拾取窗口已关闭
+	SMSG_LOOT_REMOVED             = 0x162 // 物品已被拾取
+	SMSG_LOOT_MONEY_NOTIFY        = 0x163 // 分到的金钱
+	SMSG_LOOT_CLEAR_MONEY         = 0x165 // 金钱已被拾取
+	SMSG_ITEM_PUSH_RESULT         = 0x166 // 获得物品
+	SMSG_LOOT_ALL_PASSED          = 0x29E // 所有人都放弃了掷骰
+	SMSG_LOOT_ROLL_WON            = 0x29F // 掷骰结果
+	SMSG_LOOT_START_ROLL          = 0x2A1 // 开始掷骰
+	SMSG_LOOT_ROLL                = 0x2A2 // 成员的掷骰选择
+	SMSG_LOOT_MASTER_LIST         = 0x2A4 // 可以分配的成员
+
+	// 移动操作码 (MSG) - 客户端上报，服务器转发给附近玩家
+	MSG_MOVE_START_FORWARD      = 0x0B5 // 开始前进
+	MSG_MOVE_START_BACKWARD     = 0x0B6 // 开始后退
+	MSG_MOVE_STOP               = 0x0B7 // 停止移动
+	MSG_MOVE_START_STRAFE_LEFT  = 0x0B8 // 开始左平移
+	MSG_MOVE_START_STRAFE_RIGHT = 0x0B9 // 开始右平移
+	MSG_MOVE_STOP_STRAFE        = 0x0BA // 停止平移
+	MSG_MOVE_JUMP               = 0x0BB // 跳跃
+	MSG_MOVE_START_TURN_LEFT    = 0x0BC // 开始左转
+	MSG_MOVE_START_TURN_RIGHT   = 0x0BD // 开始右转
+	MSG_MOVE_STOP_TURN          = 0x0BE // 停止转身
+	MSG_MOVE_START_PITCH_UP     = 0x0BF // 开始抬头
+	MSG_MOVE_START_PITCH_DOWN   = 0x0C0 // 开始低头
+	MSG_MOVE_STOP_PITCH         = 0x0C1 // 停止俯仰
+	MSG_MOVE_SET_RUN_MODE       = 0x0C2 // 切换为奔跑
+	MSG_MOVE_SET_WALK_MODE      = 0x0C3 // 切换为行走
+	MSG_MOVE_TELEPORT_ACK       = 0x0C7 // 瞬移确认(服务器拉回客户端位置)
+	MSG_MOVE_FALL_LAND          = 0x0C9 // 落地
+	MSG_MOVE_START_SWIM         = 0x0CA // 开始游泳
+	MSG_MOVE_STOP_SWIM          = 0x0CB // 停止游泳
+	MSG_MOVE_SET_FACING         = 0x0DA // 设置朝向
+	MSG_MOVE_SET_PITCH          = 0x0DB // 设置俯仰角
+	MSG_MOVE_HEARTBEAT          = 0x0EE // 移动心跳
 )
 
+// movementOpcodeNames 移动操作码名称，这些操作码共用MovementInfo格式
+var movementOpcodeNames = map[uint16]string{
+	MSG_MOVE_START_FORWARD:      "MSG_MOVE_START_FORWARD",
+	MSG_MOVE_START_BACKWARD:     "MSG_MOVE_START_BACKWARD",
+	MSG_MOVE_STOP:               "MSG_MOVE_STOP",
+	MSG_MOVE_START_STRAFE_LEFT:  "MSG_MOVE_START_STRAFE_LEFT",
+	MSG_MOVE_START_STRAFE_RIGHT: "MSG_MOVE_START_STRAFE_RIGHT",
+	MSG_MOVE_STOP_STRAFE:        "MSG_MOVE_STOP_STRAFE",
+	MSG_MOVE_JUMP:               "MSG_MOVE_JUMP",
+	MSG_MOVE_START_TURN_LEFT:    "MSG_MOVE_START_TURN_LEFT",
+	MSG_MOVE_START_TURN_RIGHT:   "MSG_MOVE_START_TURN_RIGHT",
+	MSG_MOVE_STOP_TURN:          "MSG_MOVE_STOP_TURN",
+	MSG_MOVE_START_PITCH_UP:     "MSG_MOVE_START_PITCH_UP",
+	MSG_MOVE_START_PITCH_DOWN:   "MSG_MOVE_START_PITCH_DOWN",
+	MSG_MOVE_STOP_PITCH:         "MSG_MOVE_STOP_PITCH",
+	MSG_MOVE_SET_RUN_MODE:       "MSG_MOVE_SET_RUN_MODE",
+	MSG_MOVE_SET_WALK_MODE:      "MSG_MOVE_SET_WALK_MODE",
+	MSG_MOVE_FALL_LAND:          "MSG_MOVE_FALL_LAND",
+	MSG_MOVE_START_SWIM:         "MSG_MOVE_START_SWIM",
+	MSG_MOVE_STOP_SWIM:          "MSG_MOVE_STOP_SWIM",
+	MSG_MOVE_SET_FACING:         "MSG_MOVE_SET_FACING",
+	MSG_MOVE_SET_PITCH:          "MSG_MOVE_SET_PITCH",
+	MSG_MOVE_HEARTBEAT:          "MSG_MOVE_HEARTBEAT",
+}
+
 // 数据包处理类型 - 基于AzerothCore的PacketProcessing
 const (
 	PROCESS_INPLACE      = 0 // 立即处理
@@ -105,6 +222,14 @@ func (wp *WorldPacket) WriteUint8(val uint8) {
 	wp.wpos += 1
 }
 
+// WriteUint16 写入16位整数
+func (wp *WorldPacket) WriteUint16(val uint16) {
+	buf := make([]byte, 2)
+	binary.LittleEndian.PutUint16(buf, val)
+	wp.data = append(wp.data, buf...)
+	wp.wpos += 2
+}
+
 // WriteUint64 写入64位整数
 func (wp *WorldPacket) WriteUint64(val uint64) {
 	buf := make([]byte, 8)
@@ -128,6 +253,26 @@ func (wp *WorldPacket) WriteFloat32(val float32) {
 	wp.wpos += 4
 }
 
+// ReadUint8 读取8位整数
+func (wp *WorldPacket) ReadUint8() uint8 {
+	if wp.rpos+1 > len(wp.data) {
+		return 0
+	}
+	val := wp.data[wp.rpos]
+	wp.rpos++
+	return val
+}
+
+// ReadUint16 读取16位整数
+func (wp *WorldPacket) ReadUint16() uint16 {
+	if wp.rpos+2 > len(wp.data) {
+		return 0
+	}
+	val := binary.LittleEndian.Uint16(wp.data[wp.rpos:])
+	wp.rpos += 2
+	return val
+}
+
 // ReadUint32 读取32位整数
 func (wp *WorldPacket) ReadUint32() uint32 {
 	if wp.rpos+4 > len(wp.data) {
@@ -138,6 +283,20 @@ func (wp *WorldPacket) ReadUint32() uint32 {
 	return val
 }
 
+// ReadString 读取以0结尾的字符串
+func (wp *WorldPacket) ReadString() string {
+	start := wp.rpos
+	for wp.rpos < len(wp.data) {
+		if wp.data[wp.rpos] == 0 {
+			str := string(wp.data[start:wp.rpos])
+			wp.rpos++
+			return str
+		}
+		wp.rpos++
+	}
+	return string(wp.data[start:])
+}
+
 // ReadFloat32 读取32位浮点数
 func (wp *WorldPacket) ReadFloat32() float32 {
 	if wp.rpos+4 > len(wp.data) {
@@ -329,28 +488,94 @@ func (ot *OpcodeTable) Initialize() {
 		handler:    (*WorldSession).HandleKeepAliveOpcode,
 	})
 
-	ot.RegisterHandler(CMSG_DAMAGE_TAKEN, &ClientOpcodeHandler{
-		name:       "CMSG_DAMAGE_TAKEN",
+	ot.RegisterHandler(CMSG_MESSAGECHAT, &ClientOpcodeHandler{
+		name:       "CMSG_MESSAGECHAT",
 		status:     STATUS_LOGGEDIN,
-		processing: PROCESS_THREADSAFE,
-		handler:    (*WorldSession).HandleDamageTakenOpcode,
+		processing: PROCESS_THREADUNSAFE,
+		handler:    (*WorldSession).HandleMessageChatOpcode,
 	})
 
-	// 注册移动相关操作码 - 基于AzerothCore的移动系统
-	ot.RegisterHandler(CMSG_MOVE_START_FORWARD, &ClientOpcodeHandler{
-		name:       "CMSG_MOVE_START_FORWARD",
+	ot.RegisterHandler(CMSG_DAMAGE_TAKEN, &ClientOpcodeHandler{
+		name:       "CMSG_DAMAGE_TAKEN",
 		status:     STATUS_LOGGEDIN,
 		processing: PROCESS_THREADSAFE,
-		handler:    (*WorldSession).HandleMoveStartForwardOpcode,
+		handler:    (*WorldSession).HandleClientHealthChangeOpcode,
 	})
 
-	ot.RegisterHandler(CMSG_MOVE_STOP, &ClientOpcodeHandler{
-		name:       "CMSG_MOVE_STOP",
+	ot.RegisterHandler(CMSG_TOGGLE_PVP, &ClientOpcodeHandler{
+		name:       "CMSG_TOGGLE_PVP",
 		status:     STATUS_LOGGEDIN,
-		processing: PROCESS_THREADSAFE,
-		handler:    (*WorldSession).HandleMoveStopOpcode,
+		processing: PROCESS_THREADUNSAFE,
+		handler:    (*WorldSession).HandleTogglePvPOpcode,
 	})
 
+	// 注册决斗相关操作码 - 决斗状态由世界线程修改
+	duelHandlers := map[uint16]*ClientOpcodeHandler{
+		CMSG_DUEL_ACCEPTED:  {name: "CMSG_DUEL_ACCEPTED", handler: (*WorldSession).HandleDuelAcceptedOpcode},
+		CMSG_DUEL_CANCELLED: {name: "CMSG_DUEL_CANCELLED", handler: (*WorldSession).HandleDuelCancelledOpcode},
+	}
+	for opcode, handler := range duelHandlers {
+		handler.status = STATUS_LOGGEDIN
+		handler.processing = PROCESS_THREADUNSAFE
+		ot.RegisterHandler(opcode, handler)
+	}
+
+	// 注册死亡和复活相关操作码
+	deathHandlers := map[uint16]*ClientOpcodeHandler{
+		CMSG_REPOP_REQUEST:          {name: "CMSG_REPOP_REQUEST", handler: (*WorldSession).HandleRepopRequestOpcode},
+		CMSG_RESURRECT_RESPONSE:     {name: "CMSG_RESURRECT_RESPONSE", handler: (*WorldSession).HandleResurrectResponseOpcode},
+		CMSG_RECLAIM_CORPSE:         {name: "CMSG_RECLAIM_CORPSE", handler: (*WorldSession).HandleReclaimCorpseOpcode},
+		CMSG_SPIRIT_HEALER_ACTIVATE: {name: "CMSG_SPIRIT_HEALER_ACTIVATE", handler: (*WorldSession).HandleSpiritHealerActivateOpcode},
+	}
+	for opcode, handler := range deathHandlers {
+		handler.status = STATUS_LOGGEDIN
+		handler.processing = PROCESS_THREADUNSAFE
+		ot.RegisterHandler(opcode, handler)
+	}
+
+	// 注册拾取相关操作码 - 拾取和掷骰状态由世界线程修改
+	lootHandlers := map[uint16]*ClientOpcodeHandler{
+		CMSG_LOOT:                {name: "CMSG_LOOT", handler: (*WorldSession).HandleLootOpcode},
+		CMSG_AUTOSTORE_LOOT_ITEM: {name: "CMSG_AUTOSTORE_LOOT_ITEM", handler: (*WorldSession).HandleAutostoreLootItemOpcode},
+		CMSG_LOOT_MONEY:          {name: "CMSG_LOOT_MONEY", handler: (*WorldSession).HandleLootMoneyOpcode},
+		CMSG_LOOT_RELEASE:        {name: "CMSG_LOOT_RELEASE", handler: (*WorldSession).HandleLootReleaseOpcode},
+		CMSG_LOOT_ROLL:           {name: "CMSG_LOOT_ROLL", handler: (*WorldSession).HandleLootRollOpcode},
+		CMSG_LOOT_MASTER_GIVE:    {name: "CMSG_LOOT_MASTER_GIVE", handler: (*WorldSession).HandleLootMasterGiveOpcode},
+	}
+	for opcode, handler := range lootHandlers {
+		handler.status = STATUS_LOGGEDIN
+		handler.processing = PROCESS_THREADUNSAFE
+		ot.RegisterHandler(opcode, handler)
+	}
+
+	// 注册队伍相关操作码 - 队伍状态由世界线程修改
+	groupHandlers := map[uint16]*ClientOpcodeHandler{
+		CMSG_GROUP_INVITE:           {name: "CMSG_GROUP_INVITE", handler: (*WorldSession).HandleGroupInviteOpcode},
+		CMSG_GROUP_ACCEPT:           {name: "CMSG_GROUP_ACCEPT", handler: (*WorldSession).HandleGroupAcceptOpcode},
+		CMSG_GROUP_DECLINE:          {name: "CMSG_GROUP_DECLINE", handler: (*WorldSession).HandleGroupDeclineOpcode},
+		CMSG_GROUP_UNINVITE_GUID:    {name: "CMSG_GROUP_UNINVITE_GUID", handler: (*WorldSession).HandleGroupUninviteGuidOpcode},
+		CMSG_GROUP_SET_LEADER:       {name: "CMSG_GROUP_SET_LEADER", handler: (*WorldSession).HandleGroupSetLeaderOpcode},
+		CMSG_LOOT_METHOD:            {name: "CMSG_LOOT_METHOD", handler: (*WorldSession).HandleLootMethodOpcode},
+		CMSG_GROUP_DISBAND:          {name: "CMSG_GROUP_DISBAND", handler: (*WorldSession).HandleGroupDisbandOpcode},
+		CMSG_GROUP_CHANGE_SUB_GROUP: {name: "CMSG_GROUP_CHANGE_SUB_GROUP", handler: (*WorldSession).HandleGroupChangeSubGroupOpcode},
+		CMSG_GROUP_RAID_CONVERT:     {name: "CMSG_GROUP_RAID_CONVERT", handler: (*WorldSession).HandleGroupRaidConvertOpcode},
+	}
+	for opcode, handler := range groupHandlers {
+		handler.status = STATUS_LOGGEDIN
+		handler.processing = PROCESS_THREADUNSAFE
+		ot.RegisterHandler(opcode, handler)
+	}
+
+	// 注册移动相关操作码 - 基于AzerothCore的移动系统，统一由HandleMovementOpcodes处理
+	for opcode, name := range movementOpcodeNames {
+		ot.RegisterHandler(opcode, &ClientOpcodeHandler{
+			name:       name,
+			status:     STATUS_LOGGEDIN,
+			processing: PROCESS_THREADSAFE,
+			handler:    (*WorldSession).HandleMovementOpcodes,
+		})
+	}
+
 }
 
 // RegisterHandler 注册处理器
@@ -540,10 +765,12 @@ func (ws *WorldSocket) IsOpen() bool {
 
 // WorldSession - 基于AzerothCore的WorldSession
 type WorldSession struct {
-	id          uint32
-	accountName string
-	player      IUnit
-	socket      *WorldSocket
+	id           uint32
+	accountName  string
+	security     uint8            // 账号权限等级(SEC_*)
+	cheatReports map[uint8]uint32 // 各类作弊嫌疑次数
+	player       IUnit
+	socket       *WorldSocket
 
 	opcodeTable *OpcodeTable
 	lastUpdate  time.Time
@@ -596,6 +823,20 @@ func (ws *WorldSession) GetPlayer() IUnit {
 	return ws.player
 }
 
+// GetSecurity 获取账号权限等级
+func (ws *WorldSession) GetSecurity() uint8 {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+	return ws.security
+}
+
+// SetSecurity 设置账号权限等级
+func (ws *WorldSession) SetSecurity(security uint8) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	ws.security = security
+}
+
 // SetPlayer 设置玩家
 func (ws *WorldSession) SetPlayer(player IUnit) {
 	ws.mutex.Lock()
@@ -796,8 +1037,57 @@ func (ws *WorldSession) GetPlayerInfo() string {
 	return fmt.Sprintf("Account: %s (ID: %d)", ws.accountName, ws.id)
 }
 
+// LoginPlayer 角色进入世界 - 恢复存档中的冷却并同步给客户端
+func (ws *WorldSession) LoginPlayer(player *Player) {
+	ws.SetPlayer(player)
+
+	if GlobalCharacterDatabase == nil {
+		return
+	}
+	data, err := GlobalCharacterDatabase.LoadCharacter(player.GetGUID())
+	if err != nil {
+		fmt.Printf("加载角色 %s 失败: %v\n", player.GetName(), err)
+		return
+	}
+	if data == nil {
+		return
+	}
+
+	player.LoadFromCharacterData(data)
+	if cooldowns := player.GetSpellCooldowns(); len(cooldowns) > 0 {
+		ws.SendSpellCooldown(player, cooldowns)
+		fmt.Printf("角色 %s 恢复了 %d 个法术冷却\n", player.GetName(), len(cooldowns))
+	}
+}
+
+// LogoutPlayer 角色离开世界 - 基于AzerothCore的WorldSession::LogoutPlayer，保存角色数据
+func (ws *WorldSession) LogoutPlayer() {
+	player, ok := ws.GetPlayer().(*Player)
+	if !ok {
+		return
+	}
+
+	// 下线视为逃离决斗
+	if player.duel != nil {
+		player.DuelComplete(DUEL_FLED)
+	}
+	if player.lootGuid != 0 {
+		player.ReleaseLoot(player.lootGuid)
+	}
+
+	if GlobalCharacterDatabase == nil {
+		return
+	}
+
+	if err := GlobalCharacterDatabase.SaveCharacter(player.BuildCharacterData()); err != nil {
+		fmt.Printf("%v\n", err)
+	}
+}
+
 // Close 关闭会话
 func (ws *WorldSession) Close() {
+	ws.LogoutPlayer()
+
 	if ws.socket != nil {
 		ws.socket.Close()
 	}
@@ -927,7 +1217,7 @@ func (ws *WorldSession) HandleCastSpellOpcode(packet *WorldPacket) {
 	spellInfo := GlobalSpellManager.GetSpell(spellId)
 	if spellInfo == nil {
 		fmt.Printf("玩家 %s 尝试施放未知法术 %d\n", ws.GetPlayerInfo(), spellId)
-		ws.SendSpellFailure(player, spellId, "未知法术")
+		ws.SendSpellFailure(player, spellId, SPELL_FAILED_NOT_KNOWN)
 		return
 	}
 
@@ -942,7 +1232,7 @@ func (ws *WorldSession) HandleCastSpellOpcode(packet *WorldPacket) {
 		target = ws.world.GetUnit(targetGuid)
 		if target == nil {
 			fmt.Printf("找不到目标 GUID: %d\n", targetGuid)
-			ws.SendSpellFailure(player, spellId, "无效目标")
+			ws.SendSpellFailure(player, spellId, SPELL_FAILED_BAD_TARGETS)
 			return
 		}
 	}
@@ -957,128 +1247,63 @@ func (ws *WorldSession) HandleCancelCastOpcode(packet *WorldPacket) {
 
 	fmt.Printf("玩家 %s 取消施法 %d\n", ws.GetPlayerInfo(), spellId)
 
-	player := ws.GetPlayer()
-	if player != nil {
-		if unit, ok := player.(*Unit); ok {
-			unit.InterruptSpell(CURRENT_GENERIC_SPELL)
-		}
-	}
-
+	ws.cancelCurrentSpell(CURRENT_GENERIC_SPELL, spellId)
 }
 
 // HandleCancelChannellingOpcode 处理取消引导操作码
 func (ws *WorldSession) HandleCancelChannellingOpcode(packet *WorldPacket) {
-	fmt.Printf("玩家 %s 取消引导\n", ws.GetPlayerInfo())
-
-	player := ws.GetPlayer()
-	if player != nil {
-		if unit, ok := player.(*Unit); ok {
-			unit.InterruptSpell(CURRENT_CHANNELED_SPELL)
-		}
-	}
+	spellId := packet.ReadUint32()
 
-}
+	fmt.Printf("玩家 %s 取消引导\n", ws.GetPlayerInfo())
 
-// HandleKeepAliveOpcode 处理保持连接操作码
-func (ws *WorldSession) HandleKeepAliveOpcode(packet *WorldPacket) {
-	ws.ResetTimeOutTime(true)
+	ws.cancelCurrentSpell(CURRENT_CHANNELED_SPELL, spellId)
 }
 
-// HandleDamageTakenOpcode 处理受到伤害操作码
-func (ws *WorldSession) HandleDamageTakenOpcode(packet *WorldPacket) {
-	targetGuid := packet.ReadUint64()
-	damage := packet.ReadUint32()
-
-	player := ws.GetPlayer()
-	if player == nil || player.GetGUID() != targetGuid {
+// cancelCurrentSpell 取消当前法术 - 与打断共用Unit::InterruptSpell流程，spellId为0时不校验法术
+func (ws *WorldSession) cancelCurrentSpell(spellType int, spellId uint32) {
+	unit := getBaseUnit(ws.GetPlayer())
+	if unit == nil {
 		return
 	}
 
-	fmt.Printf("玩家 %s 受到伤害: %d\n", ws.GetPlayerInfo(), damage)
-
-	// 处理伤害
-	oldHealth := player.GetHealth()
-	newHealth := oldHealth
-	if oldHealth > damage {
-		newHealth = oldHealth - damage
-	} else {
-		newHealth = 1 // 保持至少1点血
-	}
-
-	player.SetHealth(newHealth)
-
-	// 发送血量更新给客户端
-	ws.SendHealthUpdate(player, newHealth, player.GetMaxHealth())
-
-	// 广播血量更新给其他玩家
-	ws.world.BroadcastHealthUpdate(player, oldHealth, newHealth)
-
-	// 添加批量更新 - 基于AzerothCore的血量同步
-	if unit, ok := player.(*Unit); ok {
-		unit.AddBatchUpdateForFullState() // 血量变化需要完整状态更新
+	spell := unit.GetCurrentSpell(spellType)
+	if spell == nil {
+		return
 	}
-
-	// 使用oldHealth避免编译警告
-	_ = oldHealth
-
-}
-
-// HandleMoveStartForwardOpcode 处理开始前进操作码 - 基于AzerothCore的移动同步
-func (ws *WorldSession) HandleMoveStartForwardOpcode(packet *WorldPacket) {
-	// 读取移动数据
-	x := packet.ReadFloat32()
-	y := packet.ReadFloat32()
-	z := packet.ReadFloat32()
-	orientation := packet.ReadFloat32()
-
-	player := ws.GetPlayer()
-	if player == nil {
+	if spellId != 0 && spell.info.ID != spellId {
+		fmt.Printf("取消的法术 %d 与当前法术 %d 不一致，忽略\n", spellId, spell.info.ID)
 		return
 	}
 
-	fmt.Printf("玩家 %s 开始前进到位置: (%.2f, %.2f, %.2f), 朝向: %.2f\n",
-		ws.GetPlayerInfo(), x, y, z, orientation)
-
-	// 更新玩家位置
-	if unit, ok := player.(*Unit); ok {
-		unit.SetPosition(x, y, z)
-		unit.orientation = orientation
-
-		// 添加批量更新 - 基于AzerothCore的移动同步
-		unit.AddBatchUpdateForMovement() // 移动需要位置更新
+	unit.InterruptSpell(spellType)
+}
 
-		fmt.Printf("[BatchUpdate] 移动更新: %s 位置(%.2f, %.2f, %.2f)\n",
-			unit.GetName(), x, y, z)
-	}
+// HandleKeepAliveOpcode 处理保持连接操作码
+func (ws *WorldSession) HandleKeepAliveOpcode(packet *WorldPacket) {
+	ws.ResetTimeOutTime(true)
 }
 
-// HandleMoveStopOpcode 处理停止移动操作码 - 基于AzerothCore的移动同步
-func (ws *WorldSession) HandleMoveStopOpcode(packet *WorldPacket) {
-	// 读取停止位置数据
-	x := packet.ReadFloat32()
-	y := packet.ReadFloat32()
-	z := packet.ReadFloat32()
-	orientation := packet.ReadFloat32()
+// HandleMessageChatOpcode 处理聊天消息 - 以"."开头的消息作为GM命令处理
+func (ws *WorldSession) HandleMessageChatOpcode(packet *WorldPacket) {
+	chatType := packet.ReadUint32()
+	packet.ReadUint32() // language
+	message := packet.ReadString()
 
-	player := ws.GetPlayer()
-	if player == nil {
+	if strings.HasPrefix(message, ".") {
+		ws.HandleChatCommand(message[1:])
 		return
 	}
 
-	fmt.Printf("玩家 %s 停止移动在位置: (%.2f, %.2f, %.2f), 朝向: %.2f\n",
-		ws.GetPlayerInfo(), x, y, z, orientation)
-
-	// 更新玩家位置
-	if unit, ok := player.(*Unit); ok {
-		unit.SetPosition(x, y, z)
-		unit.orientation = orientation
-
-		// 添加批量更新 - 基于AzerothCore的移动同步
-		unit.AddBatchUpdateForMovement() // 停止移动也需要位置更新
+	fmt.Printf("[聊天] %s (类型%d): %s\n", ws.GetPlayerInfo(), chatType, message)
+}
 
-		fmt.Printf("[BatchUpdate] 停止移动更新: %s 位置(%.2f, %.2f, %.2f)\n",
-			unit.GetName(), x, y, z)
-	}
+// SendSysMessage 发送系统消息
+func (ws *WorldSession) SendSysMessage(message string) {
+	packet := NewWorldPacket(SMSG_MESSAGECHAT)
+	packet.WriteUint8(CHAT_MSG_SYSTEM)
+	packet.WriteUint32(0) // language
+	packet.WriteString(message)
+	ws.SendPacket(packet)
 }
 
 // === 服务器数据包发送方法 ===
@@ -1113,7 +1338,7 @@ func (ws *WorldSession) SendAttackerStateUpdate(attacker, victim IUnit, damage u
 }
 
 // SendSpellGo 发送法术施放
-func (ws *WorldSession) SendSpellGo(caster IUnit, spellId uint32, targets []IUnit) {
+func (ws *WorldSession) SendSpellGo(caster IUnit, spellId uint32, targets []IUnit, misses []SpellMissTarget) {
 	packet := NewWorldPacket(SMSG_SPELLGO)
 	packet.WriteUint64(caster.GetGUID())
 	packet.WriteUint32(spellId)
@@ -1121,6 +1346,11 @@ func (ws *WorldSession) SendSpellGo(caster IUnit, spellId uint32, targets []IUni
 	for _, target := range targets {
 		packet.WriteUint64(target.GetGUID())
 	}
+	packet.WriteUint32(uint32(len(misses)))
+	for _, miss := range misses {
+		packet.WriteUint64(miss.target.GetGUID())
+		packet.WriteUint8(miss.missInfo)
+	}
 	ws.SendPacket(packet)
 }
 
@@ -1137,21 +1367,25 @@ func (ws *WorldSession) SendSpellStart(caster IUnit, spellId uint32, targets []I
 	ws.SendPacket(packet)
 }
 
-// SendSpellFailure 发送法术失败
-func (ws *WorldSession) SendSpellFailure(caster IUnit, spellId uint32, reason string) {
+// SendSpellFailure 发送法术失败 - reason为SpellCastResult
+func (ws *WorldSession) SendSpellFailure(caster IUnit, spellId uint32, reason uint8) {
 	packet := NewWorldPacket(SMSG_SPELL_FAILURE)
 	packet.WriteUint64(caster.GetGUID())
 	packet.WriteUint32(spellId)
-	packet.WriteString(reason)
+	packet.WriteUint8(reason)
 	ws.SendPacket(packet)
 }
 
-// SendSpellCooldown 发送法术冷却
-func (ws *WorldSession) SendSpellCooldown(caster IUnit, spellId uint32, cooldown time.Duration) {
+// SendSpellCooldown 发送法术冷却 - 一个数据包携带本次触发的所有冷却
+func (ws *WorldSession) SendSpellCooldown(caster IUnit, cooldowns []SpellCooldownEntry) {
 	packet := NewWorldPacket(SMSG_SPELL_COOLDOWN)
 	packet.WriteUint64(caster.GetGUID())
-	packet.WriteUint32(spellId)
-	packet.WriteUint32(uint32(cooldown.Milliseconds()))
+	packet.WriteUint8(0) // flags
+	packet.WriteUint32(uint32(len(cooldowns)))
+	for _, cooldown := range cooldowns {
+		packet.WriteUint32(cooldown.SpellId)
+		packet.WriteUint32(uint32(cooldown.Cooldown.Milliseconds()))
+	}
 	ws.SendPacket(packet)
 }
 
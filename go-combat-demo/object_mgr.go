@@ -0,0 +1,770 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// 生物和副本数据文件 - 基于AzerothCore世界数据库的CSV导出，第一行为列名
+const (
+	CREATURE_TEMPLATE_PATH = "data/creature_template.csv"
+	CREATURE_SPAWN_PATH    = "data/creature.csv"
+	INSTANCE_TEMPLATE_PATH = "data/instance_template.csv"
+	INSTANCE_DOOR_PATH     = "data/instance_door.csv"
+	CREATURE_TEXT_PATH     = "data/creature_text.csv"
+	SMART_SCRIPTS_PATH     = "data/smart_scripts.csv"
+	GRAVEYARD_PATH         = "data/game_graveyard.csv"
+	ITEM_TEMPLATE_PATH     = "data/item_template.csv"
+	CREATURE_LOOT_PATH     = "data/creature_loot_template.csv"
+)
+
+// 编译时内置的数据，数据文件不存在时使用
+var (
+	//go:embed data/creature_template.csv
+	defaultCreatureTemplate []byte
+	//go:embed data/creature.csv
+	defaultCreatureSpawns []byte
+	//go:embed data/instance_template.csv
+	defaultInstanceTemplate []byte
+	//go:embed data/instance_door.csv
+	defaultInstanceDoors []byte
+	//go:embed data/creature_text.csv
+	defaultCreatureTexts []byte
+	//go:embed data/smart_scripts.csv
+	defaultSmartScripts []byte
+	//go:embed data/game_graveyard.csv
+	defaultGraveyards []byte
+	//go:embed data/item_template.csv
+	defaultItemTemplate []byte
+	//go:embed data/creature_loot_template.csv
+	defaultCreatureLoot []byte
+)
+
+// 生物模板常量
+const (
+	CREATURE_MAX_SPELLS = 4
+
+	// 简化的creature_classlevelstats：基础生命和法力随等级线性增长，再乘以模板的修正系数
+	CREATURE_BASE_HEALTH_PER_LEVEL = 50
+	CREATURE_BASE_MANA_PER_LEVEL   = 40
+)
+
+// NPC功能标志 - 基于AzerothCore的NPCFlags
+const (
+	UNIT_NPC_FLAG_SPIRITHEALER = 0x00004000 // 灵魂医者
+)
+
+// 生物职业 - 基于AzerothCore的unit_class，决定能量类型
+const (
+	UNIT_CLASS_WARRIOR = 1 // 怒气
+	UNIT_CLASS_PALADIN = 2 // 法力
+	UNIT_CLASS_ROGUE   = 4 // 能量
+	UNIT_CLASS_MAGE    = 8 // 法力
+)
+
+// CreatureTemplate 生物模板 - 基于AzerothCore的creature_template表
+type CreatureTemplate struct {
+	Entry              uint32
+	Name               string
+	MinLevel           uint8
+	MaxLevel           uint8
+	UnitClass          uint8
+	Type               uint8
+	Faction            uint32
+	HealthModifier     float32
+	ManaModifier       float32
+	DamageModifier     float32
+	MechanicImmuneMask uint32
+	AIName             string
+	ScriptName         string
+	Spells             [CREATURE_MAX_SPELLS]uint32
+	NpcFlag            uint32 // NPC功能(UNIT_NPC_FLAG_*)
+	MinGold            uint32 // 掉落金钱的范围(铜币)
+	MaxGold            uint32
+}
+
+// CreatureData 生物刷新点 - 基于AzerothCore的creature表
+// Formation把同组的小怪编成一组一起拉，Boss为副本中的首领编号
+type CreatureData struct {
+	GUID          uint32
+	Entry         uint32
+	MapId         uint32
+	X, Y, Z       float32
+	Orientation   float32
+	SpawnTimeSecs uint32
+	Formation     uint32
+	Boss          uint32
+}
+
+// CreatureText 生物的台词 - 基于AzerothCore的creature_text表，同一组有多条时随机选一条
+type CreatureText struct {
+	CreatureID uint32
+	GroupID    uint32
+	ID         uint32
+	Text       string
+	Type       uint8 // CHAT_MSG_MONSTER_SAY或CHAT_MSG_MONSTER_YELL
+}
+
+// GraveyardData 墓地 - 基于AzerothCore的game_graveyard表，释放灵魂后传送到同一地图最近的墓地
+type GraveyardData struct {
+	ID      uint32
+	MapId   uint32
+	X, Y, Z float32
+	Name    string
+}
+
+// ObjectMgr 世界数据管理器 - 基于AzerothCore的ObjectMgr，加载生物模板、刷新点和副本数据
+type ObjectMgr struct {
+	creatureTemplates map[uint32]*CreatureTemplate
+	creatureSpawns    map[uint32][]*CreatureData // 按地图分组
+	instances         map[uint32]*InstanceData
+	creatureTexts     map[uint32][]*CreatureText // 按生物模板分组
+	smartScripts      map[uint32][]*SmartScript  // 按生物模板分组，保持id顺序
+	graveyards        []*GraveyardData
+	itemTemplates     map[uint32]*ItemTemplate
+	lootTemplates     map[uint32]*LootTemplate // 按生物模板编号
+	mutex             sync.RWMutex
+}
+
+// 全局世界数据管理器
+var GlobalObjectMgr *ObjectMgr
+
+// InitObjectMgr 初始化世界数据管理器
+func InitObjectMgr() {
+	GlobalObjectMgr = &ObjectMgr{
+		creatureTemplates: make(map[uint32]*CreatureTemplate),
+		creatureSpawns:    make(map[uint32][]*CreatureData),
+		instances:         make(map[uint32]*InstanceData),
+		creatureTexts:     make(map[uint32][]*CreatureText),
+		smartScripts:      make(map[uint32][]*SmartScript),
+		itemTemplates:     make(map[uint32]*ItemTemplate),
+		lootTemplates:     make(map[uint32]*LootTemplate),
+	}
+	if err := GlobalObjectMgr.LoadAll(); err != nil {
+		fmt.Printf("加载世界数据失败: %v\n", err)
+	}
+}
+
+// LoadAll 按依赖顺序加载所有数据：模板 → 刷新点 → 副本 → 台词 → SmartAI脚本 → 墓地 → 物品 → 掉落
+func (m *ObjectMgr) LoadAll() error {
+	if err := m.LoadCreatureTemplates(CREATURE_TEMPLATE_PATH); err != nil {
+		return err
+	}
+	if err := m.LoadCreatureSpawns(CREATURE_SPAWN_PATH); err != nil {
+		return err
+	}
+	if err := m.LoadInstanceTemplates(INSTANCE_TEMPLATE_PATH, INSTANCE_DOOR_PATH); err != nil {
+		return err
+	}
+	if err := m.LoadCreatureTexts(CREATURE_TEXT_PATH); err != nil {
+		return err
+	}
+	if err := m.LoadSmartScripts(SMART_SCRIPTS_PATH); err != nil {
+		return err
+	}
+	if err := m.LoadGraveyards(GRAVEYARD_PATH); err != nil {
+		return err
+	}
+	if err := m.LoadItemTemplates(ITEM_TEMPLATE_PATH); err != nil {
+		return err
+	}
+	return m.LoadCreatureLootTemplates(CREATURE_LOOT_PATH)
+}
+
+// readDataFile 读取数据文件，文件不存在时使用内置数据
+func readDataFile(path string, fallback []byte) ([]byte, string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fallback, "内置数据", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("读取数据文件 %s 失败: %v", path, err)
+	}
+	return content, path, nil
+}
+
+// dataRow CSV中的一行，按列名取值，第一个解析错误会被记录下来
+type dataRow struct {
+	columns map[string]int
+	fields  []string
+	err     error
+}
+
+// readDataTable 解析带列名的CSV
+func readDataTable(content []byte) ([]*dataRow, error) {
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	columns := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	rows := make([]*dataRow, 0, len(records)-1)
+	for _, fields := range records[1:] {
+		rows = append(rows, &dataRow{columns: columns, fields: fields})
+	}
+	return rows, nil
+}
+
+func (r *dataRow) str(column string) string {
+	i, ok := r.columns[column]
+	if !ok {
+		if r.err == nil {
+			r.err = fmt.Errorf("缺少列 %s", column)
+		}
+		return ""
+	}
+	return r.fields[i]
+}
+
+func (r *dataRow) uint(column string, bits int) uint64 {
+	value, err := strconv.ParseUint(r.str(column), 10, bits)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("列 %s 的值 %q 无效", column, r.str(column))
+	}
+	return value
+}
+
+func (r *dataRow) uint8(column string) uint8   { return uint8(r.uint(column, 8)) }
+func (r *dataRow) uint32(column string) uint32 { return uint32(r.uint(column, 32)) }
+
+func (r *dataRow) float32(column string) float32 {
+	value, err := strconv.ParseFloat(r.str(column), 32)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("列 %s 的值 %q 无效", column, r.str(column))
+	}
+	return float32(value)
+}
+
+// LoadCreatureTemplates 加载生物模板 - 基于AzerothCore的ObjectMgr::LoadCreatureTemplates
+func (m *ObjectMgr) LoadCreatureTemplates(path string) error {
+	content, source, err := readDataFile(path, defaultCreatureTemplate)
+	if err != nil {
+		return err
+	}
+	rows, err := readDataTable(content)
+	if err != nil {
+		return fmt.Errorf("解析生物模板失败: %v", err)
+	}
+
+	templates := make(map[uint32]*CreatureTemplate, len(rows))
+	for _, row := range rows {
+		template := &CreatureTemplate{
+			Entry:              row.uint32("entry"),
+			Name:               row.str("name"),
+			MinLevel:           row.uint8("minlevel"),
+			MaxLevel:           row.uint8("maxlevel"),
+			UnitClass:          row.uint8("unit_class"),
+			Type:               row.uint8("type"),
+			Faction:            row.uint32("faction"),
+			HealthModifier:     row.float32("HealthModifier"),
+			ManaModifier:       row.float32("ManaModifier"),
+			DamageModifier:     row.float32("DamageModifier"),
+			MechanicImmuneMask: row.uint32("mechanic_immune_mask"),
+			AIName:             row.str("AIName"),
+			ScriptName:         row.str("ScriptName"),
+			NpcFlag:            row.uint32("npcflag"),
+			MinGold:            row.uint32("mingold"),
+			MaxGold:            row.uint32("maxgold"),
+		}
+		for i := range template.Spells {
+			template.Spells[i] = row.uint32(fmt.Sprintf("spell%d", i+1))
+		}
+		if row.err == nil {
+			row.err = template.Validate()
+		}
+		if row.err != nil {
+			fmt.Printf("[creature_template] 跳过无效的生物模板 %d: %v\n", template.Entry, row.err)
+			continue
+		}
+		templates[template.Entry] = template
+	}
+
+	m.mutex.Lock()
+	m.creatureTemplates = templates
+	m.mutex.Unlock()
+	fmt.Printf("从%s加载了 %d 个生物模板\n", source, len(templates))
+	return nil
+}
+
+// Validate 检查生物模板 - 基于AzerothCore的ObjectMgr::CheckCreatureTemplate
+func (t *CreatureTemplate) Validate() error {
+	if t.Entry == 0 {
+		return fmt.Errorf("模板编号不能为0")
+	}
+	if t.MinLevel == 0 || t.MinLevel > t.MaxLevel {
+		return fmt.Errorf("等级范围 %d-%d 无效", t.MinLevel, t.MaxLevel)
+	}
+	switch t.UnitClass {
+	case UNIT_CLASS_WARRIOR, UNIT_CLASS_PALADIN, UNIT_CLASS_ROGUE, UNIT_CLASS_MAGE:
+	default:
+		return fmt.Errorf("生物职业 %d 无效", t.UnitClass)
+	}
+	if t.Type == 0 || t.Type > CREATURE_TYPE_MECHANICAL {
+		return fmt.Errorf("生物类型 %d 无效", t.Type)
+	}
+	if t.HealthModifier <= 0 || t.DamageModifier < 0 || t.ManaModifier < 0 {
+		return fmt.Errorf("修正系数无效")
+	}
+	if t.MinGold > t.MaxGold {
+		return fmt.Errorf("金钱范围 %d-%d 无效", t.MinGold, t.MaxGold)
+	}
+	return nil
+}
+
+// LoadCreatureSpawns 加载生物刷新点 - 基于AzerothCore的ObjectMgr::LoadCreatures
+func (m *ObjectMgr) LoadCreatureSpawns(path string) error {
+	content, source, err := readDataFile(path, defaultCreatureSpawns)
+	if err != nil {
+		return err
+	}
+	rows, err := readDataTable(content)
+	if err != nil {
+		return fmt.Errorf("解析生物刷新点失败: %v", err)
+	}
+
+	spawns := make(map[uint32][]*CreatureData)
+	count := 0
+	for _, row := range rows {
+		data := &CreatureData{
+			GUID:          row.uint32("guid"),
+			Entry:         row.uint32("id"),
+			MapId:         row.uint32("map"),
+			X:             row.float32("position_x"),
+			Y:             row.float32("position_y"),
+			Z:             row.float32("position_z"),
+			Orientation:   row.float32("orientation"),
+			SpawnTimeSecs: row.uint32("spawntimesecs"),
+			Formation:     row.uint32("formation"),
+			Boss:          row.uint32("boss"),
+		}
+		if row.err == nil && m.GetCreatureTemplate(data.Entry) == nil {
+			row.err = fmt.Errorf("生物模板 %d 不存在", data.Entry)
+		}
+		if row.err != nil {
+			fmt.Printf("[creature] 跳过无效的刷新点 %d: %v\n", data.GUID, row.err)
+			continue
+		}
+		spawns[data.MapId] = append(spawns[data.MapId], data)
+		count++
+	}
+
+	m.mutex.Lock()
+	m.creatureSpawns = spawns
+	m.mutex.Unlock()
+	fmt.Printf("从%s加载了 %d 个生物刷新点\n", source, count)
+	return nil
+}
+
+// LoadInstanceTemplates 加载副本和副本中的门 - 基于AzerothCore的ObjectMgr::LoadInstanceTemplate
+func (m *ObjectMgr) LoadInstanceTemplates(templatePath, doorPath string) error {
+	content, source, err := readDataFile(templatePath, defaultInstanceTemplate)
+	if err != nil {
+		return err
+	}
+	rows, err := readDataTable(content)
+	if err != nil {
+		return fmt.Errorf("解析副本数据失败: %v", err)
+	}
+
+	instances := make(map[uint32]*InstanceData, len(rows))
+	for _, row := range rows {
+		data := &InstanceData{
+			mapId:      row.uint32("map"),
+			name:       row.str("name"),
+			minLevel:   row.uint8("min_level"),
+			maxLevel:   row.uint8("max_level"),
+			maxPlayers: row.uint8("max_players"),
+			entrance:   Vector3{row.float32("entrance_x"), row.float32("entrance_y"), row.float32("entrance_z")},
+		}
+		if row.err == nil && data.maxPlayers == 0 {
+			row.err = fmt.Errorf("人数上限不能为0")
+		}
+		if row.err != nil {
+			fmt.Printf("[instance_template] 跳过无效的副本 %d: %v\n", data.mapId, row.err)
+			continue
+		}
+		instances[data.mapId] = data
+	}
+
+	content, _, err = readDataFile(doorPath, defaultInstanceDoors)
+	if err != nil {
+		return err
+	}
+	rows, err = readDataTable(content)
+	if err != nil {
+		return fmt.Errorf("解析副本的门失败: %v", err)
+	}
+	for _, row := range rows {
+		mapId := row.uint32("map")
+		door := DoorData{
+			name:     row.str("name"),
+			x:        row.float32("position_x"),
+			bossId:   row.uint32("boss"),
+			doorType: row.uint8("door_type"),
+		}
+		if row.err == nil && instances[mapId] == nil {
+			row.err = fmt.Errorf("副本 %d 不存在", mapId)
+		}
+		if row.err == nil && door.doorType != DOOR_TYPE_ROOM && door.doorType != DOOR_TYPE_PASSAGE {
+			row.err = fmt.Errorf("门的类型 %d 无效", door.doorType)
+		}
+		if row.err != nil {
+			fmt.Printf("[instance_door] 跳过无效的门 %s: %v\n", door.name, row.err)
+			continue
+		}
+		instances[mapId].doors = append(instances[mapId].doors, door)
+	}
+
+	m.mutex.Lock()
+	m.instances = instances
+	m.mutex.Unlock()
+	fmt.Printf("从%s加载了 %d 个副本\n", source, len(instances))
+	return nil
+}
+
+// LoadCreatureTexts 加载生物台词 - 基于AzerothCore的CreatureTextMgr::LoadCreatureTexts
+func (m *ObjectMgr) LoadCreatureTexts(path string) error {
+	content, source, err := readDataFile(path, defaultCreatureTexts)
+	if err != nil {
+		return err
+	}
+	rows, err := readDataTable(content)
+	if err != nil {
+		return fmt.Errorf("解析生物台词失败: %v", err)
+	}
+
+	texts := make(map[uint32][]*CreatureText)
+	count := 0
+	for _, row := range rows {
+		text := &CreatureText{
+			CreatureID: row.uint32("CreatureID"),
+			GroupID:    row.uint32("GroupID"),
+			ID:         row.uint32("ID"),
+			Text:       row.str("Text"),
+			Type:       row.uint8("Type"),
+		}
+		if row.err == nil && m.GetCreatureTemplate(text.CreatureID) == nil {
+			row.err = fmt.Errorf("生物模板 %d 不存在", text.CreatureID)
+		}
+		if row.err == nil && text.Type != CHAT_MSG_MONSTER_SAY && text.Type != CHAT_MSG_MONSTER_YELL {
+			row.err = fmt.Errorf("台词类型 %d 无效", text.Type)
+		}
+		if row.err != nil {
+			fmt.Printf("[creature_text] 跳过无效的台词 %d-%d-%d: %v\n", text.CreatureID, text.GroupID, text.ID, row.err)
+			continue
+		}
+		texts[text.CreatureID] = append(texts[text.CreatureID], text)
+		count++
+	}
+
+	m.mutex.Lock()
+	m.creatureTexts = texts
+	m.mutex.Unlock()
+	fmt.Printf("从%s加载了 %d 条生物台词\n", source, count)
+	return nil
+}
+
+// LoadSmartScripts 加载SmartAI脚本 - 基于AzerothCore的SmartAIMgr::LoadSmartAIFromDB
+func (m *ObjectMgr) LoadSmartScripts(path string) error {
+	content, source, err := readDataFile(path, defaultSmartScripts)
+	if err != nil {
+		return err
+	}
+	rows, err := readDataTable(content)
+	if err != nil {
+		return fmt.Errorf("解析SmartAI脚本失败: %v", err)
+	}
+
+	scripts := make(map[uint32][]*SmartScript)
+	count := 0
+	for _, row := range rows {
+		script := &SmartScript{
+			Entry:          row.uint32("entryorguid"),
+			ID:             row.uint32("id"),
+			EventType:      row.uint8("event_type"),
+			EventPhaseMask: row.uint32("event_phase_mask"),
+			EventChance:    row.uint32("event_chance"),
+			EventFlags:     row.uint32("event_flags"),
+			ActionType:     row.uint8("action_type"),
+			TargetType:     row.uint8("target_type"),
+			Comment:        row.str("comment"),
+		}
+		for i := range script.EventParams {
+			script.EventParams[i] = row.uint32(fmt.Sprintf("event_param%d", i+1))
+		}
+		for i := range script.ActionParams {
+			script.ActionParams[i] = row.uint32(fmt.Sprintf("action_param%d", i+1))
+		}
+		if row.err == nil {
+			row.err = m.validateSmartScript(script)
+		}
+		if row.err != nil {
+			fmt.Printf("[smart_scripts] 跳过无效的脚本 %d-%d: %v\n", script.Entry, script.ID, row.err)
+			continue
+		}
+		scripts[script.Entry] = append(scripts[script.Entry], script)
+		count++
+	}
+	for _, list := range scripts {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	}
+
+	m.mutex.Lock()
+	m.smartScripts = scripts
+	m.mutex.Unlock()
+	fmt.Printf("从%s加载了 %d 条SmartAI脚本\n", source, count)
+	return nil
+}
+
+// LoadGraveyards 加载墓地 - 基于AzerothCore的ObjectMgr::LoadGraveyardZones
+func (m *ObjectMgr) LoadGraveyards(path string) error {
+	content, source, err := readDataFile(path, defaultGraveyards)
+	if err != nil {
+		return err
+	}
+	rows, err := readDataTable(content)
+	if err != nil {
+		return fmt.Errorf("解析墓地失败: %v", err)
+	}
+
+	graveyards := make([]*GraveyardData, 0, len(rows))
+	for _, row := range rows {
+		graveyard := &GraveyardData{
+			ID:    row.uint32("ID"),
+			MapId: row.uint32("Map"),
+			X:     row.float32("x"),
+			Y:     row.float32("y"),
+			Z:     row.float32("z"),
+			Name:  row.str("Comment"),
+		}
+		if row.err != nil {
+			fmt.Printf("[game_graveyard] 跳过无效的墓地 %d: %v\n", graveyard.ID, row.err)
+			continue
+		}
+		graveyards = append(graveyards, graveyard)
+	}
+
+	m.mutex.Lock()
+	m.graveyards = graveyards
+	m.mutex.Unlock()
+	fmt.Printf("从%s加载了 %d 个墓地\n", source, len(graveyards))
+	return nil
+}
+
+// GetClosestGraveyard 地图上离位置最近的墓地，没有时返回nil - 基于AzerothCore的GraveyardStore::GetClosestGraveyard
+func (m *ObjectMgr) GetClosestGraveyard(mapId uint32, x, y, z float32) *GraveyardData {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	var closest *GraveyardData
+	var closestDist float32
+	for _, graveyard := range m.graveyards {
+		if graveyard.MapId != mapId {
+			continue
+		}
+		dx, dy, dz := graveyard.X-x, graveyard.Y-y, graveyard.Z-z
+		dist := dx*dx + dy*dy + dz*dz
+		if closest == nil || dist < closestDist {
+			closest, closestDist = graveyard, dist
+		}
+	}
+	return closest
+}
+
+// LoadItemTemplates 加载物品模板 - 基于AzerothCore的ObjectMgr::LoadItemTemplates
+func (m *ObjectMgr) LoadItemTemplates(path string) error {
+	content, source, err := readDataFile(path, defaultItemTemplate)
+	if err != nil {
+		return err
+	}
+	rows, err := readDataTable(content)
+	if err != nil {
+		return fmt.Errorf("解析物品模板失败: %v", err)
+	}
+
+	items := make(map[uint32]*ItemTemplate, len(rows))
+	for _, row := range rows {
+		item := &ItemTemplate{
+			Entry:   row.uint32("entry"),
+			Name:    row.str("name"),
+			Quality: row.uint8("Quality"),
+		}
+		if row.err == nil && (item.Entry == 0 || item.Quality >= MAX_ITEM_QUALITY) {
+			row.err = fmt.Errorf("编号或品质 %d 无效", item.Quality)
+		}
+		if row.err != nil {
+			fmt.Printf("[item_template] 跳过无效的物品 %d: %v\n", item.Entry, row.err)
+			continue
+		}
+		items[item.Entry] = item
+	}
+
+	m.mutex.Lock()
+	m.itemTemplates = items
+	m.mutex.Unlock()
+	fmt.Printf("从%s加载了 %d 个物品模板\n", source, len(items))
+	return nil
+}
+
+// LoadCreatureLootTemplates 加载生物掉落 - 基于AzerothCore的LoadLootTemplates_Creature和LootStoreItem::IsValid
+// 分组为0的物品各自按几率掉落；同一分组最多掉落一件，几率为0的物品平分分组中剩余的几率
+func (m *ObjectMgr) LoadCreatureLootTemplates(path string) error {
+	content, source, err := readDataFile(path, defaultCreatureLoot)
+	if err != nil {
+		return err
+	}
+	rows, err := readDataTable(content)
+	if err != nil {
+		return fmt.Errorf("解析生物掉落失败: %v", err)
+	}
+
+	templates := make(map[uint32]*LootTemplate)
+	count := 0
+	for _, row := range rows {
+		entry := row.uint32("Entry")
+		item := &LootStoreItem{
+			ItemId:   row.uint32("Item"),
+			Chance:   row.float32("Chance"),
+			GroupId:  row.uint8("GroupId"),
+			MinCount: row.uint8("MinCount"),
+			MaxCount: row.uint8("MaxCount"),
+		}
+		if row.err == nil {
+			row.err = item.Validate()
+		}
+		if row.err == nil && m.GetCreatureTemplate(entry) == nil {
+			row.err = fmt.Errorf("生物模板 %d 不存在", entry)
+		}
+		if row.err == nil && m.GetItemTemplate(item.ItemId) == nil {
+			row.err = fmt.Errorf("物品 %d 不存在", item.ItemId)
+		}
+		if row.err != nil {
+			fmt.Printf("[creature_loot_template] 跳过生物 %d 的无效掉落 %d: %v\n", entry, item.ItemId, row.err)
+			continue
+		}
+		template := templates[entry]
+		if template == nil {
+			template = &LootTemplate{}
+			templates[entry] = template
+		}
+		template.AddEntry(item)
+		count++
+	}
+
+	m.mutex.Lock()
+	m.lootTemplates = templates
+	m.mutex.Unlock()
+	fmt.Printf("从%s加载了 %d 条生物掉落\n", source, count)
+	return nil
+}
+
+// GetItemTemplate 按编号查找物品模板
+func (m *ObjectMgr) GetItemTemplate(entry uint32) *ItemTemplate {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.itemTemplates[entry]
+}
+
+// GetLootTemplate 生物模板的掉落表，没有掉落时返回nil
+func (m *ObjectMgr) GetLootTemplate(entry uint32) *LootTemplate {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.lootTemplates[entry]
+}
+
+// GetCreatureTemplate 按编号查找生物模板
+func (m *ObjectMgr) GetCreatureTemplate(entry uint32) *CreatureTemplate {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.creatureTemplates[entry]
+}
+
+// GetCreatureSpawns 地图上的生物刷新点
+func (m *ObjectMgr) GetCreatureSpawns(mapId uint32) []*CreatureData {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.creatureSpawns[mapId]
+}
+
+// GetInstanceData 按地图查找副本数据
+func (m *ObjectMgr) GetInstanceData(mapId uint32) *InstanceData {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.instances[mapId]
+}
+
+// GetCreatureText 随机选择生物某一组的台词，没有时返回nil
+func (m *ObjectMgr) GetCreatureText(entry, group uint32) *CreatureText {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	var candidates []*CreatureText
+	for _, text := range m.creatureTexts[entry] {
+		if text.GroupID == group {
+			candidates = append(candidates, text)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+// GetSmartScripts 生物模板的SmartAI脚本
+func (m *ObjectMgr) GetSmartScripts(entry uint32) []*SmartScript {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.smartScripts[entry]
+}
+
+// CreateCreature 按模板创建生物 - 基于AzerothCore的Creature::Create和Creature::UpdateEntry
+// 等级在模板范围内随机，生命和法力由等级和修正系数决定，AI由AI注册表选择
+func (m *ObjectMgr) CreateCreature(entry uint32) *Creature {
+	template := m.GetCreatureTemplate(entry)
+	if template == nil {
+		fmt.Printf("生物模板 %d 不存在\n", entry)
+		return nil
+	}
+
+	level := template.MinLevel
+	if template.MaxLevel > template.MinLevel {
+		level += uint8(rand.Intn(int(template.MaxLevel-template.MinLevel) + 1))
+	}
+	creature := NewCreature(template.Name, level, template.Type)
+	creature.entry = entry
+	creature.spells = template.Spells
+
+	health := uint32(math.Round(float64(level) * CREATURE_BASE_HEALTH_PER_LEVEL * float64(template.HealthModifier)))
+	creature.SetMaxHealth(max(health, 1))
+	creature.SetHealth(creature.GetMaxHealth())
+	switch template.UnitClass {
+	case UNIT_CLASS_WARRIOR:
+		creature.SetMaxPower(POWER_RAGE, 100)
+		creature.SetPower(POWER_RAGE, 0)
+	case UNIT_CLASS_ROGUE:
+		creature.SetMaxPower(POWER_ENERGY, 100)
+		creature.SetPower(POWER_ENERGY, 100)
+	default:
+		mana := uint32(float32(uint32(level)*CREATURE_BASE_MANA_PER_LEVEL) * template.ManaModifier)
+		creature.SetMaxPower(POWER_MANA, mana)
+		creature.SetPower(POWER_MANA, mana)
+	}
+
+	creature.SetDamageModifier(template.DamageModifier)
+	creature.SetFaction(template.Faction)
+	creature.SetMechanicImmuneMask(template.MechanicImmuneMask)
+	creature.npcFlags = template.NpcFlag
+	creature.SetAI(selectCreatureAI(creature, template))
+	return creature
+}
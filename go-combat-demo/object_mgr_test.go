@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreatureTemplatesSpawnsAndAIRegistry(t *testing.T) {
+	if GlobalObjectMgr == nil {
+		InitObjectMgr()
+	}
+
+	// 无效的模板和引用不存在模板的刷新点被跳过
+	dir := t.TempDir()
+	templates := filepath.Join(dir, "creature_template.csv")
+	spawns := filepath.Join(dir, "creature.csv")
+	os.WriteFile(templates, []byte("entry,name,minlevel,maxlevel,unit_class,type,faction,HealthModifier,ManaModifier,DamageModifier,mechanic_immune_mask,AIName,ScriptName,spell1,spell2,spell3,spell4,npcflag,mingold,maxgold\n"+
+		"1,狼,5,6,1,1,14,1,1,1,0,AggressorAI,,0,0,0,0,0,0,0\n"+
+		"2,坏等级,9,3,1,1,14,1,1,1,0,,,0,0,0,0,0,0,0\n"+
+		"3,坏数字,x,3,1,1,14,1,1,1,0,,,0,0,0,0,0,0,0\n"+
+		"4,没有脚本,5,5,1,7,14,1,1,1,0,,npc_missing,0,0,0,0,0,0,0\n"), 0644)
+	os.WriteFile(spawns, []byte("guid,id,map,position_x,position_y,position_z,orientation,spawntimesecs,formation,boss\n"+
+		"1,1,0,10,0,0,0,60,0,0\n"+
+		"2,2,0,20,0,0,0,60,0,0\n"), 0644)
+	mgr := &ObjectMgr{}
+	if err := mgr.LoadCreatureTemplates(templates); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.LoadCreatureSpawns(spawns); err != nil {
+		t.Fatal(err)
+	}
+	if mgr.GetCreatureTemplate(1) == nil || mgr.GetCreatureTemplate(2) != nil || mgr.GetCreatureTemplate(3) != nil {
+		t.Fatal("invalid templates should be skipped")
+	}
+	if len(mgr.GetCreatureSpawns(0)) != 1 {
+		t.Fatalf("spawns of missing templates should be skipped, got %d", len(mgr.GetCreatureSpawns(0)))
+	}
+	wolf := mgr.CreateCreature(1)
+	if wolf.GetLevel() < 5 || wolf.GetLevel() > 6 || wolf.GetMaxHealth() != uint32(wolf.GetLevel())*CREATURE_BASE_HEALTH_PER_LEVEL || wolf.GetFaction() != 14 {
+		t.Fatalf("creature should be built from its template, level %d health %d", wolf.GetLevel(), wolf.GetMaxHealth())
+	}
+	if _, ok := mgr.CreateCreature(4).GetAI().(*CreatureAI); !ok {
+		t.Fatal("unregistered script should fall back to the default AI")
+	}
+
+	// ScriptName优先于AIName
+	if _, ok := GlobalObjectMgr.CreateCreature(598).GetAI().(*SmartAI); !ok {
+		t.Fatal("miner should use SmartAI")
+	}
+	if _, ok := GlobalObjectMgr.CreateCreature(636).GetAI().(*CombatAI); !ok {
+		t.Fatal("blackguard should use CombatAI")
+	}
+	if _, ok := GlobalObjectMgr.CreateCreature(639).GetAI().(*BossAI); !ok {
+		t.Fatal("VanCleef should use his boss script")
+	}
+
+	// 死亡矿井完全由数据组成：编队成为小怪组，首领成为遭遇战，小怪按刷新时间重生
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	dm := NewDeadminesDungeon(world, DIFFICULTY_NORMAL)
+	if len(dm.trash) != 3 || len(dm.GetTrashGroup(1).creatures) != 3 || len(dm.encounters) != 2 || len(dm.doors) != 2 {
+		t.Fatalf("dungeon should be built from spawn data, got %d packs %d bosses %d doors", len(dm.trash), len(dm.encounters), len(dm.doors))
+	}
+	if NewDungeon(world, 9999, DIFFICULTY_NORMAL) != nil {
+		t.Fatal("unknown map should not create a dungeon")
+	}
+	tank, _ := newGroupTestPlayer(world, 1, "tank", CLASS_WARRIOR, 0)
+	dm.AddPlayer(tank)
+	pack := dm.GetTrashGroup(3)
+	for _, elite := range pack.creatures {
+		elite.health = 0
+		elite.AddUnitState(UNIT_STATE_DIED)
+	}
+	dm.Update(100)
+	if !pack.isCleared {
+		t.Fatal("dead pack should be cleared")
+	}
+	dm.Update(7200 * 1000)
+	if !pack.creatures[0].IsAlive() || pack.isCleared {
+		t.Fatal("pack should respawn after its spawn time")
+	}
+}
@@ -27,7 +27,11 @@ func NewPacketOrderingTest() *PacketOrderingTest {
 	// 创建测试单位
 	units := make([]IUnit, 2)
 	for i := 0; i < 2; i++ {
-		unit := NewUnit(fmt.Sprintf("TestUnit%d", i+1), 1000, 1000, 500, 500)
+		unit := NewUnit(generateGUID(), fmt.Sprintf("TestUnit%d", i+1), 60, UNIT_TYPE_PLAYER)
+		unit.SetMaxHealth(1000)
+		unit.SetHealth(1000)
+		unit.SetMaxPower(POWER_MANA, 500)
+		unit.SetPower(POWER_MANA, 500)
 		units[i] = unit
 		world.AddUnit(unit)
 	}
@@ -72,7 +76,7 @@ func (pot *PacketOrderingTest) simulateOrderingProblem() {
 	batchPacket.SetPriority(1)   // 高优先级
 	batchPacket.SetUpdateId(100) // 更新ID 100
 
-	fmt.Printf("批量更新数据包：血量=800, 优先级=%d, 更新ID=%d, 时间戳=%v\n",
+	fmt.Printf("批量更新数据包：血量=%d, 优先级=%d, 更新ID=%d, 时间戳=%v\n",
 		800, batchPacket.GetPriority(), batchPacket.GetUpdateId(), batchPacket.GetTimestamp())
 
 	// 3. 模拟定期更新：发送旧状态（血量 1000）
@@ -84,7 +88,7 @@ func (pot *PacketOrderingTest) simulateOrderingProblem() {
 	periodicPacket.SetPriority(3)    // 低优先级
 	periodicPacket.SetUpdateId(99)   // 更新ID 99（更旧）
 
-	fmt.Printf("定期更新数据包：血量=1000, 优先级=%d, 更新ID=%d, 时间戳=%v\n",
+	fmt.Printf("定期更新数据包：血量=%d, 优先级=%d, 更新ID=%d, 时间戳=%v\n",
 		1000, periodicPacket.GetPriority(), periodicPacket.GetUpdateId(), periodicPacket.GetTimestamp())
 
 	// 4. 模拟网络延迟导致的乱序
@@ -0,0 +1,115 @@
+package main
+
+// 能量恢复常量 - 基于AzerothCore的Player::Regenerate
+const (
+	REGEN_TIME_INTERVAL        = 2000 // 能量恢复间隔(毫秒)
+	POWER_REGEN_FIVE_SECOND    = 5000 // 五秒规则 - 消耗法力后5秒内不恢复法力
+	MANA_REGEN_BASE_PER_TICK   = 12   // 每次恢复的基础法力
+	MANA_REGEN_SPIRIT_DIVISOR  = 4    // 每4点精神每次额外恢复1点法力
+	ENERGY_REGEN_PER_TICK      = 20   // 每次恢复20点能量(每秒10点)
+	RAGE_DECAY_PER_TICK        = 2    // 脱战后每次衰减2点怒气(每秒1点)
+	FOCUS_REGEN_PER_TICK       = 12   // 每次恢复12点集中值(每4秒24点)
+	DEFAULT_SPIRIT_PER_LEVEL   = 1    // 默认每级精神
+	DEFAULT_SPIRIT_BASE        = 20   // 默认基础精神
+	POWER_COST_PERCENT_DIVISOR = 100  // 百分比消耗的除数
+)
+
+// GetSpirit 获取精神
+func (u *Unit) GetSpirit() uint32 {
+	return u.spirit
+}
+
+// SetSpirit 设置精神
+func (u *Unit) SetSpirit(spirit uint32) {
+	u.spirit = spirit
+}
+
+// GetCreateMana 获取基础法力 - 百分比法力消耗以此为基数，未设置时使用法力上限
+func (u *Unit) GetCreateMana() uint32 {
+	if u.createMana > 0 {
+		return u.createMana
+	}
+	return u.GetMaxPower(POWER_MANA)
+}
+
+// SetCreateMana 设置基础法力
+func (u *Unit) SetCreateMana(mana uint32) {
+	u.createMana = mana
+}
+
+// SetLastManaUse 消耗法力后触发五秒规则
+func (u *Unit) SetLastManaUse() {
+	u.manaRegenDelay = POWER_REGEN_FIVE_SECOND
+}
+
+// IsUnderFiveSecondRule 是否处于五秒规则内
+func (u *Unit) IsUnderFiveSecondRule() bool {
+	return u.manaRegenDelay > 0
+}
+
+// updatePowerRegen 按固定间隔恢复能量 - 基于AzerothCore的Player::RegenerateAll
+func (u *Unit) updatePowerRegen(diff uint32) {
+	if u.manaRegenDelay > diff {
+		u.manaRegenDelay -= diff
+	} else {
+		u.manaRegenDelay = 0
+	}
+
+	if !u.IsAlive() {
+		u.regenTimer = 0
+		return
+	}
+
+	u.regenTimer += diff
+	for u.regenTimer >= REGEN_TIME_INTERVAL {
+		u.regenTimer -= REGEN_TIME_INTERVAL
+		for powerType := range u.maxPowers {
+			u.regenerate(powerType)
+		}
+	}
+}
+
+// regenerate 单次能量恢复 - 基于AzerothCore的Player::Regenerate(Powers)
+func (u *Unit) regenerate(powerType uint8) {
+	var delta int32
+	switch powerType {
+	case POWER_MANA:
+		// 五秒规则内不恢复法力
+		if u.IsUnderFiveSecondRule() {
+			return
+		}
+		delta = MANA_REGEN_BASE_PER_TICK + int32(u.spirit/MANA_REGEN_SPIRIT_DIVISOR)
+	case POWER_ENERGY:
+		delta = ENERGY_REGEN_PER_TICK
+	case POWER_RAGE:
+		// 怒气只在脱战后衰减
+		if u.inCombat {
+			return
+		}
+		delta = -RAGE_DECAY_PER_TICK
+	case POWER_FOCUS:
+		delta = FOCUS_REGEN_PER_TICK
+	default:
+		return
+	}
+
+	current := u.GetPower(powerType)
+	if (delta > 0 && current >= u.GetMaxPower(powerType)) || (delta < 0 && current == 0) {
+		return
+	}
+	u.ModifyPower(powerType, delta)
+}
+
+// calcPowerCost 计算法术消耗 - 基于AzerothCore的SpellInfo::CalcPowerCost
+// 百分比消耗以基础法力(法力)或能量上限(其他能量)为基数
+func (s *Spell) calcPowerCost() uint32 {
+	cost := s.info.ManaCost
+	if s.info.ManaCostPercentage > 0 {
+		base := s.caster.GetMaxPower(s.info.PowerType)
+		if unit := getBaseUnit(s.caster); unit != nil && s.info.PowerType == POWER_MANA {
+			base = unit.GetCreateMana()
+		}
+		cost += base * s.info.ManaCostPercentage / POWER_COST_PERCENT_DIVISOR
+	}
+	return cost
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// SmartAI 数据驱动的生物AI - 基于AzerothCore的SmartAI和smart_scripts表
+// 每一行脚本是"事件 → 动作 → 目标"：事件满足时对选出的目标执行动作，小怪的行为只需要数据
+
+// SmartAI事件类型 - 基于AzerothCore的SMART_EVENT
+const (
+	SMART_EVENT_UPDATE_IC  = 0 // 战斗中定时：首次最小值、首次最大值、重复最小值、重复最大值(毫秒)
+	SMART_EVENT_HEALTH_PCT = 2 // 生命值百分比在区间内：最小百分比、最大百分比、重复最小值、重复最大值
+	SMART_EVENT_AGGRO      = 4 // 进入战斗，触发者为仇恨目标
+	SMART_EVENT_DEATH      = 6 // 死亡，触发者为击杀者
+)
+
+// SmartAI动作类型 - 基于AzerothCore的SMART_ACTION
+const (
+	SMART_ACTION_TALK            = 1  // 说出台词：creature_text的组
+	SMART_ACTION_CAST            = 11 // 施放法术：法术ID
+	SMART_ACTION_SUMMON_CREATURE = 12 // 召唤生物：生物模板编号，召唤物攻击目标
+	SMART_ACTION_SET_EVENT_PHASE = 22 // 设置事件阶段：阶段
+)
+
+// SmartAI目标类型 - 基于AzerothCore的SMART_TARGET
+const (
+	SMART_TARGET_SELF           = 1 // 自己
+	SMART_TARGET_VICTIM         = 2 // 当前攻击目标
+	SMART_TARGET_HOSTILE_RANDOM = 5 // 仇恨列表中的随机目标
+	SMART_TARGET_ACTION_INVOKER = 7 // 触发事件的单位
+)
+
+// SmartAI事件标记 - 基于AzerothCore的SMART_EVENT_FLAG
+const (
+	SMART_EVENT_FLAG_NOT_REPEATABLE = 0x01 // 每次战斗只触发一次
+)
+
+// SmartScript 一行SmartAI脚本 - 基于AzerothCore的SmartScriptHolder
+type SmartScript struct {
+	Entry          uint32
+	ID             uint32
+	EventType      uint8
+	EventPhaseMask uint32 // 第n阶段对应第n-1位，0表示所有阶段
+	EventChance    uint32 // 触发概率(百分比)
+	EventFlags     uint32
+	EventParams    [4]uint32
+	ActionType     uint8
+	ActionParams   [3]uint32
+	TargetType     uint8
+	Comment        string
+}
+
+// validateSmartScript 检查脚本行 - 基于AzerothCore的SmartAIMgr::IsEventValid
+func (m *ObjectMgr) validateSmartScript(script *SmartScript) error {
+	if m.GetCreatureTemplate(script.Entry) == nil {
+		return fmt.Errorf("生物模板 %d 不存在", script.Entry)
+	}
+	if script.EventChance == 0 || script.EventChance > 100 {
+		return fmt.Errorf("触发概率 %d 无效", script.EventChance)
+	}
+
+	params := script.EventParams
+	switch script.EventType {
+	case SMART_EVENT_UPDATE_IC:
+		if params[0] > params[1] || params[2] > params[3] {
+			return fmt.Errorf("定时区间无效")
+		}
+	case SMART_EVENT_HEALTH_PCT:
+		if params[0] > params[1] || params[1] > 100 || params[2] > params[3] {
+			return fmt.Errorf("生命值区间无效")
+		}
+	case SMART_EVENT_AGGRO, SMART_EVENT_DEATH:
+	default:
+		return fmt.Errorf("事件类型 %d 无效", script.EventType)
+	}
+
+	switch script.ActionType {
+	case SMART_ACTION_TALK:
+		if m.GetCreatureText(script.Entry, script.ActionParams[0]) == nil {
+			return fmt.Errorf("台词组 %d 不存在", script.ActionParams[0])
+		}
+	case SMART_ACTION_CAST:
+		if GlobalSpellManager != nil && GlobalSpellManager.GetSpell(script.ActionParams[0]) == nil {
+			return fmt.Errorf("法术 %d 不存在", script.ActionParams[0])
+		}
+	case SMART_ACTION_SUMMON_CREATURE:
+		if m.GetCreatureTemplate(script.ActionParams[0]) == nil {
+			return fmt.Errorf("召唤的生物模板 %d 不存在", script.ActionParams[0])
+		}
+	case SMART_ACTION_SET_EVENT_PHASE:
+		if script.ActionParams[0] > 32 {
+			return fmt.Errorf("阶段 %d 无效", script.ActionParams[0])
+		}
+	default:
+		return fmt.Errorf("动作类型 %d 无效", script.ActionType)
+	}
+
+	switch script.TargetType {
+	case SMART_TARGET_SELF, SMART_TARGET_VICTIM, SMART_TARGET_HOSTILE_RANDOM, SMART_TARGET_ACTION_INVOKER:
+	default:
+		return fmt.Errorf("目标类型 %d 无效", script.TargetType)
+	}
+	return nil
+}
+
+func init() {
+	RegisterCreatureAI("SmartAI", func(creature *Creature) IAI { return NewSmartAI(creature) })
+}
+
+// smartEvent 脚本行的运行状态
+type smartEvent struct {
+	script  *SmartScript
+	timer   uint32 // 距离下次可以触发的时间
+	enabled bool   // 不可重复的事件触发后关闭，脱战时恢复
+}
+
+// SmartAI 执行生物模板的SmartAI脚本
+type SmartAI struct {
+	owner   *Creature
+	events  []*smartEvent
+	phase   uint32
+	summons []*Creature
+}
+
+// NewSmartAI 按生物模板编号加载脚本创建SmartAI
+func NewSmartAI(owner *Creature) *SmartAI {
+	ai := &SmartAI{owner: owner}
+	if GlobalObjectMgr != nil {
+		for _, script := range GlobalObjectMgr.GetSmartScripts(owner.GetEntry()) {
+			ai.events = append(ai.events, &smartEvent{script: script})
+		}
+	}
+	ai.resetEvents()
+	return ai
+}
+
+// GetPhase 当前事件阶段
+func (ai *SmartAI) GetPhase() uint32 { return ai.phase }
+
+// GetSummons 召唤的生物
+func (ai *SmartAI) GetSummons() []*Creature { return ai.summons }
+
+// resetEvents 恢复所有事件并重新计时
+func (ai *SmartAI) resetEvents() {
+	ai.phase = 0
+	for _, event := range ai.events {
+		event.enabled = true
+		event.timer = 0
+		if event.script.EventType == SMART_EVENT_UPDATE_IC {
+			event.timer = randomBetween(event.script.EventParams[0], event.script.EventParams[1])
+		}
+	}
+}
+
+// randomBetween 区间内的随机值
+func randomBetween(min, max uint32) uint32 {
+	if max <= min {
+		return min
+	}
+	return min + uint32(rand.Intn(int(max-min)+1))
+}
+
+// isInPhase 事件是否属于当前阶段 - 基于AzerothCore的SmartScript::IsInPhase
+func (ai *SmartAI) isInPhase(mask uint32) bool {
+	if mask == 0 {
+		return true
+	}
+	return ai.phase > 0 && mask&(1<<(ai.phase-1)) != 0
+}
+
+func (ai *SmartAI) UpdateAI(diff uint32) {
+	if !ai.owner.IsAlive() {
+		return
+	}
+	victim := ai.owner.UpdateVictim()
+	if victim == nil {
+		return
+	}
+
+	for _, event := range ai.events {
+		script := event.script
+		if !event.enabled || !ai.isInPhase(script.EventPhaseMask) {
+			continue
+		}
+		if script.EventType != SMART_EVENT_UPDATE_IC && script.EventType != SMART_EVENT_HEALTH_PCT {
+			continue
+		}
+		if event.timer > diff {
+			event.timer -= diff
+			continue
+		}
+		event.timer = 0
+
+		if script.EventType == SMART_EVENT_HEALTH_PCT {
+			healthPct := uint32(ai.owner.GetHealth() * 100 / ai.owner.GetMaxHealth())
+			if healthPct < script.EventParams[0] || healthPct > script.EventParams[1] {
+				continue
+			}
+		}
+		// 施法期间不打断，到期的施法事件留到施法结束后
+		if script.ActionType == SMART_ACTION_CAST && ai.owner.isCurrentlySpellCasting() {
+			continue
+		}
+
+		ai.processEvent(event, victim)
+		repeatMin, repeatMax := script.EventParams[2], script.EventParams[3]
+		if repeatMax == 0 {
+			event.enabled = false
+		} else {
+			event.timer = randomBetween(repeatMin, repeatMax)
+		}
+	}
+}
+
+// processEvents 触发某一类型的所有事件
+func (ai *SmartAI) processEvents(eventType uint8, invoker IUnit) {
+	for _, event := range ai.events {
+		if event.enabled && event.script.EventType == eventType && ai.isInPhase(event.script.EventPhaseMask) {
+			ai.processEvent(event, invoker)
+		}
+	}
+}
+
+// processEvent 按概率执行事件的动作 - 基于AzerothCore的SmartScript::ProcessEvent
+func (ai *SmartAI) processEvent(event *smartEvent, invoker IUnit) {
+	script := event.script
+	if script.EventFlags&SMART_EVENT_FLAG_NOT_REPEATABLE != 0 {
+		event.enabled = false
+	}
+	if script.EventChance < 100 && uint32(rand.Intn(100)) >= script.EventChance {
+		return
+	}
+
+	targets := ai.getTargets(script.TargetType, invoker)
+	switch script.ActionType {
+	case SMART_ACTION_TALK:
+		ai.talk(script.ActionParams[0])
+	case SMART_ACTION_CAST:
+		for _, target := range targets {
+			ai.owner.CastSpell(target, script.ActionParams[0])
+		}
+	case SMART_ACTION_SUMMON_CREATURE:
+		ai.summon(script.ActionParams[0], targets)
+	case SMART_ACTION_SET_EVENT_PHASE:
+		ai.phase = script.ActionParams[0]
+	}
+}
+
+// getTargets 选择动作的目标 - 基于AzerothCore的SmartScript::GetTargets
+func (ai *SmartAI) getTargets(targetType uint8, invoker IUnit) []IUnit {
+	var target IUnit
+	switch targetType {
+	case SMART_TARGET_SELF:
+		target = ai.owner
+	case SMART_TARGET_VICTIM:
+		target = ai.owner.GetVictim()
+	case SMART_TARGET_HOSTILE_RANDOM:
+		var candidates []IUnit
+		for _, info := range ai.owner.threatManager.GetSortedThreatList() {
+			if info.unit.IsAlive() {
+				candidates = append(candidates, info.unit)
+			}
+		}
+		if len(candidates) > 0 {
+			target = candidates[rand.Intn(len(candidates))]
+		}
+	case SMART_TARGET_ACTION_INVOKER:
+		target = invoker
+	}
+	if target == nil {
+		return nil
+	}
+	return []IUnit{target}
+}
+
+// talk 说出一组台词中的一条 - 基于AzerothCore的CreatureTextMgr::SendChat
+func (ai *SmartAI) talk(group uint32) {
+	text := GlobalObjectMgr.GetCreatureText(ai.owner.GetEntry(), group)
+	if text == nil {
+		return
+	}
+	if text.Type == CHAT_MSG_MONSTER_YELL {
+		ai.owner.Yell(text.Text)
+	} else {
+		ai.owner.Say(text.Text)
+	}
+}
+
+// summon 召唤生物攻击目标，没有目标时攻击自己的攻击目标
+func (ai *SmartAI) summon(entry uint32, targets []IUnit) {
+	creature := ai.owner.SummonCreature(entry)
+	if creature == nil {
+		return
+	}
+	ai.summons = append(ai.summons, creature)
+	fmt.Printf("%s 召唤了 %s\n", ai.owner.GetName(), creature.GetName())
+
+	target := ai.owner.GetVictim()
+	if len(targets) > 0 && targets[0] != IUnit(ai.owner) {
+		target = targets[0]
+	}
+	if target != nil && target.IsAlive() {
+		creature.CombatStart(target)
+		creature.UpdateVictim()
+	}
+}
+
+// despawnSummons 移除召唤的生物
+func (ai *SmartAI) despawnSummons() {
+	for _, summon := range ai.summons {
+		if summon.IsAlive() {
+			summon.EnterEvadeMode()
+		}
+		if ai.owner.world != nil {
+			ai.owner.world.RemoveUnit(summon.GetGUID())
+		}
+	}
+	ai.summons = nil
+}
+
+// Reset 脱战时恢复事件和阶段，移除召唤物
+func (ai *SmartAI) Reset() {
+	ai.resetEvents()
+	ai.despawnSummons()
+}
+
+func (ai *SmartAI) AttackStart(target IUnit) {}
+
+func (ai *SmartAI) EnterCombat(target IUnit) {
+	ai.resetEvents()
+	ai.processEvents(SMART_EVENT_AGGRO, target)
+}
+
+func (ai *SmartAI) JustDied(killer IUnit) {
+	ai.processEvents(SMART_EVENT_DEATH, killer)
+}
+
+func (ai *SmartAI) DamageTaken(attacker IUnit, damage uint32) {}
+
+func (ai *SmartAI) DamageDealt(victim IUnit, damage uint32) {}
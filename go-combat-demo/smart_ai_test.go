@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSmartAIEventsActionsAndTargets(t *testing.T) {
+	if GlobalObjectMgr == nil {
+		InitObjectMgr()
+	}
+
+	// 无效的事件、动作和台词组被跳过
+	dir := t.TempDir()
+	path := filepath.Join(dir, "smart_scripts.csv")
+	os.WriteFile(path, []byte("entryorguid,id,event_type,event_phase_mask,event_chance,event_flags,event_param1,event_param2,event_param3,event_param4,action_type,action_param1,action_param2,action_param3,target_type,comment\n"+
+		"598,1,4,0,100,0,0,0,0,0,1,0,0,0,1,有效\n"+
+		"598,0,99,0,100,0,0,0,0,0,1,0,0,0,1,事件无效\n"+
+		"598,2,4,0,100,0,0,0,0,0,1,9,0,0,1,台词组不存在\n"+
+		"598,3,0,0,100,0,5000,1000,0,0,11,133,0,0,2,定时区间无效\n"+
+		"598,4,6,0,0,0,0,0,0,0,1,1,0,0,1,概率无效\n"+
+		"598,5,4,0,100,0,0,0,0,0,11,133,0,0,9,目标无效\n"+
+		"598,6,0,0,100,0,0,0,0,0,11,999999,0,0,2,法术不存在\n"), 0644)
+	mgr := &ObjectMgr{}
+	mgr.LoadCreatureTemplates(CREATURE_TEMPLATE_PATH)
+	mgr.LoadCreatureTexts(CREATURE_TEXT_PATH)
+	if err := mgr.LoadSmartScripts(path); err != nil {
+		t.Fatal(err)
+	}
+	if scripts := mgr.GetSmartScripts(598); len(scripts) != 1 || scripts[0].ID != 1 {
+		t.Fatalf("only the valid script row should load, got %d", len(scripts))
+	}
+
+	// 精英：进入战斗进入阶段1，生命值低于30%时激怒并进入阶段2
+	tank := newThreatTestUnit("tank", 2)
+	elite := GlobalObjectMgr.CreateCreature(1725)
+	ai := elite.GetAI().(*SmartAI)
+	elite.CombatStart(tank)
+	if ai.GetPhase() != 1 {
+		t.Fatalf("aggro should set phase 1, got %d", ai.GetPhase())
+	}
+	elite.SetHealth(elite.GetMaxHealth() / 4)
+	elite.Update(100)
+	if ai.GetPhase() != 2 || elite.GetAttackTime() >= BASE_ATTACK_TIME {
+		t.Fatalf("elite should enrage and enter phase 2 below 30%%, phase %d", ai.GetPhase())
+	}
+	for _, event := range ai.events {
+		if event.script.EventType == SMART_EVENT_HEALTH_PCT && event.enabled {
+			t.Fatal("not repeatable health events should fire once")
+		}
+	}
+
+	// 监工：生命值低于50%时召唤矿工攻击目标，脱战时移除召唤物并恢复事件
+	overseer := GlobalObjectMgr.CreateCreature(634)
+	overseerAI := overseer.GetAI().(*SmartAI)
+	overseer.CombatStart(tank)
+	overseer.Update(100)
+	if len(overseerAI.GetSummons()) != 0 {
+		t.Fatal("overseer should not summon at full health")
+	}
+	overseer.SetHealth(overseer.GetMaxHealth() * 2 / 5)
+	overseer.Update(100)
+	overseer.Update(100)
+	summons := overseerAI.GetSummons()
+	if len(summons) != 1 || summons[0].GetEntry() != 598 || summons[0].GetVictim() != IUnit(tank) {
+		t.Fatalf("overseer should summon one miner attacking the tank, got %d", len(summons))
+	}
+	overseer.EnterEvadeMode()
+	if len(overseerAI.GetSummons()) != 0 {
+		t.Fatal("evade should despawn summons")
+	}
+	for _, event := range overseerAI.events {
+		if !event.enabled {
+			t.Fatal("evade should re-enable not repeatable events")
+		}
+	}
+}
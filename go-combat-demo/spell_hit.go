@@ -0,0 +1,390 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// 命中结算常量 - 基于AzerothCore的Unit::MagicSpellHitResult/MeleeSpellHitResult
+const (
+	SPELL_BASE_HIT_CHANCE        = 96   // 同等级法术基础命中率
+	SPELL_HIGH_LEVEL_HIT_CHANCE  = 94   // 高出2级以上时的起始命中率
+	SPELL_HIT_LEVEL_PENALTY_NPC  = 11   // 目标为NPC时每级额外降低的命中率 - 高3级的首领为17%未命中
+	SPELL_HIT_LEVEL_PENALTY_PVP  = 7    // 目标为玩家时每级额外降低的命中率
+	MELEE_SPELL_BASE_MISS_CHANCE = 5    // 物理技能基础未命中率
+	MAX_AVERAGE_RESIST           = 0.75 // 平均抗性上限
+	RESISTANCE_PER_LEVEL         = 5    // 生物每高出一级获得的等级抗性
+)
+
+// creatureTypeMechanicImmunities 生物类型天生免疫的机制 - 亡灵和机械无法被变形，机械不会恐惧
+var creatureTypeMechanicImmunities = map[uint8]uint32{
+	CREATURE_TYPE_UNDEAD:     mechanicMask(MECHANIC_POLYMORPH),
+	CREATURE_TYPE_MECHANICAL: mechanicMask(MECHANIC_POLYMORPH) | mechanicMask(MECHANIC_FEAR),
+}
+
+// mechanicMask 机制对应的免疫掩码位
+func mechanicMask(mechanic int) uint32 {
+	if mechanic <= 0 {
+		return 0
+	}
+	return 1 << uint(mechanic-1)
+}
+
+// SpellMissTarget SMSG_SPELLGO中未命中的目标
+type SpellMissTarget struct {
+	target   IUnit
+	missInfo uint8
+}
+
+// GetCreatureType 获取生物类型
+func (u *Unit) GetCreatureType() uint8 {
+	return u.creatureType
+}
+
+// SetMechanicImmuneMask 设置机制免疫掩码
+func (u *Unit) SetMechanicImmuneMask(mask uint32) {
+	u.mechanicImmuneMask = mask
+}
+
+// SetResistance 设置学派抗性
+func (u *Unit) SetResistance(school int, value int32) {
+	u.resistances[school] = value
+}
+
+// GetResistance 获取学派抗性
+func (u *Unit) GetResistance(schoolMask int) int32 {
+	var resistance int32
+	for school, value := range u.resistances {
+		if school&schoolMask != 0 && value > resistance {
+			resistance = value
+		}
+	}
+	return resistance
+}
+
+// GetSpellHitChanceBonus 法术命中加成(百分比)
+func (u *Unit) GetSpellHitChanceBonus() float32 {
+	var bonus float32
+	for _, aura := range u.auras {
+		if aura.auraType == SPELL_AURA_MOD_SPELL_HIT_CHANCE {
+			bonus += float32(aura.value)
+		}
+	}
+	return bonus
+}
+
+// IsImmunedToSchool 检查是否免疫法术学派 - 所有学派都被免疫时才算免疫
+func (u *Unit) IsImmunedToSchool(schoolMask int) bool {
+	var immuneMask int
+	for _, aura := range u.auras {
+		if aura.auraType == SPELL_AURA_SCHOOL_IMMUNITY {
+			immuneMask |= int(aura.miscValue)
+		}
+	}
+	return schoolMask != 0 && immuneMask&schoolMask == schoolMask
+}
+
+// IsImmunedToMechanic 检查是否免疫机制 - 生物类型、模板免疫掩码和免疫光环
+func (u *Unit) IsImmunedToMechanic(mechanic int) bool {
+	mask := mechanicMask(mechanic)
+	if mask == 0 {
+		return false
+	}
+	if creatureTypeMechanicImmunities[u.creatureType]&mask != 0 || u.mechanicImmuneMask&mask != 0 {
+		return true
+	}
+	for _, aura := range u.auras {
+		if aura.auraType == SPELL_AURA_MECHANIC_IMMUNITY && int(aura.miscValue) == mechanic {
+			return true
+		}
+	}
+	return false
+}
+
+// IsImmunedToSpell 检查是否免疫法术 - 基于AzerothCore的Unit::IsImmunedToSpell
+// 脱战返回中免疫一切；负面法术受学派免疫影响；所有效果的机制都被免疫时整个法术免疫
+func (u *Unit) IsImmunedToSpell(spellInfo *SpellInfo) bool {
+	if u.HasUnitState(UNIT_STATE_EVADE) {
+		return true
+	}
+	if !spellInfo.IsPositive() && u.IsImmunedToSchool(spellInfo.SchoolMask) {
+		return true
+	}
+
+	immuneEffects := 0
+	for _, effect := range spellInfo.Effects {
+		if effect.Mechanic != MECHANIC_NONE && u.IsImmunedToMechanic(effect.Mechanic) {
+			immuneEffects++
+		}
+	}
+	return len(spellInfo.Effects) > 0 && immuneEffects == len(spellInfo.Effects)
+}
+
+// IsPositive 是否为有益法术 - 所有效果都作用于友方或自身
+func (info *SpellInfo) IsPositive() bool {
+	for i := range info.Effects {
+		if !info.isPositiveEffect(&info.Effects[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (info *SpellInfo) isPositiveEffect(effect *SpellEffect) bool {
+	switch effect.EffectType {
+	case SPELL_EFFECT_HEAL, SPELL_EFFECT_ENERGIZE, SPELL_EFFECT_RESURRECT:
+		return true
+	case SPELL_EFFECT_SCHOOL_DAMAGE, SPELL_EFFECT_WEAPON_DAMAGE, SPELL_EFFECT_INSTAKILL, SPELL_EFFECT_INTERRUPT_CAST:
+		return false
+	}
+
+	targetType := effect.ImplicitTargetA
+	if targetType == 0 {
+		targetType = info.TargetType
+	}
+	switch targetType {
+	case TARGET_UNIT_CASTER, TARGET_UNIT_TARGET_ALLY, TARGET_UNIT_CASTER_AREA_PARTY,
+		TARGET_UNIT_CASTER_AREA_RAID, TARGET_UNIT_TARGET_CHAINHEAL_ALLY:
+		return true
+	}
+	return false
+}
+
+// hasDamageEffect 是否带有伤害效果 - 伤害法术部分抵抗，控制法术整体抵抗
+func (info *SpellInfo) hasDamageEffect() bool {
+	for _, effect := range info.Effects {
+		if effect.EffectType == SPELL_EFFECT_SCHOOL_DAMAGE || effect.EffectType == SPELL_EFFECT_WEAPON_DAMAGE {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRequiringDeadTarget 是否只能作用于死亡的目标 - 基于AzerothCore的SpellInfo::IsRequiringDeadTarget
+func (info *SpellInfo) IsRequiringDeadTarget() bool {
+	for _, effect := range info.Effects {
+		if effect.EffectType == SPELL_EFFECT_RESURRECT {
+			return true
+		}
+	}
+	return false
+}
+
+// canBeResisted 神圣和物理伤害无法被抵抗
+func canBeResisted(schoolMask int) bool {
+	return schoolMask&(SPELL_SCHOOL_NORMAL|SPELL_SCHOOL_HOLY) == 0
+}
+
+// calcSpellHitResult 计算法术对目标的命中结果 - 基于AzerothCore的Unit::SpellHitResult
+func (s *Spell) calcSpellHitResult(target IUnit) uint8 {
+	if target.GetGUID() == s.caster.GetGUID() {
+		return SPELL_MISS_NONE
+	}
+	if target.HasUnitState(UNIT_STATE_EVADE) {
+		return SPELL_MISS_EVADE
+	}
+	if isImmunedToSpell(target, s.info) {
+		return SPELL_MISS_IMMUNE
+	}
+	if s.info.IsPositive() {
+		return SPELL_MISS_NONE
+	}
+
+	// 单体魔法可以被反射
+	if s.canReflect(target) && s.rollReflect(target) {
+		return SPELL_MISS_REFLECT
+	}
+
+	if s.info.SchoolMask == SPELL_SCHOOL_NORMAL {
+		missChance := meleeSpellMissChance(s.caster.GetLevel(), target.GetLevel())
+		if caster := getBaseUnit(s.caster); caster != nil {
+			missChance -= caster.GetSpellHitChanceBonus()
+		}
+		if rollChance(missChance) {
+			return SPELL_MISS_MISS
+		}
+		return SPELL_MISS_NONE
+	}
+
+	targetIsPlayer := false
+	if unit := getBaseUnit(target); unit != nil {
+		targetIsPlayer = unit.unitType == UNIT_TYPE_PLAYER
+	}
+	missChance := magicSpellMissChance(s.caster.GetLevel(), target.GetLevel(), targetIsPlayer)
+	if caster := getBaseUnit(s.caster); caster != nil {
+		missChance -= caster.GetSpellHitChanceBonus()
+	}
+	if rollChance(missChance) {
+		return SPELL_MISS_MISS
+	}
+
+	// 控制类法术整体抵抗
+	if !s.info.hasDamageEffect() && canBeResisted(s.info.SchoolMask) &&
+		rand.Float32() < calcAverageResist(s.caster, target, s.info.SchoolMask) {
+		return SPELL_MISS_RESIST
+	}
+	return SPELL_MISS_NONE
+}
+
+// magicSpellMissChance 法术未命中率 - 同级4%，高3级首领17%
+func magicSpellMissChance(casterLevel, targetLevel uint8, targetIsPlayer bool) float32 {
+	levelDiff := int(targetLevel) - int(casterLevel)
+
+	var hitChance int
+	if levelDiff < 3 {
+		hitChance = SPELL_BASE_HIT_CHANCE - levelDiff
+	} else {
+		penalty := SPELL_HIT_LEVEL_PENALTY_NPC
+		if targetIsPlayer {
+			penalty = SPELL_HIT_LEVEL_PENALTY_PVP
+		}
+		hitChance = SPELL_HIGH_LEVEL_HIT_CHANCE - (levelDiff-2)*penalty
+	}
+
+	if hitChance < 1 {
+		hitChance = 1
+	} else if hitChance > 100 {
+		hitChance = 100
+	}
+	return float32(100 - hitChance)
+}
+
+// meleeSpellMissChance 物理技能未命中率 - 基础5%，目标每高一级增加1%
+func meleeSpellMissChance(casterLevel, targetLevel uint8) float32 {
+	missChance := float32(MELEE_SPELL_BASE_MISS_CHANCE)
+	if targetLevel > casterLevel {
+		missChance += float32(targetLevel - casterLevel)
+	}
+	return missChance
+}
+
+// calcAverageResist 平均抵抗比例 - 基于AzerothCore的Unit::GetEffectiveResistChance
+// 生物比施法者每高一级获得5点等级抗性
+func calcAverageResist(caster, target IUnit, schoolMask int) float32 {
+	unit := getBaseUnit(target)
+	if unit == nil || !canBeResisted(schoolMask) {
+		return 0
+	}
+
+	resistance := float32(unit.GetResistance(schoolMask))
+	if unit.unitType == UNIT_TYPE_CREATURE && target.GetLevel() > caster.GetLevel() {
+		resistance += float32(target.GetLevel()-caster.GetLevel()) * RESISTANCE_PER_LEVEL
+	}
+	if resistance <= 0 {
+		return 0
+	}
+
+	averageResist := resistance / (resistance + float32(caster.GetLevel())*5)
+	if averageResist > MAX_AVERAGE_RESIST {
+		averageResist = MAX_AVERAGE_RESIST
+	}
+	return averageResist
+}
+
+// calcPartialResist 部分抵抗 - 基于AzerothCore的Unit::CalcAbsorbResist中的离散抵抗分布
+// 以10%为一档，抵抗比例集中在平均抵抗附近
+func calcPartialResist(caster, target IUnit, schoolMask int, damage uint32) uint32 {
+	averageResist := calcAverageResist(caster, target, schoolMask)
+	if averageResist <= 0 {
+		return 0
+	}
+
+	var probabilities [11]float32
+	for i := range probabilities {
+		p := 0.5 - 2.5*float32(math.Abs(float64(0.1*float32(i)-averageResist)))
+		if p > 0 {
+			probabilities[i] = p
+		}
+	}
+	if averageResist <= 0.1 {
+		probabilities[0] = 1 - 7.5*averageResist
+		probabilities[1] = 5 * averageResist
+		probabilities[2] = 2.5 * averageResist
+	}
+
+	roll := rand.Float32()
+	level := 0
+	sum := probabilities[0]
+	for roll >= sum && level < 10 {
+		level++
+		sum += probabilities[level]
+	}
+	return damage * uint32(level) / 10
+}
+
+// canReflect 只有针对单一目标的负面魔法可以被反射
+func (s *Spell) canReflect(target IUnit) bool {
+	return s.info.SchoolMask != SPELL_SCHOOL_NORMAL && s.explicitTarget != nil &&
+		s.explicitTarget.GetGUID() == target.GetGUID()
+}
+
+// rollReflect 按反射光环的几率判定反射，成功后消耗该光环
+func (s *Spell) rollReflect(target IUnit) bool {
+	unit := getBaseUnit(target)
+	if unit == nil {
+		return false
+	}
+	for _, aura := range unit.auras {
+		if aura.auraType == SPELL_AURA_REFLECT_SPELLS && rollChance(float32(aura.value)) {
+			fmt.Printf("%s 反射了 %s 的 %s\n", target.GetName(), s.caster.GetName(), s.info.Name)
+			unit.RemoveAura(aura, AURA_REMOVE_BY_DEFAULT)
+			return true
+		}
+	}
+	return false
+}
+
+// rollTargetHits 为每个目标计算命中结果，未命中的目标不再受效果影响
+func (s *Spell) rollTargetHits() {
+	s.targetMissInfo = make(map[uint64]uint8, len(s.targets))
+	for _, target := range s.targets {
+		missInfo := s.calcSpellHitResult(target)
+		s.targetMissInfo[target.GetGUID()] = missInfo
+		if missInfo != SPELL_MISS_NONE && missInfo != SPELL_MISS_REFLECT {
+			fmt.Printf("%s 的 %s 对 %s %s\n", s.caster.GetName(), s.info.Name, target.GetName(), getSpellMissName(missInfo))
+		}
+	}
+}
+
+// getHitAndMissTargets 拆分命中和未命中的目标，用于SMSG_SPELLGO
+func (s *Spell) getHitAndMissTargets() ([]IUnit, []SpellMissTarget) {
+	var hits []IUnit
+	var misses []SpellMissTarget
+	for _, target := range s.targets {
+		missInfo := s.targetMissInfo[target.GetGUID()]
+		if missInfo == SPELL_MISS_NONE {
+			hits = append(hits, target)
+		} else {
+			misses = append(misses, SpellMissTarget{target: target, missInfo: missInfo})
+		}
+	}
+	return hits, misses
+}
+
+// getEffectTarget 效果实际作用的单位 - 被反射时作用于施法者，未命中时返回nil
+func (s *Spell) getEffectTarget(target IUnit) IUnit {
+	switch s.targetMissInfo[target.GetGUID()] {
+	case SPELL_MISS_NONE:
+		return target
+	case SPELL_MISS_REFLECT:
+		return s.caster
+	}
+	return nil
+}
+
+func getSpellMissName(missInfo uint8) string {
+	switch missInfo {
+	case SPELL_MISS_MISS:
+		return "未命中"
+	case SPELL_MISS_RESIST:
+		return "被抵抗"
+	case SPELL_MISS_EVADE:
+		return "被规避"
+	case SPELL_MISS_IMMUNE:
+		return "免疫"
+	case SPELL_MISS_REFLECT:
+		return "被反射"
+	}
+	return "命中"
+}
@@ -0,0 +1,171 @@
+package main
+
+import "fmt"
+
+// SpellScript 法术脚本 - 基于AzerothCore的SpellScript/AuraScript
+// 按法术ID注册，核心流程在对应时机调用，未设置的钩子不生效
+type SpellScript struct {
+	// OnCheckCast 额外的施法检查，返回SPELL_CAST_OK以外的结果时施法失败
+	OnCheckCast func(spell *Spell, target IUnit) uint8
+	// CalcDamage 修正法术计算出的伤害/治疗量
+	CalcDamage func(spell *Spell, damage uint32) uint32
+	// OnEffectHit 效果命中目标，返回true时跳过默认效果处理(PreventDefaultEffect)
+	OnEffectHit func(spell *Spell, target IUnit, effect *SpellEffect) bool
+	// OnEffectPeriodic 周期光环或引导法术每次触发，返回本次实际生效的数值，返回0时跳过本次触发
+	OnEffectPeriodic func(caster IUnit, target IUnit, effect *SpellEffect, amount uint32) uint32
+	// OnAuraApply 光环应用到目标后
+	OnAuraApply func(aura *Aura)
+	// OnAuraRemove 光环从目标移除后
+	OnAuraRemove func(aura *Aura, removeMode int)
+}
+
+// UnitScript 单位脚本 - 基于AzerothCore的CreatureScript/UnitScript，按脚本名注册
+type UnitScript struct {
+	// OnDamageTaken 受到伤害前调用，返回修正后的伤害
+	OnDamageTaken func(unit *Unit, attacker IUnit, damage uint32, damageType int) uint32
+	// OnKill 该单位杀死目标后调用
+	OnKill func(unit *Unit, victim IUnit)
+}
+
+var (
+	spellScripts = make(map[uint32][]*SpellScript)
+	unitScripts  = make(map[string]*UnitScript)
+)
+
+// RegisterSpellScript 注册法术脚本，同一法术可以注册多个脚本
+func RegisterSpellScript(spellId uint32, script *SpellScript) {
+	spellScripts[spellId] = append(spellScripts[spellId], script)
+}
+
+// GetSpellScripts 获取法术的所有脚本
+func GetSpellScripts(spellId uint32) []*SpellScript {
+	return spellScripts[spellId]
+}
+
+// RegisterUnitScript 注册单位脚本
+func RegisterUnitScript(scriptName string, script *UnitScript) {
+	unitScripts[scriptName] = script
+}
+
+// SetScriptName 为单位绑定脚本 - 基于AzerothCore的creature_template.ScriptName
+func (u *Unit) SetScriptName(scriptName string) {
+	script, exists := unitScripts[scriptName]
+	if !exists {
+		fmt.Printf("单位 %s 的脚本 %s 不存在\n", u.name, scriptName)
+		return
+	}
+	u.script = script
+}
+
+// callCheckCastScripts 调用施法检查脚本
+func (s *Spell) callCheckCastScripts(target IUnit) uint8 {
+	for _, script := range GetSpellScripts(s.info.ID) {
+		if script.OnCheckCast == nil {
+			continue
+		}
+		if result := script.OnCheckCast(s, target); result != SPELL_CAST_OK {
+			return result
+		}
+	}
+	return SPELL_CAST_OK
+}
+
+// callCalcDamageScripts 调用伤害计算脚本
+func (s *Spell) callCalcDamageScripts(damage uint32) uint32 {
+	for _, script := range GetSpellScripts(s.info.ID) {
+		if script.CalcDamage != nil {
+			damage = script.CalcDamage(s, damage)
+		}
+	}
+	return damage
+}
+
+// callEffectHitScripts 调用效果命中脚本，返回是否阻止默认效果
+func (s *Spell) callEffectHitScripts(target IUnit, effect *SpellEffect) bool {
+	prevented := false
+	for _, script := range GetSpellScripts(s.info.ID) {
+		if script.OnEffectHit != nil && script.OnEffectHit(s, target, effect) {
+			prevented = true
+		}
+	}
+	return prevented
+}
+
+// callEffectPeriodicScripts 调用周期效果脚本 - 周期光环和引导法术的每次触发共用
+func callEffectPeriodicScripts(spellId uint32, caster, target IUnit, effect *SpellEffect, amount uint32) uint32 {
+	for _, script := range GetSpellScripts(spellId) {
+		if script.OnEffectPeriodic != nil {
+			amount = script.OnEffectPeriodic(caster, target, effect, amount)
+		}
+	}
+	return amount
+}
+
+// callAuraApplyScripts 调用光环应用脚本
+func (a *Aura) callAuraApplyScripts() {
+	for _, script := range GetSpellScripts(a.id) {
+		if script.OnAuraApply != nil {
+			script.OnAuraApply(a)
+		}
+	}
+}
+
+// callAuraRemoveScripts 调用光环移除脚本
+func (a *Aura) callAuraRemoveScripts(removeMode int) {
+	for _, script := range GetSpellScripts(a.id) {
+		if script.OnAuraRemove != nil {
+			script.OnAuraRemove(a, removeMode)
+		}
+	}
+}
+
+// 内置法术脚本 - 原先在handleDummyEffect中按法术ID特殊处理的效果
+func init() {
+	// 嘲讽 - 仇恨提升至最高并强制攻击施法者
+	RegisterSpellScript(SPELL_TAUNT, &SpellScript{
+		OnEffectHit: func(spell *Spell, target IUnit, effect *SpellEffect) bool {
+			if effect.EffectType != SPELL_EFFECT_DUMMY || target.GetAI() == nil {
+				return false
+			}
+			if tm := getThreatManager(target); tm != nil {
+				tm.Taunt(spell.caster)
+			}
+			target.SetVictim(spell.caster)
+			fmt.Printf("%s 被 %s 嘲讽了\n", target.GetName(), spell.caster.GetName())
+			return true
+		},
+	})
+
+	// 渐隐术 - 临时降低施法者在所有敌人仇恨列表中的仇恨
+	RegisterSpellScript(SPELL_FADE, &SpellScript{
+		OnEffectHit: func(spell *Spell, target IUnit, effect *SpellEffect) bool {
+			caster := getBaseUnit(spell.caster)
+			if effect.EffectType != SPELL_EFFECT_DUMMY || caster == nil {
+				return false
+			}
+			// 包括只有治疗仇恨、没有攻击施法者的怪物
+			for _, tm := range caster.threatManager.GetThreatenedByMeList() {
+				tm.ApplyTemporaryThreatReduction(spell.caster, float32(effect.BasePoints), uint32(effect.Amplitude))
+			}
+			return true
+		},
+	})
+
+	// 真言术：盾 - 护盾效果，简化实现
+	RegisterSpellScript(SPELL_POWER_WORD_SHIELD, &SpellScript{
+		OnEffectHit: func(spell *Spell, target IUnit, effect *SpellEffect) bool {
+			if effect.EffectType != SPELL_EFFECT_DUMMY {
+				return false
+			}
+			fmt.Printf("%s 获得了 %d 点护盾保护\n", target.GetName(), spell.healing)
+			return true
+		},
+	})
+
+	// 训练假人 - 不会受到伤害
+	RegisterUnitScript("npc_training_dummy", &UnitScript{
+		OnDamageTaken: func(unit *Unit, attacker IUnit, damage uint32, damageType int) uint32 {
+			return 0
+		},
+	})
+}
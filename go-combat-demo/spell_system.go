@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"math/rand"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -23,19 +25,30 @@ const (
 	CURRENT_AUTOREPEAT_SPELL = 3 // 自动重复法术
 
 	// 法术效果类型 - 基于AzerothCore的SpellEffects
-	SPELL_EFFECT_NONE          = 0   // 无效果
-	SPELL_EFFECT_INSTAKILL     = 1   // 即死
-	SPELL_EFFECT_SCHOOL_DAMAGE = 2   // 学派伤害
-	SPELL_EFFECT_DUMMY         = 3   // 虚拟效果
-	SPELL_EFFECT_HEAL          = 10  // 治疗
-	SPELL_EFFECT_ENERGIZE      = 43  // 回复能量
-	SPELL_EFFECT_WEAPON_DAMAGE = 121 // 武器伤害
+	SPELL_EFFECT_NONE           = 0   // 无效果
+	SPELL_EFFECT_INSTAKILL      = 1   // 即死
+	SPELL_EFFECT_SCHOOL_DAMAGE  = 2   // 学派伤害
+	SPELL_EFFECT_DUMMY          = 3   // 虚拟效果
+	SPELL_EFFECT_APPLY_AURA     = 6   // 应用光环
+	SPELL_EFFECT_HEAL           = 10  // 治疗
+	SPELL_EFFECT_RESURRECT      = 18  // 复活(按百分比恢复生命和法力)
+	SPELL_EFFECT_INTERRUPT_CAST = 68  // 打断施法
+	SPELL_EFFECT_ENERGIZE       = 43  // 回复能量
+	SPELL_EFFECT_DUEL           = 83  // 发起决斗
+	SPELL_EFFECT_WEAPON_DAMAGE  = 121 // 武器伤害
 
 	// 法术目标类型 - 基于AzerothCore的Targets
-	TARGET_UNIT_TARGET_ENEMY = 6  // 敌方单体目标
-	TARGET_UNIT_CASTER       = 1  // 施法者自己
-	TARGET_UNIT_TARGET_ALLY  = 7  // 友方单体目标
-	TARGET_DEST_TARGET_ENEMY = 16 // 敌方目标位置
+	TARGET_UNIT_CASTER                = 1  // 施法者自己
+	TARGET_UNIT_TARGET_ENEMY          = 6  // 敌方单体目标
+	TARGET_UNIT_SRC_AREA_ENEMY        = 15 // 区域中心(施法者)周围的敌人
+	TARGET_UNIT_DEST_AREA_ENEMY       = 16 // 目标位置周围的敌人
+	TARGET_UNIT_CASTER_AREA_PARTY     = 20 // 施法者周围的队友
+	TARGET_UNIT_TARGET_ALLY           = 21 // 友方单体目标
+	TARGET_SRC_CASTER                 = 22 // 以施法者为区域中心
+	TARGET_UNIT_CONE_ENEMY            = 24 // 施法者前方锥形范围内的敌人
+	TARGET_UNIT_TARGET_CHAINHEAL_ALLY = 45 // 友方目标，跳跃到最需要治疗的友方
+	TARGET_DEST_TARGET_ENEMY          = 53 // 以敌方目标的位置为区域中心
+	TARGET_UNIT_CASTER_AREA_RAID      = 56 // 施法者周围的团队成员
 
 	// 法术属性 - 基于AzerothCore的SpellAttr
 	SPELL_ATTR0_ON_NEXT_SWING_1               = 0x00000004 // 下次攻击触发
@@ -53,6 +66,54 @@ const (
 	SPELL_ATTR0_UNAFFECTED_BY_INVULNERABILITY = 0x00008000 // 不受无敌影响
 	SPELL_ATTR0_HEARTBEAT_RESIST_CHECK        = 0x00010000 // 心跳抗性检查
 	SPELL_ATTR0_CANT_CANCEL                   = 0x00020000 // 无法取消
+
+	// 施法推迟 - 基于AzerothCore的Spell::Delayed/DelayedChannel
+	SPELL_PUSHBACK_DELAY       = 500 * time.Millisecond // 每次受击推迟施法时间
+	SPELL_CHANNEL_PUSHBACK_PCT = 25                     // 每次受击缩短引导时间的百分比
+	SPELL_MAX_PUSHBACK_COUNT   = 2                      // 单次施法最多被推迟的次数
+
+	// 公共冷却 - 基于AzerothCore的Spell::TriggerGlobalCooldown
+	SPELL_GCD_DURATION = 1500 * time.Millisecond // 标准公共冷却
+	SPELL_MIN_GCD      = 1000 * time.Millisecond // 急速能缩短到的最短公共冷却
+
+	// 法术类别 - 同类别法术共享冷却(SpellCategory.dbc)
+	SPELL_CATEGORY_GCD_DEFAULT  = 133  // 标准公共冷却类别(StartRecoveryCategory)
+	SPELL_CATEGORY_HUNTER_SHOTS = 1173 // 瞄准射击与多重射击共享冷却
+)
+
+// 施法结果 - 基于AzerothCore的SpellCastResult
+const (
+	SPELL_FAILED_BAD_TARGETS       uint8 = 12  // 无效的目标
+	SPELL_FAILED_CASTER_DEAD       uint8 = 23  // 施法者已死亡
+	SPELL_FAILED_CONFUSED          uint8 = 26  // 处于迷惑状态
+	SPELL_FAILED_FLEEING           uint8 = 34  // 处于恐惧状态
+	SPELL_FAILED_INTERRUPTED       uint8 = 40  // 被打断
+	SPELL_FAILED_NOT_KNOWN         uint8 = 63  // 未知法术
+	SPELL_FAILED_NOT_READY         uint8 = 67  // 冷却中(含学派封锁)
+	SPELL_FAILED_NO_POWER          uint8 = 85  // 能量不足
+	SPELL_FAILED_OUT_OF_RANGE      uint8 = 97  // 超出距离
+	SPELL_FAILED_SPELL_IN_PROGRESS uint8 = 105 // 正在施放其他法术
+	SPELL_FAILED_STUNNED           uint8 = 108 // 处于昏迷状态
+	SPELL_CAST_OK                  uint8 = 255 // 施法成功
+)
+
+// 法术未命中类型 - 基于AzerothCore的SpellMissInfo
+const (
+	SPELL_MISS_NONE    uint8 = 0  // 命中
+	SPELL_MISS_MISS    uint8 = 1  // 未命中
+	SPELL_MISS_RESIST  uint8 = 2  // 抵抗
+	SPELL_MISS_DODGE   uint8 = 3  // 闪避
+	SPELL_MISS_PARRY   uint8 = 4  // 招架
+	SPELL_MISS_BLOCK   uint8 = 5  // 格挡
+	SPELL_MISS_EVADE   uint8 = 6  // 脱战返回中
+	SPELL_MISS_IMMUNE  uint8 = 7  // 免疫
+	SPELL_MISS_DEFLECT uint8 = 9  // 偏转
+	SPELL_MISS_REFLECT uint8 = 11 // 反射
+)
+
+// 弹道 - 基于AzerothCore的Spell::CalculateDelayMomentForDst
+const (
+	SPELL_MIN_MISSILE_DISTANCE = 5.0 // 距离小于5码时按5码计算飞行时间
 )
 
 // 法术ID定义 - 基于经典魔兽世界法术
@@ -74,6 +135,8 @@ const (
 	SPELL_POWER_WORD_SHIELD = 17   // 真言术：盾 - 即时法术
 	SPELL_HOLY_LIGHT        = 635  // 圣光术 - 施法法术
 	SPELL_SMITE             = 585  // 惩击 - 施法法术
+	SPELL_FADE              = 586  // 渐隐术 - 即时法术(临时降低仇恨)
+	SPELL_RESURRECTION      = 2006 // 复活术 - 施法法术(复活死亡的友方玩家)
 
 	// 术士法术
 	SPELL_SHADOW_BOLT = 686  // 暗影箭 - 施法法术
@@ -87,31 +150,48 @@ const (
 	SPELL_CHARGE        = 100   // 冲锋 - 即时技能
 	SPELL_TAUNT         = 355   // 嘲讽 - 即时技能
 	SPELL_SHIELD_SLAM   = 23922 // 盾牌猛击 - 即时技能
+	SPELL_BATTLE_SHOUT  = 6673  // 战斗怒吼 - 即时技能(小队攻击强度)
 
 	// 猎人技能
 	SPELL_AIMED_SHOT  = 19434 // 瞄准射击 - 施法技能
 	SPELL_MULTI_SHOT  = 2643  // 多重射击 - 即时技能
 	SPELL_HUNTER_MARK = 1130  // 猎人印记 - 即时技能
+
+	// 圣骑士技能
+	SPELL_HAMMER_OF_JUSTICE = 853 // 制裁之锤 - 即时技能(昏迷)
+	SPELL_DIVINE_SHIELD     = 642 // 圣盾术 - 即时技能(免疫所有学派)
 )
 
 // SpellInfo 法术信息 - 基于AzerothCore的SpellInfo
 type SpellInfo struct {
-	ID             uint32        // 法术ID
-	Name           string        // 法术名称
-	Description    string        // 法术描述
-	CastTime       time.Duration // 施法时间
-	Cooldown       time.Duration // 冷却时间
-	ManaCost       uint32        // 法力消耗
-	Range          float32       // 施法距离
-	SchoolMask     int           // 法术学派
-	Effects        []SpellEffect // 法术效果
-	Attributes     uint32        // 法术属性
-	TargetType     int           // 目标类型
-	IsChanneled    bool          // 是否为引导法术
-	ChannelTime    time.Duration // 引导时间
-	BaseDamage     uint32        // 基础伤害
-	DamageVariance float32       // 伤害浮动
-	Level          uint8         // 法术等级
+	ID                 uint32        // 法术ID
+	Name               string        // 法术名称
+	Description        string        // 法术描述
+	CastTime           time.Duration // 施法时间
+	Cooldown           time.Duration // 冷却时间
+	ManaCost           uint32        // 能量消耗(按PowerType计)
+	PowerType          uint8         // 消耗的能量类型(POWER_*)
+	ManaCostPercentage uint32        // 按基础能量百分比计算的额外消耗
+	Range              float32       // 施法距离
+	SchoolMask         int           // 法术学派
+	Effects            []SpellEffect // 法术效果
+	Attributes         uint32        // 法术属性
+	TargetType         int           // 目标类型
+	IsChanneled        bool          // 是否为引导法术
+	ChannelTime        time.Duration // 引导时间
+	BaseDamage         uint32        // 基础伤害
+	DamageVariance     float32       // 伤害浮动
+	Level              uint8         // 法术等级
+	Duration           time.Duration // 光环持续时间(打断类法术为学派封锁时间)
+
+	Category              uint32        // 法术类别，同类别共享冷却
+	CategoryCooldown      time.Duration // 类别冷却时间
+	StartRecoveryCategory uint32        // 公共冷却类别，0表示不触发公共冷却
+	StartRecoveryTime     time.Duration // 公共冷却时间
+
+	AuraInterruptFlags uint32  // 光环打断标志(AURA_INTERRUPT_FLAG_*)
+	MaxAffectedTargets uint32  // 范围效果最多影响的目标数，0表示不限制
+	Speed              float32 // 弹道速度(码/秒)，0表示立即命中
 }
 
 // SpellEffect 法术效果
@@ -127,6 +207,8 @@ type SpellEffect struct {
 	ApplyAuraName      int     // 应用光环名称
 	Amplitude          int32   // 振幅（DOT/HOT间隔）
 	MultipleValue      float32 // 倍数值
+	ChainTarget        uint32  // 连锁目标数(包括第一个目标)
+	MiscValue          int32   // 附加数值(如免疫光环的学派掩码或机制)
 }
 
 // Spell 法术实例 - 基于AzerothCore的Spell类
@@ -142,11 +224,43 @@ type Spell struct {
 	healing     uint32        // 计算出的治疗
 	interrupted bool          // 是否被打断
 	world       *World        // 世界引用
+
+	delayAtDamageCount int // 本次施法已被推迟的次数
+
+	explicitTarget IUnit            // 施法时选择的目标
+	dest           SpellDestination // 目标位置(地面范围法术)
+	effectTargets  [][]IUnit        // 每个效果选中的目标
+
+	targetMissInfo map[uint64]uint8   // 每个目标的命中结果(SPELL_MISS_*)
+	delayedTargets []*SpellTargetInfo // 弹道飞行中的目标
+	delayMoment    uint32             // 弹道发射后经过的时间(毫秒)
+}
+
+// SpellTargetInfo 弹道法术的目标 - 基于AzerothCore的Spell::TargetInfo
+type SpellTargetInfo struct {
+	target    IUnit
+	timeDelay uint32 // 命中所需时间(毫秒)
+	processed bool   // 是否已处理
+}
+
+// SpellThreatEntry 法术仇恨修正 - 基于AzerothCore的spell_threat表
+type SpellThreatEntry struct {
+	FlatMod int32   // 额外固定仇恨
+	PctMod  float32 // 伤害仇恨倍率
+}
+
+// SpellCooldownEntry 冷却通知条目 - 一个SMSG_SPELL_COOLDOWN可携带多个
+type SpellCooldownEntry struct {
+	SpellId  uint32
+	Cooldown time.Duration
 }
 
 // SpellManager 法术管理器 - 管理所有法术信息
 type SpellManager struct {
-	spells map[uint32]*SpellInfo // 法术信息表
+	spells       map[uint32]*SpellInfo        // 法术信息表
+	spellThreats map[uint32]*SpellThreatEntry // 法术仇恨修正表
+	templatePath string                       // 法术数据文件路径
+	mutex        sync.RWMutex                 // 保护法术信息表，重载时整体替换
 }
 
 // 全局法术管理器
@@ -155,338 +269,80 @@ var GlobalSpellManager *SpellManager
 // 初始化法术管理器
 func InitSpellManager() {
 	GlobalSpellManager = &SpellManager{
-		spells: make(map[uint32]*SpellInfo),
+		spells:       make(map[uint32]*SpellInfo),
+		spellThreats: make(map[uint32]*SpellThreatEntry),
+		templatePath: SPELL_TEMPLATE_PATH,
+	}
+	if err := GlobalSpellManager.LoadSpells(); err != nil {
+		fmt.Printf("加载法术数据失败: %v\n", err)
 	}
-	GlobalSpellManager.LoadSpells()
+	GlobalSpellManager.LoadSpellThreats()
 }
 
 // LoadSpells 加载法术信息 - 基于AzerothCore的spell_template表
-func (sm *SpellManager) LoadSpells() {
-	// 法师法术
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_FROSTBOLT,
-		Name:           "寒冰箭",
-		Description:    "向目标发射一枚寒冰箭，造成冰霜伤害并降低移动速度",
-		CastTime:       2500 * time.Millisecond, // 2.5秒施法时间
-		Cooldown:       0,
-		ManaCost:       125,
-		Range:          30.0,
-		SchoolMask:     SPELL_SCHOOL_FROST,
-		TargetType:     TARGET_UNIT_TARGET_ENEMY,
-		BaseDamage:     350,
-		DamageVariance: 0.15, // 15%伤害浮动
-		Level:          20,
-		Effects: []SpellEffect{
-			{
-				EffectType:      SPELL_EFFECT_SCHOOL_DAMAGE,
-				BasePoints:      350,
-				DicePerLevel:    2.8,
-				ImplicitTargetA: TARGET_UNIT_TARGET_ENEMY,
-			},
-		},
-	})
-
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_FIREBALL,
-		Name:           "火球术",
-		Description:    "向目标发射一枚火球，造成火焰伤害",
-		CastTime:       3000 * time.Millisecond, // 3秒施法时间
-		Cooldown:       0,
-		ManaCost:       155,
-		Range:          35.0,
-		SchoolMask:     SPELL_SCHOOL_FIRE,
-		TargetType:     TARGET_UNIT_TARGET_ENEMY,
-		BaseDamage:     450,
-		DamageVariance: 0.2, // 20%伤害浮动
-		Level:          25,
-		Effects: []SpellEffect{
-			{
-				EffectType:      SPELL_EFFECT_SCHOOL_DAMAGE,
-				BasePoints:      450,
-				DicePerLevel:    3.2,
-				ImplicitTargetA: TARGET_UNIT_TARGET_ENEMY,
-			},
-		},
-	})
-
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_FROST_NOVA,
-		Name:           "冰霜新星",
-		Description:    "冻结周围的敌人，造成冰霜伤害并定身",
-		CastTime:       0, // 即时法术
-		Cooldown:       25 * time.Second,
-		ManaCost:       85,
-		Range:          0, // 以自身为中心
-		SchoolMask:     SPELL_SCHOOL_FROST,
-		TargetType:     TARGET_UNIT_CASTER,
-		BaseDamage:     180,
-		DamageVariance: 0.1,
-		Level:          10,
-		Attributes:     SPELL_ATTR0_IMPOSSIBLE_DODGE_PARRY_BLOCK,
-		Effects: []SpellEffect{
-			{
-				EffectType:   SPELL_EFFECT_SCHOOL_DAMAGE,
-				BasePoints:   180,
-				DicePerLevel: 1.5,
-				RadiusIndex:  8, // 8码范围
-			},
-		},
-	})
-
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_BLIZZARD,
-		Name:           "暴风雪",
-		Description:    "在目标区域召唤暴风雪，持续造成冰霜伤害",
-		CastTime:       0, // 即时施法
-		Cooldown:       8 * time.Second,
-		ManaCost:       320,
-		Range:          35.0,
-		SchoolMask:     SPELL_SCHOOL_FROST,
-		TargetType:     TARGET_DEST_TARGET_ENEMY,
-		IsChanneled:    true,
-		ChannelTime:    8 * time.Second, // 8秒引导
-		BaseDamage:     120,
-		DamageVariance: 0.25,
-		Level:          20,
-		Effects: []SpellEffect{
-			{
-				EffectType:   SPELL_EFFECT_SCHOOL_DAMAGE,
-				BasePoints:   120,
-				DicePerLevel: 2.0,
-				Amplitude:    1000, // 每秒触发
-				RadiusIndex:  8,    // 8码范围
-			},
-		},
-	})
-
-	// 牧师法术
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_HEAL,
-		Name:           "治疗术",
-		Description:    "治疗友方目标",
-		CastTime:       3000 * time.Millisecond, // 3秒施法
-		Cooldown:       0,
-		ManaCost:       155,
-		Range:          40.0,
-		SchoolMask:     SPELL_SCHOOL_HOLY,
-		TargetType:     TARGET_UNIT_TARGET_ALLY,
-		BaseDamage:     600, // 这里用作治疗量
-		DamageVariance: 0.15,
-		Level:          15,
-		Effects: []SpellEffect{
-			{
-				EffectType:      SPELL_EFFECT_HEAL,
-				BasePoints:      600,
-				DicePerLevel:    4.0,
-				ImplicitTargetA: TARGET_UNIT_TARGET_ALLY,
-			},
-		},
-	})
-
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_FLASH_HEAL,
-		Name:           "快速治疗",
-		Description:    "快速治疗友方目标",
-		CastTime:       1500 * time.Millisecond, // 1.5秒施法
-		Cooldown:       0,
-		ManaCost:       215,
-		Range:          40.0,
-		SchoolMask:     SPELL_SCHOOL_HOLY,
-		TargetType:     TARGET_UNIT_TARGET_ALLY,
-		BaseDamage:     400, // 治疗量
-		DamageVariance: 0.2,
-		Level:          20,
-		Effects: []SpellEffect{
-			{
-				EffectType:      SPELL_EFFECT_HEAL,
-				BasePoints:      400,
-				DicePerLevel:    3.0,
-				ImplicitTargetA: TARGET_UNIT_TARGET_ALLY,
-			},
-		},
-	})
-
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_POWER_WORD_SHIELD,
-		Name:           "真言术：盾",
-		Description:    "为目标提供伤害吸收护盾",
-		CastTime:       0, // 即时法术
-		Cooldown:       4 * time.Second,
-		ManaCost:       125,
-		Range:          30.0,
-		SchoolMask:     SPELL_SCHOOL_HOLY,
-		TargetType:     TARGET_UNIT_TARGET_ALLY,
-		BaseDamage:     500, // 护盾吸收量
-		DamageVariance: 0.1,
-		Level:          12,
-		Effects: []SpellEffect{
-			{
-				EffectType:      SPELL_EFFECT_DUMMY, // 护盾效果
-				BasePoints:      500,
-				DicePerLevel:    3.5,
-				ImplicitTargetA: TARGET_UNIT_TARGET_ALLY,
-			},
-		},
-	})
-
-	// 术士法术
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_SHADOW_BOLT,
-		Name:           "暗影箭",
-		Description:    "向目标发射暗影能量，造成暗影伤害",
-		CastTime:       2500 * time.Millisecond, // 2.5秒施法
-		Cooldown:       0,
-		ManaCost:       140,
-		Range:          30.0,
-		SchoolMask:     SPELL_SCHOOL_SHADOW,
-		TargetType:     TARGET_UNIT_TARGET_ENEMY,
-		BaseDamage:     380,
-		DamageVariance: 0.18,
-		Level:          18,
-		Effects: []SpellEffect{
-			{
-				EffectType:      SPELL_EFFECT_SCHOOL_DAMAGE,
-				BasePoints:      380,
-				DicePerLevel:    3.0,
-				ImplicitTargetA: TARGET_UNIT_TARGET_ENEMY,
-			},
-		},
-	})
-
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_IMMOLATE,
-		Name:           "献祭",
-		Description:    "点燃目标，立即造成火焰伤害并持续燃烧",
-		CastTime:       2000 * time.Millisecond, // 2秒施法
-		Cooldown:       0,
-		ManaCost:       110,
-		Range:          30.0,
-		SchoolMask:     SPELL_SCHOOL_FIRE,
-		TargetType:     TARGET_UNIT_TARGET_ENEMY,
-		BaseDamage:     180,
-		DamageVariance: 0.15,
-		Level:          8,
-		Effects: []SpellEffect{
-			{
-				EffectType:      SPELL_EFFECT_SCHOOL_DAMAGE,
-				BasePoints:      180,
-				DicePerLevel:    1.8,
-				ImplicitTargetA: TARGET_UNIT_TARGET_ENEMY,
-			},
-		},
-	})
+// 数据来自法术数据文件，解析后合并脚本修正再整体替换，加载失败时保留原有数据
+func (sm *SpellManager) LoadSpells() error {
+	content, source, err := readSpellTemplate(sm.templatePath)
+	if err != nil {
+		return err
+	}
+	spells, err := parseSpellTemplate(content)
+	if err != nil {
+		return err
+	}
+	applySpellInfoOverrides(spells)
 
-	// 战士技能
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_HEROIC_STRIKE,
-		Name:           "英勇打击",
-		Description:    "下次近战攻击造成额外伤害",
-		CastTime:       0, // 即时技能
-		Cooldown:       0,
-		ManaCost:       0, // 消耗怒气
-		Range:          5.0,
-		SchoolMask:     SPELL_SCHOOL_NORMAL,
-		TargetType:     TARGET_UNIT_TARGET_ENEMY,
-		BaseDamage:     150,
-		DamageVariance: 0.1,
-		Level:          1,
-		Attributes:     SPELL_ATTR0_ON_NEXT_SWING_1,
-		Effects: []SpellEffect{
-			{
-				EffectType:      SPELL_EFFECT_WEAPON_DAMAGE,
-				BasePoints:      150,
-				DicePerLevel:    2.0,
-				ImplicitTargetA: TARGET_UNIT_TARGET_ENEMY,
-			},
-		},
-	})
-
-	sm.AddSpell(&SpellInfo{
-		ID:          SPELL_TAUNT,
-		Name:        "嘲讽",
-		Description: "强制敌人攻击你",
-		CastTime:    0, // 即时技能
-		Cooldown:    10 * time.Second,
-		ManaCost:    0,
-		Range:       5.0,
-		SchoolMask:  SPELL_SCHOOL_NORMAL,
-		TargetType:  TARGET_UNIT_TARGET_ENEMY,
-		BaseDamage:  0,
-		Level:       10,
-		Attributes:  SPELL_ATTR0_IMPOSSIBLE_DODGE_PARRY_BLOCK,
-		Effects: []SpellEffect{
-			{
-				EffectType:      SPELL_EFFECT_DUMMY, // 嘲讽效果
-				BasePoints:      0,
-				ImplicitTargetA: TARGET_UNIT_TARGET_ENEMY,
-			},
-		},
-	})
+	sm.mutex.Lock()
+	sm.spells = spells
+	sm.mutex.Unlock()
 
-	// 猎人技能
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_AIMED_SHOT,
-		Name:           "瞄准射击",
-		Description:    "精确瞄准射击，造成大量伤害",
-		CastTime:       3000 * time.Millisecond, // 3秒施法
-		Cooldown:       6 * time.Second,
-		ManaCost:       0, // 消耗集中值
-		Range:          35.0,
-		SchoolMask:     SPELL_SCHOOL_NORMAL,
-		TargetType:     TARGET_UNIT_TARGET_ENEMY,
-		BaseDamage:     550,
-		DamageVariance: 0.12,
-		Level:          20,
-		Effects: []SpellEffect{
-			{
-				EffectType:      SPELL_EFFECT_WEAPON_DAMAGE,
-				BasePoints:      550,
-				DicePerLevel:    4.5,
-				ImplicitTargetA: TARGET_UNIT_TARGET_ENEMY,
-			},
-		},
-	})
-
-	sm.AddSpell(&SpellInfo{
-		ID:             SPELL_MULTI_SHOT,
-		Name:           "多重射击",
-		Description:    "同时射击多个目标",
-		CastTime:       0, // 即时技能
-		Cooldown:       10 * time.Second,
-		ManaCost:       0,
-		Range:          35.0,
-		SchoolMask:     SPELL_SCHOOL_NORMAL,
-		TargetType:     TARGET_UNIT_TARGET_ENEMY,
-		BaseDamage:     280,
-		DamageVariance: 0.2,
-		Level:          18,
-		Effects: []SpellEffect{
-			{
-				EffectType:   SPELL_EFFECT_WEAPON_DAMAGE,
-				BasePoints:   280,
-				DicePerLevel: 2.8,
-				RadiusIndex:  8, // 影响范围内多个目标
-			},
-		},
-	})
-
-	fmt.Printf("法术管理器初始化完成，加载了 %d 个法术\n", len(sm.spells))
+	fmt.Printf("法术管理器初始化完成，从%s加载了 %d 个法术\n", source, len(spells))
+	return nil
 }
 
+// LoadSpellThreats 加载法术仇恨修正 - 基于AzerothCore的spell_threat表
+func (sm *SpellManager) LoadSpellThreats() {
+	sm.spellThreats[SPELL_HEROIC_STRIKE] = &SpellThreatEntry{FlatMod: 173, PctMod: 1.0}
+	sm.spellThreats[SPELL_SHIELD_SLAM] = &SpellThreatEntry{FlatMod: 770, PctMod: 1.0}
+	sm.spellThreats[SPELL_MULTI_SHOT] = &SpellThreatEntry{FlatMod: 0, PctMod: 0.5}
 
+	fmt.Printf("加载了 %d 条法术仇恨修正\n", len(sm.spellThreats))
+}
 
-
+// GetSpellThreatEntry 获取法术仇恨修正
+func (sm *SpellManager) GetSpellThreatEntry(spellId uint32) *SpellThreatEntry {
+	return sm.spellThreats[spellId]
+}
 
 // AddSpell 添加法术
 func (sm *SpellManager) AddSpell(spell *SpellInfo) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
 	sm.spells[spell.ID] = spell
 }
 
 // GetSpell 获取法术信息
 func (sm *SpellManager) GetSpell(spellId uint32) *SpellInfo {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
 	return sm.spells[spellId]
 }
 
+// GetSpellsByCategory 获取同一类别的所有法术，按ID排序
+func (sm *SpellManager) GetSpellsByCategory(category uint32) []*SpellInfo {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	var spells []*SpellInfo
+	for _, spell := range sm.spells {
+		if spell.Category == category {
+			spells = append(spells, spell)
+		}
+	}
+	sort.Slice(spells, func(i, j int) bool { return spells[i].ID < spells[j].ID })
+	return spells
+}
+
 // NewSpell 创建法术实例 - 基于AzerothCore的Spell构造函数
 func NewSpell(caster IUnit, spellInfo *SpellInfo, world *World) *Spell {
 	return &Spell{
@@ -504,14 +360,13 @@ func NewSpell(caster IUnit, spellInfo *SpellInfo, world *World) *Spell {
 // Prepare 准备法术 - 基于AzerothCore的Spell::prepare
 func (s *Spell) Prepare(target IUnit) bool {
 	// 检查施法条件
-	if !s.checkCast(target) {
+	if result := s.checkCast(target); result != SPELL_CAST_OK {
+		s.sendCastResult(result)
 		return false
 	}
 
-	// 添加目标
-	if target != nil {
-		s.targets = append(s.targets, target)
-	}
+	// 记录施法目标
+	s.setExplicitTarget(target)
 
 	// 计算伤害/治疗
 	s.calculateDamage()
@@ -537,6 +392,7 @@ func (s *Spell) Prepare(target IUnit) bool {
 			s.caster.GetName(), s.info.Name, s.info.ChannelTime.Seconds())
 
 		// 立即开始引导效果
+		s.selectSpellTargets()
 		s.sendSpellGo()
 		s.startChanneling()
 	} else {
@@ -552,84 +408,91 @@ func (s *Spell) Prepare(target IUnit) bool {
 	return true
 }
 
-// checkCast 检查施法条件 - 基于AzerothCore的Spell::CheckCast
-func (s *Spell) checkCast(target IUnit) bool {
+// checkCast 检查施法条件 - 基于AzerothCore的Spell::CheckCast，返回SpellCastResult
+func (s *Spell) checkCast(target IUnit) uint8 {
 	// 检查施法者是否存活
 	if !s.caster.IsAlive() {
 		fmt.Printf("%s 已死亡，无法施法\n", s.caster.GetName())
-		return false
+		return SPELL_FAILED_CASTER_DEAD
+	}
+
+	// 昏迷、恐惧、变形期间无法施法
+	switch {
+	case s.caster.HasUnitState(UNIT_STATE_STUNNED):
+		fmt.Printf("%s 处于昏迷状态，无法施放 %s\n", s.caster.GetName(), s.info.Name)
+		return SPELL_FAILED_STUNNED
+	case s.caster.HasUnitState(UNIT_STATE_FLEEING):
+		fmt.Printf("%s 处于恐惧状态，无法施放 %s\n", s.caster.GetName(), s.info.Name)
+		return SPELL_FAILED_FLEEING
+	case s.caster.HasUnitState(UNIT_STATE_CONFUSED):
+		fmt.Printf("%s 处于迷惑状态，无法施放 %s\n", s.caster.GetName(), s.info.Name)
+		return SPELL_FAILED_CONFUSED
+	}
+
+	// 检查学派封锁(法术反制等)
+	if caster := getBaseUnit(s.caster); caster != nil && caster.IsSchoolLocked(s.info.SchoolMask) {
+		fmt.Printf("%s 的%s系法术被封锁，无法施放 %s\n", s.caster.GetName(), s.getSchoolName(), s.info.Name)
+		return SPELL_FAILED_NOT_READY
 	}
 
 	// 检查法力/能量
 	if !s.checkPower() {
 		fmt.Printf("%s 能量不足，无法施放 %s\n", s.caster.GetName(), s.info.Name)
-		return false
+		return SPELL_FAILED_NO_POWER
 	}
 
 	// 检查目标
 	if target != nil && !s.isValidTarget(target) {
 		fmt.Printf("无效的目标\n")
-		return false
+		return SPELL_FAILED_BAD_TARGETS
 	}
 
 	// 检查距离
 	if target != nil && s.info.Range > 0 {
 		distance := s.caster.GetDistanceTo(target)
+		// 已释放灵魂的玩家按尸体的位置计算距离
+		if player := getPlayer(target); player != nil && player.GetCorpse() != nil {
+			distance = player.GetCorpse().GetDistanceTo(s.caster)
+		}
 		if distance > s.info.Range {
 			fmt.Printf("目标距离过远 (%.1f > %.1f)\n", distance, s.info.Range)
-			return false
+			return SPELL_FAILED_OUT_OF_RANGE
 		}
 	}
 
-	return true
+	// 法术脚本的额外检查
+	return s.callCheckCastScripts(target)
 }
 
-// checkPower 检查能量消耗
-func (s *Spell) checkPower() bool {
-	if s.info.ManaCost == 0 {
-		return true
+// sendCastResult 通知施法者施法失败原因
+func (s *Spell) sendCastResult(result uint8) {
+	if s.world == nil || result == SPELL_CAST_OK {
+		return
 	}
+	s.world.SendCastResult(s.caster, s.info.ID, result)
+}
 
-	// 根据职业检查不同的能量类型
-	if player, ok := s.caster.(*Player); ok {
-		switch player.class {
-		case CLASS_WARRIOR:
-			// 战士技能通常消耗怒气，这里简化处理
-			return s.caster.GetPower(POWER_RAGE) >= s.info.ManaCost
-		case CLASS_HUNTER:
-			// 猎人技能消耗集中值
-			return s.caster.GetPower(POWER_FOCUS) >= s.info.ManaCost
-		case CLASS_ROGUE:
-			// 盗贼技能消耗能量
-			return s.caster.GetPower(POWER_ENERGY) >= s.info.ManaCost
-		default:
-			// 其他职业消耗法力
-			return s.caster.GetPower(POWER_MANA) >= s.info.ManaCost
-		}
+// checkPower 检查能量消耗 - 消耗的能量类型由法术决定
+func (s *Spell) checkPower() bool {
+	cost := s.calcPowerCost()
+	if cost == 0 {
+		return true
 	}
-
-	return s.caster.GetPower(POWER_MANA) >= s.info.ManaCost
+	return s.caster.GetPower(s.info.PowerType) >= cost
 }
 
-// takePower 消耗能量
+// takePower 消耗能量，消耗法力时触发五秒规则
 func (s *Spell) takePower() {
-	if s.info.ManaCost == 0 {
+	cost := s.calcPowerCost()
+	if cost == 0 {
 		return
 	}
 
-	if player, ok := s.caster.(*Player); ok {
-		switch player.class {
-		case CLASS_WARRIOR:
-			s.caster.ModifyPower(POWER_RAGE, -int32(s.info.ManaCost))
-		case CLASS_HUNTER:
-			s.caster.ModifyPower(POWER_FOCUS, -int32(s.info.ManaCost))
-		case CLASS_ROGUE:
-			s.caster.ModifyPower(POWER_ENERGY, -int32(s.info.ManaCost))
-		default:
-			s.caster.ModifyPower(POWER_MANA, -int32(s.info.ManaCost))
+	s.caster.ModifyPower(s.info.PowerType, -int32(cost))
+	if s.info.PowerType == POWER_MANA {
+		if unit := getBaseUnit(s.caster); unit != nil {
+			unit.SetLastManaUse()
 		}
-	} else {
-		s.caster.ModifyPower(POWER_MANA, -int32(s.info.ManaCost))
 	}
 }
 
@@ -644,16 +507,19 @@ func (s *Spell) isValidTarget(target IUnit) bool {
 		// 敌方目标
 		return s.caster.IsValidAttackTarget(target)
 	case TARGET_UNIT_TARGET_ALLY:
-		// 友方目标 - 简化处理，同类型为友方
-		if _, ok := s.caster.(*Player); ok {
-			if _, ok := target.(*Player); ok {
-				return true // 玩家之间可以互相治疗
-			}
+		// 友方目标
+		caster := getBaseUnit(s.caster)
+		if caster == nil || !caster.IsFriendlyTo(target) {
+			return false
+		}
+		// 复活法术只能对死亡的玩家施放
+		if s.info.IsRequiringDeadTarget() {
+			return getPlayer(target) != nil && !target.IsAlive()
 		}
-		return target == s.caster // 可以对自己施法
+		return true
 	case TARGET_UNIT_CASTER:
 		// 施法者自己
-		return target == s.caster
+		return target.GetGUID() == s.caster.GetGUID()
 	}
 
 	return true
@@ -678,14 +544,18 @@ func (s *Spell) calculateDamage() {
 	if finalDamage < 1 {
 		finalDamage = 1
 	}
+	amount := s.callCalcDamageScripts(uint32(finalDamage))
 
 	// 根据法术效果类型设置
 	for _, effect := range s.info.Effects {
 		switch effect.EffectType {
 		case SPELL_EFFECT_SCHOOL_DAMAGE, SPELL_EFFECT_WEAPON_DAMAGE:
-			s.damage = uint32(finalDamage)
+			s.damage = amount
+			if caster := getBaseUnit(s.caster); caster != nil {
+				s.damage = uint32(float32(amount) * caster.GetDamageDoneMultiplier())
+			}
 		case SPELL_EFFECT_HEAL:
-			s.healing = uint32(finalDamage)
+			s.healing = amount
 		}
 	}
 }
@@ -697,10 +567,10 @@ func (s *Spell) Update(diff uint32) bool {
 		// 施法时间倒计时
 		s.castTime -= time.Duration(diff) * time.Millisecond
 		if s.castTime <= 0 {
-			// 施法完成
+			// 施法完成，弹道法术进入飞行状态
 			s.state = SPELL_STATE_FINISHED
 			s.cast()
-			return false // 法术完成，可以移除
+			return s.state == SPELL_STATE_DELAYED
 		}
 		return true
 
@@ -724,7 +594,12 @@ func (s *Spell) Update(diff uint32) bool {
 		return false // 法术已完成
 
 	case SPELL_STATE_DELAYED:
-		// 处理延迟状态
+		// 弹道飞行中，到达的目标依次结算
+		s.delayMoment += diff
+		if s.handleDelayedTargets() {
+			s.state = SPELL_STATE_FINISHED
+			return false
+		}
 		return true
 	}
 
@@ -736,60 +611,247 @@ func (s *Spell) cast() {
 	// 清除施法状态
 	s.caster.ClearUnitState(UNIT_STATE_CASTING)
 
+	// 选择效果目标
+	s.selectSpellTargets()
+
 	// 发送法术生效包
 	s.sendSpellGo()
 
+	// 弹道法术等命中时再结算
+	if s.info.Speed > 0 {
+		s.launchMissile()
+		fmt.Printf("%s 完成施放 %s，弹道飞行中\n", s.caster.GetName(), s.info.Name)
+		return
+	}
+
 	// 应用法术效果
 	s.applyEffects()
 
 	fmt.Printf("%s 完成施放 %s\n", s.caster.GetName(), s.info.Name)
 }
 
-// applyEffects 应用法术效果
-func (s *Spell) applyEffects() {
+// launchMissile 按距离计算每个目标的命中时间 - 基于AzerothCore的Spell::AddUnitTarget中的弹道延迟
+func (s *Spell) launchMissile() {
+	s.delayedTargets = s.delayedTargets[:0]
+	s.delayMoment = 0
 	for _, target := range s.targets {
-		if target == nil || !target.IsAlive() {
+		if s.getEffectTarget(target) == nil {
 			continue
 		}
+		var timeDelay uint32
+		if target.GetGUID() != s.caster.GetGUID() {
+			distance := s.caster.GetDistanceTo(target)
+			if distance < SPELL_MIN_MISSILE_DISTANCE {
+				distance = SPELL_MIN_MISSILE_DISTANCE
+			}
+			timeDelay = uint32(distance / s.info.Speed * 1000)
+		}
+		s.delayedTargets = append(s.delayedTargets, &SpellTargetInfo{target: target, timeDelay: timeDelay})
+	}
+	s.state = SPELL_STATE_DELAYED
+}
 
-		for _, effect := range s.info.Effects {
-			s.applyEffect(target, &effect)
+// handleDelayedTargets 结算已经到达的弹道，全部结算后返回true - 基于AzerothCore的Spell::handle_delayed
+func (s *Spell) handleDelayedTargets() bool {
+	allProcessed := true
+	for _, info := range s.delayedTargets {
+		if info.processed {
+			continue
+		}
+		if info.timeDelay > s.delayMoment {
+			allProcessed = false
+			continue
+		}
+		info.processed = true
+		s.handleDelayedHit(info.target)
+	}
+	return allProcessed
+}
+
+// handleDelayedHit 弹道到达目标 - 目标已死亡或在飞行中变为免疫时未命中
+func (s *Spell) handleDelayedHit(target IUnit) {
+	missInfo := SPELL_MISS_NONE
+	switch {
+	case !target.IsAlive():
+		missInfo = SPELL_MISS_MISS
+	case target.HasUnitState(UNIT_STATE_EVADE):
+		missInfo = SPELL_MISS_EVADE
+	case isImmunedToSpell(target, s.info):
+		missInfo = SPELL_MISS_IMMUNE
+	}
+
+	if missInfo != SPELL_MISS_NONE {
+		if s.world != nil {
+			s.world.BroadcastSpellMiss(s.caster, target, s.info.ID, missInfo)
+		}
+		fmt.Printf("%s 的 %s 未能命中 %s\n", s.caster.GetName(), s.info.Name, target.GetName())
+		return
+	}
+
+	effectTarget := s.getEffectTarget(target)
+	for i := range s.info.Effects {
+		if i < len(s.effectTargets) && containsUnit(s.effectTargets[i], target) {
+			s.applyEffect(effectTarget, &s.info.Effects[i])
+		}
+	}
+}
+
+// isImmunedToSpell 检查目标是否免疫该法术
+func isImmunedToSpell(target IUnit, spellInfo *SpellInfo) bool {
+	unit := getBaseUnit(target)
+	return unit != nil && unit.IsImmunedToSpell(spellInfo)
+}
+
+// containsUnit 检查目标列表中是否包含指定单位
+func containsUnit(units []IUnit, unit IUnit) bool {
+	for _, u := range units {
+		if u.GetGUID() == unit.GetGUID() {
+			return true
+		}
+	}
+	return false
+}
+
+// applyEffects 对每个效果选中的目标应用效果
+func (s *Spell) applyEffects() {
+	for i := range s.info.Effects {
+		if i >= len(s.effectTargets) {
+			break
+		}
+		for _, target := range s.effectTargets[i] {
+			// 复活法术只作用于死亡的目标，其他法术只作用于存活的目标
+			if target == nil || target.IsAlive() == s.info.IsRequiringDeadTarget() {
+				continue
+			}
+			// 未命中的目标跳过，被反射时作用于施法者
+			if effectTarget := s.getEffectTarget(target); effectTarget != nil {
+				s.applyEffect(effectTarget, &s.info.Effects[i])
+			}
 		}
 	}
 }
 
 // applyEffect 应用单个效果
 func (s *Spell) applyEffect(target IUnit, effect *SpellEffect) {
+	// 效果机制免疫
+	if unit := getBaseUnit(target); unit != nil && unit.IsImmunedToMechanic(effect.Mechanic) {
+		fmt.Printf("%s 免疫 %s 的效果\n", target.GetName(), s.info.Name)
+		return
+	}
+
+	// 法术脚本可以接管效果处理
+	if s.callEffectHitScripts(target, effect) {
+		return
+	}
+
 	switch effect.EffectType {
 	case SPELL_EFFECT_SCHOOL_DAMAGE, SPELL_EFFECT_WEAPON_DAMAGE:
-		// 造成伤害
-		actualDamage := target.DealDamage(s.caster, s.damage, SPELL_DIRECT_DAMAGE, s.info.SchoolMask)
+		// 造成伤害，魔法伤害可以被部分抵抗
+		damage := s.damage
+		if resisted := calcPartialResist(s.caster, target, s.info.SchoolMask, damage); resisted > 0 {
+			damage -= resisted
+			fmt.Printf("%s 抵抗了 %d 点%s伤害\n", target.GetName(), resisted, s.getSchoolName())
+		}
+		actualDamage := target.DealDamage(s.caster, damage, SPELL_DIRECT_DAMAGE, s.info.SchoolMask)
 		fmt.Printf("%s 对 %s 造成 %d 点%s伤害\n",
 			s.caster.GetName(), target.GetName(), actualDamage, s.getSchoolName())
+		s.addSpellThreat(target, actualDamage)
 
 	case SPELL_EFFECT_HEAL:
 		// 治疗效果
 		target.Heal(s.caster, s.healing)
 
-	case SPELL_EFFECT_DUMMY:
-		// 特殊效果处理
-		s.handleDummyEffect(target, effect)
+	case SPELL_EFFECT_APPLY_AURA:
+		// 应用光环
+		s.applyAura(target, effect)
+
+	case SPELL_EFFECT_INTERRUPT_CAST:
+		// 打断施法并封锁学派
+		s.effectInterruptCast(target)
+
+	case SPELL_EFFECT_RESURRECT:
+		// 向死亡的玩家发出复活请求
+		s.effectResurrect(target, effect)
+
+	case SPELL_EFFECT_DUEL:
+		// 向目标发起决斗
+		s.effectDuel(target)
+	}
+}
+
+// effectResurrect 复活效果 - 基于AzerothCore的Spell::EffectResurrect
+// 目标确认后在施法者的位置复活，按基础点数恢复生命和法力的百分比
+func (s *Spell) effectResurrect(target IUnit, effect *SpellEffect) {
+	player := getPlayer(target)
+	if player == nil || player.IsAlive() {
+		return
+	}
+	if !player.SetResurrectRequest(s.caster, uint32(effect.BasePoints)) {
+		fmt.Printf("%s 已经有待确认的复活请求\n", player.GetName())
+	}
+}
+
+// effectInterruptCast 打断目标施法 - 基于AzerothCore的Spell::EffectInterruptCast
+// 只有目标正在施法时才会封锁被打断法术的学派
+func (s *Spell) effectInterruptCast(target IUnit) {
+	unit := getBaseUnit(target)
+	if unit == nil {
+		return
+	}
+
+	for _, spellType := range []int{CURRENT_GENERIC_SPELL, CURRENT_CHANNELED_SPELL} {
+		spell := unit.GetCurrentSpell(spellType)
+		if spell == nil || (spell.state != SPELL_STATE_PREPARING && spell.state != SPELL_STATE_CASTING) {
+			continue
+		}
+
+		unit.LockSpellSchool(spell.info.SchoolMask, s.info.Duration)
+		fmt.Printf("%s 打断了 %s 的 %s，%s系法术被封锁 %.0f 秒\n",
+			s.caster.GetName(), target.GetName(), spell.info.Name, spell.getSchoolName(), s.info.Duration.Seconds())
+		unit.InterruptSpell(spellType)
 	}
 }
 
-// handleDummyEffect 处理特殊效果
-func (s *Spell) handleDummyEffect(target IUnit, effect *SpellEffect) {
-	switch s.info.ID {
-	case SPELL_TAUNT:
-		// 嘲讽效果
-		if target.GetAI() != nil {
-			target.SetVictim(s.caster)
-			fmt.Printf("%s 被 %s 嘲讽了\n", target.GetName(), s.caster.GetName())
+// applyAura 应用光环效果 - PvP控制效果受递减影响
+func (s *Spell) applyAura(target IUnit, effect *SpellEffect) {
+	unit := getBaseUnit(target)
+	if unit == nil {
+		return
+	}
+
+	duration := uint32(s.info.Duration.Milliseconds())
+	group := getDiminishingReturnsGroup(AuraType(effect.ApplyAuraName))
+	if caster := getBaseUnit(s.caster); group != DIMINISHING_NONE && caster != nil &&
+		caster.unitType == UNIT_TYPE_PLAYER && unit.unitType == UNIT_TYPE_PLAYER {
+		level := unit.GetDiminishing(group)
+		if level >= DIMINISHING_LEVEL_IMMUNE {
+			fmt.Printf("%s 对 %s 免疫 (递减)\n", target.GetName(), s.info.Name)
+			return
 		}
+		duration = applyDiminishingToDuration(duration, level)
+		unit.IncrDiminishing(group)
+	}
+
+	unit.AddAura(NewAura(s.info, effect, s.caster, target, duration))
+}
+
+// addSpellThreat 根据spell_threat修正附加仇恨 - 基础仇恨已在DealDamage中按实际伤害计算
+func (s *Spell) addSpellThreat(target IUnit, damage uint32) {
+	if GlobalSpellManager == nil || !target.IsAlive() {
+		return
+	}
+	entry := GlobalSpellManager.GetSpellThreatEntry(s.info.ID)
+	if entry == nil {
+		return
+	}
+	tm := getThreatManager(target)
+	if tm == nil {
+		return
+	}
 
-	case SPELL_POWER_WORD_SHIELD:
-		// 护盾效果 - 简化实现
-		fmt.Printf("%s 获得了 %d 点护盾保护\n", target.GetName(), s.healing)
+	bonus := float32(damage)*(entry.PctMod-1) + float32(entry.FlatMod)
+	if bonus != 0 {
+		tm.AddThreat(s.caster, bonus)
 	}
 }
 
@@ -798,16 +860,33 @@ func (s *Spell) startChanneling() {
 	fmt.Printf("%s 开始引导 %s\n", s.caster.GetName(), s.info.Name)
 }
 
-// applyChannelEffect 应用引导效果
+// applyChannelEffect 应用引导效果 - 带间隔的伤害效果按引导时间平分总伤害
 func (s *Spell) applyChannelEffect() {
-	// 暴风雪等引导法术的持续效果
-	if s.info.ID == SPELL_BLIZZARD {
-		for _, target := range s.targets {
-			if target != nil && target.IsAlive() {
-				damage := s.damage / 8 // 分8次造成伤害
-				actualDamage := target.DealDamage(s.caster, damage, SPELL_DIRECT_DAMAGE, s.info.SchoolMask)
-				fmt.Printf("暴风雪对 %s 造成 %d 点冰霜伤害\n", target.GetName(), actualDamage)
+	for i := range s.info.Effects {
+		effect := &s.info.Effects[i]
+		if effect.Amplitude <= 0 || s.info.ChannelTime <= 0 {
+			continue
+		}
+		if effect.EffectType != SPELL_EFFECT_SCHOOL_DAMAGE && effect.EffectType != SPELL_EFFECT_WEAPON_DAMAGE {
+			continue
+		}
+		ticks := uint32(s.info.ChannelTime.Milliseconds() / int64(effect.Amplitude))
+		if ticks == 0 {
+			ticks = 1
+		}
+
+		// 每次触发重新选择范围内的目标
+		for _, target := range s.selectEffectTargets(effect) {
+			if !target.IsAlive() || isImmunedToSpell(target, s.info) {
+				continue
 			}
+			damage := callEffectPeriodicScripts(s.info.ID, s.caster, target, effect, s.damage/ticks)
+			damage -= calcPartialResist(s.caster, target, s.info.SchoolMask, damage)
+			if damage == 0 {
+				continue
+			}
+			actualDamage := target.DealDamage(s.caster, damage, SPELL_DIRECT_DAMAGE, s.info.SchoolMask)
+			fmt.Printf("%s对 %s 造成 %d 点%s伤害\n", s.info.Name, target.GetName(), actualDamage, s.getSchoolName())
 		}
 	}
 }
@@ -818,13 +897,67 @@ func (s *Spell) finishChanneling() {
 	fmt.Printf("%s 完成引导 %s\n", s.caster.GetName(), s.info.Name)
 }
 
-// interrupt 打断法术 - 基于AzerothCore的Spell::cancel
+// Interrupt 打断法术 - 基于AzerothCore的Spell::cancel
 func (s *Spell) Interrupt() {
-	if s.state == SPELL_STATE_PREPARING || s.state == SPELL_STATE_CASTING {
-		s.interrupted = true
-		s.state = SPELL_STATE_FINISHED
-		s.caster.ClearUnitState(UNIT_STATE_CASTING)
-		fmt.Printf("%s 的 %s 被打断了\n", s.caster.GetName(), s.info.Name)
+	s.Cancel(SPELL_FAILED_INTERRUPTED)
+}
+
+// Cancel 以指定原因取消法术，并向周围玩家广播SMSG_SPELL_FAILURE
+func (s *Spell) Cancel(result uint8) {
+	if s.state != SPELL_STATE_PREPARING && s.state != SPELL_STATE_CASTING {
+		return
+	}
+
+	s.interrupted = true
+	s.state = SPELL_STATE_FINISHED
+	s.caster.ClearUnitState(UNIT_STATE_CASTING)
+	fmt.Printf("%s 的 %s 被打断了\n", s.caster.GetName(), s.info.Name)
+
+	if s.world != nil {
+		s.world.BroadcastSpellFailure(s.caster, s.info.ID, result)
+	}
+}
+
+// Delayed 施法推迟 - 基于AzerothCore的Spell::Delayed
+// 每次受击增加0.5秒施法时间，最多两次，且不会超过完整施法时间
+func (s *Spell) Delayed() {
+	if s.state != SPELL_STATE_PREPARING || s.delayAtDamageCount >= SPELL_MAX_PUSHBACK_COUNT {
+		return
+	}
+	s.delayAtDamageCount++
+
+	delay := SPELL_PUSHBACK_DELAY
+	if s.castTime+delay > s.info.CastTime {
+		delay = s.info.CastTime - s.castTime
+	}
+	if delay <= 0 {
+		return
+	}
+	s.castTime += delay
+
+	fmt.Printf("%s 的 %s 被推迟 %.1f 秒\n", s.caster.GetName(), s.info.Name, delay.Seconds())
+	if s.world != nil {
+		s.world.BroadcastSpellDelayed(s.caster, delay)
+	}
+}
+
+// DelayedChannel 引导缩短 - 基于AzerothCore的Spell::DelayedChannel
+// 每次受击缩短25%的引导时间，最多两次
+func (s *Spell) DelayedChannel() {
+	if s.state != SPELL_STATE_CASTING || !s.info.IsChanneled || s.delayAtDamageCount >= SPELL_MAX_PUSHBACK_COUNT {
+		return
+	}
+	s.delayAtDamageCount++
+
+	delay := s.info.ChannelTime * SPELL_CHANNEL_PUSHBACK_PCT / 100
+	if s.channelTime <= delay {
+		delay = s.channelTime
+	}
+	s.channelTime -= delay
+
+	fmt.Printf("%s 的 %s 引导时间缩短 %.1f 秒\n", s.caster.GetName(), s.info.Name, delay.Seconds())
+	if s.world != nil {
+		s.world.BroadcastChannelUpdate(s.caster, s.channelTime)
 	}
 }
 
@@ -865,5 +998,6 @@ func (s *Spell) sendSpellGo() {
 	}
 
 	// 向所有相关客户端发送法术生效包
-	s.world.BroadcastSpellGo(s.caster, s.info.ID, s.targets)
+	hits, misses := s.getHitAndMissTargets()
+	s.world.BroadcastSpellGo(s.caster, s.info.ID, hits, misses)
 }
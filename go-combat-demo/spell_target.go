@@ -0,0 +1,288 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// 目标选择常量 - 基于AzerothCore的Spell::SelectImplicitChainTargets等
+const (
+	SPELL_CONE_ANGLE         = math.Pi / 2 // 锥形法术的默认角度(前方90度)
+	CHAIN_JUMP_RADIUS_RANGED = 7.5         // 远程物理连锁(多重射击)的跳跃距离
+	CHAIN_JUMP_RADIUS_MAGIC  = 12.5        // 法术连锁(闪电链、治疗链)的跳跃距离
+)
+
+// spellRadiusStore 法术半径 - 基于AzerothCore的SpellRadius.dbc
+var spellRadiusStore = map[int]float32{
+	7:  2,
+	8:  5,
+	9:  20,
+	10: 30,
+	11: 45,
+	12: 100,
+	13: 10,
+	14: 8,
+	15: 3,
+	18: 15,
+	28: 50,
+}
+
+// GetSpellRadius 根据半径索引获取法术半径
+func GetSpellRadius(radiusIndex int) float32 {
+	return spellRadiusStore[radiusIndex]
+}
+
+// SpellDestination 法术目标位置
+type SpellDestination struct {
+	x, y, z float32
+}
+
+// setExplicitTarget 记录施法目标和目标位置
+func (s *Spell) setExplicitTarget(target IUnit) {
+	s.explicitTarget = target
+	if target != nil {
+		s.targets = append(s.targets, target)
+		s.dest.x, s.dest.y, s.dest.z = target.GetPosition()
+	} else {
+		s.dest.x, s.dest.y, s.dest.z = s.caster.GetPosition()
+	}
+}
+
+// selectSpellTargets 为每个效果选择目标 - 基于AzerothCore的Spell::SelectSpellTargets
+// s.targets更新为所有效果目标的并集，用于SMSG_SPELLGO
+func (s *Spell) selectSpellTargets() {
+	s.effectTargets = make([][]IUnit, len(s.info.Effects))
+	seen := make(map[uint64]bool)
+	s.targets = s.targets[:0]
+
+	for i := range s.info.Effects {
+		s.effectTargets[i] = s.selectEffectTargets(&s.info.Effects[i])
+		for _, target := range s.effectTargets[i] {
+			if !seen[target.GetGUID()] {
+				seen[target.GetGUID()] = true
+				s.targets = append(s.targets, target)
+			}
+		}
+	}
+
+	// 每个目标只判定一次命中
+	s.rollTargetHits()
+}
+
+// selectEffectTargets 根据效果的隐式目标类型选择目标
+// 目标A为区域中心(TARGET_SRC_CASTER、TARGET_DEST_TARGET_ENEMY)时由目标B选择区域内的单位
+func (s *Spell) selectEffectTargets(effect *SpellEffect) []IUnit {
+	targetType := effect.ImplicitTargetA
+	if targetType == 0 || targetType == TARGET_SRC_CASTER {
+		targetType = effect.ImplicitTargetB
+	}
+	radius := GetSpellRadius(effect.RadiusIndex)
+	casterX, casterY, casterZ := s.caster.GetPosition()
+
+	switch targetType {
+	case TARGET_UNIT_CASTER:
+		return []IUnit{s.caster}
+
+	case TARGET_DEST_TARGET_ENEMY:
+		// 对地范围法术：区域中心为敌方目标当前的位置，选择该位置周围的敌人
+		if s.explicitTarget != nil {
+			s.dest.x, s.dest.y, s.dest.z = s.explicitTarget.GetPosition()
+		}
+		return s.selectAreaTargets(s.dest.x, s.dest.y, s.dest.z, radius, s.isAreaEnemy)
+
+	case TARGET_UNIT_SRC_AREA_ENEMY:
+		return s.selectAreaTargets(casterX, casterY, casterZ, radius, s.isAreaEnemy)
+
+	case TARGET_UNIT_DEST_AREA_ENEMY:
+		return s.selectAreaTargets(s.dest.x, s.dest.y, s.dest.z, radius, s.isAreaEnemy)
+
+	case TARGET_UNIT_CONE_ENEMY:
+		return s.selectAreaTargets(casterX, casterY, casterZ, radius, func(target IUnit) bool {
+			return s.isAreaEnemy(target) && s.isInCone(target)
+		})
+
+	case TARGET_UNIT_CASTER_AREA_PARTY:
+		return s.selectAreaTargets(casterX, casterY, casterZ, radius, func(target IUnit) bool {
+			return s.isAreaGroupMember(target, false)
+		})
+
+	case TARGET_UNIT_CASTER_AREA_RAID:
+		return s.selectAreaTargets(casterX, casterY, casterZ, radius, func(target IUnit) bool {
+			return s.isAreaGroupMember(target, true)
+		})
+
+	case TARGET_UNIT_TARGET_CHAINHEAL_ALLY:
+		return s.selectChainTargets(effect, s.isAreaAlly, true)
+
+	case TARGET_UNIT_TARGET_ENEMY:
+		return s.selectChainTargets(effect, s.isAreaEnemy, false)
+	}
+
+	// 其他目标类型(包括未填写隐式目标的效果)作用于施法目标
+	return s.selectChainTargets(effect, func(IUnit) bool { return true }, false)
+}
+
+// selectAreaTargets 选择范围内满足条件的目标，并按最大目标数截取
+func (s *Spell) selectAreaTargets(x, y, z, radius float32, check func(IUnit) bool) []IUnit {
+	var targets []IUnit
+	for _, unit := range s.searchUnitsInRadius(x, y, z, radius) {
+		if !unit.IsAlive() || !check(unit) || !s.isInLineOfSight(x, y, z, unit) {
+			continue
+		}
+		targets = append(targets, unit)
+	}
+
+	// 超过上限时随机保留 - 基于AzerothCore的Acore::Containers::RandomResize
+	if s.info.MaxAffectedTargets > 0 && uint32(len(targets)) > s.info.MaxAffectedTargets {
+		rand.Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+		targets = targets[:s.info.MaxAffectedTargets]
+	}
+	return targets
+}
+
+// selectChainTargets 从施法目标开始跳跃选择连锁目标 - 基于AzerothCore的Spell::SearchChainTargets
+// 伤害连锁跳向离上一个目标最近的单位，治疗连锁跳向生命比例最低的单位
+func (s *Spell) selectChainTargets(effect *SpellEffect, check func(IUnit) bool, mostInjured bool) []IUnit {
+	if s.explicitTarget == nil || s.explicitTarget.IsAlive() == s.info.IsRequiringDeadTarget() {
+		return nil
+	}
+	targets := []IUnit{s.explicitTarget}
+	if effect.ChainTarget <= 1 {
+		return targets
+	}
+
+	jumpRadius := GetSpellRadius(effect.RadiusIndex)
+	if jumpRadius == 0 {
+		jumpRadius = CHAIN_JUMP_RADIUS_MAGIC
+		if s.info.SchoolMask == SPELL_SCHOOL_NORMAL {
+			jumpRadius = CHAIN_JUMP_RADIUS_RANGED
+		}
+	}
+
+	hit := map[uint64]bool{s.explicitTarget.GetGUID(): true}
+	current := s.explicitTarget
+	for uint32(len(targets)) < effect.ChainTarget {
+		x, y, z := current.GetPosition()
+		var candidates []IUnit
+		for _, unit := range s.searchUnitsInRadius(x, y, z, jumpRadius) {
+			if hit[unit.GetGUID()] || !unit.IsAlive() || !check(unit) || !s.isInLineOfSight(x, y, z, unit) {
+				continue
+			}
+			candidates = append(candidates, unit)
+		}
+		if len(candidates) == 0 {
+			break
+		}
+
+		sort.Slice(candidates, func(i, j int) bool {
+			if mostInjured {
+				return healthPct(candidates[i]) < healthPct(candidates[j])
+			}
+			return current.GetDistanceTo(candidates[i]) < current.GetDistanceTo(candidates[j])
+		})
+		current = candidates[0]
+		hit[current.GetGUID()] = true
+		targets = append(targets, current)
+	}
+	return targets
+}
+
+// searchUnitsInRadius 查找范围内的单位 - 有世界时使用空间索引，否则在施法者的战斗关系中查找
+func (s *Spell) searchUnitsInRadius(x, y, z, radius float32) []IUnit {
+	if s.world != nil {
+		return s.world.GetUnitsInRadius(x, y, z, radius)
+	}
+
+	candidates := make(map[uint64]IUnit)
+	add := func(unit IUnit) {
+		if unit != nil {
+			candidates[unit.GetGUID()] = unit
+		}
+	}
+	add(s.caster)
+	add(s.explicitTarget)
+	if caster := getBaseUnit(s.caster); caster != nil {
+		for _, attacker := range caster.attackers {
+			add(attacker)
+		}
+		for _, info := range caster.threatManager.GetSortedThreatList() {
+			add(info.unit)
+		}
+	}
+
+	var units []IUnit
+	for _, unit := range candidates {
+		dx, dy, dz := unit.GetX()-x, unit.GetY()-y, unit.GetZ()-z
+		if dx*dx+dy*dy+dz*dz <= radius*radius {
+			units = append(units, unit)
+		}
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].GetGUID() < units[j].GetGUID() })
+	return units
+}
+
+// isAreaEnemy 范围效果的敌方目标
+func (s *Spell) isAreaEnemy(target IUnit) bool {
+	caster := getBaseUnit(s.caster)
+	return caster != nil && caster.IsValidAttackTarget(target) && !caster.IsFriendlyTo(target)
+}
+
+// isAreaAlly 范围效果的友方目标
+func (s *Spell) isAreaAlly(target IUnit) bool {
+	caster := getBaseUnit(s.caster)
+	return caster != nil && caster.IsFriendlyTo(target)
+}
+
+// isAreaGroupMember 队伍范围效果的目标 - 玩家施法时只作用于同一小队(raid为true时同一团队)的成员，
+// 没有队伍时只作用于自己；生物施法时作用于所有友方
+func (s *Spell) isAreaGroupMember(target IUnit, raid bool) bool {
+	if !s.isAreaAlly(target) {
+		return false
+	}
+	player := getPlayer(s.caster)
+	if player == nil {
+		return true
+	}
+	if raid {
+		return player.IsInSameRaidWith(target)
+	}
+	return player.IsInSameGroupWith(target)
+}
+
+// isInCone 目标是否在施法者前方的锥形范围内，有施法目标时面向施法目标
+func (s *Spell) isInCone(target IUnit) bool {
+	facing := float32(0)
+	if caster := getBaseUnit(s.caster); caster != nil {
+		facing = caster.orientation
+	}
+	if s.explicitTarget != nil && s.explicitTarget.GetGUID() != s.caster.GetGUID() {
+		facing = calculateAngle(s.caster.GetX(), s.caster.GetY(), s.explicitTarget.GetX(), s.explicitTarget.GetY())
+	}
+
+	angle := calculateAngle(s.caster.GetX(), s.caster.GetY(), target.GetX(), target.GetY()) - facing
+	for angle > math.Pi {
+		angle -= 2 * math.Pi
+	}
+	for angle < -math.Pi {
+		angle += 2 * math.Pi
+	}
+	return math.Abs(float64(angle)) <= SPELL_CONE_ANGLE/2
+}
+
+// isInLineOfSight 检查效果中心与目标之间的视线
+func (s *Spell) isInLineOfSight(x, y, z float32, target IUnit) bool {
+	if s.world == nil {
+		return true
+	}
+	tx, ty, tz := target.GetPosition()
+	return s.world.IsInLineOfSight(x, y, z, tx, ty, tz)
+}
+
+// healthPct 生命值百分比
+func healthPct(unit IUnit) float32 {
+	if unit.GetMaxHealth() == 0 {
+		return 0
+	}
+	return float32(unit.GetHealth()) * 100 / float32(unit.GetMaxHealth())
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// 法术数据文件 - 基于AzerothCore的spell_template导出，数值单位为毫秒/码
+const SPELL_TEMPLATE_PATH = "data/spell_template.json"
+
+// 编译时内置的一份法术数据，数据文件不存在时使用
+//
+//go:embed data/spell_template.json
+var defaultSpellTemplate []byte
+
+// SpellTemplateEntry spell_template中的一行
+type SpellTemplateEntry struct {
+	ID                    uint32                     `json:"id"`
+	Name                  string                     `json:"name"`
+	Description           string                     `json:"description"`
+	CastTime              uint32                     `json:"cast_time"`
+	Cooldown              uint32                     `json:"cooldown"`
+	Category              uint32                     `json:"category"`
+	CategoryCooldown      uint32                     `json:"category_cooldown"`
+	StartRecoveryCategory uint32                     `json:"start_recovery_category"`
+	StartRecoveryTime     uint32                     `json:"start_recovery_time"`
+	ManaCost              uint32                     `json:"mana_cost"`
+	PowerType             uint8                      `json:"power_type"`
+	ManaCostPercentage    uint32                     `json:"mana_cost_percentage"`
+	Range                 float32                    `json:"range"`
+	SchoolMask            int                        `json:"school_mask"`
+	TargetType            int                        `json:"target_type"`
+	Attributes            uint32                     `json:"attributes"`
+	IsChanneled           bool                       `json:"is_channeled"`
+	ChannelTime           uint32                     `json:"channel_time"`
+	BaseDamage            uint32                     `json:"base_damage"`
+	DamageVariance        float32                    `json:"damage_variance"`
+	Level                 uint8                      `json:"level"`
+	Duration              uint32                     `json:"duration"`
+	AuraInterruptFlags    uint32                     `json:"aura_interrupt_flags"`
+	MaxAffectedTargets    uint32                     `json:"max_affected_targets"`
+	Speed                 float32                    `json:"speed"`
+	Effects               []SpellTemplateEffectEntry `json:"effects"`
+}
+
+// SpellTemplateEffectEntry spell_template中的法术效果
+type SpellTemplateEffectEntry struct {
+	Effect             int     `json:"effect"`
+	BasePoints         int32   `json:"base_points"`
+	DicePerLevel       float32 `json:"dice_per_level"`
+	RealPointsPerLevel float32 `json:"real_points_per_level"`
+	Mechanic           int     `json:"mechanic"`
+	ImplicitTargetA    int     `json:"implicit_target_a"`
+	ImplicitTargetB    int     `json:"implicit_target_b"`
+	RadiusIndex        int     `json:"radius_index"`
+	ApplyAuraName      int     `json:"apply_aura_name"`
+	Amplitude          int32   `json:"amplitude"`
+	MultipleValue      float32 `json:"multiple_value"`
+	ChainTarget        uint32  `json:"chain_target"`
+	MiscValue          int32   `json:"misc_value"`
+}
+
+func msToDuration(ms uint32) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+// ToSpellInfo 转换为运行时使用的SpellInfo
+func (e *SpellTemplateEntry) ToSpellInfo() *SpellInfo {
+	info := &SpellInfo{
+		ID:                    e.ID,
+		Name:                  e.Name,
+		Description:           e.Description,
+		CastTime:              msToDuration(e.CastTime),
+		Cooldown:              msToDuration(e.Cooldown),
+		ManaCost:              e.ManaCost,
+		PowerType:             e.PowerType,
+		ManaCostPercentage:    e.ManaCostPercentage,
+		Range:                 e.Range,
+		SchoolMask:            e.SchoolMask,
+		Attributes:            e.Attributes,
+		TargetType:            e.TargetType,
+		IsChanneled:           e.IsChanneled,
+		ChannelTime:           msToDuration(e.ChannelTime),
+		BaseDamage:            e.BaseDamage,
+		DamageVariance:        e.DamageVariance,
+		Level:                 e.Level,
+		Duration:              msToDuration(e.Duration),
+		AuraInterruptFlags:    e.AuraInterruptFlags,
+		MaxAffectedTargets:    e.MaxAffectedTargets,
+		Speed:                 e.Speed,
+		Category:              e.Category,
+		CategoryCooldown:      msToDuration(e.CategoryCooldown),
+		StartRecoveryCategory: e.StartRecoveryCategory,
+		StartRecoveryTime:     msToDuration(e.StartRecoveryTime),
+	}
+
+	for _, effect := range e.Effects {
+		info.Effects = append(info.Effects, SpellEffect{
+			EffectType:         effect.Effect,
+			BasePoints:         effect.BasePoints,
+			DicePerLevel:       effect.DicePerLevel,
+			RealPointsPerLevel: effect.RealPointsPerLevel,
+			Mechanic:           effect.Mechanic,
+			ImplicitTargetA:    effect.ImplicitTargetA,
+			ImplicitTargetB:    effect.ImplicitTargetB,
+			RadiusIndex:        effect.RadiusIndex,
+			ApplyAuraName:      effect.ApplyAuraName,
+			Amplitude:          effect.Amplitude,
+			MultipleValue:      effect.MultipleValue,
+			ChainTarget:        effect.ChainTarget,
+			MiscValue:          effect.MiscValue,
+		})
+	}
+	return info
+}
+
+// 支持的效果、目标与光环类型，数据文件中出现其它值时该法术不会被加载
+var (
+	validSpellEffects = map[int]bool{
+		SPELL_EFFECT_INSTAKILL:      true,
+		SPELL_EFFECT_SCHOOL_DAMAGE:  true,
+		SPELL_EFFECT_DUMMY:          true,
+		SPELL_EFFECT_APPLY_AURA:     true,
+		SPELL_EFFECT_HEAL:           true,
+		SPELL_EFFECT_RESURRECT:      true,
+		SPELL_EFFECT_ENERGIZE:       true,
+		SPELL_EFFECT_INTERRUPT_CAST: true,
+		SPELL_EFFECT_DUEL:           true,
+		SPELL_EFFECT_WEAPON_DAMAGE:  true,
+	}
+	validSpellTargets = map[int]bool{
+		TARGET_UNIT_CASTER:                true,
+		TARGET_UNIT_TARGET_ENEMY:          true,
+		TARGET_UNIT_TARGET_ALLY:           true,
+		TARGET_UNIT_DEST_AREA_ENEMY:       true,
+		TARGET_DEST_TARGET_ENEMY:          true,
+		TARGET_UNIT_CASTER_AREA_PARTY:     true,
+		TARGET_UNIT_SRC_AREA_ENEMY:        true,
+		TARGET_SRC_CASTER:                 true,
+		TARGET_UNIT_CONE_ENEMY:            true,
+		TARGET_UNIT_TARGET_CHAINHEAL_ALLY: true,
+		TARGET_UNIT_CASTER_AREA_RAID:      true,
+	}
+	validAuraTypes = map[AuraType]bool{
+		SPELL_AURA_PERIODIC_DAMAGE:             true,
+		SPELL_AURA_MOD_CONFUSE:                 true,
+		SPELL_AURA_MOD_FEAR:                    true,
+		SPELL_AURA_PERIODIC_HEAL:               true,
+		SPELL_AURA_MOD_STUN:                    true,
+		SPELL_AURA_MOD_DAMAGE_DONE:             true,
+		SPELL_AURA_MOD_DAMAGE_TAKEN:            true,
+		SPELL_AURA_MOD_ROOT:                    true,
+		SPELL_AURA_MOD_STAT:                    true,
+		SPELL_AURA_SCHOOL_IMMUNITY:             true,
+		SPELL_AURA_MOD_SPELL_HIT_CHANCE:        true,
+		SPELL_AURA_MOD_INCREASE_SPEED:          true,
+		SPELL_AURA_MOD_DECREASE_SPEED:          true,
+		SPELL_AURA_REFLECT_SPELLS:              true,
+		SPELL_AURA_MECHANIC_IMMUNITY:           true,
+		SPELL_AURA_MOD_DAMAGE_PERCENT_DONE:     true,
+		SPELL_AURA_MOD_ATTACK_POWER:            true,
+		SPELL_AURA_MOD_CASTING_SPEED_NOT_STACK: true,
+		SPELL_AURA_MOD_HEALING:                 true,
+		SPELL_AURA_MOD_HEALING_DONE:            true,
+		SPELL_AURA_MOD_MELEE_HASTE:             true,
+	}
+)
+
+// SPELL_SCHOOL_MASK_ALL 所有法术学派
+const SPELL_SCHOOL_MASK_ALL = SPELL_SCHOOL_NORMAL | SPELL_SCHOOL_HOLY | SPELL_SCHOOL_FIRE |
+	SPELL_SCHOOL_NATURE | SPELL_SCHOOL_FROST | SPELL_SCHOOL_SHADOW | SPELL_SCHOOL_ARCANE
+
+// Validate 检查法术数据 - 基于AzerothCore的SpellMgr::LoadSpellInfoStore中的数据校验
+func (e *SpellTemplateEntry) Validate() error {
+	if e.ID == 0 {
+		return fmt.Errorf("法术ID不能为0")
+	}
+	if e.SchoolMask == 0 || e.SchoolMask&^SPELL_SCHOOL_MASK_ALL != 0 {
+		return fmt.Errorf("法术 %d 的学派 %d 无效", e.ID, e.SchoolMask)
+	}
+	if !validSpellTargets[e.TargetType] {
+		return fmt.Errorf("法术 %d 的目标类型 %d 无效", e.ID, e.TargetType)
+	}
+	if e.PowerType > POWER_ENERGY {
+		return fmt.Errorf("法术 %d 的能量类型 %d 无效", e.ID, e.PowerType)
+	}
+	if e.ManaCostPercentage > 100 {
+		return fmt.Errorf("法术 %d 的百分比消耗 %d 超过100", e.ID, e.ManaCostPercentage)
+	}
+	if e.IsChanneled && e.ChannelTime == 0 {
+		return fmt.Errorf("法术 %d 是引导法术但没有引导时间", e.ID)
+	}
+	if len(e.Effects) == 0 {
+		return fmt.Errorf("法术 %d 没有任何效果", e.ID)
+	}
+
+	for i, effect := range e.Effects {
+		if !validSpellEffects[effect.Effect] {
+			return fmt.Errorf("法术 %d 效果%d 的类型 %d 无效", e.ID, i, effect.Effect)
+		}
+		for _, target := range []int{effect.ImplicitTargetA, effect.ImplicitTargetB} {
+			if target != 0 && !validSpellTargets[target] {
+				return fmt.Errorf("法术 %d 效果%d 的目标类型 %d 无效", e.ID, i, target)
+			}
+		}
+		if (isAreaTargetType(effect.ImplicitTargetA) || isAreaTargetType(effect.ImplicitTargetB)) && GetSpellRadius(effect.RadiusIndex) == 0 {
+			return fmt.Errorf("法术 %d 效果%d 是范围效果但半径索引 %d 无效", e.ID, i, effect.RadiusIndex)
+		}
+		if effect.Effect == SPELL_EFFECT_APPLY_AURA && !validAuraTypes[AuraType(effect.ApplyAuraName)] {
+			return fmt.Errorf("法术 %d 效果%d 的光环类型 %d 无效", e.ID, i, effect.ApplyAuraName)
+		}
+	}
+	return nil
+}
+
+// isAreaTargetType 需要半径的范围目标类型
+func isAreaTargetType(targetType int) bool {
+	switch targetType {
+	case TARGET_UNIT_SRC_AREA_ENEMY, TARGET_UNIT_DEST_AREA_ENEMY, TARGET_DEST_TARGET_ENEMY, TARGET_UNIT_CONE_ENEMY,
+		TARGET_UNIT_CASTER_AREA_PARTY, TARGET_UNIT_CASTER_AREA_RAID:
+		return true
+	}
+	return false
+}
+
+// readSpellTemplate 读取法术数据文件，文件不存在时使用内置数据
+func readSpellTemplate(path string) ([]byte, string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return defaultSpellTemplate, "内置数据", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("读取法术数据 %s 失败: %v", path, err)
+	}
+	return content, path, nil
+}
+
+// parseSpellTemplate 解析并校验法术数据，无效的法术被跳过并打印原因
+func parseSpellTemplate(content []byte) (map[uint32]*SpellInfo, error) {
+	var entries []SpellTemplateEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("解析法术数据失败: %v", err)
+	}
+
+	spells := make(map[uint32]*SpellInfo, len(entries))
+	for i := range entries {
+		entry := &entries[i]
+		if err := entry.Validate(); err != nil {
+			fmt.Printf("[spell_template] 跳过无效法术: %v\n", err)
+			continue
+		}
+		if _, exists := spells[entry.ID]; exists {
+			fmt.Printf("[spell_template] 法术 %d 重复定义，使用后一条\n", entry.ID)
+		}
+		spells[entry.ID] = entry.ToSpellInfo()
+	}
+	return spells, nil
+}
+
+// SpellInfoOverride 脚本对法术数据的修正 - 基于AzerothCore的SpellMgr::LoadSpellInfoCorrections
+type SpellInfoOverride func(spellInfo *SpellInfo)
+
+var spellInfoOverrides = make(map[uint32][]SpellInfoOverride)
+
+// RegisterSpellInfoOverride 注册法术数据修正，在每次加载/重载法术数据后执行
+func RegisterSpellInfoOverride(spellId uint32, override SpellInfoOverride) {
+	spellInfoOverrides[spellId] = append(spellInfoOverrides[spellId], override)
+}
+
+// applySpellInfoOverrides 把脚本修正合并到从文件加载的法术数据上
+func applySpellInfoOverrides(spells map[uint32]*SpellInfo) {
+	for spellId, overrides := range spellInfoOverrides {
+		spellInfo, exists := spells[spellId]
+		if !exists {
+			fmt.Printf("[spell_template] 法术修正引用了不存在的法术 %d\n", spellId)
+			continue
+		}
+		for _, override := range overrides {
+			override(spellInfo)
+		}
+	}
+}
@@ -0,0 +1,533 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain 所有测试共用一份从数据文件加载的法术数据
+func TestMain(m *testing.M) {
+	InitSpellManager()
+	os.Exit(m.Run())
+}
+
+func newCasterTestUnit(name string, x float32) *Unit {
+	unit := newThreatTestUnit(name, x)
+	unit.SetMaxPower(POWER_MANA, 5000)
+	unit.SetPower(POWER_MANA, 5000)
+	// 同级4%的基础未命中率由命中加成抵消，保证测试结果稳定
+	hitInfo := &SpellInfo{ID: 1, Name: "命中"}
+	unit.AddAura(NewAura(hitInfo, &SpellEffect{ApplyAuraName: int(SPELL_AURA_MOD_SPELL_HIT_CHANCE), BasePoints: 4}, unit, unit, 3600000))
+	return unit
+}
+
+func TestCastPushbackIsCappedAtTwoHits(t *testing.T) {
+	mage := newCasterTestUnit("mage", 0)
+	mob := newThreatTestUnit("mob", 10)
+
+	mage.CastSpell(mob, SPELL_FIREBALL)
+	spell := mage.GetCurrentSpell(CURRENT_GENERIC_SPELL)
+	if spell == nil {
+		t.Fatalf("fireball should be casting")
+	}
+
+	mage.Update(1000)
+	for i := 0; i < 3; i++ {
+		mage.DealDamage(mob, 10, DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	}
+	if spell.castTime != 3000*time.Millisecond {
+		t.Fatalf("expected remaining cast time 3s after two pushbacks, got %v", spell.castTime)
+	}
+}
+
+func TestChannelPushbackShortensDuration(t *testing.T) {
+	mage := newCasterTestUnit("mage", 0)
+	mob := newThreatTestUnit("mob", 10)
+
+	mage.CastSpell(mob, SPELL_BLIZZARD)
+	spell := mage.GetCurrentSpell(CURRENT_CHANNELED_SPELL)
+	if spell == nil {
+		t.Fatalf("blizzard should be channeling")
+	}
+
+	mage.DealDamage(mob, 10, DIRECT_DAMAGE, SPELL_SCHOOL_NORMAL)
+	if spell.channelTime != 6*time.Second {
+		t.Fatalf("expected channel shortened to 6s, got %v", spell.channelTime)
+	}
+}
+
+func TestCounterspellLocksInterruptedSchool(t *testing.T) {
+	mage := newCasterTestUnit("mage", 0)
+	enemy := newCasterTestUnit("enemy", 10)
+
+	enemy.CastSpell(mage, SPELL_FIREBALL)
+	if enemy.GetCurrentSpell(CURRENT_GENERIC_SPELL) == nil {
+		t.Fatalf("enemy should be casting")
+	}
+
+	counter := NewSpell(mage, GlobalSpellManager.GetSpell(SPELL_COUNTERSPELL), nil)
+	counter.effectInterruptCast(enemy)
+
+	if enemy.GetCurrentSpell(CURRENT_GENERIC_SPELL) != nil || enemy.HasUnitState(UNIT_STATE_CASTING) {
+		t.Fatalf("counterspell should interrupt the cast")
+	}
+	if !enemy.IsSchoolLocked(SPELL_SCHOOL_FIRE) {
+		t.Fatalf("fire school should be locked")
+	}
+	if enemy.IsSchoolLocked(SPELL_SCHOOL_FROST) {
+		t.Fatalf("frost school should not be locked")
+	}
+
+	fireball := NewSpell(enemy, GlobalSpellManager.GetSpell(SPELL_FIREBALL), nil)
+	if result := fireball.checkCast(nil); result != 67 {
+		t.Fatalf("expected SPELL_FAILED_NOT_READY (67) during lockout, got %d", result)
+	}
+}
+
+func TestCastFailureReasonsUseCoreCodes(t *testing.T) {
+	// 客户端按SharedDefines.h中SpellCastResult的数值显示失败原因
+	codes := map[uint8]uint8{
+		SPELL_FAILED_BAD_TARGETS:       12,
+		SPELL_FAILED_CASTER_DEAD:       23,
+		SPELL_FAILED_CONFUSED:          26,
+		SPELL_FAILED_FLEEING:           34,
+		SPELL_FAILED_INTERRUPTED:       40,
+		SPELL_FAILED_NOT_KNOWN:         63,
+		SPELL_FAILED_NOT_READY:         67,
+		SPELL_FAILED_NO_POWER:          85,
+		SPELL_FAILED_OUT_OF_RANGE:      97,
+		SPELL_FAILED_SPELL_IN_PROGRESS: 105,
+		SPELL_FAILED_STUNNED:           108,
+	}
+	for got, want := range codes {
+		if got != want {
+			t.Fatalf("cast result %d should be %d", got, want)
+		}
+	}
+
+	mage := newCasterTestUnit("mage", 0)
+	target := newThreatTestUnit("target", 100)
+	fireball := GlobalSpellManager.GetSpell(SPELL_FIREBALL)
+	if result := NewSpell(mage, fireball, nil).checkCast(target); result != 97 {
+		t.Fatalf("expected SPELL_FAILED_OUT_OF_RANGE (97), got %d", result)
+	}
+	target.SetPosition(20, 0, 0)
+	mage.SetPower(POWER_MANA, 0)
+	if result := NewSpell(mage, fireball, nil).checkCast(target); result != 85 {
+		t.Fatalf("expected SPELL_FAILED_NO_POWER (85), got %d", result)
+	}
+	mage.SetHealth(0)
+	if result := NewSpell(mage, fireball, nil).checkCast(target); result != 23 {
+		t.Fatalf("expected SPELL_FAILED_CASTER_DEAD (23), got %d", result)
+	}
+}
+
+func TestGlobalCooldownScalesWithHaste(t *testing.T) {
+	mage := newCasterTestUnit("mage", 0)
+	frostbolt := GlobalSpellManager.GetSpell(SPELL_FROSTBOLT)
+
+	mage.AddGlobalCooldown(frostbolt)
+	if !mage.HasGlobalCooldown(GlobalSpellManager.GetSpell(SPELL_FIREBALL)) {
+		t.Fatalf("fireball should share the global cooldown")
+	}
+	if mage.HasGlobalCooldown(GlobalSpellManager.GetSpell(SPELL_COUNTERSPELL)) {
+		t.Fatalf("counterspell is off the global cooldown")
+	}
+
+	// 100%急速时公共冷却被限制在1秒
+	haste := &Aura{id: 1, spellInfo: frostbolt, auraType: SPELL_AURA_MOD_CASTING_SPEED_NOT_STACK, value: 100, duration: 10000}
+	mage.auras = append(mage.auras, haste)
+	mage.globalCooldowns = make(map[uint32]time.Time)
+	mage.AddGlobalCooldown(frostbolt)
+	remaining := time.Until(mage.globalCooldowns[SPELL_CATEGORY_GCD_DEFAULT])
+	if remaining > SPELL_MIN_GCD || remaining < SPELL_MIN_GCD-100*time.Millisecond {
+		t.Fatalf("expected hasted GCD clamped to 1s, got %v", remaining)
+	}
+}
+
+func TestCategoryCooldownIsShared(t *testing.T) {
+	hunter := newCasterTestUnit("hunter", 0)
+
+	hunter.AddSpellCooldown(GlobalSpellManager.GetSpell(SPELL_AIMED_SHOT))
+	if !hunter.HasSpellCooldown(SPELL_MULTI_SHOT) {
+		t.Fatalf("multi-shot should share aimed shot's category cooldown")
+	}
+	if delay := hunter.GetSpellCooldownDelay(SPELL_AIMED_SHOT); delay <= 6*time.Second {
+		t.Fatalf("aimed shot should use the longer category cooldown, got %v", delay)
+	}
+}
+
+func TestSpellCooldownsSurviveRelog(t *testing.T) {
+	db := &CharacterDatabase{dir: t.TempDir()}
+
+	player := NewPlayer("hunter", 20, CLASS_HUNTER)
+	player.AddSpellCooldown(GlobalSpellManager.GetSpell(SPELL_MULTI_SHOT))
+	if err := db.SaveCharacter(player.BuildCharacterData()); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	relogged := NewPlayer("hunter", 20, CLASS_HUNTER)
+	relogged.SetGUID(player.GetGUID())
+	data, err := db.LoadCharacter(player.GetGUID())
+	if err != nil || data == nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	relogged.LoadFromCharacterData(data)
+
+	if !relogged.HasSpellCooldown(SPELL_MULTI_SHOT) || !relogged.HasSpellCooldown(SPELL_AIMED_SHOT) {
+		t.Fatalf("spell and category cooldowns should be restored after relog")
+	}
+}
+
+func TestSpellTemplateReloadValidatesAndAppliesOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spell_template.json")
+	sm := &SpellManager{spells: make(map[uint32]*SpellInfo), templatePath: path}
+	if err := sm.LoadSpells(); err != nil || sm.GetSpell(SPELL_FIREBALL) == nil {
+		t.Fatalf("missing data file should fall back to embedded spells: %v", err)
+	}
+
+	RegisterSpellInfoOverride(SPELL_FIREBALL, func(info *SpellInfo) { info.Range = 40 })
+	defer delete(spellInfoOverrides, SPELL_FIREBALL)
+
+	edited := strings.Replace(string(defaultSpellTemplate), `"mana_cost": 155`, `"mana_cost": 999`, 1)
+	edited = strings.Replace(edited, `"school_mask": 16`, `"school_mask": 1024`, 1)
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.LoadSpells(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	fireball := sm.GetSpell(SPELL_FIREBALL)
+	if fireball.ManaCost != 999 || fireball.Range != 40 {
+		t.Fatalf("expected reloaded mana cost and script range override, got %d/%.0f", fireball.ManaCost, fireball.Range)
+	}
+	if sm.GetSpell(SPELL_BLIZZARD) != nil {
+		t.Fatalf("spell with invalid school mask should be skipped")
+	}
+
+	if err := os.WriteFile(path, []byte("{broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.LoadSpells(); err == nil || sm.GetSpell(SPELL_FIREBALL) != fireball {
+		t.Fatalf("failed reload must keep the previously loaded spells")
+	}
+}
+
+func TestSpellAndUnitScriptHooks(t *testing.T) {
+	mage := newCasterTestUnit("mage", 0)
+	dummy := newThreatTestUnit("dummy", 10)
+	dummy.SetScriptName("npc_training_dummy")
+
+	var hits, kills int
+	RegisterSpellScript(SPELL_FIREBALL, &SpellScript{
+		OnCheckCast: func(spell *Spell, target IUnit) uint8 {
+			if target.GetHealth() < 100 {
+				return SPELL_FAILED_BAD_TARGETS
+			}
+			return SPELL_CAST_OK
+		},
+		CalcDamage: func(spell *Spell, damage uint32) uint32 { return 2000 },
+		OnEffectHit: func(spell *Spell, target IUnit, effect *SpellEffect) bool {
+			hits++
+			return false
+		},
+	})
+	defer delete(spellScripts, SPELL_FIREBALL)
+	RegisterUnitScript("test_killer", &UnitScript{
+		OnKill: func(unit *Unit, victim IUnit) { kills++ },
+	})
+	defer delete(unitScripts, "test_killer")
+	mage.SetScriptName("test_killer")
+
+	fireball := NewSpell(mage, GlobalSpellManager.GetSpell(SPELL_FIREBALL), nil)
+	fireball.setExplicitTarget(dummy)
+	fireball.calculateDamage()
+	fireball.selectSpellTargets()
+	fireball.applyEffects()
+	if hits != 1 || dummy.GetHealth() != 1000 {
+		t.Fatalf("training dummy should ignore the hit, hits=%d health=%d", hits, dummy.GetHealth())
+	}
+
+	target := newThreatTestUnit("target", 10)
+	fireball.setExplicitTarget(target)
+	fireball.selectSpellTargets()
+	fireball.applyEffects()
+	if target.IsAlive() || kills != 1 {
+		t.Fatalf("script damage should kill the target and notify the killer script")
+	}
+
+	wounded := newThreatTestUnit("wounded", 10)
+	if result := NewSpell(mage, fireball.info, nil).checkCast(wounded); result != SPELL_CAST_OK {
+		t.Fatalf("healthy target should pass the check cast script, got %d", result)
+	}
+	wounded.SetHealth(50)
+	if result := NewSpell(mage, fireball.info, nil).checkCast(wounded); result != 12 {
+		t.Fatalf("check cast script should reject the target, got %d", result)
+	}
+}
+
+func TestAreaAndChainTargetSelection(t *testing.T) {
+	world := NewWorld()
+	defer world.GetBatchSyncManager().Stop()
+
+	mage := newCasterTestUnit("mage", 0)
+	priest := newThreatTestUnit("priest", 3)
+	near := NewUnit(generateGUID(), "near", 20, UNIT_TYPE_CREATURE)
+	near.SetPosition(5, 0, 0)
+	behind := NewUnit(generateGUID(), "behind", 20, UNIT_TYPE_CREATURE)
+	behind.SetPosition(-6, 0, 0)
+	far := NewUnit(generateGUID(), "far", 20, UNIT_TYPE_CREATURE)
+	far.SetPosition(40, 0, 0)
+	farPack := NewUnit(generateGUID(), "farPack", 20, UNIT_TYPE_CREATURE)
+	farPack.SetPosition(44, 0, 0)
+	mage.SetFaction(FACTION_TEMPLATE_HUMAN)
+	priest.SetFaction(FACTION_TEMPLATE_HUMAN)
+	for _, unit := range []*Unit{mage, priest, near, behind, far, farPack} {
+		unit.SetMaxHealth(1000)
+		unit.SetHealth(1000)
+		world.AddUnit(unit)
+	}
+
+	nova := NewSpell(mage, GlobalSpellManager.GetSpell(SPELL_FROST_NOVA), world)
+	nova.setExplicitTarget(mage)
+	nova.selectSpellTargets()
+	if got := len(nova.effectTargets[0]); got != 2 || nova.effectTargets[0][0] == IUnit(priest) {
+		t.Fatalf("frost nova should hit the two nearby enemies only, got %d targets", got)
+	}
+
+	cone := &SpellInfo{ID: 1, Name: "cone", SchoolMask: SPELL_SCHOOL_FROST, Effects: []SpellEffect{
+		{EffectType: SPELL_EFFECT_SCHOOL_DAMAGE, ImplicitTargetA: TARGET_UNIT_CONE_ENEMY, RadiusIndex: 13},
+	}}
+	spell := NewSpell(mage, cone, world)
+	spell.setExplicitTarget(near)
+	spell.selectSpellTargets()
+	if len(spell.targets) != 1 || spell.targets[0] != IUnit(near) {
+		t.Fatalf("cone should only hit the enemy in front, got %d targets", len(spell.targets))
+	}
+
+	// 暴风雪以敌方目标的位置为区域中心，命中目标周围的敌人而不是施法者周围的
+	blizzard := NewSpell(mage, GlobalSpellManager.GetSpell(SPELL_BLIZZARD), world)
+	blizzard.setExplicitTarget(far)
+	blizzard.selectSpellTargets()
+	if len(blizzard.targets) != 2 || blizzard.targets[0] == IUnit(near) || blizzard.targets[1] == IUnit(near) {
+		t.Fatalf("blizzard should hit the two enemies around its target, got %d targets", len(blizzard.targets))
+	}
+
+	far.SetPosition(10, 0, 0)
+	behind.SetPosition(9, 3, 0)
+	extra := NewUnit(generateGUID(), "extra", 20, UNIT_TYPE_CREATURE)
+	extra.SetPosition(12, 2, 0)
+	extra.SetMaxHealth(1000)
+	extra.SetHealth(1000)
+	world.AddUnit(extra)
+	world.relocateUnits()
+	multiShot := NewSpell(mage, GlobalSpellManager.GetSpell(SPELL_MULTI_SHOT), world)
+	multiShot.setExplicitTarget(near)
+	multiShot.selectSpellTargets()
+	if len(multiShot.targets) != 3 {
+		t.Fatalf("multi-shot should chain to 3 enemies, got %d", len(multiShot.targets))
+	}
+
+	world.SetLineOfSightChecker(func(x1, y1, z1, x2, y2, z2 float32) bool { return x2 < 8 })
+	nova.selectSpellTargets()
+	if len(nova.effectTargets[0]) != 1 {
+		t.Fatalf("targets out of line of sight should be skipped")
+	}
+}
+
+func TestMissileHitsAfterTravelTime(t *testing.T) {
+	mage := newCasterTestUnit("mage", 0)
+	mob := newThreatTestUnit("mob", 24)
+	info := GlobalSpellManager.GetSpell(SPELL_FIREBALL)
+
+	mage.CastSpell(mob, SPELL_FIREBALL)
+	mage.Update(uint32(info.CastTime.Milliseconds()))
+	if mage.GetCurrentSpell(CURRENT_GENERIC_SPELL) != nil || len(mage.delayedSpells) != 1 {
+		t.Fatalf("fireball should leave the cast slot and travel as a missile")
+	}
+	if mob.GetHealth() != 1000 {
+		t.Fatalf("fireball should not hit before the missile arrives")
+	}
+
+	mage.Update(999)
+	if mob.GetHealth() != 1000 {
+		t.Fatalf("24 yards at 24 yards/sec should take one second")
+	}
+	mage.Update(1)
+	if mob.GetHealth() == 1000 || len(mage.delayedSpells) != 0 {
+		t.Fatalf("fireball should hit after one second of flight")
+	}
+
+	evading := newThreatTestUnit("evading", 24)
+	mage.spellCooldowns = make(map[uint32]time.Time)
+	mage.globalCooldowns = make(map[uint32]time.Time)
+	mage.CastSpell(evading, SPELL_FIREBALL)
+	mage.Update(uint32(info.CastTime.Milliseconds()))
+	evading.AddUnitState(UNIT_STATE_EVADE)
+	mage.Update(1000)
+	if evading.GetHealth() != 1000 {
+		t.Fatalf("target that became immune in flight should not be hit")
+	}
+}
+
+func TestSpellHitChanceAgainstHigherLevels(t *testing.T) {
+	if chance := magicSpellMissChance(80, 80, false); chance != 4 {
+		t.Fatalf("same level miss chance should be 4%%, got %.0f", chance)
+	}
+	if chance := magicSpellMissChance(80, 83, false); chance != 17 {
+		t.Fatalf("boss three levels higher should have 17%% miss chance, got %.0f", chance)
+	}
+	if chance := magicSpellMissChance(80, 83, true); chance != 13 {
+		t.Fatalf("player three levels higher should have 13%% miss chance, got %.0f", chance)
+	}
+
+	mage := newCasterTestUnit("mage", 0)
+	if chance := magicSpellMissChance(mage.GetLevel(), mage.GetLevel(), false) - mage.GetSpellHitChanceBonus(); chance != 0 {
+		t.Fatalf("hit bonus should offset the base miss chance, got %.0f", chance)
+	}
+}
+
+func TestImmunitiesReportedAsSpellMiss(t *testing.T) {
+	mage := newCasterTestUnit("mage", 0)
+	undead := newThreatTestUnit("undead", 10)
+	undead.creatureType = CREATURE_TYPE_UNDEAD
+
+	polymorph := NewSpell(mage, GlobalSpellManager.GetSpell(SPELL_POLYMORPH), nil)
+	if !undead.IsImmunedToSpell(polymorph.info) {
+		t.Fatalf("undead should be immune to polymorph")
+	}
+	polymorph.setExplicitTarget(undead)
+	polymorph.selectSpellTargets()
+	polymorph.applyEffects()
+	hits, misses := polymorph.getHitAndMissTargets()
+	if len(hits) != 0 || len(misses) != 1 || misses[0].missInfo != SPELL_MISS_IMMUNE {
+		t.Fatalf("polymorph on undead should be reported as SPELL_MISS_IMMUNE, got %v", misses)
+	}
+	if undead.HasUnitState(UNIT_STATE_CONFUSED) {
+		t.Fatalf("immune target should not be polymorphed")
+	}
+
+	paladin := newThreatTestUnit("paladin", 10)
+	shield := NewSpell(paladin, GlobalSpellManager.GetSpell(SPELL_DIVINE_SHIELD), nil)
+	shield.setExplicitTarget(paladin)
+	shield.selectSpellTargets()
+	shield.applyEffects()
+	if !paladin.IsImmunedToSchool(SPELL_SCHOOL_FIRE) {
+		t.Fatalf("divine shield should grant school immunity")
+	}
+
+	fireball := NewSpell(mage, GlobalSpellManager.GetSpell(SPELL_FIREBALL), nil)
+	fireball.setExplicitTarget(paladin)
+	fireball.selectSpellTargets()
+	fireball.applyEffects()
+	if fireball.targetMissInfo[paladin.GetGUID()] != SPELL_MISS_IMMUNE || paladin.GetHealth() != 1000 {
+		t.Fatalf("fireball should not hit a target under divine shield")
+	}
+}
+
+func TestPowerRegenerationByType(t *testing.T) {
+	mage := newCasterTestUnit("mage", 0)
+	mage.SetPower(POWER_MANA, 1000)
+	mage.SetSpirit(40)
+
+	mage.Update(REGEN_TIME_INTERVAL)
+	if got := mage.GetPower(POWER_MANA); got != 1000+MANA_REGEN_BASE_PER_TICK+10 {
+		t.Fatalf("mana should regenerate from spirit, got %d", got)
+	}
+
+	// 消耗法力后五秒内不恢复
+	mage.CastSpell(mage, SPELL_FADE)
+	spent := mage.GetPower(POWER_MANA)
+	mage.Update(REGEN_TIME_INTERVAL)
+	mage.Update(REGEN_TIME_INTERVAL)
+	if mage.GetPower(POWER_MANA) != spent {
+		t.Fatalf("mana should not regenerate under the five second rule")
+	}
+	mage.Update(REGEN_TIME_INTERVAL)
+	if mage.GetPower(POWER_MANA) <= spent {
+		t.Fatalf("mana should regenerate once five seconds have passed")
+	}
+
+	rogue := newThreatTestUnit("rogue", 0)
+	rogue.SetMaxPower(POWER_ENERGY, 100)
+	rogue.SetPower(POWER_ENERGY, 50)
+	rogue.Update(REGEN_TIME_INTERVAL / 2)
+	if rogue.GetPower(POWER_ENERGY) != 50 {
+		t.Fatalf("energy should tick every two seconds")
+	}
+	rogue.Update(REGEN_TIME_INTERVAL / 2)
+	if rogue.GetPower(POWER_ENERGY) != 70 {
+		t.Fatalf("energy should regenerate 20 per tick, got %d", rogue.GetPower(POWER_ENERGY))
+	}
+
+	warrior := newThreatTestUnit("warrior", 0)
+	warrior.SetMaxPower(POWER_RAGE, 100)
+	warrior.SetPower(POWER_RAGE, 30)
+	warrior.SetInCombat(true)
+	warrior.combatTimer = 0
+	warrior.victim = rogue
+	warrior.Update(REGEN_TIME_INTERVAL)
+	if warrior.GetPower(POWER_RAGE) != 30 {
+		t.Fatalf("rage should not decay in combat")
+	}
+	warrior.victim = nil
+	warrior.SetInCombat(false)
+	warrior.Update(REGEN_TIME_INTERVAL)
+	if warrior.GetPower(POWER_RAGE) != 30-RAGE_DECAY_PER_TICK {
+		t.Fatalf("rage should decay out of combat, got %d", warrior.GetPower(POWER_RAGE))
+	}
+}
+
+func TestSpellPowerTypeAndPercentCost(t *testing.T) {
+	warrior := newThreatTestUnit("warrior", 0)
+	warrior.SetMaxPower(POWER_RAGE, 100)
+	warrior.SetMaxPower(POWER_MANA, 1000)
+	warrior.SetPower(POWER_MANA, 1000)
+
+	strike := NewSpell(warrior, GlobalSpellManager.GetSpell(SPELL_HEROIC_STRIKE), nil)
+	if strike.checkPower() {
+		t.Fatalf("heroic strike should require rage, not mana")
+	}
+	warrior.SetPower(POWER_RAGE, 20)
+	if !strike.checkPower() {
+		t.Fatalf("heroic strike should be castable with enough rage")
+	}
+	strike.takePower()
+	if warrior.GetPower(POWER_RAGE) != 5 || warrior.GetPower(POWER_MANA) != 1000 {
+		t.Fatalf("heroic strike should consume 15 rage")
+	}
+
+	hunter := newThreatTestUnit("hunter", 0)
+	hunter.SetMaxPower(POWER_MANA, 3000)
+	hunter.SetPower(POWER_MANA, 3000)
+	hunter.SetCreateMana(1000)
+	shot := NewSpell(hunter, GlobalSpellManager.GetSpell(SPELL_MULTI_SHOT), nil)
+	if cost := shot.calcPowerCost(); cost != 90 {
+		t.Fatalf("multi-shot should cost 9%% of base mana, got %d", cost)
+	}
+}
+
+func TestPowerUpdatesCoalescePerWorldTick(t *testing.T) {
+	world := NewWorld()
+	defer world.batchSyncManager.Stop()
+	rogue := newThreatTestUnit("rogue", 0)
+	rogue.SetMaxPower(POWER_ENERGY, 100)
+	rogue.SetWorld(world)
+
+	rogue.ModifyPower(POWER_ENERGY, 20)
+	rogue.ModifyPower(POWER_ENERGY, 20)
+	rogue.ModifyPower(POWER_ENERGY, -10)
+
+	key := pendingPowerKey{guid: rogue.GetGUID(), powerType: POWER_ENERGY}
+	pending := world.pendingPowerUpdates[key]
+	if len(world.pendingPowerUpdates) != 1 || pending.oldPower != 0 || pending.newPower != 30 {
+		t.Fatalf("power changes within one tick should merge into a single update")
+	}
+	world.flushPowerUpdates()
+	if len(world.pendingPowerUpdates) != 0 {
+		t.Fatalf("pending power updates should be sent on flush")
+	}
+}
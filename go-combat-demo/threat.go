@@ -0,0 +1,357 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// 仇恨系统常量 - 基于AzerothCore的ThreatManager
+const (
+	THREAT_SWITCH_MELEE_PCT  = 1.10 // 近战范围内切换目标需要超过当前目标110%的仇恨
+	THREAT_SWITCH_RANGED_PCT = 1.30 // 近战范围外切换目标需要超过当前目标130%的仇恨
+	TAUNT_FIXATE_DURATION    = 3000 // 嘲讽强制攻击时间(毫秒)
+	HEALING_THREAT_PCT       = 0.5  // 治疗仇恨系数 - 每点有效治疗产生0.5点仇恨
+	THREAT_UPDATE_INTERVAL   = 1000 // 仇恨表同步间隔(毫秒)
+)
+
+// ThreatManager 仇恨管理器 - 记录对owner产生仇恨的单位
+type ThreatManager struct {
+	owner         IUnit                  // 仇恨表的拥有者(通常是怪物)
+	threatList    map[uint64]*ThreatInfo // 仇恨列表，key为产生仇恨的单位GUID
+	currentVictim *ThreatInfo            // 当前攻击目标对应的仇恨条目
+	fixateTarget  IUnit                  // 嘲讽者，在fixateTimer内强制攻击
+	fixateTimer   uint32                 // 嘲讽剩余时间(毫秒)
+	updateTimer   uint32                 // 仇恨表同步计时器
+	dirty         bool                   // 仇恨表是否有未同步的变化
+
+	// 仇恨列表中有owner的其它仇恨管理器 - 基于AzerothCore的ThreatManager::_threatenedByMe
+	threatenedByMe map[*ThreatManager]bool
+}
+
+// ThreatInfo 仇恨条目 - 基于AzerothCore的ThreatReference
+type ThreatInfo struct {
+	unit       IUnit
+	threat     float32
+	tempThreat float32 // 被渐隐等效果临时移除的仇恨，到期后返还
+	tempTimer  uint32  // 临时仇恨剩余时间(毫秒)
+}
+
+func NewThreatManager(owner IUnit) *ThreatManager {
+	return &ThreatManager{
+		owner:          owner,
+		threatList:     make(map[uint64]*ThreatInfo),
+		threatenedByMe: make(map[*ThreatManager]bool),
+	}
+}
+
+// getThreatManager 获取单位的仇恨管理器
+func getThreatManager(unit IUnit) *ThreatManager {
+	if base := getBaseUnit(unit); base != nil {
+		return base.threatManager
+	}
+	return nil
+}
+
+func (tm *ThreatManager) getOrCreate(unit IUnit) *ThreatInfo {
+	guid := unit.GetGUID()
+	info, exists := tm.threatList[guid]
+	if !exists {
+		info = &ThreatInfo{unit: unit}
+		tm.threatList[guid] = info
+		if other := getThreatManager(unit); other != nil {
+			other.threatenedByMe[tm] = true
+		}
+	}
+	return info
+}
+
+// GetThreatenedByMeList 仇恨列表中有owner的仇恨管理器，治疗仇恨等不经过攻击的仇恨也会记录
+func (tm *ThreatManager) GetThreatenedByMeList() []*ThreatManager {
+	list := make([]*ThreatManager, 0, len(tm.threatenedByMe))
+	for other := range tm.threatenedByMe {
+		list = append(list, other)
+	}
+	return list
+}
+
+func (tm *ThreatManager) AddThreat(unit IUnit, threat float32) {
+	if unit == nil {
+		return
+	}
+
+	info := tm.getOrCreate(unit)
+	info.threat += threat
+	if info.threat < 0 {
+		info.threat = 0
+	}
+	tm.dirty = true
+
+	if threat > 50 {
+		fmt.Printf("威胁值更新: %s 对目标的威胁值增加 %.1f\n", unit.GetName(), threat)
+	}
+}
+
+// GetThreat 获取单位当前的仇恨值
+func (tm *ThreatManager) GetThreat(unit IUnit) float32 {
+	if info, exists := tm.threatList[unit.GetGUID()]; exists {
+		return info.threat
+	}
+	return 0
+}
+
+// HasThreat 检查单位是否在仇恨列表中
+func (tm *ThreatManager) HasThreat(unit IUnit) bool {
+	_, exists := tm.threatList[unit.GetGUID()]
+	return exists
+}
+
+func (tm *ThreatManager) getHighest() *ThreatInfo {
+	var highest *ThreatInfo
+	for _, info := range tm.threatList {
+		if !info.unit.IsAlive() {
+			continue
+		}
+		if highest == nil || info.threat > highest.threat {
+			highest = info
+		}
+	}
+	return highest
+}
+
+func (tm *ThreatManager) GetHighestThreatTarget() IUnit {
+	if highest := tm.getHighest(); highest != nil {
+		return highest.unit
+	}
+	return nil
+}
+
+// SelectVictim 选择攻击目标 - 基于AzerothCore的ThreatManager::getHostilTarget
+// 只有当新目标的仇恨超过当前目标的110%(近战范围内)或130%(近战范围外)时才切换目标
+func (tm *ThreatManager) SelectVictim() IUnit {
+	// 嘲讽期间强制攻击嘲讽者
+	if tm.fixateTimer > 0 && tm.fixateTarget != nil && tm.fixateTarget.IsAlive() {
+		if info, exists := tm.threatList[tm.fixateTarget.GetGUID()]; exists {
+			tm.setCurrentVictim(info)
+			return info.unit
+		}
+	}
+
+	highest := tm.getHighest()
+	if highest == nil {
+		tm.currentVictim = nil
+		return nil
+	}
+
+	current := tm.currentVictim
+	if current == nil || !current.unit.IsAlive() || tm.threatList[current.unit.GetGUID()] != current {
+		tm.setCurrentVictim(highest)
+		return highest.unit
+	}
+
+	if highest != current {
+		switchPct := float32(THREAT_SWITCH_RANGED_PCT)
+		if tm.owner != nil && tm.owner.IsWithinMeleeRange(highest.unit) {
+			switchPct = THREAT_SWITCH_MELEE_PCT
+		}
+		if highest.threat > current.threat*switchPct {
+			tm.setCurrentVictim(highest)
+		}
+	}
+
+	return tm.currentVictim.unit
+}
+
+func (tm *ThreatManager) setCurrentVictim(info *ThreatInfo) {
+	if tm.currentVictim == info {
+		return
+	}
+	tm.currentVictim = info
+	tm.dirty = false
+	tm.updateTimer = 0
+
+	if tm.owner != nil {
+		fmt.Printf("[仇恨] %s 的目标切换为 %s (仇恨: %.1f)\n",
+			tm.owner.GetName(), info.unit.GetName(), info.threat)
+		if world := getWorldFromUnit(tm.owner); world != nil {
+			world.BroadcastHighestThreatUpdate(tm.owner, info.unit, tm.GetSortedThreatList())
+		}
+	}
+}
+
+// Taunt 嘲讽 - 将嘲讽者的仇恨提升到当前最高仇恨，并强制攻击一段时间
+func (tm *ThreatManager) Taunt(taunter IUnit) {
+	info := tm.getOrCreate(taunter)
+	if highest := tm.getHighest(); highest != nil && highest.threat > info.threat {
+		info.threat = highest.threat
+	}
+
+	tm.fixateTarget = taunter
+	tm.fixateTimer = TAUNT_FIXATE_DURATION
+	tm.setCurrentVictim(info)
+}
+
+// ModifyThreatPercent 按百分比修改仇恨，-100表示清空该单位的仇恨
+func (tm *ThreatManager) ModifyThreatPercent(unit IUnit, pct int32) {
+	info, exists := tm.threatList[unit.GetGUID()]
+	if !exists {
+		return
+	}
+
+	info.threat += info.threat * float32(pct) / 100
+	if info.threat < 0 {
+		info.threat = 0
+	}
+	tm.dirty = true
+}
+
+// ApplyTemporaryThreatReduction 临时降低仇恨(渐隐术)，duration毫秒后返还
+func (tm *ThreatManager) ApplyTemporaryThreatReduction(unit IUnit, amount float32, duration uint32) {
+	info, exists := tm.threatList[unit.GetGUID()]
+	if !exists {
+		return
+	}
+
+	if amount > info.threat {
+		amount = info.threat
+	}
+	info.threat -= amount
+	info.tempThreat += amount
+	info.tempTimer = duration
+	tm.dirty = true
+
+	fmt.Printf("[仇恨] %s 的仇恨临时降低 %.1f 点，持续 %.1f 秒\n",
+		unit.GetName(), amount, float32(duration)/1000)
+}
+
+func (tm *ThreatManager) RemoveThreat(unit IUnit) {
+	guid := unit.GetGUID()
+	if _, exists := tm.threatList[guid]; !exists {
+		return
+	}
+
+	if tm.currentVictim != nil && tm.currentVictim.unit.GetGUID() == guid {
+		tm.currentVictim = nil
+	}
+	if tm.fixateTarget != nil && tm.fixateTarget.GetGUID() == guid {
+		tm.fixateTarget = nil
+		tm.fixateTimer = 0
+	}
+	delete(tm.threatList, guid)
+	if other := getThreatManager(unit); other != nil {
+		delete(other.threatenedByMe, tm)
+	}
+
+	if tm.owner != nil {
+		if world := getWorldFromUnit(tm.owner); world != nil {
+			world.BroadcastThreatRemove(tm.owner, unit)
+		}
+	}
+}
+
+func (tm *ThreatManager) ClearAllThreat() {
+	hadThreat := len(tm.threatList) > 0
+	for _, info := range tm.threatList {
+		if other := getThreatManager(info.unit); other != nil {
+			delete(other.threatenedByMe, tm)
+		}
+	}
+	tm.threatList = make(map[uint64]*ThreatInfo)
+	tm.currentVictim = nil
+	tm.fixateTarget = nil
+	tm.fixateTimer = 0
+	tm.dirty = false
+
+	if hadThreat && tm.owner != nil {
+		if world := getWorldFromUnit(tm.owner); world != nil {
+			world.BroadcastThreatClear(tm.owner)
+		}
+	}
+}
+
+func (tm *ThreatManager) IsEmpty() bool {
+	for _, info := range tm.threatList {
+		if info.unit.IsAlive() {
+			return false
+		}
+	}
+	return true
+}
+
+// GetSortedThreatList 获取按仇恨从高到低排序的仇恨列表
+func (tm *ThreatManager) GetSortedThreatList() []*ThreatInfo {
+	list := make([]*ThreatInfo, 0, len(tm.threatList))
+	for _, info := range tm.threatList {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].threat != list[j].threat {
+			return list[i].threat > list[j].threat
+		}
+		return list[i].unit.GetGUID() < list[j].unit.GetGUID()
+	})
+	return list
+}
+
+// Update 更新嘲讽、临时仇恨计时器并定期同步仇恨表
+func (tm *ThreatManager) Update(diff uint32) {
+	if tm.fixateTimer > 0 {
+		if tm.fixateTimer > diff {
+			tm.fixateTimer -= diff
+		} else {
+			tm.fixateTimer = 0
+			tm.fixateTarget = nil
+		}
+	}
+
+	for _, info := range tm.threatList {
+		if info.tempTimer == 0 {
+			continue
+		}
+		if info.tempTimer > diff {
+			info.tempTimer -= diff
+			continue
+		}
+		// 临时仇恨到期，返还被移除的仇恨
+		info.tempTimer = 0
+		info.threat += info.tempThreat
+		info.tempThreat = 0
+		tm.dirty = true
+	}
+
+	tm.updateTimer += diff
+	if tm.dirty && tm.updateTimer >= THREAT_UPDATE_INTERVAL {
+		tm.updateTimer = 0
+		tm.dirty = false
+		if tm.owner != nil && len(tm.threatList) > 0 {
+			if world := getWorldFromUnit(tm.owner); world != nil {
+				world.BroadcastThreatUpdate(tm.owner, tm.GetSortedThreatList())
+			}
+		}
+	}
+}
+
+// addHealingThreat 治疗仇恨 - 治疗量的一半平分给所有仇恨列表中有被治疗者的怪物
+// 基于AzerothCore的ThreatManager::ForwardThreatForAssistingMe
+func addHealingThreat(healer IUnit, healed *Unit, healing uint32) {
+	if healer == nil || healing == 0 {
+		return
+	}
+
+	var engaged []*ThreatManager
+	for _, attacker := range healed.attackers {
+		if !attacker.IsAlive() {
+			continue
+		}
+		if tm := getThreatManager(attacker); tm != nil && tm.HasThreat(healed) {
+			engaged = append(engaged, tm)
+		}
+	}
+	if len(engaged) == 0 {
+		return
+	}
+
+	threat := float32(healing) * HEALING_THREAT_PCT / float32(len(engaged))
+	for _, tm := range engaged {
+		tm.AddThreat(healer, threat)
+	}
+}
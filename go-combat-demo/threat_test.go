@@ -0,0 +1,144 @@
+package main
+
+import "testing"
+
+func newThreatTestUnit(name string, x float32) *Unit {
+	unit := NewUnit(generateGUID(), name, 20, UNIT_TYPE_PLAYER)
+	unit.SetMaxHealth(1000)
+	unit.SetHealth(1000)
+	unit.SetPosition(x, 0, 0)
+	return unit
+}
+
+func TestThreatSwitchInMeleeRange(t *testing.T) {
+	mob := newThreatTestUnit("mob", 0)
+	tank := newThreatTestUnit("tank", 1)
+	rogue := newThreatTestUnit("rogue", 1)
+
+	tm := mob.threatManager
+	tm.AddThreat(tank, 1000)
+	tm.AddThreat(rogue, 500)
+	if victim := tm.SelectVictim(); victim != tank {
+		t.Fatalf("expected tank as initial victim, got %v", victim.GetName())
+	}
+
+	tm.AddThreat(rogue, 600) // 1100 = 110%, not above
+	if victim := tm.SelectVictim(); victim != tank {
+		t.Fatalf("victim switched at exactly 110%%")
+	}
+
+	tm.AddThreat(rogue, 1) // 1101 > 110%
+	if victim := tm.SelectVictim(); victim != rogue {
+		t.Fatalf("expected switch to rogue above 110%%, got %v", victim.GetName())
+	}
+}
+
+func TestThreatSwitchOutOfMeleeRange(t *testing.T) {
+	mob := newThreatTestUnit("mob", 0)
+	tank := newThreatTestUnit("tank", 1)
+	mage := newThreatTestUnit("mage", 30)
+
+	tm := mob.threatManager
+	tm.AddThreat(tank, 1000)
+	tm.SelectVictim()
+
+	tm.AddThreat(mage, 1200)
+	if victim := tm.SelectVictim(); victim != tank {
+		t.Fatalf("ranged attacker pulled aggro below 130%%")
+	}
+
+	tm.AddThreat(mage, 101)
+	if victim := tm.SelectVictim(); victim != mage {
+		t.Fatalf("expected switch to mage above 130%%, got %v", victim.GetName())
+	}
+}
+
+func TestTauntMatchesTopThreatAndFixates(t *testing.T) {
+	mob := newThreatTestUnit("mob", 0)
+	tank := newThreatTestUnit("tank", 1)
+	mage := newThreatTestUnit("mage", 30)
+
+	tm := mob.threatManager
+	tm.AddThreat(mage, 2000)
+	tm.AddThreat(tank, 100)
+	tm.SelectVictim()
+
+	tm.Taunt(tank)
+	if got := tm.GetThreat(tank); got != 2000 {
+		t.Fatalf("taunt should raise threat to 2000, got %.1f", got)
+	}
+
+	tm.AddThreat(mage, 5000)
+	if victim := tm.SelectVictim(); victim != tank {
+		t.Fatalf("taunted mob must stay on taunter during fixate")
+	}
+
+	tm.Update(TAUNT_FIXATE_DURATION)
+	if victim := tm.SelectVictim(); victim != mage {
+		t.Fatalf("expected mob to return to mage after fixate expires")
+	}
+}
+
+func TestHealingThreatSplitAcrossEngagedMobs(t *testing.T) {
+	tank := newThreatTestUnit("tank", 0)
+	priest := newThreatTestUnit("priest", 20)
+	mob1 := newThreatTestUnit("mob1", 1)
+	mob2 := newThreatTestUnit("mob2", 1)
+
+	mob1.CombatStart(tank)
+	mob2.CombatStart(tank)
+
+	tank.SetHealth(500)
+	tank.Heal(priest, 400)
+
+	for _, mob := range []*Unit{mob1, mob2} {
+		if got := mob.threatManager.GetThreat(priest); got != 100 {
+			t.Fatalf("%s: expected 100 healing threat, got %.1f", mob.GetName(), got)
+		}
+	}
+}
+
+func TestTemporaryThreatReductionIsRestored(t *testing.T) {
+	mob := newThreatTestUnit("mob", 0)
+	priest := newThreatTestUnit("priest", 20)
+
+	tm := mob.threatManager
+	tm.AddThreat(priest, 1000)
+	tm.ApplyTemporaryThreatReduction(priest, 1500, 10000)
+	if got := tm.GetThreat(priest); got != 0 {
+		t.Fatalf("expected threat reduced to 0, got %.1f", got)
+	}
+
+	tm.Update(10000)
+	if got := tm.GetThreat(priest); got != 1000 {
+		t.Fatalf("expected threat restored to 1000, got %.1f", got)
+	}
+}
+
+func TestFadeReducesHealingOnlyThreat(t *testing.T) {
+	tank := newThreatTestUnit("tank", 0)
+	priest := newCasterTestUnit("priest", 20)
+	mob := newThreatTestUnit("mob", 1)
+
+	// 只有治疗仇恨：怪物没有攻击牧师，但仇恨列表中有牧师
+	mob.CombatStart(tank)
+	tank.SetHealth(500)
+	tank.Heal(priest, 400)
+	if _, attacking := priest.attackers[mob.GetGUID()]; attacking || mob.threatManager.GetThreat(priest) != 200 {
+		t.Fatalf("healer should only have healing threat, got %.1f", mob.threatManager.GetThreat(priest))
+	}
+
+	priest.CastSpell(priest, SPELL_FADE)
+	if got := mob.threatManager.GetThreat(priest); got != 0 {
+		t.Fatalf("fade should reduce healing threat, got %.1f", got)
+	}
+	mob.threatManager.Update(10000)
+	if got := mob.threatManager.GetThreat(priest); got != 200 {
+		t.Fatalf("healing threat should be restored after fade, got %.1f", got)
+	}
+
+	mob.threatManager.ClearAllThreat()
+	if len(priest.threatManager.GetThreatenedByMeList()) != 0 {
+		t.Fatal("cleared threat list should no longer reference the healer")
+	}
+}
@@ -61,6 +61,7 @@ const (
 	// 单位状态 - 使用位掩码表示各种状态，可以同时拥有多个状态
 	UNIT_STATE_DIED            = 0x00000001 // 死亡状态 - 单位已死亡
 	UNIT_STATE_MELEE_ATTACKING = 0x00000002 // 近战攻击中 - 正在进行近战攻击
+	UNIT_STATE_CHASE           = 0x00000020 // 追击状态 - 正在追向攻击目标
 	UNIT_STATE_CHARMED         = 0x00000400 // 魅惑状态 - 被敌人控制
 	UNIT_STATE_STUNNED         = 0x00000800 // 昏迷状态 - 无法行动
 	UNIT_STATE_ROOTED          = 0x00001000 // 定身状态 - 无法移动但可以攻击
@@ -82,6 +83,10 @@ const (
 	UNIT_STATE_FLEEING         = 0x10000000 // 逃跑状态 - 正在逃离战斗
 	UNIT_STATE_IN_COMBAT       = 0x20000000 // 战斗状态 - 处于战斗中
 
+	// 组合状态 - 基于AzerothCore的UNIT_STATE_LOST_CONTROL/UNIT_STATE_NOT_MOVE
+	UNIT_STATE_LOST_CONTROL = UNIT_STATE_STUNNED | UNIT_STATE_CONFUSED | UNIT_STATE_FLEEING // 失控 - 无法攻击和施法
+	UNIT_STATE_NOT_MOVE     = UNIT_STATE_ROOTED | UNIT_STATE_ROOT | UNIT_STATE_STUNNED | UNIT_STATE_DIED
+
 	// 单位标志 - 额外的状态标记
 	UNIT_FLAG_IN_COMBAT     = 0x00080000 // 战斗标志 - 标记单位处于战斗状态
 	UNIT_FLAG_PET_IN_COMBAT = 0x00100000 // 宠物战斗标志 - 宠物处于战斗状态
@@ -96,6 +101,20 @@ const (
 	// 战斗距离 - 近战攻击的有效范围(码)
 	MIN_MELEE_REACH = 1.5 // 最小近战范围 - 近战攻击的最小距离
 
+	// 移动速度 - 单位默认移动速度(码/秒)
+	BASE_RUN_SPEED = 7.0 // 基础奔跑速度
+
+	// 脱战与拉扯 - 基于AzerothCore的Creature::CanCreatureAttack
+	CREATURE_LEASH_RANGE    = 60.0 // 拉扯距离 - 离开出生点超过此距离时脱战返回
+	EVADE_REGEN_PCT_PER_SEC = 20   // 脱战返回时每秒恢复的生命百分比
+
+	// 仇恨范围 - 基于AzerothCore的Creature::GetAggroRange，同等级20码，每级差1码
+	CREATURE_AGGRO_RADIUS_BASE = 20.0
+	CREATURE_AGGRO_RADIUS_MIN  = 5.0
+	CREATURE_AGGRO_RADIUS_MAX  = 45.0
+
+	CREATURE_SUMMON_DISTANCE = 3.0 // 召唤物出现在召唤者周围的距离
+
 	// 命中结果 - 攻击的各种可能结果（主要定义在damage.go中）
 	MELEE_HIT_CRUSHING = 7 // 碾压 - 高等级对低等级的强力攻击
 
@@ -174,6 +193,11 @@ type IAI interface {
 	DamageDealt(victim IUnit, damage uint32)
 }
 
+// 可重置的AI - 脱战时恢复初始状态，基于AzerothCore的CreatureAI::Reset
+type IResettableAI interface {
+	Reset()
+}
+
 // 基础单位结构
 type Unit struct {
 	// 基础标识信息
@@ -214,9 +238,52 @@ type Unit struct {
 	target IUnit // 当前选择的目标，用于技能施放和交互
 
 	// 法术系统 - 基于AzerothCore的法术管理
-	currentSpells  map[int]*Spell       // 当前施法中的法术，key为法术类型(CURRENT_GENERIC_SPELL等)
-	spellCooldowns map[uint32]time.Time // 法术冷却时间，key为法术ID，value为冷却结束时间
-	world          *World               // 世界引用，用于法术系统
+	currentSpells     map[int]*Spell       // 当前施法中的法术，key为法术类型(CURRENT_GENERIC_SPELL等)
+	spellCooldowns    map[uint32]time.Time // 法术冷却时间，key为法术ID，value为冷却结束时间
+	categoryCooldowns map[uint32]time.Time // 类别冷却时间，key为法术类别
+	globalCooldowns   map[uint32]time.Time // 公共冷却时间，key为公共冷却类别(StartRecoveryCategory)
+	schoolLockouts    map[int]time.Time    // 学派封锁，key为法术学派，value为封锁结束时间
+	world             *World               // 世界引用，用于法术系统
+
+	// 光环系统 - 控制效果和递减
+	auras       []*Aura                    // 身上的光环
+	diminishing map[int]*DiminishingReturn // PvP控制递减，key为递减分组
+
+	script *UnitScript // 单位脚本(受伤、击杀钩子)
+
+	delayedSpells []*Spell // 已施放、弹道尚未命中的法术
+
+	// 法术命中结算
+	creatureType       uint8         // 生物类型(CREATURE_TYPE_*)，玩家为人形
+	mechanicImmuneMask uint32        // 机制免疫掩码 - 基于creature_template.mechanic_immune_mask
+	resistances        map[int]int32 // 各学派抗性，key为学派掩码
+
+	// 能量恢复
+	spirit         uint32 // 精神，影响法力恢复
+	createMana     uint32 // 基础法力，百分比法力消耗的基数
+	regenTimer     uint32 // 能量恢复计时器
+	manaRegenDelay uint32 // 五秒规则剩余时间，期间不恢复法力
+
+	// 环境伤害
+	breathTimer      uint32 // 剩余呼吸时间
+	environmentTimer uint32 // 溺水、岩浆伤害计时器
+
+	lastMoveTime time.Time    // 上次接受客户端移动的时间，用于速度校验
+	movementInfo MovementInfo // 最后一次的移动信息(标志、时间戳、载具等)
+	lastFallZ    float32      // 开始下落时的高度，落地时计算摔落伤害
+
+	// 服务器驱动的移动
+	motionMaster *MotionMaster // 移动生成器栈(待机、巡逻、追击、逃跑等)
+	moveSpline   *MoveSpline   // 当前样条移动，nil表示静止
+
+	// 玩家和击杀奖励
+	player             *Player   // 单位所属的玩家对象，生物为nil - 对应AzerothCore的Unit::ToPlayer
+	creature           *Creature // 单位所属的生物对象，玩家为nil - 对应AzerothCore的Unit::ToCreature
+	lootRecipient      *Player   // 第一个造成伤害的玩家，获得经验和拾取权
+	lootRecipientGroup *Group    // 拾取权所属的队伍
+
+	damageModifier float32 // 伤害系数 - 对应AzerothCore的creature_template.DamageModifier，副本难度会调整
+	faction        uint32  // 阵营模板 - 对应AzerothCore的FactionTemplate
 }
 
 // 创建基础单位
@@ -230,11 +297,24 @@ func NewUnit(guid uint64, name string, level uint8, unitType int) *Unit {
 		maxPowers:      make(map[uint8]uint32),
 		attackers:      make(map[uint64]IUnit),
 		attackTimer:    make(map[int]int32),
-		threatManager:  NewThreatManager(),
 		currentSpells:  make(map[int]*Spell),
 		spellCooldowns: make(map[uint32]time.Time),
+		schoolLockouts: make(map[int]time.Time),
+
+		categoryCooldowns: make(map[uint32]time.Time),
+		globalCooldowns:   make(map[uint32]time.Time),
+		diminishing:       make(map[int]*DiminishingReturn),
+		creatureType:      CREATURE_TYPE_HUMANOID,
+		resistances:       make(map[int]int32),
+		spirit:            DEFAULT_SPIRIT_BASE + uint32(level)*DEFAULT_SPIRIT_PER_LEVEL,
+		breathTimer:       BREATH_TIMER_MAX,
+		damageModifier:    1,
 	}
 
+	// 仇恨表以自身为拥有者，用于目标切换时的距离判断
+	unit.threatManager = NewThreatManager(unit)
+	unit.motionMaster = NewMotionMaster(unit)
+
 	// 初始化攻击计时器
 	unit.attackTimer[BASE_ATTACK] = 0
 	unit.attackTimer[OFF_ATTACK] = 0
@@ -249,6 +329,12 @@ func (u *Unit) GetName() string      { return u.name }
 func (u *Unit) GetLevel() uint8      { return u.level }
 func (u *Unit) SetLevel(level uint8) { u.level = level }
 
+// GetFaction 阵营模板
+func (u *Unit) GetFaction() uint32 { return u.faction }
+
+// SetFaction 设置阵营模板
+func (u *Unit) SetFaction(faction uint32) { u.faction = faction }
+
 func (u *Unit) GetHealth() uint32    { return u.health }
 func (u *Unit) GetMaxHealth() uint32 { return u.maxHealth }
 func (u *Unit) SetHealth(health uint32) {
@@ -353,13 +439,9 @@ func (u *Unit) ModifyPower(powerType uint8, delta int32) int32 {
 		newPower = maxPower
 	}
 
+	// SetPower负责网络同步
 	u.SetPower(powerType, uint32(newPower))
 
-	// 网络同步 - 基于AzerothCore的SMSG_POWER_UPDATE
-	if u.world != nil && oldPower != newPower {
-		u.world.BroadcastPowerUpdate(u, powerType, uint32(oldPower), uint32(newPower))
-	}
-
 	return newPower - oldPower
 }
 
@@ -425,6 +507,11 @@ func (u *Unit) ClearUnitState(state uint32) {
 	u.unitState &= ^state
 }
 
+// CanMove 检查单位能否主动移动 - 定身、昏迷、恐惧、变形期间不能
+func (u *Unit) CanMove() bool {
+	return !u.HasUnitState(UNIT_STATE_NOT_MOVE | UNIT_STATE_LOST_CONTROL)
+}
+
 func (u *Unit) GetAI() IAI {
 	return u.ai
 }
@@ -466,13 +553,15 @@ func (u *Unit) AttackStop() {
 
 // 开始战斗
 func (u *Unit) CombatStart(target IUnit) {
-	if !u.IsInCombat() {
+	engaged := !u.IsInCombat()
+	if engaged {
 		u.SetInCombat(true)
 		fmt.Printf("%s 进入战斗状态\n", u.name)
 	}
 
 	// 目标也进入战斗
-	if !target.IsInCombat() {
+	targetEngaged := !target.IsInCombat()
+	if targetEngaged {
 		target.SetInCombat(true)
 		fmt.Printf("%s 进入战斗状态\n", target.GetName())
 	}
@@ -487,11 +576,17 @@ func (u *Unit) CombatStart(target IUnit) {
 		selfUnit.attackers[target.GetGUID()] = target
 	}
 
-	// 通知AI进入战斗
-	if u.ai != nil {
+	// 进入战斗时双方互相加入仇恨列表(0点仇恨)
+	if targetThreat := getThreatManager(target); targetThreat != nil {
+		targetThreat.AddThreat(u, 0)
+	}
+	u.threatManager.AddThreat(target, 0)
+
+	// 只在刚进入战斗时通知AI - 对应AzerothCore的JustEngagedWith
+	if engaged && u.ai != nil {
 		u.ai.EnterCombat(target)
 	}
-	if target.GetAI() != nil {
+	if targetEngaged && target.GetAI() != nil {
 		target.GetAI().EnterCombat(u)
 	}
 }
@@ -500,6 +595,7 @@ func (u *Unit) CombatStart(target IUnit) {
 func (u *Unit) setDeathState() {
 	u.AddUnitState(UNIT_STATE_DIED)
 	u.AttackStop()
+	u.RemoveAllAuras(AURA_REMOVE_BY_DEATH)
 
 	// 清除战斗状态
 	u.SetInCombat(false)
@@ -517,6 +613,11 @@ func (u *Unit) setDeathState() {
 		u.ai.JustDied(killer)
 	}
 
+	// 玩家留在尸体上等待复活或释放灵魂
+	if u.player != nil {
+		u.player.deathState = DEATH_STATE_DEAD
+	}
+
 	fmt.Printf("%s 死亡了\n", u.name)
 }
 
@@ -543,19 +644,35 @@ func (u *Unit) Update(diff uint32) {
 		}
 	}
 
+	// 更新仇恨系统 - 嘲讽计时、临时仇恨和仇恨表同步
+	u.threatManager.Update(diff)
+
+	// 能量恢复
+	u.updatePowerRegen(diff)
+
+	// 呼吸和液体伤害
+	u.updateEnvironment(diff)
+
+	// 更新控制递减和光环持续时间 - 递减先于光环更新，本次到期的控制从下次更新开始计时
+	u.updateDiminishing(diff)
+	u.updateAuras(diff)
+
 	// 更新法术系统 - 基于AzerothCore的法术更新逻辑
 	u.updateSpells(diff)
 
-	// 执行攻击
-	if u.victim != nil && u.IsAlive() && u.victim.IsAlive() {
+	// 样条移动和移动生成器
+	u.updateMovement(diff)
+
+	// 执行攻击 - 昏迷、恐惧、变形期间无法近战攻击
+	if u.victim != nil && u.IsAlive() && u.victim.IsAlive() && !u.HasUnitState(UNIT_STATE_LOST_CONTROL) {
 		if u.attackTimer[BASE_ATTACK] <= 0 {
 			u.performMeleeAttack(u.victim)
-			u.attackTimer[BASE_ATTACK] = BASE_ATTACK_TIME
+			u.attackTimer[BASE_ATTACK] = u.GetAttackTime()
 		}
 	}
 
-	// 更新AI
-	if u.ai != nil {
+	// 更新AI - 失控期间不做决策
+	if u.ai != nil && !u.HasUnitState(UNIT_STATE_LOST_CONTROL) {
 		u.ai.UpdateAI(diff)
 	}
 }
@@ -609,8 +726,10 @@ func (u *Unit) performMeleeAttack(target IUnit) {
 
 // 计算近战伤害
 func (u *Unit) calculateMeleeDamage(target IUnit) uint32 {
-	// 基础伤害基于等级
+	// 基础伤害基于等级，每14点攻击强度每秒增加1点伤害
 	baseDamage := float32(u.level) * 10.0
+	baseDamage += float32(u.GetAttackPowerMod()) / 14 * BASE_ATTACK_TIME / 1000
+	baseDamage *= u.GetDamageDoneMultiplier()
 
 	// 添加一些随机性
 	variance := baseDamage * 0.3 // 30%的变化范围
@@ -707,10 +826,37 @@ func (u *Unit) IsValidAttackTarget(target IUnit) bool {
 		return false
 	}
 
-	// 简化版：玩家可以攻击生物，生物可以攻击玩家
+	// 脱战返回中的单位无法被攻击
+	if target.HasUnitState(UNIT_STATE_EVADE) {
+		return false
+	}
+
+	// 不能攻击友方，中立的单位可以攻击 - 基于AzerothCore的Unit::_IsValidAttackTarget
+	other := getBaseUnit(target)
+	if other == nil || u.IsFriendlyTo(target) || other.IsFriendlyTo(u) {
+		return false
+	}
+
+	// 玩家之间只能攻击决斗对手或开启了PvP的玩家
+	if u.player != nil && other.player != nil && !u.player.IsInDuelWith(other.player) && !other.player.IsPvP() {
+		return false
+	}
 	return true
 }
 
+// getBaseUnit 获取IUnit对应的基础Unit结构
+func getBaseUnit(unit IUnit) *Unit {
+	switch u := unit.(type) {
+	case *Unit:
+		return u
+	case *Player:
+		return u.Unit
+	case *Creature:
+		return u.Unit
+	}
+	return nil
+}
+
 // SetTarget 设置目标
 func (u *Unit) SetTarget(target IUnit) {
 	u.target = target
@@ -723,6 +869,11 @@ func (u *Unit) GetTarget() IUnit {
 
 // CastSpell 施放法术 - 基于AzerothCore的Unit::CastSpell
 func (u *Unit) CastSpell(target IUnit, spellId uint32) {
+	if GlobalSpellManager == nil {
+		fmt.Printf("法术管理器未初始化，%s 无法施放法术 %d\n", u.GetName(), spellId)
+		return
+	}
+
 	// 获取法术信息
 	spellInfo := GlobalSpellManager.GetSpell(spellId)
 	if spellInfo == nil {
@@ -733,12 +884,27 @@ func (u *Unit) CastSpell(target IUnit, spellId uint32) {
 	// 检查是否在冷却中
 	if u.isSpellOnCooldown(spellId) {
 		fmt.Printf("%s 的 %s 还在冷却中\n", u.GetName(), spellInfo.Name)
+		if u.world != nil {
+			u.world.SendCastResult(u, spellId, SPELL_FAILED_NOT_READY)
+		}
+		return
+	}
+
+	// 检查公共冷却
+	if u.HasGlobalCooldown(spellInfo) {
+		fmt.Printf("%s 的 %s 处于公共冷却中\n", u.GetName(), spellInfo.Name)
+		if u.world != nil {
+			u.world.SendCastResult(u, spellId, SPELL_FAILED_NOT_READY)
+		}
 		return
 	}
 
 	// 检查是否已在施法
 	if u.isCurrentlySpellCasting() {
 		fmt.Printf("%s 正在施法，无法施放新法术\n", u.GetName())
+		if u.world != nil {
+			u.world.SendCastResult(u, spellId, SPELL_FAILED_SPELL_IN_PROGRESS)
+		}
 		return
 	}
 
@@ -753,17 +919,30 @@ func (u *Unit) CastSpell(target IUnit, spellId uint32) {
 			spellType = CURRENT_CHANNELED_SPELL
 		}
 
-		u.currentSpells[spellType] = spell
-
-		// 设置冷却时间
-		if spellInfo.Cooldown > 0 {
-			u.spellCooldowns[spellId] = time.Now().Add(spellInfo.Cooldown)
+		// 已发射的弹道不占用施法槽位
+		if spell.state == SPELL_STATE_DELAYED {
+			u.delayedSpells = append(u.delayedSpells, spell)
+		} else {
+			u.currentSpells[spellType] = spell
 		}
+
+		// 设置公共冷却和冷却时间
+		u.AddGlobalCooldown(spellInfo)
+		u.AddSpellCooldown(spellInfo)
 	}
 }
 
 // updateSpells 更新法术状态 - 基于AzerothCore的Unit::_UpdateSpells
 func (u *Unit) updateSpells(diff uint32) {
+	// 更新飞行中的弹道 - 先于施法更新，本次刚发射的弹道从下次更新开始计时
+	remaining := u.delayedSpells[:0]
+	for _, spell := range u.delayedSpells {
+		if spell.Update(diff) {
+			remaining = append(remaining, spell)
+		}
+	}
+	u.delayedSpells = remaining
+
 	// 更新所有当前施法中的法术
 	for spellType, spell := range u.currentSpells {
 		if spell != nil {
@@ -771,6 +950,10 @@ func (u *Unit) updateSpells(diff uint32) {
 			if !spell.Update(diff) {
 				// 法术完成或被打断，移除
 				delete(u.currentSpells, spellType)
+			} else if spell.state == SPELL_STATE_DELAYED {
+				// 施法完成后弹道独立飞行，施法者可以继续施法
+				delete(u.currentSpells, spellType)
+				u.delayedSpells = append(u.delayedSpells, spell)
 			}
 		}
 	}
@@ -782,16 +965,132 @@ func (u *Unit) updateSpells(diff uint32) {
 			delete(u.spellCooldowns, spellId)
 		}
 	}
+	for category, cooldownEnd := range u.categoryCooldowns {
+		if now.After(cooldownEnd) {
+			delete(u.categoryCooldowns, category)
+		}
+	}
+	for category, cooldownEnd := range u.globalCooldowns {
+		if now.After(cooldownEnd) {
+			delete(u.globalCooldowns, category)
+		}
+	}
+	for school, lockoutEnd := range u.schoolLockouts {
+		if now.After(lockoutEnd) {
+			delete(u.schoolLockouts, school)
+		}
+	}
+}
+
+// LockSpellSchool 封锁法术学派 - 基于AzerothCore的SpellHistory::LockSpellSchool
+func (u *Unit) LockSpellSchool(schoolMask int, duration time.Duration) {
+	u.schoolLockouts[schoolMask] = time.Now().Add(duration)
+}
+
+// IsSchoolLocked 检查法术学派是否被封锁
+func (u *Unit) IsSchoolLocked(schoolMask int) bool {
+	for school, lockoutEnd := range u.schoolLockouts {
+		if school&schoolMask != 0 && time.Now().Before(lockoutEnd) {
+			return true
+		}
+	}
+	return false
 }
 
-// isSpellOnCooldown 检查法术是否在冷却中
+// isSpellOnCooldown 检查法术是否在冷却中(包括类别冷却)
 func (u *Unit) isSpellOnCooldown(spellId uint32) bool {
-	if cooldownEnd, exists := u.spellCooldowns[spellId]; exists {
+	return u.GetSpellCooldownDelay(spellId) > 0
+}
+
+// HasGlobalCooldown 检查法术是否受公共冷却限制 - 基于AzerothCore的SpellHistory::HasGlobalCooldown
+func (u *Unit) HasGlobalCooldown(spellInfo *SpellInfo) bool {
+	if spellInfo.StartRecoveryCategory == 0 {
+		return false
+	}
+	if cooldownEnd, exists := u.globalCooldowns[spellInfo.StartRecoveryCategory]; exists {
 		return time.Now().Before(cooldownEnd)
 	}
 	return false
 }
 
+// AddGlobalCooldown 触发公共冷却 - 基于AzerothCore的Spell::TriggerGlobalCooldown
+// 法术类公共冷却受急速影响，最低缩短到1秒；物理技能不受急速影响
+func (u *Unit) AddGlobalCooldown(spellInfo *SpellInfo) {
+	if spellInfo.StartRecoveryCategory == 0 || spellInfo.StartRecoveryTime <= 0 {
+		return
+	}
+
+	gcd := spellInfo.StartRecoveryTime
+	if spellInfo.SchoolMask != SPELL_SCHOOL_NORMAL && gcd >= SPELL_MIN_GCD && gcd <= SPELL_GCD_DURATION {
+		gcd = time.Duration(float32(gcd) * u.GetCastSpeedMod())
+		if gcd < SPELL_MIN_GCD {
+			gcd = SPELL_MIN_GCD
+		}
+	}
+	u.globalCooldowns[spellInfo.StartRecoveryCategory] = time.Now().Add(gcd)
+}
+
+// GetCastSpeedMod 施法速度系数 - 基于AzerothCore的UNIT_MOD_CAST_SPEED，急速越高系数越小
+func (u *Unit) GetCastSpeedMod() float32 {
+	mod := float32(1.0)
+	for _, aura := range u.auras {
+		if aura.auraType == SPELL_AURA_MOD_CASTING_SPEED_NOT_STACK {
+			mod *= 100 / (100 + float32(aura.value))
+		}
+	}
+	return mod
+}
+
+// AddSpellCooldown 添加法术冷却和类别冷却，并把受影响的法术合并到一个SMSG_SPELL_COOLDOWN中发送
+func (u *Unit) AddSpellCooldown(spellInfo *SpellInfo) {
+	now := time.Now()
+	var cooldowns []SpellCooldownEntry
+
+	if spellInfo.Cooldown > 0 {
+		u.spellCooldowns[spellInfo.ID] = now.Add(spellInfo.Cooldown)
+		cooldowns = append(cooldowns, SpellCooldownEntry{SpellId: spellInfo.ID, Cooldown: spellInfo.Cooldown})
+	}
+
+	if spellInfo.Category != 0 && spellInfo.CategoryCooldown > 0 {
+		u.categoryCooldowns[spellInfo.Category] = now.Add(spellInfo.CategoryCooldown)
+		if GlobalSpellManager != nil {
+			for _, other := range GlobalSpellManager.GetSpellsByCategory(spellInfo.Category) {
+				if other.ID == spellInfo.ID && spellInfo.Cooldown >= spellInfo.CategoryCooldown {
+					continue
+				}
+				cooldowns = append(cooldowns, SpellCooldownEntry{SpellId: other.ID, Cooldown: spellInfo.CategoryCooldown})
+			}
+		}
+	}
+
+	if len(cooldowns) > 0 && u.world != nil {
+		u.world.SendSpellCooldowns(u, cooldowns)
+	}
+}
+
+// GetSpellCooldowns 获取所有未结束的冷却(包括类别冷却影响的法术)，用于登录时同步给客户端
+func (u *Unit) GetSpellCooldowns() []SpellCooldownEntry {
+	var cooldowns []SpellCooldownEntry
+	for spellId := range u.spellCooldowns {
+		if delay := u.GetSpellCooldownDelay(spellId); delay > 0 {
+			cooldowns = append(cooldowns, SpellCooldownEntry{SpellId: spellId, Cooldown: delay})
+		}
+	}
+	if GlobalSpellManager != nil {
+		for category := range u.categoryCooldowns {
+			for _, spellInfo := range GlobalSpellManager.GetSpellsByCategory(category) {
+				if _, exists := u.spellCooldowns[spellInfo.ID]; exists {
+					continue
+				}
+				if delay := u.GetSpellCooldownDelay(spellInfo.ID); delay > 0 {
+					cooldowns = append(cooldowns, SpellCooldownEntry{SpellId: spellInfo.ID, Cooldown: delay})
+				}
+			}
+		}
+	}
+	return cooldowns
+}
+
 // isCurrentlySpellCasting 检查是否正在施法
 func (u *Unit) isCurrentlySpellCasting() bool {
 	return len(u.currentSpells) > 0
@@ -832,15 +1131,23 @@ func (u *Unit) HasSpellCooldown(spellId uint32) bool {
 	return u.isSpellOnCooldown(spellId)
 }
 
-// GetSpellCooldownDelay 获取法术冷却剩余时间
+// GetSpellCooldownDelay 获取法术冷却剩余时间，取法术冷却和类别冷却中较长的一个
 func (u *Unit) GetSpellCooldownDelay(spellId uint32) time.Duration {
+	var remaining time.Duration
 	if cooldownEnd, exists := u.spellCooldowns[spellId]; exists {
-		remaining := time.Until(cooldownEnd)
-		if remaining > 0 {
-			return remaining
+		remaining = time.Until(cooldownEnd)
+	}
+	if GlobalSpellManager != nil {
+		if spellInfo := GlobalSpellManager.GetSpell(spellId); spellInfo != nil && spellInfo.Category != 0 {
+			if cooldownEnd, exists := u.categoryCooldowns[spellInfo.Category]; exists && time.Until(cooldownEnd) > remaining {
+				remaining = time.Until(cooldownEnd)
+			}
 		}
 	}
-	return 0
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
 }
 
 // Heal 治疗
@@ -858,6 +1165,9 @@ func (u *Unit) Heal(caster IUnit, amount uint32) {
 		fmt.Printf("%s 被治疗了 %d 点生命值 (%d/%d)\n",
 			u.GetName(), actualHealing, newHealth, u.GetMaxHealth())
 
+		// 治疗仇恨分摊给所有与被治疗者交战的怪物
+		addHealingThreat(caster, u, actualHealing)
+
 		// 网络广播治疗信息 - 基于AzerothCore的网络同步
 		if u.world != nil && caster != nil {
 			u.world.BroadcastUnitUpdate(u) // 广播生命值更新
@@ -334,7 +334,13 @@ func (bsm *BatchSyncManager) buildSinglePacket(update *BatchUpdate) *WorldPacket
 func (bsm *BatchSyncManager) GetStatistics() BatchSyncStats {
 	bsm.statistics.mutex.RLock()
 	defer bsm.statistics.mutex.RUnlock()
-	return *bsm.statistics
+	return BatchSyncStats{
+		batchUpdatesSent:     bsm.statistics.batchUpdatesSent,
+		immediateUpdatesSent: bsm.statistics.immediateUpdatesSent,
+		totalPacketsSent:     bsm.statistics.totalPacketsSent,
+		batchesProcessed:     bsm.statistics.batchesProcessed,
+		averageLatency:       bsm.statistics.averageLatency,
+	}
 }
 
 // PrintStatistics 打印统计信息
@@ -360,8 +366,40 @@ type World struct {
 	updateInterval      time.Duration            // 更新间隔
 	maxPacketsPerUpdate int                      // 每次更新最大数据包数
 	batchSyncManager    *BatchSyncManager        // 批量同步管理器
+	grid                *GridMap                 // 单位空间索引
+	losChecker          LineOfSightChecker       // 视线检查，未设置时视为没有阻挡
+	liquidChecker       LiquidStatusChecker      // 液体检查，未设置时视为不在液体中
+	walkabilityChecker  WalkabilityChecker       // 可行走检查，未设置时视为可行走
+	pathGenerator       PathGenerator            // 寻路，未设置时走直线
+	groups              map[uint64]*Group        // 所有队伍
+	groupMutex          sync.Mutex               // 队伍锁，队伍操作在会话更新中进行，不能使用世界锁
+	instanceSaves       *InstanceSaveManager     // 副本存档和锁定
+	dungeons            []*Dungeon               // 副本实例，由世界更新驱动
+	nextDungeonSlot     uint32                   // 下一个副本实例在世界地图上的位置
+	dungeonMutex        sync.Mutex
+	duelPlayers         map[uint64]*Player // 有决斗请求或正在决斗的玩家
+	duelMutex           sync.Mutex
+
+	pendingPowerUpdates map[pendingPowerKey]*pendingPowerUpdate // 本次更新内待广播的能量变化
+	powerMutex          sync.Mutex
+}
+
+// pendingPowerKey 待广播能量变化的键
+type pendingPowerKey struct {
+	guid      uint64
+	powerType uint8
 }
 
+// pendingPowerUpdate 合并后的能量变化
+type pendingPowerUpdate struct {
+	unit     IUnit
+	oldPower uint32
+	newPower uint32
+}
+
+// LineOfSightChecker 视线检查 - 对应AzerothCore中基于vmap的Map::isInLineOfSight
+type LineOfSightChecker func(x1, y1, z1, x2, y2, z2 float32) bool
+
 func NewWorld() *World {
 	world := &World{
 		units:               make(map[uint64]IUnit),
@@ -372,6 +410,11 @@ func NewWorld() *World {
 		lastUpdateTime:      time.Now(),
 		updateInterval:      200 * time.Millisecond, // 200ms更新间隔
 		maxPacketsPerUpdate: 150,                    // AzerothCore的限制
+		grid:                NewGridMap(),
+		pendingPowerUpdates: make(map[pendingPowerKey]*pendingPowerUpdate),
+		groups:              make(map[uint64]*Group),
+		duelPlayers:         make(map[uint64]*Player),
+		instanceSaves:       NewInstanceSaveManager(),
 	}
 
 	// 初始化批量同步管理器
@@ -393,15 +436,26 @@ func (w *World) AddUnit(unit IUnit) {
 	}
 
 	w.units[unit.GetGUID()] = unit
+	w.grid.AddUnit(unit)
 	fmt.Printf("单位 %s 加入世界 (GUID: %d)\n", unit.GetName(), unit.GetGUID())
 }
 
+// GenerateGUID 分配新的GUID - 用于不加入世界单位列表的对象，如决斗旗帜
+func (w *World) GenerateGUID() uint64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	guid := w.nextGUID
+	w.nextGUID++
+	return guid
+}
+
 func (w *World) RemoveUnit(guid uint64) {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 
 	if unit, exists := w.units[guid]; exists {
 		delete(w.units, guid)
+		w.grid.RemoveUnit(guid)
 		fmt.Printf("单位 %s 离开世界\n", unit.GetName())
 	}
 }
@@ -433,6 +487,30 @@ func (w *World) GetSession(sessionId uint32) *WorldSession {
 	return w.sessions[sessionId]
 }
 
+// GetSessionByPlayerGUID 根据角色GUID查找会话
+func (w *World) GetSessionByPlayerGUID(guid uint64) *WorldSession {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	for _, session := range w.sessions {
+		if player := session.GetPlayer(); player != nil && player.GetGUID() == guid {
+			return session
+		}
+	}
+	return nil
+}
+
+// GetSessionByPlayerName 根据角色名查找会话
+func (w *World) GetSessionByPlayerName(name string) *WorldSession {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	for _, session := range w.sessions {
+		if player := session.GetPlayer(); player != nil && player.GetName() == name {
+			return session
+		}
+	}
+	return nil
+}
+
 // GetSessionCount 获取会话数量
 func (w *World) GetSessionCount() int {
 	w.mutex.RLock()
@@ -607,6 +685,24 @@ func (w *World) Update(diff uint32) {
 	}
 	w.mutex.RUnlock()
 
+	// 同步队伍成员状态
+	w.updateGroups(diff)
+
+	// 决斗倒计时和区域检查
+	w.updateDuels(diff)
+
+	// 重置到期的副本实例
+	w.instanceSaves.Update(currentTime)
+
+	// 更新副本实例中的玩家和生物
+	w.updateDungeons(diff)
+
+	// 更新单位所在单元格
+	w.relocateUnits()
+
+	// 发送本次更新内合并的能量变化
+	w.flushPowerUpdates()
+
 	// 定期广播状态更新
 	w.broadcastPeriodicUpdates(diff)
 
@@ -614,6 +710,47 @@ func (w *World) Update(diff uint32) {
 	w.cleanupDeadUnits()
 }
 
+// relocateUnits 每次世界更新时把移动过的单位放入新的单元格
+func (w *World) relocateUnits() {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	for _, unit := range w.units {
+		w.grid.Relocate(unit)
+	}
+}
+
+// GetUnitsInRadius 通过空间索引查找范围内的单位
+func (w *World) GetUnitsInRadius(x, y, z, radius float32) []IUnit {
+	return w.grid.GetUnitsInRadius(x, y, z, radius)
+}
+
+// SetLineOfSightChecker 设置视线检查
+func (w *World) SetLineOfSightChecker(checker LineOfSightChecker) {
+	w.losChecker = checker
+}
+
+// IsInLineOfSight 检查两点之间是否没有阻挡
+func (w *World) IsInLineOfSight(x1, y1, z1, x2, y2, z2 float32) bool {
+	if w.losChecker == nil {
+		return true
+	}
+	return w.losChecker(x1, y1, z1, x2, y2, z2)
+}
+
+// BroadcastEnvironmentalDamage 广播环境伤害日志 - 基于AzerothCore的SMSG_ENVIRONMENTALDAMAGELOG
+func (w *World) BroadcastEnvironmentalDamage(unit IUnit, damageType uint8, damage, resist uint32) {
+	packet := NewWorldPacket(SMSG_ENVIRONMENTALDAMAGELOG)
+	packet.WriteUint64(unit.GetGUID())
+	packet.WriteUint8(damageType)
+	packet.WriteUint32(damage)
+	packet.WriteUint32(0) // 吸收
+	packet.WriteUint32(resist)
+	packet.SetPriority(0)
+
+	x, y, z := unit.GetPosition()
+	w.BroadcastToPlayersInRange(x, y, z, 100.0, packet)
+}
+
 // BroadcastToPlayersInRange 向范围内的玩家广播（选择性更新）
 func (w *World) BroadcastToPlayersInRange(centerX, centerY, centerZ float32, rangeDist float32, packet *WorldPacket) {
 	players := w.GetPlayersInRange(centerX, centerY, centerZ, rangeDist)
@@ -674,8 +811,8 @@ func (w *World) BroadcastSpellStart(caster IUnit, spellId uint32, targets []IUni
 	fmt.Printf("[批量同步] 法术开始: %s 施放 %s (范围: %d玩家)\n", caster.GetName(), spellName, len(players))
 }
 
-// BroadcastSpellGo 批量广播法术生效
-func (w *World) BroadcastSpellGo(caster IUnit, spellId uint32, targets []IUnit) {
+// BroadcastSpellGo 批量广播法术生效 - 命中目标和未命中目标(附带SPELL_MISS_*)分开列出
+func (w *World) BroadcastSpellGo(caster IUnit, spellId uint32, targets []IUnit, misses []SpellMissTarget) {
 	// 只向范围内的玩家广播
 	casterX, casterY, casterZ := caster.GetPosition()
 	players := w.GetPlayersInRange(casterX, casterY, casterZ, 100.0) // 100码范围
@@ -692,6 +829,11 @@ func (w *World) BroadcastSpellGo(caster IUnit, spellId uint32, targets []IUnit)
 			packet.WriteUint64(0)
 		}
 	}
+	packet.WriteUint32(uint32(len(misses)))
+	for _, miss := range misses {
+		packet.WriteUint64(miss.target.GetGUID())
+		packet.WriteUint8(miss.missInfo)
+	}
 
 	// 收集目标会话ID
 	var sessionTargets []uint32
@@ -714,6 +856,67 @@ func (w *World) BroadcastSpellGo(caster IUnit, spellId uint32, targets []IUnit)
 	fmt.Printf("[批量同步] 法术生效: %s 的 %s 生效 (范围: %d玩家)\n", caster.GetName(), spellName, len(players))
 }
 
+// SendCastResult 通知施法者施法失败 - 只发送给施法者自己的会话
+func (w *World) SendCastResult(caster IUnit, spellId uint32, result uint8) {
+	if session := w.GetSessionByPlayerGUID(caster.GetGUID()); session != nil {
+		session.SendSpellFailure(caster, spellId, result)
+	}
+}
+
+// SendSpellCooldowns 通知施法者冷却变化 - 只发送给施法者自己的会话
+func (w *World) SendSpellCooldowns(caster IUnit, cooldowns []SpellCooldownEntry) {
+	if session := w.GetSessionByPlayerGUID(caster.GetGUID()); session != nil {
+		session.SendSpellCooldown(caster, cooldowns)
+	}
+}
+
+// BroadcastSpellFailure 广播法术被打断 - 基于AzerothCore的Spell::SendInterrupted
+func (w *World) BroadcastSpellFailure(caster IUnit, spellId uint32, result uint8) {
+	packet := NewWorldPacket(SMSG_SPELL_FAILURE)
+	packet.WriteUint64(caster.GetGUID())
+	packet.WriteUint32(spellId)
+	packet.WriteUint8(result)
+	packet.SetPriority(0) // 打断需要立即同步，客户端据此中止施法条
+
+	x, y, z := caster.GetPosition()
+	w.BroadcastToPlayersInRange(x, y, z, 100.0, packet)
+}
+
+// BroadcastSpellDelayed 广播施法推迟 - 基于AzerothCore的SMSG_SPELL_DELAYED
+func (w *World) BroadcastSpellDelayed(caster IUnit, delay time.Duration) {
+	packet := NewWorldPacket(SMSG_SPELL_DELAYED)
+	packet.WriteUint64(caster.GetGUID())
+	packet.WriteUint32(uint32(delay.Milliseconds()))
+
+	x, y, z := caster.GetPosition()
+	w.BroadcastToPlayersInRange(x, y, z, 100.0, packet)
+}
+
+// BroadcastSpellMiss 广播法术未命中 - 基于AzerothCore的Unit::SendSpellMiss
+// 弹道命中时客户端按SMSG_SPELLGO中的速度播放飞行效果，由伤害日志结算，只有未命中需要单独通知
+func (w *World) BroadcastSpellMiss(caster, target IUnit, spellId uint32, missInfo uint8) {
+	packet := NewWorldPacket(SMSG_SPELLLOGMISS)
+	packet.WriteUint32(spellId)
+	packet.WriteUint64(caster.GetGUID())
+	packet.WriteUint8(0)
+	packet.WriteUint32(1) // 目标数量
+	packet.WriteUint64(target.GetGUID())
+	packet.WriteUint8(missInfo)
+
+	x, y, z := target.GetPosition()
+	w.BroadcastToPlayersInRange(x, y, z, 100.0, packet)
+}
+
+// BroadcastChannelUpdate 广播引导剩余时间 - 基于AzerothCore的MSG_CHANNEL_UPDATE
+func (w *World) BroadcastChannelUpdate(caster IUnit, remaining time.Duration) {
+	packet := NewWorldPacket(MSG_CHANNEL_UPDATE)
+	packet.WriteUint64(caster.GetGUID())
+	packet.WriteUint32(uint32(remaining.Milliseconds()))
+
+	x, y, z := caster.GetPosition()
+	w.BroadcastToPlayersInRange(x, y, z, 100.0, packet)
+}
+
 // BroadcastHealthUpdate 批量广播血量更新
 func (w *World) BroadcastHealthUpdate(unit IUnit, oldHealth, newHealth uint32) {
 	// 只向范围内的玩家广播
@@ -749,8 +952,36 @@ func (w *World) BroadcastHealthUpdate(unit IUnit, oldHealth, newHealth uint32) {
 		unit.GetName(), oldHealth, newHealth, unit.GetMaxHealth(), len(players), healthChangePercent)
 }
 
-// BroadcastPowerUpdate 批量广播能量更新
+// BroadcastPowerUpdate 记录能量变化 - 同一单位同一能量类型在一次世界更新内只广播一次
+// 恢复、消耗等频繁变化合并为从首次旧值到最新值的一条更新
 func (w *World) BroadcastPowerUpdate(unit IUnit, powerType uint8, oldPower, newPower uint32) {
+	w.powerMutex.Lock()
+	defer w.powerMutex.Unlock()
+
+	key := pendingPowerKey{guid: unit.GetGUID(), powerType: powerType}
+	if pending, exists := w.pendingPowerUpdates[key]; exists {
+		pending.newPower = newPower
+		return
+	}
+	w.pendingPowerUpdates[key] = &pendingPowerUpdate{unit: unit, oldPower: oldPower, newPower: newPower}
+}
+
+// flushPowerUpdates 每次世界更新时发送合并后的能量更新
+func (w *World) flushPowerUpdates() {
+	w.powerMutex.Lock()
+	pending := w.pendingPowerUpdates
+	w.pendingPowerUpdates = make(map[pendingPowerKey]*pendingPowerUpdate)
+	w.powerMutex.Unlock()
+
+	for key, update := range pending {
+		if update.oldPower != update.newPower {
+			w.sendPowerUpdate(update.unit, key.powerType, update.oldPower, update.newPower)
+		}
+	}
+}
+
+// sendPowerUpdate 批量广播能量更新
+func (w *World) sendPowerUpdate(unit IUnit, powerType uint8, oldPower, newPower uint32) {
 	// 只向范围内的玩家广播
 	unitX, unitY, unitZ := unit.GetPosition()
 	players := w.GetPlayersInRange(unitX, unitY, unitZ, 100.0) // 100码范围
@@ -921,6 +1152,78 @@ func (w *World) BroadcastUnitUpdateWithPriority(unit IUnit, priority uint8, upda
 		unit.GetName(), len(players), priorityName, updateId)
 }
 
+// writeThreatList 写入仇恨列表 - 客户端仇恨值以100倍整数表示
+func writeThreatList(packet *WorldPacket, threatList []*ThreatInfo) {
+	packet.WriteUint32(uint32(len(threatList)))
+	for _, info := range threatList {
+		packet.WriteUint64(info.unit.GetGUID())
+		packet.WriteUint32(uint32(info.threat * 100))
+	}
+}
+
+// BroadcastThreatUpdate 广播仇恨表 - 基于AzerothCore的SMSG_THREAT_UPDATE，供客户端仇恨统计使用
+func (w *World) BroadcastThreatUpdate(owner IUnit, threatList []*ThreatInfo) {
+	packet := NewWorldPacket(SMSG_THREAT_UPDATE)
+	packet.WriteUint64(owner.GetGUID())
+	writeThreatList(packet, threatList)
+
+	x, y, z := owner.GetPosition()
+	w.BroadcastToPlayersInRange(x, y, z, 100.0, packet)
+}
+
+// BroadcastHighestThreatUpdate 广播最高仇恨目标变化 - 基于AzerothCore的SMSG_HIGHEST_THREAT_UPDATE
+func (w *World) BroadcastHighestThreatUpdate(owner, newHighest IUnit, threatList []*ThreatInfo) {
+	packet := NewWorldPacket(SMSG_HIGHEST_THREAT_UPDATE)
+	packet.WriteUint64(owner.GetGUID())
+	packet.WriteUint64(newHighest.GetGUID())
+	writeThreatList(packet, threatList)
+	packet.SetPriority(0) // 目标切换需要立即同步
+
+	x, y, z := owner.GetPosition()
+	w.BroadcastToPlayersInRange(x, y, z, 100.0, packet)
+}
+
+// BroadcastThreatRemove 广播仇恨条目移除 - 基于AzerothCore的SMSG_THREAT_REMOVE
+func (w *World) BroadcastThreatRemove(owner, removed IUnit) {
+	packet := NewWorldPacket(SMSG_THREAT_REMOVE)
+	packet.WriteUint64(owner.GetGUID())
+	packet.WriteUint64(removed.GetGUID())
+
+	x, y, z := owner.GetPosition()
+	w.BroadcastToPlayersInRange(x, y, z, 100.0, packet)
+}
+
+// BroadcastThreatClear 广播仇恨表清空 - 基于AzerothCore的SMSG_THREAT_CLEAR
+func (w *World) BroadcastThreatClear(owner IUnit) {
+	packet := NewWorldPacket(SMSG_THREAT_CLEAR)
+	packet.WriteUint64(owner.GetGUID())
+
+	x, y, z := owner.GetPosition()
+	w.BroadcastToPlayersInRange(x, y, z, 100.0, packet)
+}
+
+// BroadcastAuraUpdate 广播光环变化 - 基于AzerothCore的SMSG_AURA_UPDATE
+func (w *World) BroadcastAuraUpdate(target IUnit, aura *Aura, removed bool) {
+	packet := NewWorldPacket(SMSG_AURA_UPDATE)
+	packet.WriteUint64(target.GetGUID())
+	packet.WriteUint32(aura.id)
+	if removed {
+		packet.WriteUint8(1)
+	} else {
+		packet.WriteUint8(0)
+	}
+	packet.WriteUint32(aura.duration)
+	packet.WriteUint32(aura.maxDuration)
+	if aura.caster != nil {
+		packet.WriteUint64(aura.caster.GetGUID())
+	} else {
+		packet.WriteUint64(0)
+	}
+
+	x, y, z := target.GetPosition()
+	w.BroadcastToPlayersInRange(x, y, z, 100.0, packet)
+}
+
 // 获取能量类型名称
 func getPowerTypeName(powerType uint8) string {
 	switch powerType {
@@ -1017,30 +1320,6 @@ func (w *World) GetAliveUnits() []IUnit {
 
 // 法术广播方法 - 基于AzerothCore的法术网络同步
 
-// 光环系统（简化版）
-type AuraType int
-
-const (
-	AURA_MOD_DAMAGE_DONE = iota
-	AURA_MOD_DAMAGE_TAKEN
-	AURA_MOD_HEALING_DONE
-	AURA_MOD_HEALING_TAKEN
-	AURA_MOD_ATTACK_SPEED
-	AURA_MOD_CAST_SPEED
-	AURA_MOD_STAT
-	AURA_PERIODIC_DAMAGE
-	AURA_PERIODIC_HEAL
-)
-
-type Aura struct {
-	id       uint32
-	caster   IUnit
-	target   IUnit
-	duration uint32
-	auraType AuraType
-	value    int32
-}
-
 // 战斗日志系统
 type CombatLog struct {
 	entries []CombatLogEntry